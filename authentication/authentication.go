@@ -8,7 +8,8 @@ import (
 	"github.com/dgrijalva/jwt-go"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
 )
 
 var (
@@ -17,15 +18,8 @@ var (
 var secretKey = []byte(os.Getenv("JWT_SECRET_KEY"))
 
 func InitializeProductionLogger() {
-
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("AuthenticationLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("AuthenticationLog.json")
 }
 
 func init() {
@@ -0,0 +1,172 @@
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+
+	"go.uber.org/zap"
+)
+
+var (
+	Logger *zap.Logger
+)
+
+func InitializeProductionLogger() {
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("BackfillLog.json")
+}
+
+func init() {
+	InitializeProductionLogger()
+}
+
+// Package consists of API, that Fills the Newly Added Database Columns of the Existing Rows
+// With the Data, Fetched from the vSphere
+
+const VMFieldsCheckpoint = "vm_fields" // Name of the Checkpoint, used by the `BackfillVMFields`
+
+type BackfillReport struct {
+	StartedFromID int            `json:"StartedFromID" xml:"StartedFromID"` // ID, the Backfill has been Resumed From
+	LastProcessed int            `json:"LastProcessed" xml:"LastProcessed"` // ID of the Last Processed Virtual Machine
+	Processed     int            `json:"Processed" xml:"Processed"`         // Amount of the Processed Virtual Machines
+	Updated       int            `json:"Updated" xml:"Updated"`             // Amount of the Updated Virtual Machines
+	Failed        int            `json:"Failed" xml:"Failed"`               // Amount of the Failed Virtual Machines
+	Errors        map[int]string `json:"Errors" xml:"-"`                    // Errors by the Virtual Machine ID
+}
+
+func BackfillVMFields(Context context.Context, Client vim25.Client, BatchSize int, Rate rate.Limit) (*BackfillReport, error) {
+	// Fills the Empty `State`, `UUID`, `HardwareVersion` and `CreatedAt` Fields of the Virtual Machines
+	// Virtual Machines are being Processed in Batches by ID, vSphere API Calls are Limited by the `Rate`
+	// Progress is being Saved after every Batch, so the Backfill Continues from the Last Processed ID after Restart
+
+	if BatchSize <= 0 {
+		return nil, fmt.Errorf("Batch Size should be Positive, got %d", BatchSize)
+	}
+
+	Checkpoint, CheckpointError := models.GetBackfillCheckpoint(VMFieldsCheckpoint)
+	if CheckpointError != nil {
+		Logger.Error("Failed to Load Backfill Checkpoint", zap.Error(CheckpointError))
+		return nil, CheckpointError
+	}
+
+	Report := &BackfillReport{
+		StartedFromID: Checkpoint.LastProcessedID,
+		LastProcessed: Checkpoint.LastProcessedID,
+		Errors:        map[int]string{},
+	}
+	Limiter := rate.NewLimiter(Rate, 1)
+	Finder := find.NewFinder(&Client)
+	Collector := property.DefaultCollector(&Client)
+
+	for {
+		var VirtualMachines []models.VirtualMachine
+		if Gorm := models.Database.Model(&models.VirtualMachine{}).Select(
+			"id", "item_path", "state", "uuid", "hardware_version", "created_at").Where("id > ?", Report.LastProcessed).Order(
+			"id").Limit(BatchSize).Find(&VirtualMachines); Gorm.Error != nil {
+			Logger.Error("Failed to Load Virtual Machines Batch", zap.Error(Gorm.Error))
+			return Report, Gorm.Error
+		}
+		if len(VirtualMachines) == 0 {
+			break
+		}
+
+		for _, VirtualMachine := range VirtualMachines {
+			if WaitError := Limiter.Wait(Context); WaitError != nil {
+				return Report, WaitError
+			}
+			Updated, BackfillError := backfillVirtualMachine(Context, Finder, Collector, VirtualMachine)
+			Report.Processed++
+			Report.LastProcessed = VirtualMachine.ID
+
+			switch {
+			case BackfillError != nil:
+				Report.Failed++
+				Report.Errors[VirtualMachine.ID] = BackfillError.Error()
+				Logger.Error("Failed to Backfill Virtual Machine",
+					zap.Int("Virtual Machine ID", VirtualMachine.ID), zap.Error(BackfillError))
+			case Updated:
+				Report.Updated++
+			}
+		}
+
+		Checkpoint.LastProcessedID = Report.LastProcessed
+		if _, SaveError := Checkpoint.Save(); SaveError != nil {
+			Logger.Error("Failed to Save Backfill Checkpoint", zap.Error(SaveError))
+			return Report, SaveError
+		}
+		Logger.Info("Backfill Batch has been Processed",
+			zap.Int("Last Processed ID", Report.LastProcessed), zap.Int("Processed", Report.Processed),
+			zap.Int("Updated", Report.Updated), zap.Int("Failed", Report.Failed))
+	}
+
+	// The Whole Table has been Processed, so the Next Backfill should start from the Beginning
+	Checkpoint.LastProcessedID = 0
+	if _, SaveError := Checkpoint.Save(); SaveError != nil {
+		return Report, SaveError
+	}
+	return Report, nil
+}
+
+func backfillVirtualMachine(Context context.Context, Finder *find.Finder, Collector *property.Collector, VirtualMachine models.VirtualMachine) (bool, error) {
+	// Fetches Properties of the Virtual Machine and Updates its Empty Fields, Returns True if anything has been Updated
+
+	Object, FindError := Finder.VirtualMachine(Context, VirtualMachine.ItemPath)
+	if FindError != nil {
+		return false, FindError
+	}
+
+	var MoVirtualMachine mo.VirtualMachine
+	if RetrieveError := Collector.RetrieveOne(Context, Object.Reference(),
+		[]string{"runtime.powerState", "config.uuid", "config.version", "config.createDate"}, &MoVirtualMachine); RetrieveError != nil {
+		return false, RetrieveError
+	}
+
+	Updates := map[string]interface{}{}
+	var CreatedAt *time.Time
+	if len(VirtualMachine.State) == 0 {
+		if MoVirtualMachine.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+			Updates["state"] = models.StatusReady
+		} else {
+			Updates["state"] = models.StatusNotReady
+		}
+	}
+	if MoVirtualMachine.Config != nil {
+		if len(VirtualMachine.UUID) == 0 && len(MoVirtualMachine.Config.Uuid) != 0 {
+			Updates["uuid"] = MoVirtualMachine.Config.Uuid
+		}
+		if len(VirtualMachine.HardwareVersion) == 0 && len(MoVirtualMachine.Config.Version) != 0 {
+			Updates["hardware_version"] = MoVirtualMachine.Config.Version
+		}
+		if VirtualMachine.CreatedAt.IsZero() && MoVirtualMachine.Config.CreateDate != nil {
+			CreatedAt = MoVirtualMachine.Config.CreateDate
+		}
+	}
+	if len(Updates) == 0 && CreatedAt == nil {
+		return false, nil
+	}
+
+	TransactionError := models.Database.WithContext(Context).Transaction(func(Transaction *gorm.DB) error {
+		if len(Updates) != 0 {
+			if Updated := Transaction.Model(&models.VirtualMachine{}).Where("id = ?", VirtualMachine.ID).Updates(Updates); Updated.Error != nil {
+				return Updated.Error
+			}
+		}
+		if CreatedAt != nil {
+			// `CreatedAt` is Writable only on the Create (`<-:create`), so gorm Drops it from the Updates
+			return Transaction.Exec("UPDATE virtual_machines SET created_at = ? WHERE id = ?", *CreatedAt, VirtualMachine.ID).Error
+		}
+		return nil
+	})
+	return TransactionError == nil, TransactionError
+}
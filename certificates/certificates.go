@@ -0,0 +1,206 @@
+package certificates
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"go.uber.org/zap"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
+)
+
+var (
+	Logger *zap.Logger
+)
+
+func InitializeProductionLogger() {
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("CertificatesLog.json")
+}
+
+func init() {
+	InitializeProductionLogger()
+}
+
+// Package consists of API, that Reads SSL Certificates of the ESXi Hosts and vCenter,
+// And Represents them in the Format, the API Clients (Frontend) can Consume
+
+const (
+	// Statuses of the Certificate, Extending the vSphere ones (`good`, `expiring`, `expired`, `revoked`, etc...)
+	StatusMissing     = "missing"     // Certificate is not Available at all
+	StatusUnparseable = "unparseable" // Certificate Info has no Usable Data
+	StatusUntrusted   = "untrusted"   // Certificate has been Received, but Failed the Verification
+)
+
+const ExpiringThreshold = time.Hour * 24 * 30 // Valid Certificate is Considered `expiring` within this Period
+
+type CertificateSummary struct {
+	// Serializable Representation of the SSL Certificate
+	Subject     string     `json:"Subject" xml:"Subject"`
+	Issuer      string     `json:"Issuer" xml:"Issuer"`
+	NotBefore   *time.Time `json:"NotBefore,omitempty" xml:"NotBefore,omitempty"`
+	NotAfter    *time.Time `json:"NotAfter,omitempty" xml:"NotAfter,omitempty"`
+	Fingerprint string     `json:"Fingerprint,omitempty" xml:"Fingerprint,omitempty"` // SHA-256 if the Certificate is Available, SHA-1 Otherwise
+	Status      string     `json:"Status" xml:"Status"`
+}
+
+func ToCertificateSummary(Info *object.HostCertificateInfo) (*CertificateSummary, error) {
+	// Converts govmomi Certificate Info into the Summary,
+	// Missing or Unparseable Certificate is Reported within the `Status`, instead of the Error
+
+	if Info == nil {
+		return &CertificateSummary{Status: StatusMissing}, nil
+	}
+
+	Summary := &CertificateSummary{
+		Subject:   Info.Subject,
+		Issuer:    Info.Issuer,
+		NotBefore: Info.NotBefore,
+		NotAfter:  Info.NotAfter,
+		Status:    Info.Status,
+	}
+
+	if Certificate := Info.Certificate; Certificate != nil {
+		NotBefore, NotAfter := Certificate.NotBefore, Certificate.NotAfter
+		Summary.Subject = Certificate.Subject.String()
+		Summary.Issuer = Certificate.Issuer.String()
+		Summary.NotBefore, Summary.NotAfter = &NotBefore, &NotAfter
+		Summary.Fingerprint = fingerprint(Certificate.Raw)
+	}
+	if len(Summary.Fingerprint) == 0 {
+		Summary.Fingerprint = firstNonEmpty(Info.ThumbprintSHA256, Info.ThumbprintSHA1)
+	}
+
+	switch {
+	case len(Summary.Subject) == 0 && Summary.NotAfter == nil:
+		Summary.Status = StatusUnparseable
+	case Info.Err != nil:
+		Summary.Status = StatusUntrusted
+	case len(Summary.Status) == 0 || Summary.Status == string(types.HostCertificateManagerCertificateInfoCertificateStatusUnknown):
+		Summary.Status = validityStatus(Summary.NotBefore, Summary.NotAfter)
+	}
+	return Summary, nil
+}
+
+func validityStatus(NotBefore *time.Time, NotAfter *time.Time) string {
+	// Returns vSphere Status of the Certificate, based on its Validity Period
+	Now := time.Now()
+	switch {
+	case NotAfter == nil:
+		return string(types.HostCertificateManagerCertificateInfoCertificateStatusUnknown)
+	case Now.After(*NotAfter):
+		return string(types.HostCertificateManagerCertificateInfoCertificateStatusExpired)
+	case NotBefore != nil && Now.Before(*NotBefore):
+		return string(types.HostCertificateManagerCertificateInfoCertificateStatusUnknown)
+	case NotAfter.Sub(Now) < ExpiringThreshold:
+		return string(types.HostCertificateManagerCertificateInfoCertificateStatusExpiring)
+	default:
+		return string(types.HostCertificateManagerCertificateInfoCertificateStatusGood)
+	}
+}
+
+func fingerprint(Raw []byte) string {
+	// Returns SHA-256 Fingerprint of the Certificate in the `AA:BB:...` Format
+	Sum := sha256.Sum256(Raw)
+	Hex := make([]string, len(Sum))
+	for Index, Byte := range Sum {
+		Hex[Index] = fmt.Sprintf("%02X", Byte)
+	}
+	return strings.Join(Hex, ":")
+}
+
+func firstNonEmpty(Values ...string) string {
+	for _, Value := range Values {
+		if len(Value) != 0 {
+			return Value
+		}
+	}
+	return ""
+}
+
+type CertificateManager struct {
+	Client vim25.Client
+}
+
+func NewCertificateManager(Client vim25.Client) *CertificateManager {
+	return &CertificateManager{
+		Client: Client,
+	}
+}
+
+func (this *CertificateManager) GetHostCertificate(HostPath string) (*CertificateSummary, error) {
+	// Returns Summary of the SSL Certificate of the ESXi Host, Error is Returned only if the Host can't be Reached
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*30)
+	defer CancelFunc()
+
+	Finder := find.NewFinder(&this.Client)
+	if Datacenter, DatacenterError := Finder.DefaultDatacenter(TimeoutContext); DatacenterError == nil {
+		Finder.SetDatacenter(Datacenter)
+	}
+	Host, FindError := Finder.HostSystemOrDefault(TimeoutContext, HostPath)
+	if FindError != nil {
+		Logger.Error("Failed to Find Host", zap.String("Host", HostPath), zap.Error(FindError))
+		return nil, FindError
+	}
+
+	Manager, ManagerError := Host.ConfigManager().CertificateManager(TimeoutContext)
+	if ManagerError != nil {
+		Logger.Error("Host has no Certificate Manager", zap.String("Host", HostPath), zap.Error(ManagerError))
+		return ToCertificateSummary(nil)
+	}
+	Info, InfoError := Manager.CertificateInfo(TimeoutContext)
+	if InfoError != nil {
+		Logger.Error("Failed to Retrieve Host Certificate", zap.String("Host", HostPath), zap.Error(InfoError))
+		return ToCertificateSummary(nil)
+	}
+	return ToCertificateSummary(Info)
+}
+
+func GetServerCertificate(ServerURL string) (*CertificateSummary, error) {
+	// Returns Summary of the SSL Certificate, Presented by the Server (e.g vCenter),
+	// Error is Returned only if the URL is Invalid, Unreachable Server Results in the `missing` Status
+	Parsed, ParseError := url.Parse(ServerURL)
+	if ParseError != nil {
+		return nil, ParseError
+	}
+	Address := Parsed.Host
+	if len(Parsed.Port()) == 0 {
+		Address += ":443"
+	}
+
+	// Certificate is being Verified Separately, so the Untrusted one is still Retrieved and Summarized
+	Connection, DialError := tls.DialWithDialer(&net.Dialer{Timeout: time.Second * 10},
+		"tcp", Address, &tls.Config{InsecureSkipVerify: true})
+	if DialError != nil {
+		Logger.Error("Failed to Retrieve Server Certificate", zap.String("URL", ServerURL), zap.Error(DialError))
+		return ToCertificateSummary(nil)
+	}
+	PeerCertificates := Connection.ConnectionState().PeerCertificates
+	Connection.Close()
+	if len(PeerCertificates) == 0 {
+		return ToCertificateSummary(nil)
+	}
+
+	Intermediates := x509.NewCertPool()
+	for _, Intermediate := range PeerCertificates[1:] {
+		Intermediates.AddCert(Intermediate)
+	}
+	Info := (&object.HostCertificateInfo{}).FromCertificate(PeerCertificates[0])
+	if _, VerifyError := PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName: Parsed.Hostname(), Intermediates: Intermediates}); VerifyError != nil {
+		Info.Err = VerifyError
+	}
+	return ToCertificateSummary(Info)
+}
@@ -0,0 +1,175 @@
+package certificates
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/operations"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"go.uber.org/zap"
+)
+
+// Installing the Certificate is not always Effective Immediately:
+// `InstallServerCertificate` Succeeds, but `hostd` keeps Serving the Old Certificate until it Reloads it.
+// The Reload is being Triggered by the `NotifyAffectedServices` call (See `InstallOptions.RefreshServices`),
+// Which is Asynchronous, so the Verification right after the Install can Fail Transiently.
+// The Delay depends on the Load of the Host, so it is Configurable by the `InstallOptions.VerificationWindow`.
+//
+// Supported ESXi Versions:
+// ESXi 6.0 and newer - the Host Certificate Manager (`configManager.certificateManager`) and `NotifyAffectedServices`
+// Are Available, the Certificate becomes Effective after the Reload, within the Verification Window
+// ESXi 5.5 and older - the Host has no Certificate Manager, the Install Fails with the Error of the Manager Lookup,
+// The Certificate has to be Replaced Manually and `hostd` Restarted (`/etc/init.d/hostd restart`)
+// Hosts, that Reject `NotifyAffectedServices` (`RefreshServices` is Disabled), keep the Old Certificate until `hostd` is Restarted
+
+const DefaultVerificationInterval = time.Second * 5
+
+var (
+	ErrInvalidCertificate      = errors.New("Certificate is not a Valid PEM Encoded X.509 Certificate")
+	ErrCertificateNotEffective = errors.New("Installed Certificate has not become Effective on the Host")
+)
+
+type InstallOptions struct {
+	// Options of the Certificate Installation
+	RefreshServices      bool          // Notifies the Host Services to Reload the Certificate right after the Install
+	VerificationWindow   time.Duration // Period the Installed Certificate is Expected to become Effective within, 0 Verifies only once
+	VerificationInterval time.Duration // Delay between the Verification Attempts
+}
+
+func NewInstallOptions() InstallOptions {
+	return InstallOptions{
+		RefreshServices:      true,
+		VerificationInterval: DefaultVerificationInterval,
+	}
+}
+
+// Returns Certificate Info, the Host currently Serves
+type CertificateFetcher func(Context context.Context) (*object.HostCertificateInfo, error)
+
+func (this *CertificateManager) InstallHostCertificate(HostPath string, CertificatePEM string, Options InstallOptions) (*CertificateSummary, error) {
+	// Installs (Rotates) the SSL Certificate of the ESXi Host and Verifies, that the Host Serves it,
+	// Returns Summary of the Effective Certificate
+
+	if _, ParseError := ParseCertificate(CertificatePEM); ParseError != nil {
+		return nil, ParseError
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1+Options.VerificationWindow)
+	defer CancelFunc()
+
+	Finder := find.NewFinder(&this.Client)
+	if Datacenter, DatacenterError := Finder.DefaultDatacenter(TimeoutContext); DatacenterError == nil {
+		Finder.SetDatacenter(Datacenter)
+	}
+	Host, FindError := Finder.HostSystemOrDefault(TimeoutContext, HostPath)
+	if FindError != nil {
+		Logger.Error("Failed to Find Host", zap.String("Host", HostPath), zap.Error(FindError))
+		return nil, FindError
+	}
+	Manager, ManagerError := Host.ConfigManager().CertificateManager(TimeoutContext)
+	if ManagerError != nil {
+		Logger.Error("Host has no Certificate Manager", zap.String("Host", HostPath), zap.Error(ManagerError))
+		return nil, ManagerError
+	}
+
+	defer operations.Track(operations.OperationCertificateRotation, HostPath)()
+	return InstallCertificate(TimeoutContext, Manager, CertificatePEM, Options)
+}
+
+func InstallCertificate(Context context.Context, Manager *object.HostCertificateManager, CertificatePEM string, Options InstallOptions) (*CertificateSummary, error) {
+	// Installs the SSL Certificate with the Certificate Manager of the Host and Verifies, that the Host Serves it,
+	// Returns Summary of the Effective Certificate, the Context has to Outlive the `Options.VerificationWindow`
+
+	Certificate, ParseError := ParseCertificate(CertificatePEM)
+	if ParseError != nil {
+		return nil, ParseError
+	}
+
+	var InstallError error
+	if Options.RefreshServices {
+		// govmomi Notifies the Affected Services right after the Install
+		InstallError = Manager.InstallServerCertificate(Context, CertificatePEM)
+	} else {
+		_, InstallError = methods.InstallServerCertificate(Context, Manager.Client(),
+			&types.InstallServerCertificate{This: Manager.Reference(), Cert: CertificatePEM})
+	}
+	if InstallError != nil {
+		Logger.Error("Failed to Install Host Certificate", zap.String("Manager", Manager.Reference().Value), zap.Error(InstallError))
+		return nil, InstallError
+	}
+
+	Summary, VerifyError := VerifyCertificate(Context, Manager.CertificateInfo, Certificate, Options)
+	if VerifyError != nil {
+		Logger.Error("Installed Certificate is not Effective", zap.String("Manager", Manager.Reference().Value), zap.Error(VerifyError))
+		return Summary, VerifyError
+	}
+	Logger.Debug("Host Certificate has been Installed", zap.String("Manager", Manager.Reference().Value), zap.String("Fingerprint", Summary.Fingerprint))
+	return Summary, nil
+}
+
+func VerifyCertificate(Context context.Context, Fetch CertificateFetcher, Expected *x509.Certificate, Options InstallOptions) (*CertificateSummary, error) {
+	// Checks, that the Host Serves the Expected Certificate, Retrying within the Verification Window,
+	// Returns Summary of the Certificate, the Host Served at the Last Attempt
+
+	Interval := Options.VerificationInterval
+	if Interval <= 0 {
+		Interval = DefaultVerificationInterval
+	}
+	Deadline := time.Now().Add(Options.VerificationWindow)
+
+	for {
+		Info, FetchError := Fetch(Context)
+		if FetchError == nil && sameCertificate(Info, Expected) {
+			return ToCertificateSummary((&object.HostCertificateInfo{}).FromCertificate(Expected))
+		}
+
+		if !time.Now().Add(Interval).Before(Deadline) {
+			Summary, _ := ToCertificateSummary(Info)
+			if FetchError != nil {
+				return Summary, fmt.Errorf("%w: %s", ErrCertificateNotEffective, FetchError)
+			}
+			return Summary, ErrCertificateNotEffective
+		}
+		select {
+		case <-Context.Done():
+			return nil, fmt.Errorf("%w: %s", ErrCertificateNotEffective, Context.Err())
+		case <-time.After(Interval):
+		}
+	}
+}
+
+func ParseCertificate(CertificatePEM string) (*x509.Certificate, error) {
+	// Returns X.509 Certificate, Decoded from the PEM
+	Block, _ := pem.Decode([]byte(CertificatePEM))
+	if Block == nil || Block.Type != "CERTIFICATE" {
+		return nil, ErrInvalidCertificate
+	}
+	Certificate, ParseError := x509.ParseCertificate(Block.Bytes)
+	if ParseError != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCertificate, ParseError)
+	}
+	return Certificate, nil
+}
+
+func sameCertificate(Info *object.HostCertificateInfo, Expected *x509.Certificate) bool {
+	// Returns True if the Certificate Info Describes the Expected Certificate,
+	// vSphere does not Return the Certificate itself, so the Subject, Issuer and Validity Period are Compared
+	if Info == nil {
+		return false
+	}
+	if Info.Certificate != nil {
+		return Info.Certificate.Equal(Expected)
+	}
+	Described := (&object.HostCertificateInfo{}).FromCertificate(Expected)
+	return Info.NotBefore != nil && Info.NotAfter != nil &&
+		Info.Subject == Described.Subject && Info.Issuer == Described.Issuer &&
+		Info.NotBefore.Equal(*Described.NotBefore) && Info.NotAfter.Equal(*Described.NotAfter)
+}
@@ -1,10 +1,12 @@
 package rest
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 
@@ -12,16 +14,36 @@ import (
 	"time"
 
 	"github.com/LovePelmeni/Infrastructure/authentication"
+	"github.com/LovePelmeni/Infrastructure/deploy"
+	"github.com/LovePelmeni/Infrastructure/logging"
 	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/tracing"
+	"github.com/vmware/govmomi"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+var (
+	APIIp    = os.Getenv("VMWARE_SOURCE_IP")
+	Username = os.Getenv("VMWARE_SOURCE_USERNAME")
+	Password = os.Getenv("VMWARE_SOURCE_PASSWORD")
+
+	APIUrl = &url.URL{
+		Scheme: "https",
+		Path:   "/sdk/",
+		Host:   APIIp,
+		User:   url.UserPassword(Username, Password),
+	}
+)
+
+var (
+	Client *govmomi.Client // Used to Destroy the Virtual Machines of the Customer on the Forced Deletion
+)
+
 var (
 	Customer models.Customer
 )
@@ -31,19 +53,23 @@ var (
 )
 
 func InitializeProductionLogger() {
-
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("RestCustomerLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("RestCustomerLog.json")
 }
 
 func init() {
 	InitializeProductionLogger()
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer CancelFunc()
+
+	APIClient, ConnectionError := govmomi.NewClient(TimeoutContext, APIUrl, false)
+	if ConnectionError != nil {
+		Logger.Error("FAILED TO INITIALIZE CLIENT, DOES THE VMWARE HYPERVISOR ACTUALLY RUNNING?")
+		return
+	}
+	tracing.InstrumentClient(APIClient.Client)
+	Client = APIClient
 }
 
 // Authorization Rest API Endpoints
@@ -56,11 +82,16 @@ func LoginRestController(RequestContext *gin.Context) {
 
 	var Customer models.Customer
 	customer := models.Database.Model(&models.Customer{}).Where(
-		"username = ?", Username).Find(&Customer)
+		"username = ?", Username).First(&Customer)
 
-	if customer.Error != nil {
-		RequestContext.JSON(http.StatusBadRequest,
-			gin.H{"Error": "No User With This Username Exists :("})
+	if FindError := models.TranslateNotFound(customer.Error); FindError != nil {
+		if errors.Is(FindError, models.ErrNotFound) {
+			RequestContext.JSON(http.StatusBadRequest,
+				gin.H{"Error": "No User With This Username Exists :("})
+			return
+		}
+		Logger.Error("Failed to Retrieve Customer", zap.Error(FindError))
+		RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": "Login Error"})
 		return
 	}
 
@@ -108,20 +139,31 @@ func CreateCustomerRestController(RequestContext *gin.Context) {
 
 	// Checking If Customer is Already Exists...
 	var Customer models.Customer
-	if Transact := models.Database.Model(
-		&models.Customer{}).Where("username = ? OR email = ?",
-		Username, Email).Find(&Customer); &Transact.Error == nil || len(Customer.Username) != 0 {
+	Transact := models.Database.Model(
+		&models.Customer{}).Where("username = ? OR LOWER(email) = ?",
+		Username, models.NormalizeEmail(Email)).First(&Customer)
+
+	switch FindError := models.TranslateNotFound(Transact.Error); {
+	case FindError == nil:
 		RequestContext.AbortWithStatusJSON(
 			http.StatusBadRequest,
 			gin.H{"Error": "Customer with this Username or Email already exists, Wanna Login?"})
 		return
+	case !errors.Is(FindError, models.ErrNotFound):
+		Logger.Error("Failed to Check whether the Customer Exists", zap.Error(FindError))
+		RequestContext.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": "Failed to Create Customer"})
+		return
 	}
 
-	NewCustomer := models.NewCustomer(Username, Password, Email)
-	NewCustomer.BillAddress = BillingAddress
-	NewCustomer.Country = Country
-	NewCustomer.ZipCode = ZipCode
-	NewCustomer.Street = Street
+	NewCustomer, ValidationError := models.NewCustomer(Username, Password, Email, BillingAddress, Country, ZipCode, Street)
+	if ValidationError != nil {
+		if errors.Is(ValidationError, models.ErrWeakPassword) || errors.Is(ValidationError, models.ErrInvalidEmail) {
+			RequestContext.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": ValidationError.Error()})
+			return
+		}
+		RequestContext.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": "Failed to Create Customer"})
+		return
+	}
 
 	Created, Error := NewCustomer.Create()
 
@@ -184,26 +226,24 @@ func ResetPasswordRestController(RequestContext *gin.Context) {
 	// Rest Controller, Responsible for Resetting Password
 
 	NewPassword := RequestContext.PostForm("NewPassword")
-	CustomerId := RequestContext.PostForm("CustomerId")
-
-	NewPasswordHash, GenerateError := bcrypt.GenerateFromPassword([]byte(NewPassword), 14)
-	if GenerateError != nil {
-		RequestContext.JSON(http.StatusBadRequest,
-			gin.H{"Error": "Failed to Apply new Password"})
+	CustomerId, ParseError := strconv.Atoi(RequestContext.PostForm("CustomerId"))
+	if ParseError != nil {
+		RequestContext.JSON(http.StatusBadRequest, gin.H{"Error": "Invalid Customer ID"})
+		return
 	}
-	Updated := models.Database.Model(&models.Customer{}).Where(
-		"id = ?", CustomerId).Update("Password", NewPasswordHash)
 
-	if Updated.Error != nil {
-		Updated.Rollback()
+	UpdateError := (&models.Customer{ID: CustomerId}).UpdatePassword(NewPassword)
+	switch {
+	case errors.Is(UpdateError, models.ErrWeakPassword):
+		RequestContext.JSON(http.StatusBadRequest, gin.H{"Error": UpdateError.Error()})
+	case errors.Is(UpdateError, models.ErrNotFound):
+		RequestContext.JSON(http.StatusNotFound, gin.H{"Error": "Customer Does Not Exist"})
+	case UpdateError != nil:
 		RequestContext.JSON(
 			http.StatusBadGateway, gin.H{"Error": "Oops, Failed to Apply New Password"})
-		return
+	default:
+		RequestContext.JSON(http.StatusCreated, gin.H{"Status": "Applied"})
 	}
-
-	Updated.Unscoped().Update("Password", NewPasswordHash)
-	RequestContext.JSON(http.StatusCreated, gin.H{"Status": "Applied"})
-
 }
 
 func DeleteCustomerRestController(RequestContext *gin.Context) {
@@ -212,7 +252,27 @@ func DeleteCustomerRestController(RequestContext *gin.Context) {
 	token := RequestContext.Request.Header.Get("Authorization")
 	Credentials, _ := authentication.GetCustomerJwtCredentials(token)
 
-	Deleted, Error := Customer.Delete(Credentials.UserId)
+	// Customer, who still Owns Virtual Machines, can be Deleted only with the `force=true`,
+	// In that case the Virtual Machines are Destroyed in vSphere (along with their Records) before the Profile is Deleted,
+	// so no Machine is Left Running without the Owner. If any of them can't be Destroyed, the Profile is Kept
+	if Force, _ := strconv.ParseBool(RequestContext.Query("force")); Force {
+		if DestroyError := destroyCustomerVirtualMachines(Credentials.UserId); DestroyError != nil {
+			Logger.Error("Failed to Destroy Virtual Machines of the Customer", zap.Int("User ID", Credentials.UserId), zap.Error(DestroyError))
+			RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": DestroyError.Error()})
+			return
+		}
+	}
+	// Profile itself is Deleted only if no Virtual Machine is Left (e.g Created in the Meantime), even with the Force
+	Deleted, Error := Customer.Delete(Credentials.UserId, false)
+
+	if errors.Is(Error, models.ErrCustomerHasResources) {
+		RequestContext.JSON(http.StatusConflict, gin.H{"Error": Error.Error()})
+		return
+	}
+	if errors.Is(Error, models.ErrNotFound) {
+		RequestContext.JSON(http.StatusNotFound, gin.H{"Error": "Profile with this Credentials Does Not Exist"})
+		return
+	}
 
 	switch Error {
 
@@ -245,6 +305,33 @@ func DeleteCustomerRestController(RequestContext *gin.Context) {
 	}
 }
 
+func destroyCustomerVirtualMachines(CustomerID int) error {
+	// Destroys every Virtual Machine of the Customer in vSphere and Deletes their Records (See `deploy.DeleteVirtualMachinesMany`)
+	VirtualMachines, ListError := models.GetVirtualMachinesByOwner(strconv.Itoa(CustomerID))
+	if ListError != nil {
+		return ListError
+	}
+	if len(VirtualMachines) == 0 {
+		return nil
+	}
+	if Client == nil {
+		return errors.New("Virtual Machines can't be Destroyed, vSphere is not Available, Profile has not been Deleted")
+	}
+
+	VirtualMachineIDs := []string{}
+	for _, VirtualMachine := range VirtualMachines {
+		VirtualMachineIDs = append(VirtualMachineIDs, strconv.Itoa(VirtualMachine.ID))
+	}
+	Report, DeleteError := deploy.DeleteVirtualMachinesMany(*Client.Client, VirtualMachineIDs, false)
+	if DeleteError != nil {
+		return DeleteError
+	}
+	if Report.Failed != 0 {
+		return fmt.Errorf("Failed to Destroy %d of %d Virtual Machine(s), Profile has not been Deleted", Report.Failed, len(VirtualMachineIDs))
+	}
+	return nil
+}
+
 func GetCustomerProfileRestController(RequestContext *gin.Context) {
 	// Returns Customer's Profile, based on the Jwt token passed
 	Token := RequestContext.GetHeader("Authorization")
@@ -258,14 +345,18 @@ func GetCustomerProfileRestController(RequestContext *gin.Context) {
 		return
 	}
 
-	if Customer := models.Database.Model(&models.Customer{}).Where("id = ?", JwtCredentials.UserId,
-		JwtCredentials.Username, JwtCredentials.Email).Find(&Customer); Customer.Error != nil {
+	var Customer models.Customer
+	Gorm := models.Database.Model(&models.Customer{}).Where("id = ?", JwtCredentials.UserId).First(&Customer)
+
+	switch FindError := models.TranslateNotFound(Gorm.Error); {
+	case errors.Is(FindError, models.ErrNotFound):
 		RequestContext.JSON(
 			http.StatusBadRequest, gin.H{"Error": "No Such Profile has been Found"})
-		return
-	} else {
+	case FindError != nil:
+		Logger.Error("Failed to Retrieve Customer Profile", zap.Error(FindError))
+		RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": "Failed to Retrieve Profile"})
+	default:
 		RequestContext.JSON(http.StatusOK, gin.H{"Profile": Customer})
-		return
 	}
 }
 
@@ -0,0 +1,150 @@
+package datacenter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"go.uber.org/zap"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
+)
+
+var (
+	Logger *zap.Logger
+)
+
+func InitializeProductionLogger() {
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("DatacenterLog.json")
+}
+
+func init() {
+	InitializeProductionLogger()
+}
+
+// Package consists of API, that Resolves Datacenters by their Names once and Caches them,
+// So the Operations do not Re-Resolve the Datacenter Context every Time they need the Finder
+
+const DefaultDatacenterName = "" // Resolves the Default Datacenter (Only one Datacenter should Exist)
+
+var (
+	cachesMutex sync.Mutex
+	caches      = map[string]*datacenterCache{} // Shared Caches, Keyed by the vCenter URL
+)
+
+type cachedDatacenter struct {
+	Reference     types.ManagedObjectReference
+	InventoryPath string
+}
+
+type datacenterCache struct {
+	mutex       sync.Mutex
+	datacenters map[string]cachedDatacenter
+}
+
+func newDatacenterCache() *datacenterCache {
+	return &datacenterCache{datacenters: map[string]cachedDatacenter{}}
+}
+
+type DatacenterResolver struct {
+	// Resolves and Caches Datacenters of the Single vCenter, Keyed by the Name
+	// Only the References are being Cached, so the Resolved Datacenter always uses the Client of the Resolver
+	Client vim25.Client
+	cache  *datacenterCache
+}
+
+func NewDatacenterResolver(Client vim25.Client) *DatacenterResolver {
+	// Returns Resolver with its own Cache
+	return &DatacenterResolver{
+		Client: Client,
+		cache:  newDatacenterCache(),
+	}
+}
+
+func GetDatacenterResolver(Client vim25.Client) *DatacenterResolver {
+	// Returns Resolver, that Shares the Cache with every other Operation against the same vCenter
+	cachesMutex.Lock()
+	defer cachesMutex.Unlock()
+
+	Key := Client.URL().String()
+	Cache, Exists := caches[Key]
+	if !Exists {
+		Cache = newDatacenterCache()
+		caches[Key] = Cache
+	}
+	return &DatacenterResolver{Client: Client, cache: Cache}
+}
+
+func (this *DatacenterResolver) Resolve(Context context.Context, Name string) (*object.Datacenter, error) {
+	// Returns Datacenter by its Name (Or Inventory Path), `DefaultDatacenterName` Resolves the Default one
+	// Datacenter is being Looked up only once, the next Calls are Served from the Cache
+
+	this.cache.mutex.Lock()
+	Cached, Exists := this.cache.datacenters[Name]
+	this.cache.mutex.Unlock()
+	if Exists {
+		Datacenter := object.NewDatacenter(&this.Client, Cached.Reference)
+		Datacenter.InventoryPath = Cached.InventoryPath
+		return Datacenter, nil
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(Context, time.Second*10)
+	defer CancelFunc()
+
+	var Datacenter *object.Datacenter
+	var FindError error
+	Finder := find.NewFinder(&this.Client)
+	if Name == DefaultDatacenterName {
+		Datacenter, FindError = Finder.DefaultDatacenter(TimeoutContext)
+	} else {
+		Datacenter, FindError = Finder.Datacenter(TimeoutContext, Name)
+	}
+	if FindError != nil {
+		Logger.Error("Failed to Resolve Datacenter", zap.String("Datacenter", Name), zap.Error(FindError))
+		return nil, FindError
+	}
+
+	this.cache.mutex.Lock()
+	defer this.cache.mutex.Unlock()
+	this.cache.datacenters[Name] = cachedDatacenter{Reference: Datacenter.Reference(), InventoryPath: Datacenter.InventoryPath}
+	return Datacenter, nil
+}
+
+func (this *DatacenterResolver) NewFinder(Context context.Context, Name string) (*find.Finder, error) {
+	// Returns Finder, that Searches within the Datacenter
+	Datacenter, ResolveError := this.Resolve(Context, Name)
+	if ResolveError != nil {
+		return nil, ResolveError
+	}
+	Finder := find.NewFinder(&this.Client)
+	Finder.SetDatacenter(Datacenter)
+	return Finder, nil
+}
+
+func (this *DatacenterResolver) IsCached(Name string) bool {
+	// Returns True if the Datacenter is Served from the Cache
+	this.cache.mutex.Lock()
+	defer this.cache.mutex.Unlock()
+	_, Exists := this.cache.datacenters[Name]
+	return Exists
+}
+
+func (this *DatacenterResolver) Invalidate(Name string) {
+	// Removes the Datacenter from the Cache (e.g after it has been Renamed or Removed), so it is Resolved again
+	this.cache.mutex.Lock()
+	defer this.cache.mutex.Unlock()
+	delete(this.cache.datacenters, Name)
+}
+
+func (this *DatacenterResolver) InvalidateAll() {
+	// Removes every Datacenter from the Cache
+	this.cache.mutex.Lock()
+	defer this.cache.mutex.Unlock()
+	this.cache.datacenters = map[string]cachedDatacenter{}
+}
@@ -0,0 +1,91 @@
+package datacenter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrVirtualMachineNotFound  = errors.New("Virtual Machine has not been Found")
+	ErrAmbiguousVirtualMachine = errors.New("More than one Virtual Machine Matches")
+)
+
+func FindVirtualMachineByName(Context context.Context, Client *vim25.Client, Datacenter string, Name string) (*object.VirtualMachine, error) {
+	// Returns Virtual Machine with the Name from any Folder of the Datacenter (`DefaultDatacenterName` for the Default one)
+	// Names are Unique only within the Folder, so `ErrAmbiguousVirtualMachine` is Returned, if several Folders have one
+
+	ResolvedDatacenter, ResolveError := GetDatacenterResolver(*Client).Resolve(Context, Datacenter)
+	if ResolveError != nil {
+		return nil, ResolveError
+	}
+	Folders, FoldersError := ResolvedDatacenter.Folders(Context)
+	if FoldersError != nil {
+		return nil, FoldersError
+	}
+
+	ContainerView, ViewError := view.NewManager(Client).CreateContainerView(Context,
+		Folders.VmFolder.Reference(), []string{"VirtualMachine"}, true)
+	if ViewError != nil {
+		return nil, ViewError
+	}
+	defer ContainerView.Destroy(context.Background())
+
+	// Names are Compared Exactly, so the Patterns of the Property Filter (like `*`) are not being Interpreted
+	var MoVirtualMachines []mo.VirtualMachine
+	if RetrieveError := ContainerView.Retrieve(Context, []string{"VirtualMachine"},
+		[]string{"name"}, &MoVirtualMachines); RetrieveError != nil {
+		Logger.Error("Failed to Retrieve Virtual Machines", zap.String("Datacenter", Datacenter), zap.Error(RetrieveError))
+		return nil, RetrieveError
+	}
+
+	var Found []mo.VirtualMachine
+	for _, MoVirtualMachine := range MoVirtualMachines {
+		if MoVirtualMachine.Name == Name {
+			Found = append(Found, MoVirtualMachine)
+		}
+	}
+	switch len(Found) {
+	case 0:
+		return nil, fmt.Errorf("%w: `%s`", ErrVirtualMachineNotFound, Name)
+	case 1:
+		VirtualMachine := object.NewVirtualMachine(Client, Found[0].Self)
+		if InventoryPath, PathError := find.InventoryPath(Context, Client, Found[0].Self); PathError == nil {
+			VirtualMachine.InventoryPath = InventoryPath
+		}
+		return VirtualMachine, nil
+	default:
+		return nil, fmt.Errorf("%w the Name `%s` (%d Found)", ErrAmbiguousVirtualMachine, Name, len(Found))
+	}
+}
+
+func FindVirtualMachineByPath(Context context.Context, Client *vim25.Client, Datacenter string, Path string) (*object.VirtualMachine, error) {
+	// Returns Virtual Machine by its Inventory Path (Absolute or Relative to the VM Folder of the Datacenter)
+	// `ErrAmbiguousVirtualMachine` is Returned, if the Path is a Pattern, that Matches several Virtual Machines
+
+	Finder, FinderError := GetDatacenterResolver(*Client).NewFinder(Context, Datacenter)
+	if FinderError != nil {
+		return nil, FinderError
+	}
+	VirtualMachine, FindError := Finder.VirtualMachine(Context, Path)
+	if FindError != nil {
+		var NotFound *find.NotFoundError
+		var MultipleFound *find.MultipleFoundError
+		switch {
+		case errors.As(FindError, &NotFound):
+			return nil, fmt.Errorf("%w: `%s`", ErrVirtualMachineNotFound, Path)
+		case errors.As(FindError, &MultipleFound):
+			return nil, fmt.Errorf("%w the Path `%s`", ErrAmbiguousVirtualMachine, Path)
+		}
+		Logger.Error("Failed to Find Virtual Machine", zap.String("Path", Path), zap.Error(FindError))
+		return nil, FindError
+	}
+	return VirtualMachine, nil
+}
@@ -5,13 +5,12 @@ import (
 	"fmt"
 
 	"net/url"
-	"os"
 	"strings"
 
+	"github.com/LovePelmeni/Infrastructure/logging"
 	"github.com/LovePelmeni/Infrastructure/models"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/exp/maps"
@@ -23,15 +22,8 @@ var (
 )
 
 func InitializeProductionLogger() {
-
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("DependencyInstallerLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("DependencyInstallerLog.json")
 }
 
 func init() {
@@ -230,8 +222,11 @@ func (this *EnviromentDependencyInstaller) GetSshConnection(VirtualMachineId str
 	// Returns SSH Connection to the VM Server
 
 	var VirtualMachine models.VirtualMachine
-	models.Database.Model(&models.VirtualMachine{}).Where(
-		"id = ?", VirtualMachineId).Find(&VirtualMachine)
+	Gorm := models.Database.Model(&models.VirtualMachine{}).Where(
+		"id = ?", VirtualMachineId).First(&VirtualMachine)
+	if Gorm.Error != nil {
+		return nil, models.TranslateNotFound(Gorm.Error)
+	}
 
 	ClientConfig := &ssh.ClientConfig{
 		Timeout: 10,
@@ -0,0 +1,155 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+const BulkDeleteConcurrency = 5 // Max Amount of the Virtual Machines, being Deleted at the same time
+
+// Outcomes of the Bulk Deletion for the Single Virtual Machine
+const OutcomeDeleted = "Deleted"
+const OutcomeWouldDelete = "WouldDelete" // Dry Run
+const OutcomeFailed = "Failed"
+
+type BulkDeleteOutcome struct {
+	VirtualMachineID string `json:"VirtualMachineID" xml:"VirtualMachineID"`
+	Outcome          string `json:"Outcome" xml:"Outcome"`
+	RevokedKeys      int64  `json:"RevokedKeys" xml:"RevokedKeys"`
+	Error            string `json:"Error,omitempty" xml:"Error"`
+}
+
+type BulkDeleteReport struct {
+	DryRun   bool                `json:"DryRun" xml:"DryRun"`
+	Outcomes []BulkDeleteOutcome `json:"Outcomes" xml:"Outcomes"` // In the same Order as the Requested IDs
+	Deleted  int                 `json:"Deleted" xml:"Deleted"`
+	Failed   int                 `json:"Failed" xml:"Failed"`
+}
+
+func DeleteVirtualMachinesMany(Client vim25.Client, VirtualMachineIDs []string, DryRun bool) (*BulkDeleteReport, error) {
+	// Deletes many Virtual Machines at once: Revokes their SSH Keys, Destroys them in vSphere and Deletes the Database Rows
+	// Failure of the Single Virtual Machine does not Stop the others, it is being Reported in the Outcome
+	// If `DryRun` is True, only Checks, that the Virtual Machines Exist
+
+	if len(VirtualMachineIDs) == 0 {
+		return nil, errors.New("At least one Virtual Machine ID is Required")
+	}
+
+	Report := &BulkDeleteReport{DryRun: DryRun, Outcomes: make([]BulkDeleteOutcome, len(VirtualMachineIDs))}
+	Manager := NewVirtualMachineManager(Client)
+	Semaphore := make(chan struct{}, BulkDeleteConcurrency)
+	var Group sync.WaitGroup
+
+	for Index, VirtualMachineID := range VirtualMachineIDs {
+		Group.Add(1)
+		Semaphore <- struct{}{}
+		go func(Index int, VirtualMachineID string) {
+			defer Group.Done()
+			defer func() { <-Semaphore }()
+			Report.Outcomes[Index] = Manager.deleteVirtualMachine(VirtualMachineID, DryRun)
+		}(Index, VirtualMachineID)
+	}
+	Group.Wait()
+
+	for _, Outcome := range Report.Outcomes {
+		switch Outcome.Outcome {
+		case OutcomeFailed:
+			Report.Failed++
+		case OutcomeDeleted:
+			Report.Deleted++
+		}
+	}
+	Logger.Info("Bulk Deletion has been Finished", zap.Bool("Dry Run", DryRun),
+		zap.Int("Deleted", Report.Deleted), zap.Int("Failed", Report.Failed))
+	return Report, nil
+}
+
+func (this *VirtualMachineManager) deleteVirtualMachine(VirtualMachineID string, DryRun bool) BulkDeleteOutcome {
+	// Deletes Single Virtual Machine of the Bulk Deletion
+
+	Outcome := BulkDeleteOutcome{VirtualMachineID: VirtualMachineID, Outcome: OutcomeFailed}
+	Failed := func(Error error) BulkDeleteOutcome {
+		Logger.Error("Failed to Delete Virtual Machine", zap.String("Virtual Machine ID", VirtualMachineID), zap.Error(Error))
+		Outcome.Error = Error.Error()
+		return Outcome
+	}
+
+	Id, ConvertError := strconv.Atoi(VirtualMachineID)
+	if ConvertError != nil {
+		return Failed(errors.New("Invalid Virtual Machine ID"))
+	}
+	var VirtualMachineObj models.VirtualMachine
+	if Gorm := models.Database.Model(&models.VirtualMachine{}).Select("id", "item_path").Where(
+		"id = ?", Id).First(&VirtualMachineObj); Gorm.Error != nil {
+		return Failed(models.TranslateNotFound(Gorm.Error))
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer CancelFunc()
+
+	// Virtual Machine might be already Destroyed in vSphere, in that Case only Database Rows are being Deleted
+	Reference, FindError := object.NewSearchIndex(&this.VimClient).FindByInventoryPath(TimeoutContext, VirtualMachineObj.ItemPath)
+	if FindError != nil {
+		return Failed(FindError)
+	}
+	if DryRun {
+		Outcome.Outcome = OutcomeWouldDelete
+		return Outcome
+	}
+
+	RevokedKeys, RevokeError := models.RevokeSshKeys(Id)
+	if RevokeError != nil {
+		return Failed(RevokeError)
+	}
+	Outcome.RevokedKeys = RevokedKeys
+
+	if Reference != nil {
+		VirtualMachine := Reference.(*object.VirtualMachine)
+		if PowerError := this.ensurePoweredOff(VirtualMachine); PowerError != nil {
+			return Failed(PowerError)
+		}
+		if _, DestroyError := this.DestroyVirtualMachine(VirtualMachine); DestroyError != nil {
+			return Failed(DestroyError)
+		}
+	}
+
+	if DeleteError := models.DeleteVirtualMachineRecords(Id); DeleteError != nil {
+		return Failed(DeleteError)
+	}
+	models.RecordVMEvent(Id, models.EventDestroyed, "Virtual Machine has been Destroyed by the Bulk Deletion")
+	Outcome.Outcome = OutcomeDeleted
+	return Outcome
+}
+
+func (this *VirtualMachineManager) ensurePoweredOff(VirtualMachine *object.VirtualMachine) error {
+	// Powers Off the Virtual Machine, because Powered On Virtual Machine can't be Destroyed
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+	defer CancelFunc()
+
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	PowerState, StateError := VirtualMachine.PowerState(TimeoutContext)
+	if StateError != nil || PowerState == types.VirtualMachinePowerStatePoweredOff {
+		return StateError
+	}
+	PowerOffTask, PowerOffError := VirtualMachine.PowerOff(TimeoutContext)
+	if PowerOffError != nil {
+		return PowerOffError
+	}
+	return PowerOffTask.Wait(TimeoutContext)
+}
@@ -6,17 +6,19 @@ import (
 
 	"errors"
 
-	"os"
 	"time"
 
+	"github.com/LovePelmeni/Infrastructure/drs"
 	"github.com/LovePelmeni/Infrastructure/exceptions"
+	"github.com/LovePelmeni/Infrastructure/logging"
 	"github.com/LovePelmeni/Infrastructure/parsers"
 	"github.com/LovePelmeni/Infrastructure/ssh_config"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
 
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/property"
@@ -38,18 +40,8 @@ var (
 )
 
 func InitializeProductionLogger() {
-
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, Error := os.OpenFile("DeployLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if Error != nil {
-		panic(Error)
-	}
-
-	logWriter := zapcore.AddSync(file)
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("DeployLog.json")
 }
 
 func init() {
@@ -191,14 +183,15 @@ func (this *VirtualMachineManager) GetVirtualMachine(VmId string, CustomerId str
 
 	var VirtualMachineObj models.VirtualMachine
 
-	VirtualMachineGormRefError := models.Database.Model(
+	VirtualMachineGormRef := models.Database.Model(
 		&models.VirtualMachine{}).Where(
 		"owner_id = ? AND id = ?",
-		CustomerId, VmId).Find(&VirtualMachineObj)
+		CustomerId, VmId).First(&VirtualMachineObj)
 
-	if VirtualMachineGormRefError != nil {
+	if VirtualMachineGormRef.Error != nil {
 		Logger.Error("Failed to Find Virtual Machine",
-			zap.String("Virtual Machine ID", VmId), zap.String("Customer ID", CustomerId))
+			zap.String("Virtual Machine ID", VmId), zap.String("Customer ID", CustomerId),
+			zap.Error(VirtualMachineGormRef.Error))
 		return nil, exceptions.ItemDoesNotExist()
 	}
 
@@ -463,6 +456,15 @@ func (this *VirtualMachineManager) ApplyConfiguration(VirtualMachine *object.Vir
 	ConfigureTimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*5)
 	defer CancelFunc()
 
+	// Virtual Machine is Locked, until the Reconfiguration and the Customizations are Completed,
+	// the Lock is Released before the SSH Setup and the Destroy, as they Lock the Virtual Machine themselves
+	Release, LockError := vm_lock.Acquire(ConfigureTimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		Logger.Error("Failed to Lock Virtual Machine for the Configuration", zap.Error(LockError))
+		return nil, LockError
+	}
+	defer Release()
+
 	// Applying Configurations to the VM Server
 
 	ConfigureTask, ConfiguredError := object.NewReference(&this.VimClient, vm.Reference()).(*object.VirtualMachine).Reconfigure(ConfigureTimeoutContext, defaults)
@@ -474,6 +476,7 @@ func (this *VirtualMachineManager) ApplyConfiguration(VirtualMachine *object.Vir
 	if ConfiguredError != nil {
 		// If Failing To Apply First Configuration, Destroying Virtual Machine
 		Logger.Error("Failed to Configure Virtual Machine, Error has Occurred", zap.Error(ConfiguredError))
+		Release()
 		_, Error := this.DestroyVirtualMachine(VirtualMachine)
 		return nil, Error
 	}
@@ -508,6 +511,7 @@ func (this *VirtualMachineManager) ApplyConfiguration(VirtualMachine *object.Vir
 		Logger.Error("Failed to Apply Network Configuration", zap.Error(WaitNetworkCustomizationError))
 		return nil, WaitNetworkCustomizationError
 	}
+	Release()
 
 	NewVirtualMachine := object.NewVirtualMachine(&this.VimClient, vm.Reference())
 
@@ -569,89 +573,120 @@ func (this *VirtualMachineManager) ApplyConfiguration(VirtualMachine *object.Vir
 	}, nil
 }
 
-func (this *VirtualMachineManager) StartVirtualMachine(VirtualMachine *object.VirtualMachine) error {
+func (this *VirtualMachineManager) StartVirtualMachine(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) error {
 
 	// Starts Virtual Machine Server..
 
-	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	Operation := options.NewOperationOptions(time.Second*10, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
 	defer CancelFunc()
 
-	Newtask, DeployError := VirtualMachine.PowerOn(TimeoutContext)
-	AppliedError := Newtask.Wait(TimeoutContext)
+	Release, LockError := Operation.LockVirtualMachine(TimeoutContext, VirtualMachine.Reference())
+	if LockError != nil {
+		Logger.Error("Virtual Machine is Busy", zap.String("ItemPath", VirtualMachine.InventoryPath), zap.Error(LockError))
+		return LockError
+	}
+	defer Release()
 
-	switch {
-	case DeployError != nil || AppliedError != nil:
+	// Powering On through the DRS, so the Virtual Machines on the Manual DRS Clusters get Placed as well
+	PowerOnManager := drs.NewVirtualMachinePowerOnManager(this.VimClient)
+	StartError := Operation.Retry(TimeoutContext, func() error {
+		_, DeployError := PowerOnManager.PowerOn(TimeoutContext, VirtualMachine)
+		return DeployError
+	})
+
+	if StartError != nil {
 		Logger.Error("Failed to Start Virtual Machine",
-			zap.Errors("Deployment and Configuration Apply Errors", []error{DeployError, AppliedError}))
+			zap.String("ItemPath", VirtualMachine.InventoryPath), zap.Error(StartError))
 		return exceptions.VMDeployFailure()
-
-	case DeployError == nil && AppliedError == nil:
-		Logger.Debug("Virtual Machine has been Started Successfully",
-			zap.String("ItemPath", VirtualMachine.InventoryPath))
-		return nil
-	default:
-		return nil
 	}
+	Logger.Debug("Virtual Machine has been Started Successfully",
+		zap.String("ItemPath", VirtualMachine.InventoryPath))
+	return nil
 }
 
-func (this *VirtualMachineManager) RebootVirtualMachine(VirtualMachine *object.VirtualMachine) bool {
-	// Rebooting Virtual Machine Server and Operational System within this VM
+func (this *VirtualMachineManager) RebootVirtualMachine(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) error {
+	// Rebooting Virtual Machine Server and Operational System within this VM, Returns Error if it has not been Rebooted
 
-	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	Operation := options.NewOperationOptions(time.Second*10, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
 	defer CancelFunc()
 
-	RebootError := VirtualMachine.RebootGuest(TimeoutContext)
+	Release, LockError := Operation.LockVirtualMachine(TimeoutContext, VirtualMachine.Reference())
+	if LockError != nil {
+		Logger.Error("Virtual Machine is Busy", zap.String("ItemPath", VirtualMachine.InventoryPath), zap.Error(LockError))
+		return LockError
+	}
+	defer Release()
+
+	RebootError := Operation.Retry(TimeoutContext, func() error {
+		return VirtualMachine.RebootGuest(TimeoutContext)
+	})
 	if RebootError != nil {
-		Logger.Error("Failed to Reboot Guest OS, on VM: %s",
-			zap.Error(RebootError))
-		return true
-	} else {
-		return false
+		Logger.Error("Failed to Reboot Guest OS",
+			zap.String("ItemPath", VirtualMachine.InventoryPath), zap.Error(RebootError))
+		return RebootError
 	}
+	return nil
 }
 
-func (this *VirtualMachineManager) ShutdownVirtualMachine(VirtualMachine *object.VirtualMachine) error {
+func (this *VirtualMachineManager) ShutdownVirtualMachine(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) error {
 	// Shutting Down Virtual Machine Server...
 
-	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	Operation := options.NewOperationOptions(time.Second*10, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
 	defer CancelFunc()
 
-	Newtask, DeployError := VirtualMachine.PowerOff(TimeoutContext)
-	AppliedError := Newtask.Wait(TimeoutContext)
+	Release, LockError := Operation.LockVirtualMachine(TimeoutContext, VirtualMachine.Reference())
+	if LockError != nil {
+		Logger.Error("Virtual Machine is Busy", zap.String("ItemPath", VirtualMachine.InventoryPath), zap.Error(LockError))
+		return LockError
+	}
+	defer Release()
 
-	switch {
-	case DeployError != nil || AppliedError != nil:
-		Logger.Error("Failed to Shutdown Virtual Machine, with ItemPath: %s Errors: [%s, %s]",
-			zap.String("ItemPath", VirtualMachine.InventoryPath),
-			zap.NamedError("DeployError", DeployError),
-			zap.NamedError("ApplyError", AppliedError))
-		return exceptions.VMShutdownFailure()
+	ShutdownError := Operation.Retry(TimeoutContext, func() error {
+		Newtask, DeployError := VirtualMachine.PowerOff(TimeoutContext)
+		if DeployError != nil {
+			return DeployError
+		}
+		return Newtask.Wait(TimeoutContext)
+	})
 
-	case DeployError == nil && AppliedError == nil:
-		Logger.Debug("Virtual Machine has been Shutdown.",
-			zap.String("ItemPath", VirtualMachine.InventoryPath))
-		return nil
-	default:
-		Logger.Error("Unknown State has been Occurred, while Shutting Down Virtual Machine",
-			zap.String("ItemPath", VirtualMachine.InventoryPath))
-		return nil
+	if ShutdownError != nil {
+		Logger.Error("Failed to Shutdown Virtual Machine",
+			zap.String("ItemPath", VirtualMachine.InventoryPath), zap.Error(ShutdownError))
+		return exceptions.VMShutdownFailure()
 	}
+	Logger.Debug("Virtual Machine has been Shutdown.",
+		zap.String("ItemPath", VirtualMachine.InventoryPath))
+	return nil
 }
 
-func (this *VirtualMachineManager) DestroyVirtualMachine(VirtualMachine *object.VirtualMachine) (bool, error) {
+func (this *VirtualMachineManager) DestroyVirtualMachine(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) (bool, error) {
 	// Destroys Virtual Machine, Customer Decided to get rid of...
 
-	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+	Operation := options.NewOperationOptions(time.Minute*1, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
 	defer CancelFunc()
 
-	DestroyTask, DestroyError := VirtualMachine.Destroy(TimeoutContext)
-	TaskError := DestroyTask.Wait(TimeoutContext)
+	Release, LockError := Operation.LockVirtualMachine(TimeoutContext, VirtualMachine.Reference())
+	if LockError != nil {
+		Logger.Error("Virtual Machine is Busy", zap.String("ItemPath", VirtualMachine.InventoryPath), zap.Error(LockError))
+		return false, LockError
+	}
+	defer Release()
+
+	DestroyError := Operation.Retry(TimeoutContext, func() error {
+		DestroyTask, DestroyError := VirtualMachine.Destroy(TimeoutContext)
+		if DestroyError != nil {
+			return DestroyError
+		}
+		return DestroyTask.Wait(TimeoutContext)
+	})
 
-	if DestroyError != nil || TaskError != nil {
+	if DestroyError != nil {
 		Logger.Error("Failed to Destroy Virtual Machine",
-			zap.String("ItemPath", VirtualMachine.InventoryPath),
-			zap.NamedError("DestroyError", DestroyError),
-			zap.NamedError("TaskError", TaskError))
+			zap.String("ItemPath", VirtualMachine.InventoryPath), zap.Error(DestroyError))
 		return false, exceptions.DestroyFailure()
 	}
 	Logger.Info("Virtual Machine has been Destroyed",
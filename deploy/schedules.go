@@ -0,0 +1,82 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"go.uber.org/zap"
+)
+
+func RunPowerSchedules(Context context.Context, Client vim25.Client) error {
+	// Applies every Power Schedule, that is Due, Outcome of the Run is being Saved on the Schedule
+	// Meant to be Called Periodically (Every Minute at least, because it is the Cron Resolution)
+
+	Now := time.Now()
+	Schedules, ScheduleError := models.GetDuePowerSchedules(Now)
+	if ScheduleError != nil {
+		Logger.Error("Failed to Load Due Power Schedules", zap.Error(ScheduleError))
+		return ScheduleError
+	}
+
+	Manager := NewVirtualMachineManager(Client)
+	for _, Schedule := range Schedules {
+		if Context.Err() != nil {
+			return Context.Err()
+		}
+		Schedule := Schedule
+
+		Outcome := models.ScheduleOutcomeSucceeded
+		if RunError := Manager.applyPowerSchedule(Context, Schedule); RunError != nil {
+			Logger.Error("Failed to Apply Power Schedule", zap.Int("Schedule ID", Schedule.ID),
+				zap.Int("Virtual Machine ID", Schedule.VirtualMachineID), zap.Error(RunError))
+			Outcome = fmt.Sprintf("%s: %s", models.ScheduleOutcomeFailed, RunError)
+		}
+		if RecordError := Schedule.RecordRun(Now, Outcome); RecordError != nil {
+			Logger.Error("Failed to Record Power Schedule Run", zap.Int("Schedule ID", Schedule.ID), zap.Error(RecordError))
+		}
+	}
+	return nil
+}
+
+func (this *VirtualMachineManager) applyPowerSchedule(Context context.Context, Schedule models.PowerSchedule) error {
+	// Applies Power Operation of the Schedule to the Virtual Machine, the Operation is Cancelled along with the Context
+
+	var VirtualMachineObj models.VirtualMachine
+	if Gorm := models.Database.WithContext(Context).Model(&models.VirtualMachine{}).Select("id", "item_path").Where(
+		"id = ?", Schedule.VirtualMachineID).First(&VirtualMachineObj); Gorm.Error != nil {
+		return models.TranslateNotFound(Gorm.Error)
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(Context, time.Second*10)
+	defer CancelFunc()
+
+	Reference, FindError := object.NewSearchIndex(&this.VimClient).FindByInventoryPath(TimeoutContext, VirtualMachineObj.ItemPath)
+	if FindError != nil {
+		return FindError
+	}
+	if Reference == nil {
+		return fmt.Errorf("Virtual Machine `%s` does not Exist", VirtualMachineObj.ItemPath)
+	}
+	VirtualMachine := Reference.(*object.VirtualMachine)
+
+	switch Schedule.Action {
+	case models.SchedulePowerOn:
+		if StartError := this.StartVirtualMachine(VirtualMachine, options.WithContext(Context)); StartError != nil {
+			return StartError
+		}
+		models.RecordVMEvent(Schedule.VirtualMachineID, models.EventPoweredOn, "Virtual Machine has been Started by the Schedule")
+	case models.SchedulePowerOff:
+		if ShutdownError := this.ShutdownVirtualMachine(VirtualMachine, options.WithContext(Context)); ShutdownError != nil {
+			return ShutdownError
+		}
+		models.RecordVMEvent(Schedule.VirtualMachineID, models.EventPoweredOff, "Virtual Machine has been Stopped by the Schedule")
+	default:
+		return fmt.Errorf("Unsupported Power Schedule Action `%s`", Schedule.Action)
+	}
+	return nil
+}
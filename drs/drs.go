@@ -0,0 +1,184 @@
+package drs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
+	"github.com/LovePelmeni/Infrastructure/operations"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"go.uber.org/zap"
+)
+
+var (
+	Logger *zap.Logger
+)
+
+func InitializeProductionLogger() {
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("DrsLog.json")
+}
+
+func init() {
+	InitializeProductionLogger()
+}
+
+// Package consists of API, that Powers On Virtual Machines through the Datacenter,
+// So the Clusters with the Manual DRS can Place them, Placement Recommendations are either
+// Applied Automatically, or Returned to the Caller to Choose from
+
+var (
+	// Set to `false` to Return the DRS Recommendations to the Caller, instead of Applying the Top one
+	AUTO_APPLY_DRS_RECOMMENDATIONS = os.Getenv("AUTO_APPLY_DRS_RECOMMENDATIONS")
+)
+
+var (
+	ErrRecommendationRequired = errors.New("Power On requires one of the DRS Recommendations to be Applied")
+	ErrPowerOnNotAttempted    = errors.New("Power On has not been Attempted by the Cluster")
+	ErrClusterNotFound        = errors.New("Virtual Machine does not belong to any Cluster")
+)
+
+type VirtualMachinePowerOnManager struct {
+	// Manager Class, that Powers On Virtual Machines with respect to the DRS
+	Client    vim25.Client
+	AutoApply bool // Applies the Top Rated Recommendation, if the Cluster Requires one
+}
+
+func NewVirtualMachinePowerOnManager(Client vim25.Client) *VirtualMachinePowerOnManager {
+	return &VirtualMachinePowerOnManager{
+		Client:    Client,
+		AutoApply: AUTO_APPLY_DRS_RECOMMENDATIONS != "false",
+	}
+}
+
+func (this *VirtualMachinePowerOnManager) PowerOn(Context context.Context, VirtualMachine *object.VirtualMachine) ([]types.ClusterRecommendation, error) {
+	// Powers On the Virtual Machine, if the Cluster Returns Placement Recommendations instead (Manual DRS),
+	// The Top Rated one is being Applied, unless the `AutoApply` is Disabled,
+	// In that case Recommendations are Returned along with the `ErrRecommendationRequired`
+
+	Datacenter, DatacenterError := this.datacenterOf(Context, VirtualMachine)
+	if DatacenterError != nil {
+		return nil, DatacenterError
+	}
+
+	PowerOnTask, PowerOnError := Datacenter.PowerOnVM(Context, []types.ManagedObjectReference{VirtualMachine.Reference()})
+	if PowerOnError != nil {
+		Logger.Error("Failed to Power On Virtual Machine", zap.String("Virtual Machine", VirtualMachine.Reference().Value), zap.Error(PowerOnError))
+		return nil, PowerOnError
+	}
+	if PowerOnTask == nil {
+		// Standalone ESXi Host Powers the Virtual Machine On Directly, there is no DRS
+		return nil, nil
+	}
+
+	TaskInfo, WaitError := PowerOnTask.WaitForResult(Context, nil)
+	if WaitError != nil {
+		return nil, WaitError
+	}
+	Result, Valid := TaskInfo.Result.(types.ClusterPowerOnVmResult)
+	if !Valid {
+		return nil, fmt.Errorf("Unexpected Result of the Power On Task: %T", TaskInfo.Result)
+	}
+
+	for _, Attempted := range Result.Attempted {
+		if Attempted.Task == nil {
+			continue
+		}
+		if WaitError := object.NewTask(&this.Client, *Attempted.Task).Wait(Context); WaitError != nil {
+			return nil, WaitError
+		}
+	}
+	if len(Result.Attempted) != 0 {
+		return nil, nil
+	}
+
+	if len(Result.Recommendations) != 0 {
+		Recommendations := SortRecommendations(Result.Recommendations)
+		if !this.AutoApply {
+			return Recommendations, ErrRecommendationRequired
+		}
+		return Recommendations, this.ApplyRecommendation(Context, VirtualMachine, Recommendations[0])
+	}
+
+	if len(Result.NotAttempted) != 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPowerOnNotAttempted, Result.NotAttempted[0].Fault.LocalizedMessage)
+	}
+	return nil, ErrPowerOnNotAttempted
+}
+
+func (this *VirtualMachinePowerOnManager) ApplyRecommendation(Context context.Context, VirtualMachine *object.VirtualMachine, Recommendation types.ClusterRecommendation) error {
+	// Applies the DRS Recommendation, Returned by the `PowerOn` and Waits until the Virtual Machine is Powered On
+	// Recommendation Places (Migrates) the Virtual Machine onto the Host, so it is Tracked as the Relocation
+	defer operations.Track(operations.OperationRelocation, VirtualMachine.Reference().Value)()
+
+	Cluster := Recommendation.Target
+	if Cluster == nil || Cluster.Type != "ClusterComputeResource" {
+		Owner, OwnerError := this.clusterOf(Context, VirtualMachine)
+		if OwnerError != nil {
+			return OwnerError
+		}
+		Cluster = Owner
+	}
+
+	if _, ApplyError := methods.ApplyRecommendation(Context, &this.Client, &types.ApplyRecommendation{
+		This: *Cluster, Key: Recommendation.Key}); ApplyError != nil {
+		Logger.Error("Failed to Apply DRS Recommendation", zap.String("Recommendation", Recommendation.Key), zap.Error(ApplyError))
+		return ApplyError
+	}
+	Logger.Debug("DRS Recommendation has been Applied", zap.String("Recommendation", Recommendation.Key),
+		zap.String("Virtual Machine", VirtualMachine.Reference().Value))
+	return VirtualMachine.WaitForPowerState(Context, types.VirtualMachinePowerStatePoweredOn)
+}
+
+func SortRecommendations(Recommendations []types.ClusterRecommendation) []types.ClusterRecommendation {
+	// Returns Recommendations Ordered from the Highest Rated one
+	Sorted := append([]types.ClusterRecommendation{}, Recommendations...)
+	sort.SliceStable(Sorted, func(I, J int) bool { return Sorted[I].Rating > Sorted[J].Rating })
+	return Sorted
+}
+
+func (this *VirtualMachinePowerOnManager) datacenterOf(Context context.Context, VirtualMachine *object.VirtualMachine) (*object.Datacenter, error) {
+	// Returns Datacenter, the Virtual Machine belongs to
+	Ancestors, AncestorsError := mo.Ancestors(Context, this.Client.RoundTripper,
+		this.Client.ServiceContent.PropertyCollector, VirtualMachine.Reference())
+	if AncestorsError != nil {
+		return nil, AncestorsError
+	}
+	for _, Ancestor := range Ancestors {
+		if Ancestor.Self.Type == "Datacenter" {
+			return object.NewDatacenter(&this.Client, Ancestor.Self), nil
+		}
+	}
+	return nil, fmt.Errorf("Virtual Machine `%s` does not belong to any Datacenter", VirtualMachine.Reference().Value)
+}
+
+func (this *VirtualMachinePowerOnManager) clusterOf(Context context.Context, VirtualMachine *object.VirtualMachine) (*types.ManagedObjectReference, error) {
+	// Returns Cluster, that Owns the Resource Pool of the Virtual Machine
+	Collector := property.DefaultCollector(&this.Client)
+
+	var MoVirtualMachine mo.VirtualMachine
+	if RetrieveError := Collector.RetrieveOne(Context, VirtualMachine.Reference(), []string{"resourcePool"}, &MoVirtualMachine); RetrieveError != nil {
+		return nil, RetrieveError
+	}
+	if MoVirtualMachine.ResourcePool == nil {
+		return nil, ErrClusterNotFound
+	}
+	var MoResourcePool mo.ResourcePool
+	if RetrieveError := Collector.RetrieveOne(Context, *MoVirtualMachine.ResourcePool, []string{"owner"}, &MoResourcePool); RetrieveError != nil {
+		return nil, RetrieveError
+	}
+	if MoResourcePool.Owner.Type != "ClusterComputeResource" {
+		return nil, ErrClusterNotFound
+	}
+	return &MoResourcePool.Owner, nil
+}
@@ -0,0 +1,268 @@
+package guest
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
+	"github.com/LovePelmeni/Infrastructure/operations"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"go.uber.org/zap"
+)
+
+var (
+	Logger *zap.Logger
+)
+
+func InitializeProductionLogger() {
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("GuestLog.json")
+}
+
+func init() {
+	InitializeProductionLogger()
+}
+
+// Package consists of API, that performs Operations inside the Guest Operational System
+// Of the Virtual Machine Server, All of them Require VMware Tools to be Running
+
+var (
+	ErrToolsNotRunning   = errors.New("VMware Tools are not Running on the Virtual Machine")
+	ErrToolsNotInstalled = errors.New("VMware Tools are not Installed on the Virtual Machine")
+)
+
+type VirtualMachineGuestManager struct {
+	// Manager Class, that performs Operations within the Guest OS of the Virtual Machine
+	Client vim25.Client
+}
+
+func NewVirtualMachineGuestManager(Client vim25.Client) *VirtualMachineGuestManager {
+	return &VirtualMachineGuestManager{
+		Client: Client,
+	}
+}
+
+func (this *VirtualMachineGuestManager) IsToolsRunning(VirtualMachine *object.VirtualMachine) (bool, error) {
+	// Returns True if the VMware Tools are Running inside the Guest OS
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer CancelFunc()
+
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(TimeoutContext, VirtualMachine.Reference(),
+		[]string{"guest.toolsRunningStatus"}, &MoVirtualMachine); RetrieveError != nil {
+		return false, RetrieveError
+	}
+	return MoVirtualMachine.Guest != nil &&
+		MoVirtualMachine.Guest.ToolsRunningStatus == string(types.VirtualMachineToolsRunningStatusGuestToolsRunning), nil
+}
+
+func (this *VirtualMachineGuestManager) RebootGuest(VirtualMachine *object.VirtualMachine, WaitForTools bool) error {
+	// Gracefully Reboots the Guest Operational System (Unlike the Hard `Reset`, the OS is being asked to Restart itself)
+	// If `WaitForTools` is True, Waits until the VMware Tools are back up after the Reboot
+
+	Running, CheckError := this.IsToolsRunning(VirtualMachine)
+	if CheckError != nil {
+		return CheckError
+	}
+	if !Running {
+		return ErrToolsNotRunning
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*5)
+	defer CancelFunc()
+
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	if RebootError := VirtualMachine.RebootGuest(TimeoutContext); RebootError != nil {
+		Logger.Error("Failed to Reboot Guest OS", zap.String("Virtual Machine Name",
+			VirtualMachine.Name()), zap.Error(RebootError))
+		return RebootError
+	}
+
+	if !WaitForTools {
+		return nil
+	}
+
+	// Tools are going down first, while the Guest is Restarting, and going back up After
+	StoppedContext, StoppedCancelFunc := context.WithTimeout(TimeoutContext, time.Minute*1)
+	defer StoppedCancelFunc()
+	this.waitForToolsStatus(StoppedContext, VirtualMachine, false)
+
+	if WaitError := this.waitForToolsStatus(TimeoutContext, VirtualMachine, true); WaitError != nil {
+		Logger.Error("VMware Tools did not come back after the Reboot",
+			zap.String("Virtual Machine Name", VirtualMachine.Name()), zap.Error(WaitError))
+		return WaitError
+	}
+	return nil
+}
+
+func (this *VirtualMachineGuestManager) waitForToolsStatus(Context context.Context, VirtualMachine *object.VirtualMachine, Running bool) error {
+	// Waits until the VMware Tools Running Status of the Virtual Machine matches the Expected One
+
+	Collector := property.DefaultCollector(&this.Client)
+	return property.Wait(Context, Collector, VirtualMachine.Reference(),
+		[]string{"guest.toolsRunningStatus"}, func(Changes []types.PropertyChange) bool {
+			for _, Change := range Changes {
+				Status, _ := Change.Val.(string)
+				IsRunning := Status == string(types.VirtualMachineToolsRunningStatusGuestToolsRunning)
+				if IsRunning == Running {
+					return true
+				}
+			}
+			return false
+		})
+}
+
+// Detailed Power Status of the Virtual Machine, that takes the Guest OS State into Account
+//
+//	Power State   | Guest State                | Tools Status | Detailed Status
+//	------------- | -------------------------- | ------------ | ---------------
+//	poweredOff    | any                        | any          | Stopped
+//	suspended     | any                        | any          | Suspended
+//	poweredOn     | standby                    | any          | Suspended
+//	poweredOn     | shuttingDown / resetting   | any          | Stopping
+//	poweredOn     | running                    | running      | Running
+//	poweredOn     | notRunning / unknown / ... | any          | Starting
+
+type DetailedStatus string
+
+const (
+	StatusStarting  DetailedStatus = "Starting"  // Powered On, but Guest OS has not Booted yet
+	StatusRunning   DetailedStatus = "Running"   // Powered On and Guest OS is fully Running
+	StatusStopping  DetailedStatus = "Stopping"  // Guest OS is Shutting Down or Restarting
+	StatusStopped   DetailedStatus = "Stopped"   // Powered Off
+	StatusSuspended DetailedStatus = "Suspended" // Suspended or in Standby
+)
+
+func ResolveDetailedStatus(PowerState types.VirtualMachinePowerState, GuestState string, ToolsRunningStatus string) DetailedStatus {
+	// Maps the Power State, Guest State and Tools Status to the Detailed Status (See the Table above)
+
+	switch PowerState {
+	case types.VirtualMachinePowerStatePoweredOff:
+		return StatusStopped
+	case types.VirtualMachinePowerStateSuspended:
+		return StatusSuspended
+	}
+
+	switch types.VirtualMachineGuestState(GuestState) {
+	case types.VirtualMachineGuestStateStandby:
+		return StatusSuspended
+	case types.VirtualMachineGuestStateShuttingDown, types.VirtualMachineGuestStateResetting:
+		return StatusStopping
+	case types.VirtualMachineGuestStateRunning:
+		if ToolsRunningStatus == string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+			return StatusRunning
+		}
+	}
+	return StatusStarting
+}
+
+func (this *VirtualMachineGuestManager) GetDetailedPowerStatus(VirtualMachine *object.VirtualMachine) (DetailedStatus, error) {
+	// Returns Detailed Power Status of the Virtual Machine
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer CancelFunc()
+
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(TimeoutContext, VirtualMachine.Reference(),
+		[]string{"runtime.powerState", "guest.guestState", "guest.toolsRunningStatus"}, &MoVirtualMachine); RetrieveError != nil {
+		Logger.Error("Failed to Retrieve Power Status", zap.Error(RetrieveError))
+		return "", RetrieveError
+	}
+
+	var GuestState, ToolsRunningStatus string
+	if MoVirtualMachine.Guest != nil {
+		GuestState = MoVirtualMachine.Guest.GuestState
+		ToolsRunningStatus = MoVirtualMachine.Guest.ToolsRunningStatus
+	}
+	return ResolveDetailedStatus(MoVirtualMachine.Runtime.PowerState, GuestState, ToolsRunningStatus), nil
+}
+
+func ToolsUpgradeAvailable(VersionStatus string) bool {
+	// Returns True if the VMware Tools of the Status (`guest.toolsVersionStatus2`) can be Upgraded by vSphere,
+	// Tools, that are Managed by the Guest OS (e.g `open-vm-tools` Package) are never Upgraded this way
+	switch types.VirtualMachineToolsVersionStatus(VersionStatus) {
+	case types.VirtualMachineToolsVersionStatusGuestToolsNeedUpgrade,
+		types.VirtualMachineToolsVersionStatusGuestToolsSupportedOld,
+		types.VirtualMachineToolsVersionStatusGuestToolsTooOld,
+		types.VirtualMachineToolsVersionStatusGuestToolsBlacklisted:
+		return true
+	default:
+		return false
+	}
+}
+
+func (this *VirtualMachineGuestManager) GetToolsVersion(VirtualMachine *object.VirtualMachine) (string, string, error) {
+	// Returns Version Status (One of the `types.VirtualMachineToolsVersionStatus`) and Version of the VMware Tools
+	// `ErrToolsNotInstalled` is being Returned, if there is no Tools on the Virtual Machine
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer CancelFunc()
+
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(TimeoutContext, VirtualMachine.Reference(),
+		[]string{"guest.toolsVersionStatus2", "guest.toolsVersion"}, &MoVirtualMachine); RetrieveError != nil {
+		Logger.Error("Failed to Retrieve VMware Tools Version", zap.Error(RetrieveError))
+		return "", "", RetrieveError
+	}
+	if MoVirtualMachine.Guest == nil || len(MoVirtualMachine.Guest.ToolsVersionStatus2) == 0 ||
+		MoVirtualMachine.Guest.ToolsVersionStatus2 == string(types.VirtualMachineToolsVersionStatusGuestToolsNotInstalled) {
+		return string(types.VirtualMachineToolsVersionStatusGuestToolsNotInstalled), "", ErrToolsNotInstalled
+	}
+	return MoVirtualMachine.Guest.ToolsVersionStatus2, MoVirtualMachine.Guest.ToolsVersion, nil
+}
+
+func (this *VirtualMachineGuestManager) UpgradeTools(VirtualMachine *object.VirtualMachine) error {
+	// Upgrades VMware Tools of the Virtual Machine, if the Upgrade is Available, Otherwise does Nothing
+	// NOTE: Guest OS may Reboot during the Upgrade
+
+	Status, Version, VersionError := this.GetToolsVersion(VirtualMachine)
+	if VersionError != nil {
+		return VersionError
+	}
+	if !ToolsUpgradeAvailable(Status) {
+		Logger.Debug("VMware Tools Upgrade is not Available", zap.String("Virtual Machine Name",
+			VirtualMachine.Name()), zap.String("Status", Status), zap.String("Version", Version))
+		return nil
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*30)
+	defer CancelFunc()
+
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+	defer operations.Track(operations.OperationToolsUpgrade, VirtualMachine.Reference().Value)()
+
+	UpgradeTask, UpgradeError := VirtualMachine.UpgradeTools(TimeoutContext, "")
+	if UpgradeError != nil {
+		Logger.Error("Failed to Upgrade VMware Tools", zap.String("Virtual Machine Name",
+			VirtualMachine.Name()), zap.Error(UpgradeError))
+		return UpgradeError
+	}
+	if WaitError := UpgradeTask.Wait(TimeoutContext); WaitError != nil {
+		Logger.Error("Failed to Upgrade VMware Tools", zap.String("Virtual Machine Name",
+			VirtualMachine.Name()), zap.Error(WaitError))
+		return WaitError
+	}
+	Logger.Debug("VMware Tools have been Upgraded", zap.String("Virtual Machine Name", VirtualMachine.Name()),
+		zap.String("Previous Version", Version))
+	return nil
+}
@@ -0,0 +1,279 @@
+package guest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+
+	guestops "github.com/vmware/govmomi/guest"
+)
+
+// Fallback Network Configuration of the Already Running Virtual Machine, when the Customization Spec can't be Applied,
+// Network Config File is being Written to the Guest File System and Networking is being Restarted via VMware Tools
+
+type NetworkFlavor string
+
+const (
+	FlavorNetplan    NetworkFlavor = "netplan"    // Ubuntu 18.04+ (`/etc/netplan`)
+	FlavorInterfaces NetworkFlavor = "interfaces" // Debian (`/etc/network/interfaces.d`)
+	FlavorIfcfg      NetworkFlavor = "ifcfg"      // RHEL, CentOS, Rocky, Alma, Oracle Linux, Fedora (`/etc/sysconfig/network-scripts`)
+)
+
+var (
+	ErrUnsupportedGuestDistribution = errors.New("Network Configuration of the Guest OS Distribution is not Supported")
+	ErrGuestCommandFailed           = errors.New("Guest Command has Failed")
+)
+
+var (
+	interfaceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,15}$`)
+)
+
+type GuestNetworkConfig struct {
+	// Static IPv4 Configuration of the Single Network Interface of the Guest OS
+	Interface    string        `json:"Interface" xml:"Interface"`             // Name of the Interface, e.g `eth0` or `ens192`
+	IPAddress    string        `json:"IPAddress" xml:"IPAddress"`             // IPv4 Address of the Interface
+	PrefixLength int           `json:"PrefixLength" xml:"PrefixLength"`       // Subnet Prefix Length, e.g 24
+	Gateway      string        `json:"Gateway,omitempty" xml:"Gateway"`       // Default Gateway, Optional
+	DNSServers   []string      `json:"DNSServers,omitempty" xml:"DNSServers"` // DNS Servers, Optional
+	Flavor       NetworkFlavor `json:"Flavor,omitempty" xml:"Flavor"`         // Detected from the Guest OS, if not Specified
+}
+
+func (this GuestNetworkConfig) Validate() error {
+	// Checks that the Configuration has Valid Interface Name, IPv4 Addresses and the Gateway within the Subnet
+
+	if !interfaceNamePattern.MatchString(this.Interface) {
+		return fmt.Errorf("Invalid Interface Name `%s`", this.Interface)
+	}
+	IPAddress := net.ParseIP(this.IPAddress).To4()
+	if IPAddress == nil {
+		return fmt.Errorf("Invalid IPv4 Address `%s`", this.IPAddress)
+	}
+	if this.PrefixLength < 1 || this.PrefixLength > 32 {
+		return fmt.Errorf("Invalid Prefix Length %d, should be within 1-32", this.PrefixLength)
+	}
+	if len(this.Gateway) != 0 {
+		Gateway := net.ParseIP(this.Gateway).To4()
+		if Gateway == nil {
+			return fmt.Errorf("Invalid Gateway Address `%s`", this.Gateway)
+		}
+		Subnet := net.IPNet{IP: IPAddress.Mask(net.CIDRMask(this.PrefixLength, 32)), Mask: net.CIDRMask(this.PrefixLength, 32)}
+		if !Subnet.Contains(Gateway) {
+			return fmt.Errorf("Gateway `%s` is not within the Subnet `%s`", this.Gateway, Subnet.String())
+		}
+		if Gateway.Equal(IPAddress) {
+			return errors.New("Gateway can't be the same as the IP Address")
+		}
+	}
+	for _, Server := range this.DNSServers {
+		if net.ParseIP(Server) == nil {
+			return fmt.Errorf("Invalid DNS Server Address `%s`", Server)
+		}
+	}
+	switch this.Flavor {
+	case "", FlavorNetplan, FlavorInterfaces, FlavorIfcfg:
+		return nil
+	default:
+		return fmt.Errorf("%w: `%s`", ErrUnsupportedGuestDistribution, this.Flavor)
+	}
+}
+
+func DetectNetworkFlavor(GuestId string) (NetworkFlavor, error) {
+	// Returns the Way the Network is being Configured in the Guest OS, by its vSphere Guest ID (e.g `ubuntu64Guest`)
+	GuestId = strings.ToLower(GuestId)
+	switch {
+	case strings.HasPrefix(GuestId, "ubuntu"):
+		return FlavorNetplan, nil
+	case strings.HasPrefix(GuestId, "debian"):
+		return FlavorInterfaces, nil
+	case strings.HasPrefix(GuestId, "rhel"), strings.HasPrefix(GuestId, "centos"),
+		strings.HasPrefix(GuestId, "oraclelinux"), strings.HasPrefix(GuestId, "fedora"),
+		strings.HasPrefix(GuestId, "rockylinux"), strings.HasPrefix(GuestId, "almalinux"):
+		return FlavorIfcfg, nil
+	default:
+		return "", fmt.Errorf("%w: Guest `%s`", ErrUnsupportedGuestDistribution, GuestId)
+	}
+}
+
+func (this GuestNetworkConfig) Render() (string, []byte, string, error) {
+	// Returns Path of the Network Config File, its Content and the Shell Command, that Applies it
+
+	Content := &bytes.Buffer{}
+	Address := fmt.Sprintf("%s/%d", this.IPAddress, this.PrefixLength)
+
+	switch this.Flavor {
+	case FlavorNetplan:
+		fmt.Fprintf(Content, "network:\n  version: 2\n  ethernets:\n    %s:\n      dhcp4: false\n      addresses: [%s]\n", this.Interface, Address)
+		if len(this.Gateway) != 0 {
+			fmt.Fprintf(Content, "      routes:\n        - to: default\n          via: %s\n", this.Gateway)
+		}
+		if len(this.DNSServers) != 0 {
+			fmt.Fprintf(Content, "      nameservers:\n        addresses: [%s]\n", strings.Join(this.DNSServers, ", "))
+		}
+		return fmt.Sprintf("/etc/netplan/60-%s-static.yaml", this.Interface), Content.Bytes(), "netplan apply", nil
+
+	case FlavorInterfaces:
+		fmt.Fprintf(Content, "auto %s\niface %s inet static\n    address %s\n", this.Interface, this.Interface, Address)
+		if len(this.Gateway) != 0 {
+			fmt.Fprintf(Content, "    gateway %s\n", this.Gateway)
+		}
+		if len(this.DNSServers) != 0 {
+			fmt.Fprintf(Content, "    dns-nameservers %s\n", strings.Join(this.DNSServers, " "))
+		}
+		return fmt.Sprintf("/etc/network/interfaces.d/%s", this.Interface), Content.Bytes(),
+			fmt.Sprintf("ifdown %s; ifup %s", this.Interface, this.Interface), nil
+
+	case FlavorIfcfg:
+		fmt.Fprintf(Content, "DEVICE=%s\nBOOTPROTO=none\nONBOOT=yes\nIPADDR=%s\nPREFIX=%d\n", this.Interface, this.IPAddress, this.PrefixLength)
+		if len(this.Gateway) != 0 {
+			fmt.Fprintf(Content, "GATEWAY=%s\n", this.Gateway)
+		}
+		for Index, Server := range this.DNSServers {
+			fmt.Fprintf(Content, "DNS%d=%s\n", Index+1, Server)
+		}
+		return fmt.Sprintf("/etc/sysconfig/network-scripts/ifcfg-%s", this.Interface), Content.Bytes(),
+			fmt.Sprintf("(nmcli connection reload && nmcli connection up %s) || systemctl restart network", this.Interface), nil
+
+	default:
+		return "", nil, "", fmt.Errorf("%w: `%s`", ErrUnsupportedGuestDistribution, this.Flavor)
+	}
+}
+
+func (this *VirtualMachineGuestManager) ConfigureGuestNetwork(VirtualMachine *object.VirtualMachine, Auth types.NamePasswordAuthentication, Config GuestNetworkConfig) error {
+	// Sets Static IP Configuration inside the Guest OS of the Running Virtual Machine,
+	// Used as the Fallback, when the Customization Spec can't be Applied
+
+	if ValidationError := Config.Validate(); ValidationError != nil {
+		return ValidationError
+	}
+	Running, ToolsError := this.IsToolsRunning(VirtualMachine)
+	if ToolsError != nil {
+		return ToolsError
+	}
+	if !Running {
+		return fmt.Errorf("%w, Guest Network can't be Configured", ErrToolsNotRunning)
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*2)
+	defer CancelFunc()
+
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	if len(Config.Flavor) == 0 {
+		GuestId, RetrieveError := this.retrieveGuestId(TimeoutContext, VirtualMachine)
+		if RetrieveError != nil {
+			return RetrieveError
+		}
+		Flavor, FlavorError := DetectNetworkFlavor(GuestId)
+		if FlavorError != nil {
+			return FlavorError
+		}
+		Config.Flavor = Flavor
+	}
+	Path, Content, Command, RenderError := Config.Render()
+	if RenderError != nil {
+		return RenderError
+	}
+
+	OperationsManager := guestops.NewOperationsManager(&this.Client, VirtualMachine.Reference())
+	if UploadError := this.writeGuestFile(TimeoutContext, OperationsManager, &Auth, Path, Content); UploadError != nil {
+		Logger.Error("Failed to Write Guest Network Config", zap.String("Path", Path), zap.Error(UploadError))
+		return UploadError
+	}
+	if CommandError := this.runGuestCommand(TimeoutContext, OperationsManager, &Auth, Command); CommandError != nil {
+		Logger.Error("Failed to Restart Guest Networking", zap.String("Command", Command), zap.Error(CommandError))
+		return CommandError
+	}
+	Logger.Debug("Guest Network has been Configured", zap.String("Virtual Machine Name", VirtualMachine.Name()),
+		zap.String("Interface", Config.Interface), zap.String("IP Address", Config.IPAddress))
+	return nil
+}
+
+func (this *VirtualMachineGuestManager) retrieveGuestId(Context context.Context, VirtualMachine *object.VirtualMachine) (string, error) {
+	// Returns Guest ID of the Running Guest OS (Reported by the Tools), Falls back to the Configured one
+
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(Context, VirtualMachine.Reference(),
+		[]string{"guest.guestId", "config.guestId"}, &MoVirtualMachine); RetrieveError != nil {
+		return "", RetrieveError
+	}
+	if MoVirtualMachine.Guest != nil && len(MoVirtualMachine.Guest.GuestId) != 0 {
+		return MoVirtualMachine.Guest.GuestId, nil
+	}
+	if MoVirtualMachine.Config != nil {
+		return MoVirtualMachine.Config.GuestId, nil
+	}
+	return "", nil
+}
+
+func (this *VirtualMachineGuestManager) writeGuestFile(Context context.Context, OperationsManager *guestops.OperationsManager, Auth types.BaseGuestAuthentication, Path string, Content []byte) error {
+	// Uploads the File to the Guest File System, Overwriting the Existing One
+
+	FileManager, ManagerError := OperationsManager.FileManager(Context)
+	if ManagerError != nil {
+		return ManagerError
+	}
+	TransferURL, TransferError := FileManager.InitiateFileTransferToGuest(Context, Auth, Path,
+		&types.GuestPosixFileAttributes{Permissions: 0600}, int64(len(Content)), true)
+	if TransferError != nil {
+		return TransferError
+	}
+	URL, URLError := FileManager.TransferURL(Context, TransferURL)
+	if URLError != nil {
+		return URLError
+	}
+	Upload := soap.DefaultUpload
+	Upload.ContentLength = int64(len(Content))
+	return this.Client.Upload(Context, bytes.NewReader(Content), URL, &Upload)
+}
+
+func (this *VirtualMachineGuestManager) runGuestCommand(Context context.Context, OperationsManager *guestops.OperationsManager, Auth types.BaseGuestAuthentication, Command string) error {
+	// Runs the Shell Command inside the Guest OS and Waits until it Exits
+
+	ProcessManager, ManagerError := OperationsManager.ProcessManager(Context)
+	if ManagerError != nil {
+		return ManagerError
+	}
+	ProcessID, StartError := ProcessManager.StartProgram(Context, Auth, &types.GuestProgramSpec{
+		ProgramPath: "/bin/sh",
+		Arguments:   fmt.Sprintf("-c %q", Command),
+	})
+	if StartError != nil {
+		return StartError
+	}
+
+	for {
+		Processes, ListError := ProcessManager.ListProcesses(Context, Auth, []int64{ProcessID})
+		if ListError != nil {
+			return ListError
+		}
+		if len(Processes) != 0 && Processes[0].EndTime != nil {
+			if Processes[0].ExitCode != 0 {
+				return fmt.Errorf("%w: `%s` Exited with Code %d", ErrGuestCommandFailed, Command, Processes[0].ExitCode)
+			}
+			return nil
+		}
+		select {
+		case <-Context.Done():
+			return Context.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package guest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrVirtualMachineNotFound = errors.New("Virtual Machine has not been Found in the vSphere")
+)
+
+func SyncStatesForOwner(Client vim25.Client, OwnerID string) (map[string]error, error) {
+	// Syncs the `State` of every Virtual Machine of the Customer with its Power and Guest State in the vSphere
+	// Only Virtual Machines of the Customer are Fetched, their Properties are Retrieved by the Single Property Collector Call,
+	// instead of the Separate Call per Virtual Machine, so it stays Fast for Customers with a lot of Machines
+	// Returns Errors by the Virtual Machine ID, the Error itself is Returned only if Nothing could be Synced at all
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+	defer CancelFunc()
+
+	// Virtual Machines, that are still being Provisioned, have their own Lifecycle and are not Touched
+	var VirtualMachines []models.VirtualMachine
+	if Gorm := models.Database.Model(&models.VirtualMachine{}).Select("id", "item_path", "uuid", "state").Where(
+		"owner_id = ? AND state IN ?", OwnerID, []string{models.StatusReady, models.StatusNotReady, ""}).Find(
+		&VirtualMachines); Gorm.Error != nil {
+		Logger.Error("Failed to Load Virtual Machines of the Customer", zap.String("Owner ID", OwnerID), zap.Error(Gorm.Error))
+		return nil, Gorm.Error
+	}
+	Errors := map[string]error{}
+	if len(VirtualMachines) == 0 {
+		return Errors, nil
+	}
+
+	// Virtual Machines are Matched by the UUID, Rows without it (Not Backfilled yet) are Matched by the Full Inventory Path,
+	// the Name alone is not Unique across the Folders and Datacenters
+	SearchIndex := object.NewSearchIndex(&Client)
+	References := []types.ManagedObjectReference{}
+	ByReference := map[types.ManagedObjectReference][]models.VirtualMachine{}
+	for _, VirtualMachine := range VirtualMachines {
+		var Reference object.Reference
+		var FindError error
+		if len(VirtualMachine.UUID) != 0 {
+			Reference, FindError = SearchIndex.FindByUuid(TimeoutContext, nil, VirtualMachine.UUID, true, nil)
+		} else {
+			Reference, FindError = SearchIndex.FindByInventoryPath(TimeoutContext, VirtualMachine.ItemPath)
+		}
+		if FindError != nil {
+			Logger.Error("Failed to Find Virtual Machine", zap.Int("Virtual Machine ID", VirtualMachine.ID), zap.Error(FindError))
+			Errors[strconv.Itoa(VirtualMachine.ID)] = FindError
+			continue
+		}
+		if Reference == nil || Reference.Reference().Type != "VirtualMachine" {
+			Errors[strconv.Itoa(VirtualMachine.ID)] = ErrVirtualMachineNotFound
+			continue
+		}
+		if _, Found := ByReference[Reference.Reference()]; !Found {
+			References = append(References, Reference.Reference())
+		}
+		ByReference[Reference.Reference()] = append(ByReference[Reference.Reference()], VirtualMachine)
+	}
+	if len(References) == 0 {
+		return Errors, nil
+	}
+
+	var MoVirtualMachines []mo.VirtualMachine
+	if RetrieveError := property.DefaultCollector(&Client).Retrieve(TimeoutContext, References,
+		[]string{"runtime.powerState", "guest.guestState", "guest.toolsRunningStatus"},
+		&MoVirtualMachines); RetrieveError != nil {
+		Logger.Error("Failed to Retrieve Virtual Machines States", zap.Error(RetrieveError))
+		return nil, RetrieveError
+	}
+
+	States := map[string][]int{}
+	for Index := range MoVirtualMachines {
+		State := resolveVirtualMachineState(&MoVirtualMachines[Index])
+		for _, VirtualMachine := range ByReference[MoVirtualMachines[Index].Self] {
+			if State != VirtualMachine.State {
+				States[State] = append(States[State], VirtualMachine.ID)
+			}
+		}
+	}
+
+	// Rows are Updated by the State, so there are at most Two Update Queries
+	for State, IDs := range States {
+		if Gorm := models.Database.Model(&models.VirtualMachine{}).Where(
+			"id IN ?", IDs).Update("state", State); Gorm.Error != nil {
+			Logger.Error("Failed to Update Virtual Machines State", zap.String("State", State), zap.Error(Gorm.Error))
+			for _, ID := range IDs {
+				Errors[strconv.Itoa(ID)] = Gorm.Error
+			}
+		}
+	}
+	if len(Errors) != 0 {
+		Logger.Debug("Some Virtual Machines have not been Synced",
+			zap.String("Owner ID", OwnerID), zap.Int("Failed", len(Errors)))
+	}
+	return Errors, nil
+}
+
+func resolveVirtualMachineState(MoVirtualMachine *mo.VirtualMachine) string {
+	// Returns `models.StatusReady` if the Virtual Machine is Running or Booting, `models.StatusNotReady` Otherwise
+	var GuestState, ToolsRunningStatus string
+	if MoVirtualMachine.Guest != nil {
+		GuestState = MoVirtualMachine.Guest.GuestState
+		ToolsRunningStatus = MoVirtualMachine.Guest.ToolsRunningStatus
+	}
+	switch ResolveDetailedStatus(MoVirtualMachine.Runtime.PowerState, GuestState, ToolsRunningStatus) {
+	case StatusRunning, StatusStarting:
+		return models.StatusReady
+	default:
+		return models.StatusNotReady
+	}
+}
@@ -0,0 +1,70 @@
+package guest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+// Guest Operations (Programs, Files, IP Address Lookup) Fail right after the Power On,
+// until the VMware Tools inside the Guest OS have Started, so the Callers should Wait for them first
+
+const ToolsPollInterval = time.Second * 1
+
+var (
+	ErrToolsWaitTimeout = errors.New("VMware Tools have not Started in Time")
+)
+
+func WaitForGuestTools(Context context.Context, VirtualMachine *object.VirtualMachine, Timeout time.Duration) error {
+	// Polls `guest.toolsStatus` of the Virtual Machine until the Tools are Running (`toolsOk` or `toolsOld`),
+	// `ErrToolsWaitTimeout` with the Last Observed Status is Returned, if the Timeout Elapses first,
+	// Zero Timeout means no Deadline, except the one of the Context
+
+	WaitContext, CancelFunc := context.WithCancel(Context)
+	if Timeout > 0 {
+		WaitContext, CancelFunc = context.WithTimeout(Context, Timeout)
+	}
+	defer CancelFunc()
+
+	Ticker := time.NewTicker(ToolsPollInterval)
+	defer Ticker.Stop()
+
+	Collector := property.DefaultCollector(VirtualMachine.Client())
+	var LastStatus types.VirtualMachineToolsStatus
+	for {
+		var MoVirtualMachine mo.VirtualMachine
+		RetrieveError := Collector.RetrieveOne(WaitContext, VirtualMachine.Reference(),
+			[]string{"guest.toolsStatus"}, &MoVirtualMachine)
+		switch {
+		case RetrieveError == nil:
+			if MoVirtualMachine.Guest != nil {
+				LastStatus = MoVirtualMachine.Guest.ToolsStatus
+			}
+			if LastStatus == types.VirtualMachineToolsStatusToolsOk || LastStatus == types.VirtualMachineToolsStatusToolsOld {
+				return nil
+			}
+		case WaitContext.Err() == nil:
+			Logger.Error("Failed to Retrieve VMware Tools Status", zap.String("Virtual Machine Name",
+				VirtualMachine.Name()), zap.Error(RetrieveError))
+			return RetrieveError
+		}
+
+		select {
+		case <-WaitContext.Done():
+			if Context.Err() != nil {
+				return fmt.Errorf("%w, Last Observed Status `%s`", Context.Err(), LastStatus)
+			}
+			Logger.Warn("VMware Tools have not Started in Time", zap.String("Virtual Machine Name",
+				VirtualMachine.Name()), zap.String("Status", string(LastStatus)), zap.Duration("Timeout", Timeout))
+			return fmt.Errorf("%w (%s), Last Observed Status `%s`", ErrToolsWaitTimeout, Timeout, LastStatus)
+		case <-Ticker.C:
+		}
+	}
+}
@@ -11,9 +11,10 @@ import (
 
 	"github.com/LovePelmeni/Infrastructure/deploy"
 	"github.com/LovePelmeni/Infrastructure/healthcheck"
+	"github.com/LovePelmeni/Infrastructure/logging"
+	"github.com/LovePelmeni/Infrastructure/tracing"
 	
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"github.com/gin-gonic/gin"
 	"github.com/vmware/govmomi"
@@ -45,15 +46,8 @@ var (
 )
 
 func InitializeProductionLogger() {
-
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("HealthCheckLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("HealthCheckLog.json")
 }
 
 func init() {
@@ -69,6 +63,7 @@ func init() {
 		Logger.Error("FAILED TO INITIALIZE CLIENT, DOES THE VMWARE HYPERVISOR ACTUALLY RUNNING?")
 
 	case ConnectionError == nil:
+		tracing.InstrumentClient(APIClient.Client)
 		RestClient = rest.NewClient(APIClient.Client)
 		if FailedToLogin := RestClient.Login(TimeoutContext, APIUrl.User); FailedToLogin != nil {
 			Logger.Error("FAILED TO LOGIN TO THE VMWARE HYPERVISOR SERVER", zap.Error(FailedToLogin))
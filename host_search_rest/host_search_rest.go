@@ -2,12 +2,12 @@ package host_search_rest
 
 import (
 	"net/http"
-	"os"
 
 	host_search "github.com/LovePelmeni/Infrastructure/host_search"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
 )
 
 var (
@@ -15,15 +15,8 @@ var (
 )
 
 func InitializeProductionLogger() {
-	// Initializes Logger
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("HostMachineSearchRestLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("HostMachineSearchRestLog.json")
 }
 
 func init() {
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Package consists of the Logger Setup, Shared by all Packages of the Project, Loggers are Configured by the Environment:
+// `LOG_LEVEL` - Min Level of the Logs (`debug` by default),
+// `LOG_FILE` - Path to the Log File, if Empty, every Package Writes to its own Log File (e.g `ModelsLog.json`),
+// `LOG_CONSOLE` - if `true`, Logs are Written to the Stderr as well
+
+const DefaultLogLevel = zapcore.DebugLevel
+
+var (
+	ErrInvalidLogLevel = errors.New("Invalid Log Level")
+)
+
+func ParseLogLevel(Level string) (zapcore.Level, error) {
+	// Returns Level of the Logger by its Name (`debug`, `info`, `warn`, `error`), Empty Name is the `DefaultLogLevel`
+	switch strings.ToLower(strings.TrimSpace(Level)) {
+	case "":
+		return DefaultLogLevel, nil
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "warn", "warning":
+		return zapcore.WarnLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	default:
+		return DefaultLogLevel, fmt.Errorf("%w: `%s`", ErrInvalidLogLevel, Level)
+	}
+}
+
+func newEncoderConfig() zapcore.EncoderConfig {
+	config := zap.NewProductionEncoderConfig()
+	config.EncodeTime = zapcore.ISO8601TimeEncoder
+	return config
+}
+
+func NewLogger(DefaultLogFile string) (*zap.Logger, *os.File, error) {
+	// Returns Logger, Configured by the Environment, and its Log File, which should be Closed by the Caller
+	// `DefaultLogFile` is Used, unless the `LOG_FILE` is Set
+	// Returns Error if the Log File can't be Opened
+
+	Level, LevelError := ParseLogLevel(os.Getenv("LOG_LEVEL"))
+	LogFile := os.Getenv("LOG_FILE")
+	if len(LogFile) == 0 {
+		LogFile = DefaultLogFile
+	}
+
+	file, OpenError := os.OpenFile(LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if OpenError != nil {
+		return nil, nil, fmt.Errorf("Failed to Open Log File `%s`: %w", LogFile, OpenError)
+	}
+
+	config := newEncoderConfig()
+	Cores := []zapcore.Core{zapcore.NewCore(zapcore.NewJSONEncoder(config), zapcore.AddSync(file), Level)}
+	if Console, _ := strconv.ParseBool(os.Getenv("LOG_CONSOLE")); Console {
+		Cores = append(Cores, zapcore.NewCore(zapcore.NewConsoleEncoder(config), zapcore.Lock(os.Stderr), Level))
+	}
+	Logger := zap.New(zapcore.NewTee(Cores...))
+
+	if LevelError != nil {
+		Logger.Warn("Unknown Log Level, Default one is being Used",
+			zap.String("Level", DefaultLogLevel.String()), zap.Error(LevelError))
+	}
+	return Logger, file, nil
+}
+
+func NewStderrLogger() *zap.Logger {
+	// Returns Logger, Writing to the Stderr, Used when the Log File can't be Opened, so the Logs are not Lost
+	Level, _ := ParseLogLevel(os.Getenv("LOG_LEVEL"))
+	return zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(newEncoderConfig()), zapcore.Lock(os.Stderr), Level))
+}
+
+func NewProductionLogger(DefaultLogFile string) *zap.Logger {
+	// Returns Logger (See `NewLogger`), if the Log File can't be Opened, the Stderr one is Returned instead
+	// Log File is Kept Open for the whole Lifetime of the Process
+	Logger, _, LoggerError := NewLogger(DefaultLogFile)
+	if LoggerError != nil {
+		Logger = NewStderrLogger()
+		Logger.Error("Failed to Initialize Logger, Stderr is being Used", zap.Error(LoggerError))
+	}
+	return Logger
+}
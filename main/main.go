@@ -8,15 +8,20 @@ import (
 	"net/http"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/LovePelmeni/Infrastructure/healthcheck_rest"
+	"github.com/LovePelmeni/Infrastructure/logging"
 	"github.com/LovePelmeni/Infrastructure/middlewares"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/operations"
+	"github.com/LovePelmeni/Infrastructure/ssh_config"
 	"github.com/LovePelmeni/Infrastructure/ssh_rest"
+	"github.com/LovePelmeni/Infrastructure/tracing"
 
 	customer_rest "github.com/LovePelmeni/Infrastructure/customer_rest"
 	host_search_rest "github.com/LovePelmeni/Infrastructure/host_search_rest"
@@ -33,22 +38,60 @@ var (
 
 	FRONT_APPLICATION_HOST = os.Getenv("FRONT_APPLICATION_HOST")
 	FRONT_APPLICATION_PORT = os.Getenv("FRONT_APPLICATION_PORT")
+
+	TRACING_ENDPOINT = os.Getenv("TRACING_ENDPOINT") // Collector Endpoint of the Spans, Tracing is Disabled if Empty
 )
 
 var (
-	Logger *zap.Logger
+	ShutdownTracer = func(context.Context) error { return nil } // Flushes Pending Spans, Set by the `InitTracer`
 )
 
-func InitializeProductionLogger() {
+const OperationsShutdownTimeout = time.Minute * 5 // Max Time to Wait for the In-Flight Operations on Shutdown
+
+var (
+	Logger  *zap.Logger
+	logFile *os.File // Log File of the Current Logger, Closed by the `CloseLogger`
+)
+
+func InitializeLogger() error {
+	// Initializes Logger, Configured by the Environment (See `logging.NewLogger`), `Main.json` is the Default Log File
+	// Returns Error if the Log File can't be Opened, the Current Logger is Left Untouched in that Case
 
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("Main.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
+	NewLogger, file, LoggerError := logging.NewLogger("Main.json")
+	if LoggerError != nil {
+		return LoggerError
+	}
+
+	// Previous Logger is Flushed and its File is Closed, so Re-Initialization does not Leak File Handles
+	CloseLogger()
+	Logger = NewLogger
+	logFile = file
+	return nil
+}
+
+func InitializeProductionLogger() {
+	// Initializes Logger (See `InitializeLogger`), if the Log File can't be Opened, Logs are Written to the Stderr,
+	// so they are not Lost
+	if LoggerError := InitializeLogger(); LoggerError != nil {
+		CloseLogger()
+		Logger = logging.NewStderrLogger()
+		Logger.Error("Failed to Initialize Logger, Stderr is being Used", zap.Error(LoggerError))
+	}
+}
 
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+func CloseLogger() error {
+	// Flushes Buffered Logs and Closes the Log File, should be Called on Shutdown
+	// Logger is Replaced with the No-op one, so the Late Logs are Dropped instead of being Written to the Closed File
+	if Logger != nil {
+		Logger.Sync()
+	}
+	if logFile == nil {
+		return nil
+	}
+	CloseError := logFile.Close()
+	logFile = nil
+	Logger = zap.NewNop()
+	return CloseError
 }
 
 func init() {
@@ -152,6 +195,8 @@ func (this *Server) Run() {
 	)
 	{
 		SshSystemGroup.GET("/get/ssh/certificate/", ssh_rest.GetDownloadPublicSshCertificateRestController)
+		SshSystemGroup.POST("/key/upload/", ssh_rest.UploadSshKeyRestController)
+		SshSystemGroup.PUT("/key/rotate/", ssh_rest.RotateSshKeyRestController)
 	}
 
 	// Suggestions Rest Endpoints
@@ -189,17 +234,22 @@ func (this *Server) Run() {
 		os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT) // Creating Notify Context that triggers server to shut down
 	// after receiving system SIGTERM or SIGQUIT Signal from Kubernetes / Localhost.
 
-	go this.Shutdown(ServerShutDownContext, ErrorCancelMethod, Server)
+	// Server is Stopped by the `Shutdown`, which then Drains the Operations and Closes the Resources,
+	// so `Run` Returns only after it is Completed, otherwise the Process would Exit in the Middle of it
+	ShutdownCompleted := make(chan struct{})
+	go func() {
+		defer close(ShutdownCompleted)
+		this.Shutdown(ServerShutDownContext, ErrorCancelMethod, Server)
+	}()
 
 	Exception := Server.ListenAndServe()
-	if errors.Is(Exception, http.ErrServerClosed) {
-		ServerShutDownContext.Done()
-	} else {
+	if !errors.Is(Exception, http.ErrServerClosed) {
 		fmt.Print("Server has been Shutdown For Some Reason, Check `MainLog.json` for more info")
 		Logger.Error(
 			"Error while Running the Server", zap.NamedError("RuntimeError", Exception))
-		ServerShutDownContext.Done()
+		ErrorCancelMethod() // Server has not been Stopped by the Signal, so the Shutdown is Triggered Manually
 	}
+	<-ShutdownCompleted
 }
 
 func (this *Server) Shutdown(Context context.Context, CancelFunc context.CancelFunc, ServerInstance *http.Server) {
@@ -208,10 +258,41 @@ func (this *Server) Shutdown(Context context.Context, CancelFunc context.CancelF
 		defer CancelFunc()
 		ShutdownError := ServerInstance.Shutdown(context.Background())
 		Logger.Info("Server has been Shutdown", zap.NamedError("ShutdownError", ShutdownError))
+
+		// Waiting for the Long-Running Operations (Clones, Rotations, etc...), so they are not Interrupted in the Middle
+		OperationsContext, CancelOperations := context.WithTimeout(context.Background(), OperationsShutdownTimeout)
+		defer CancelOperations()
+		if Running, OperationsError := operations.Shutdown(OperationsContext); OperationsError != nil {
+			for _, Operation := range Running {
+				Logger.Error("Operation is still Running", zap.String("Type", Operation.Type),
+					zap.String("Target", Operation.Target), zap.Time("Started At", Operation.StartedAt))
+			}
+		}
+		ssh_config.CloseLogger()
+		middlewares.CloseLogger()
+
+		ModelsContext, CancelModels := context.WithTimeout(context.Background(), time.Second*30)
+		defer CancelModels()
+		if ModelsError := models.Shutdown(ModelsContext); ModelsError != nil {
+			Logger.Error("Failed to Close the Database Connections", zap.Error(ModelsError))
+		}
+		if TracerError := ShutdownTracer(ModelsContext); TracerError != nil {
+			Logger.Error("Failed to Flush Pending Spans", zap.Error(TracerError))
+		}
+		CloseLogger()
 	}
 }
 
 func main() {
+	// Secrets can't be Stored or Read without the Encryption Key, so the Server does not Start at all
+	if KeyError := models.LoadEncryptionKey(); KeyError != nil {
+		Logger.Fatal("Invalid Secrets Encryption Key", zap.Error(KeyError))
+	}
+	if Shutdown, TracerError := tracing.InitTracer(TRACING_ENDPOINT); TracerError != nil {
+		Logger.Error("Failed to Initialize Tracing, Spans are not Exported", zap.Error(TracerError))
+	} else {
+		ShutdownTracer = Shutdown
+	}
 	Logger.Debug("Running Http Application Server...")
 	httpServer := NewServer(APPLICATION_HOST, APPLICATION_PORT)
 	httpServer.Run()
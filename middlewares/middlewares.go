@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"net/http"
@@ -14,34 +15,64 @@ import (
 	"time"
 
 	"github.com/LovePelmeni/Infrastructure/authentication"
+	"github.com/LovePelmeni/Infrastructure/logging"
 	"github.com/LovePelmeni/Infrastructure/models"
 
 	"github.com/vmware/govmomi"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis"
 )
 
 var (
-	Logger *zap.Logger
+	Logger  *zap.Logger
+	logFile *os.File // Log File of the Current Logger, Closed by the `CloseLogger`
 )
 
 var (
 	RedisClient *redis.Client
 )
 
+func InitializeLogger() error {
+	// Initializes Logger, Configured by the Environment (See `logging.NewLogger`), `Main.json` is the Default Log File
+	// Returns Error if the Log File can't be Opened, the Current Logger is Left Untouched in that Case
+
+	NewLogger, file, LoggerError := logging.NewLogger("Main.json")
+	if LoggerError != nil {
+		return LoggerError
+	}
+
+	// Previous Logger is Flushed and its File is Closed, so Re-Initialization does not Leak File Handles
+	CloseLogger()
+	Logger = NewLogger
+	logFile = file
+	return nil
+}
+
 func InitializeProductionLogger() {
-	// Initializing Zap Logger
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("Main.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger (See `InitializeLogger`), if the Log File can't be Opened, Logs are Written to the Stderr,
+	// so they are not Lost
+	if LoggerError := InitializeLogger(); LoggerError != nil {
+		CloseLogger()
+		Logger = logging.NewStderrLogger()
+		Logger.Error("Failed to Initialize Logger, Stderr is being Used", zap.Error(LoggerError))
+	}
+}
+
+func CloseLogger() error {
+	// Flushes Buffered Logs and Closes the Log File, should be Called on Shutdown
+	// Logger is Replaced with the No-op one, so the Late Logs are Dropped instead of being Written to the Closed File
+	if Logger != nil {
+		Logger.Sync()
+	}
+	if logFile == nil {
+		return nil
+	}
+	CloseError := logFile.Close()
+	logFile = nil
+	Logger = zap.NewNop()
+	return CloseError
 }
 
 func init() {
@@ -72,16 +103,29 @@ func IsVirtualMachineOwnerMiddleware() gin.HandlerFunc {
 	}
 }
 
+func getVirtualMachineRecord(VirtualMachineId string) (*models.VirtualMachine, error) {
+	// Returns Database Record of the Virtual Machine, `models.ErrNotFound` if there is no such Record
+	var VirtualMachine models.VirtualMachine
+	Gorm := models.Database.Model(&models.VirtualMachine{}).Where(
+		"id = ?", VirtualMachineId).First(&VirtualMachine)
+	if Gorm.Error != nil {
+		return nil, models.TranslateNotFound(Gorm.Error)
+	}
+	return &VirtualMachine, nil
+}
+
 func SetReadyOperationMiddleware() gin.HandlerFunc {
 	// Sets status `Ready` to the Virtual Machine
 	// being called only on HTTP Response
 	return func(Context *gin.Context) {
 
 		VirtualMachineId := Context.Query("VirtualMachineId")
-		var VirtualMachine models.VirtualMachine
-
-		models.Database.Model(&models.VirtualMachine{}).Where(
-			"id = ?", VirtualMachineId).Find(&VirtualMachine)
+		VirtualMachine, FindError := getVirtualMachineRecord(VirtualMachineId)
+		if FindError != nil {
+			Logger.Debug("Virtual Machine State is not Updated", zap.String("Virtual Machine ID", VirtualMachineId), zap.Error(FindError))
+			Context.Next()
+			return
+		}
 
 		VirtualMachine.State = models.StatusNotReady
 		VirtualMachine.Save()
@@ -95,10 +139,12 @@ func SetNotReadyOperationMiddleware() gin.HandlerFunc {
 	return func(Context *gin.Context) {
 
 		VirtualMachineId := Context.Query("VirtualMachineId")
-		var VirtualMachine models.VirtualMachine
-
-		models.Database.Model(&models.VirtualMachine{}).Where(
-			"id = ?", VirtualMachineId).Find(&VirtualMachine)
+		VirtualMachine, FindError := getVirtualMachineRecord(VirtualMachineId)
+		if FindError != nil {
+			Logger.Debug("Virtual Machine State is not Updated", zap.String("Virtual Machine ID", VirtualMachineId), zap.Error(FindError))
+			Context.Next()
+			return
+		}
 
 		if Context.Request.Response.StatusCode != 0 || len(Context.Request.Response.Status) != 0 {
 			VirtualMachine.State = models.StatusReady
@@ -121,13 +167,19 @@ func IsReadyToPerformOperationMiddleware() gin.HandlerFunc {
 	return func(Context *gin.Context) {
 
 		var VirtualMachineId = Context.Query("VirtualMachineId")
-		var VirtualMachine models.VirtualMachine
-
-		models.Database.Model(&models.VirtualMachine{}).Where(
-			"id = ?", VirtualMachineId).Find(&VirtualMachine)
+		VirtualMachine, FindError := getVirtualMachineRecord(VirtualMachineId)
 
 		switch {
 
+		case errors.Is(FindError, models.ErrNotFound):
+			// Missing Virtual Machine is Reported by the Controller
+			Context.Next()
+
+		case FindError != nil:
+			Logger.Error("Failed to Retrieve Virtual Machine", zap.String("Virtual Machine ID", VirtualMachineId), zap.Error(FindError))
+			Context.AbortWithStatusJSON(http.StatusServiceUnavailable,
+				gin.H{"Error": "Failed to Check the State of the Server, please Try again later"})
+
 		case VirtualMachine.State == "NotReady":
 			Context.AbortWithStatusJSON(http.StatusServiceUnavailable,
 				gin.H{"Error": "this Server is already Performing other Operation, please Wait"})
@@ -179,12 +231,16 @@ func AuthorizationRequiredMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if _, Error := authentication.GetCustomerJwtCredentials(
-			context.GetHeader("Authorization")); Error != nil {
+		Credentials, Error := authentication.GetCustomerJwtCredentials(
+			context.GetHeader("Authorization"))
+		if Error != nil {
 			context.AbortWithStatusJSON(
 				http.StatusForbidden, gin.H{"Error": "You are Not Authorized"})
 			return
 		}
+		// Operations of the Request are being Audited on behalf of the Authorized Customer (See `models.AuditOperation`)
+		context.Request = context.Request.WithContext(models.WithAuditActor(context.Request.Context(),
+			models.AuditActor{CustomerID: Credentials.UserId, IPAddress: context.ClientIP()}))
 		context.Next()
 	}
 }
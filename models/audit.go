@@ -0,0 +1,80 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Actions, that are being Recorded to the Audit Log
+
+const AuditActionVirtualMachineCreated = "VirtualMachineCreated"
+const AuditActionVirtualMachineDeleted = "VirtualMachineDeleted"
+const AuditActionSshKeyRotated = "SshKeyRotated"
+
+const AuditResultSuccess = "Success"
+const AuditResultFailure = "Failure"
+
+type AuditLog struct {
+	// Record of the Infrastructure Action: Who has done What with Which Resource, Entries are never Updated
+	ID         int
+	CustomerID int       `json:"CustomerID" xml:"CustomerID" gorm:"<-:create;not null;default:0;index;"` // 0 for the System Actions (No Customer)
+	Action     string    `json:"Action" xml:"Action" gorm:"<-:create;type:varchar(50);not null;index;"`
+	Target     string    `json:"Target" xml:"Target" gorm:"<-:create;type:varchar(255);not null;"`                              // e.g ID or Inventory Path of the Virtual Machine
+	Result     string    `json:"Result" xml:"Result" gorm:"<-:create;type:varchar(20);not null;"`                               // `AuditResultSuccess` or `AuditResultFailure`
+	IPAddress  string    `json:"IPAddress,omitempty" xml:"IPAddress,omitempty" gorm:"<-:create;type:varchar(45);default:null;"` // IP, the Request has Come from
+	CreatedAt  time.Time `json:"CreatedAt" xml:"CreatedAt" gorm:"<-:create;not null;index;"`
+}
+
+func Audit(Entry AuditLog) error {
+	// Writes the Entry to the Audit Log, Unlike the Virtual Machine Events, the Write is Synchronous,
+	// so the Caller knows, if the Action has not been Recorded
+
+	if len(Entry.Action) == 0 || len(Entry.Target) == 0 {
+		return errors.New("Action and Target of the Audit Entry are Required")
+	}
+	if len(Entry.Result) == 0 {
+		Entry.Result = AuditResultSuccess
+	}
+	if Entry.CreatedAt.IsZero() {
+		Entry.CreatedAt = time.Now()
+	}
+	return Database.Create(&Entry).Error
+}
+
+type AuditActor struct {
+	// Customer, the Operation is Performed on behalf of, along with the IP of the Request
+	CustomerID int
+	IPAddress  string
+}
+
+type auditActorKey struct{}
+
+func WithAuditActor(Context context.Context, Actor AuditActor) context.Context {
+	// Returns Context, the Audited Operations take the Actor of the Audit Entries from
+	// (Pass it with `options.WithContext` or directly, where the Operation takes the Context)
+	return context.WithValue(Context, auditActorKey{}, Actor)
+}
+
+func AuditActorFromContext(Context context.Context) AuditActor {
+	// Returns Actor of the Context, Zero one (The System) if there is no one
+	Actor, _ := Context.Value(auditActorKey{}).(AuditActor)
+	return Actor
+}
+
+func AuditOperation(Context context.Context, Action string, Target string, OperationError error) {
+	// Records the Result of the Operation on behalf of the Actor of the Context,
+	// Failure to Record is only being Logged, so the Audit does not Change the Outcome of the Operation
+	Actor := AuditActorFromContext(Context)
+	Result := AuditResultSuccess
+	if OperationError != nil {
+		Result = AuditResultFailure
+	}
+	if AuditError := Audit(AuditLog{CustomerID: Actor.CustomerID, Action: Action, Target: Target,
+		Result: Result, IPAddress: Actor.IPAddress}); AuditError != nil {
+		Logger.Error("Failed to Write Audit Log Entry", zap.String("Action", Action),
+			zap.String("Target", Target), zap.Error(AuditError))
+	}
+}
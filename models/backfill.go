@@ -0,0 +1,33 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BackfillCheckpoint struct {
+	// Last Processed Row of the Backfill, so it can be Resumed after the Restart
+	Name            string    `json:"Name" xml:"Name" gorm:"primaryKey;type:varchar(100);"`
+	LastProcessedID int       `json:"LastProcessedID" xml:"LastProcessedID" gorm:"not null;default:0;"`
+	UpdatedAt       time.Time `json:"UpdatedAt" xml:"UpdatedAt"`
+}
+
+func GetBackfillCheckpoint(Name string) (*BackfillCheckpoint, error) {
+	// Returns Checkpoint of the Backfill, Starts from the Beginning, if there is no one yet
+	Checkpoint := &BackfillCheckpoint{Name: Name}
+	Gorm := Database.Where("name = ?", Name).Limit(1).Find(Checkpoint)
+	return Checkpoint, Gorm.Error
+}
+
+func (this *BackfillCheckpoint) Save() (*gorm.DB, error) {
+	// Saves the Current Progress of the Backfill
+	return this.SaveContext(context.Background())
+}
+
+func (this *BackfillCheckpoint) SaveContext(Context context.Context) (*gorm.DB, error) {
+	// Saves the Current Progress of the Backfill, Query is Cancelled along with the Context
+	Saved := Database.WithContext(Context).Save(this)
+	return Saved, Saved.Error
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/options"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCustomerHasResources = errors.New("Customer still has Virtual Machines")
+)
+
+func CanDeleteCustomer(OwnerID string) (bool, string, error) {
+	// Returns False with the Reason, if the Customer still Owns any Virtual Machine,
+	// Deleting such Customer would Orphan the Running Infrastructure
+	var Count int64
+	if Counted := Database.Model(&VirtualMachine{}).Where("owner_id = ?", OwnerID).Count(&Count); Counted.Error != nil {
+		return false, "", Counted.Error
+	}
+	if Count != 0 {
+		return false, fmt.Sprintf("Customer Owns %d Virtual Machine(s), that should be Deleted first", Count), nil
+	}
+	return true, "", nil
+}
+
+func deleteVirtualMachineRecords(Transaction *gorm.DB, VirtualMachineIDs []int) (*gorm.DB, error) {
+	// Deletes the Virtual Machine Rows Permanently with every Dependent Row (Keys, Tags) within the Transaction,
+	// Returns the Result of the Virtual Machine Rows Deletion (Amount of the Deleted ones is in the `RowsAffected`)
+	if len(VirtualMachineIDs) == 0 {
+		return Transaction, nil
+	}
+	if DeleteError := deleteVirtualMachineDependents(Transaction, VirtualMachineIDs); DeleteError != nil {
+		return Transaction, DeleteError
+	}
+	Deleted := Transaction.Unscoped().Where("id IN ?", VirtualMachineIDs).Delete(&VirtualMachine{})
+	return Deleted, Deleted.Error
+}
+
+func retireVirtualMachineRecords(Transaction *gorm.DB, VirtualMachineIDs []int) error {
+	// Deletes every Dependent Row of the Virtual Machines within the Transaction, but only Soft Deletes their Rows,
+	// so the Usage within the Billing Period can still be Computed (See `ComputeResourceHours`),
+	// the Rows are Removed Permanently by the `PurgeSoftDeleted`. IP Address is Released, so it can be Reused
+	if len(VirtualMachineIDs) == 0 {
+		return nil
+	}
+	if DeleteError := deleteVirtualMachineDependents(Transaction, VirtualMachineIDs); DeleteError != nil {
+		return DeleteError
+	}
+	return Transaction.Model(&VirtualMachine{}).Where("id IN ?", VirtualMachineIDs).Updates(
+		map[string]interface{}{"ip_address": nil, "deleted_at": time.Now()}).Error
+}
+
+func deleteVirtualMachineDependents(Transaction *gorm.DB, VirtualMachineIDs []int) error {
+	// Deletes the Rows, that Depend on the Virtual Machines (Keys, Tags, Schedules, Secrets) within the Transaction
+	// Secrets are Stored by the ID of the Record (See `ssh_config.SecretStore`)
+	if Deleted := Transaction.Unscoped().Where("virtual_machine_id IN ?", VirtualMachineIDs).Delete(&SSHPublicKey{}); Deleted.Error != nil {
+		return Deleted.Error
+	}
+	if Deleted := Transaction.Where("virtual_machine_id IN ?", VirtualMachineIDs).Delete(&VirtualMachineTag{}); Deleted.Error != nil {
+		return Deleted.Error
+	}
+	if Deleted := Transaction.Where("virtual_machine_id IN ?", VirtualMachineIDs).Delete(&PowerSchedule{}); Deleted.Error != nil {
+		return Deleted.Error
+	}
+	SecretIDs := []string{}
+	for _, VirtualMachineID := range VirtualMachineIDs {
+		SecretIDs = append(SecretIDs, strconv.Itoa(VirtualMachineID))
+	}
+	return Transaction.Where("virtual_machine_id IN ?", SecretIDs).Delete(&VirtualMachineSecret{}).Error
+}
+
+// There are Three ways to get rid of the Virtual Machine, Pick the one, that Matches the Intention:
+//
+//   - `deploy.DeleteVirtualMachinesMany` Destroys the Virtual Machine in vSphere and then Deletes its Records (Destroy)
+//   - `DeleteVirtualMachineRecords` Deletes the Records only, Used after the VM has been Destroyed in vSphere (Delete)
+//   - `UnmanageVirtualMachine` Deletes the Records only, but the VM Keeps Running in vSphere and the
+//     Timeline Records that it has been Unmanaged, not Destroyed (Unmanage)
+//
+// Either way the Virtual Machine Row is only Soft Deleted, so the VM is still Billed until the Deletion,
+// `PurgeSoftDeleted` Removes such Rows, once the Billing Period is Over
+
+func DeleteVirtualMachineRecords(VirtualMachineID int, Options ...options.OperationOption) error {
+	// Deletes every Dependent Row (Keys, Tags, Schedules, Secrets) of the Virtual Machine within a Single Transaction,
+	// the Virtual Machine Row itself is Soft Deleted and Purged after the Billing Period (See `retireVirtualMachineRecords`)
+	// NOTE: Timeline Events are being Kept, because they are Required for the Billing
+
+	Operation := options.NewOperationOptions(DefaultQueryTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	DeleteError := Database.WithContext(TimeoutContext).Transaction(func(Transaction *gorm.DB) error {
+		var Count int64
+		if Counted := Transaction.Model(&VirtualMachine{}).Where("id = ?", VirtualMachineID).Count(&Count); Counted.Error != nil {
+			return Counted.Error
+		}
+		if Count == 0 {
+			return ErrNotFound
+		}
+		return retireVirtualMachineRecords(Transaction, []int{VirtualMachineID})
+	})
+	AuditOperation(Operation.Context, AuditActionVirtualMachineDeleted, strconv.Itoa(VirtualMachineID), DeleteError)
+	return DeleteError
+}
+
+func UnmanageVirtualMachine(VirtualMachineID string) error {
+	// Stops Managing the Virtual Machine: Deletes its Records, but performs no vSphere Operation,
+	// So the VM Keeps Running in vCenter, `EventUnmanaged` is being Recorded to the Timeline along with the Deletion
+
+	ID, ParseError := strconv.Atoi(VirtualMachineID)
+	if ParseError != nil {
+		return fmt.Errorf("Invalid Virtual Machine ID `%s`", VirtualMachineID)
+	}
+
+	return WithTransaction(func(Transaction *gorm.DB) error {
+		var VirtualMachine VirtualMachine
+		if Selected := Transaction.Select("id", "item_path").Where("id = ?", ID).First(&VirtualMachine); Selected.Error != nil {
+			return TranslateNotFound(Selected.Error)
+		}
+		if DeleteError := retireVirtualMachineRecords(Transaction, []int{ID}); DeleteError != nil {
+			return DeleteError
+		}
+		Event := NewVMEvent(ID, EventUnmanaged, fmt.Sprintf(
+			"Virtual Machine `%s` is no longer Managed, it has not been Destroyed in vSphere", VirtualMachine.ItemPath))
+		return Transaction.Create(Event).Error
+	})
+}
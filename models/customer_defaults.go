@@ -0,0 +1,58 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CustomerDefaults struct {
+	// Standard Virtual Machine Specification of the Customer, Applied to every Provisioned VM,
+	// Unless the Request Overrides it, Zero Values are treated as "Not Specified"
+	CustomerID        int       `json:"CustomerID" xml:"CustomerID" gorm:"primaryKey;autoIncrement:false;"`
+	CpuNum            int32     `json:"CpuNum,omitempty" xml:"CpuNum" gorm:"not null;default:0;"`
+	MemoryInMegabytes int64     `json:"MemoryInMegabytes,omitempty" xml:"MemoryInMegabytes" gorm:"not null;default:0;"`
+	DiskCapacityInKB  int64     `json:"DiskCapacityInKB,omitempty" xml:"DiskCapacityInKB" gorm:"not null;default:0;"`
+	Network           string    `json:"Network,omitempty" xml:"Network" gorm:"type:varchar(255);default:null;"`
+	Folder            string    `json:"Folder,omitempty" xml:"Folder" gorm:"type:varchar(255);default:null;"`
+	UpdatedAt         time.Time `json:"UpdatedAt" xml:"UpdatedAt"`
+}
+
+func NewCustomerDefaults(CustomerID int, CpuNum int32, MemoryInMegabytes int64, DiskCapacityInKB int64, Network string, Folder string) *CustomerDefaults {
+	return &CustomerDefaults{
+		CustomerID:        CustomerID,
+		CpuNum:            CpuNum,
+		MemoryInMegabytes: MemoryInMegabytes,
+		DiskCapacityInKB:  DiskCapacityInKB,
+		Network:           Network,
+		Folder:            Folder,
+	}
+}
+
+func GetCustomerDefaults(CustomerID int) (*CustomerDefaults, error) {
+	// Returns Default Provisioning Settings of the Customer, `ErrNotFound` if the Customer does not have them
+	Defaults := &CustomerDefaults{}
+	Gorm := Database.Where("customer_id = ?", CustomerID).First(Defaults)
+	if Gorm.Error != nil {
+		return nil, TranslateNotFound(Gorm.Error)
+	}
+	return Defaults, nil
+}
+
+func (this *CustomerDefaults) Save() (*gorm.DB, error) {
+	// Creates or Replaces Default Provisioning Settings of the Customer
+	return this.SaveContext(context.Background())
+}
+
+func (this *CustomerDefaults) SaveContext(Context context.Context) (*gorm.DB, error) {
+	// Creates or Replaces Default Provisioning Settings of the Customer, Query is Cancelled along with the Context
+	Saved := Database.WithContext(Context).Save(this)
+	return Saved, Saved.Error
+}
+
+func DeleteCustomerDefaults(CustomerID int) (*gorm.DB, error) {
+	// Removes Default Provisioning Settings of the Customer
+	Deleted := Database.Where("customer_id = ?", CustomerID).Delete(&CustomerDefaults{})
+	return Deleted, Deleted.Error
+}
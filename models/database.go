@@ -0,0 +1,183 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/tracing"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Connection to the Database is being Retried with the Exponential Backoff, so the Application does not Crash,
+// if it Starts along with the Database, that is not Accepting Connections yet (Common for the Container Orchestration)
+
+const (
+	DefaultDatabaseConnectAttempts = 5
+	DefaultDatabaseConnectBackoff  = time.Second
+)
+
+var (
+	// Amount of the Connection Attempts and the Delay before the Second one, Doubled after every next Attempt
+	DatabaseConnectAttempts = parseConnectAttempts(os.Getenv("DATABASE_CONNECT_RETRIES"))
+	DatabaseConnectBackoff  = parseConnectBackoff(os.Getenv("DATABASE_CONNECT_BACKOFF"))
+)
+
+func parseConnectAttempts(Value string) int {
+	// Returns Amount of the Connection Attempts, `DefaultDatabaseConnectAttempts` if the Value is Empty or Invalid
+	Attempts, ParseError := strconv.Atoi(Value)
+	if ParseError != nil || Attempts <= 0 {
+		return DefaultDatabaseConnectAttempts
+	}
+	return Attempts
+}
+
+func parseConnectBackoff(Value string) time.Duration {
+	// Returns the Initial Backoff, Value is either the Duration (`500ms`, `2s`) or the Amount of Seconds
+	if Backoff, ParseError := time.ParseDuration(Value); ParseError == nil && Backoff > 0 {
+		return Backoff
+	}
+	if Seconds, ParseError := strconv.Atoi(Value); ParseError == nil && Seconds > 0 {
+		return time.Duration(Seconds) * time.Second
+	}
+	return DefaultDatabaseConnectBackoff
+}
+
+const (
+	DefaultDatabaseMaxOpenConnections    = 25
+	DefaultDatabaseMaxIdleConnections    = 10
+	DefaultDatabaseConnectionMaxLifetime = time.Minute * 30
+)
+
+type ConnectionPoolSettings struct {
+	// Settings of the Connection Pool of the Database
+	MaxOpenConnections    int           // `DATABASE_MAX_OPEN_CONNS`
+	MaxIdleConnections    int           // `DATABASE_MAX_IDLE_CONNS`
+	ConnectionMaxLifetime time.Duration // `DATABASE_CONN_MAX_LIFETIME`, Duration (`30m`) or Amount of Seconds
+}
+
+func NewConnectionPoolSettings() ConnectionPoolSettings {
+	// Returns Connection Pool Settings from the Environment Variables, Unset or Invalid ones get the Defaults
+	Settings := ConnectionPoolSettings{
+		MaxOpenConnections:    DefaultDatabaseMaxOpenConnections,
+		MaxIdleConnections:    DefaultDatabaseMaxIdleConnections,
+		ConnectionMaxLifetime: DefaultDatabaseConnectionMaxLifetime,
+	}
+	if MaxOpen, ParseError := strconv.Atoi(os.Getenv("DATABASE_MAX_OPEN_CONNS")); ParseError == nil && MaxOpen > 0 {
+		Settings.MaxOpenConnections = MaxOpen
+	}
+	if MaxIdle, ParseError := strconv.Atoi(os.Getenv("DATABASE_MAX_IDLE_CONNS")); ParseError == nil && MaxIdle >= 0 {
+		Settings.MaxIdleConnections = MaxIdle
+	}
+	Lifetime := os.Getenv("DATABASE_CONN_MAX_LIFETIME")
+	if Duration, ParseError := time.ParseDuration(Lifetime); ParseError == nil && Duration > 0 {
+		Settings.ConnectionMaxLifetime = Duration
+	} else if Seconds, ParseError := strconv.Atoi(Lifetime); ParseError == nil && Seconds > 0 {
+		Settings.ConnectionMaxLifetime = time.Duration(Seconds) * time.Second
+	}
+	// Idle Connections above the Open Limit would be Closed by the Pool anyway
+	if Settings.MaxIdleConnections > Settings.MaxOpenConnections {
+		Settings.MaxIdleConnections = Settings.MaxOpenConnections
+	}
+	return Settings
+}
+
+func ConfigureConnectionPool(Instance *gorm.DB, Settings ConnectionPoolSettings) error {
+	// Applies the Settings to the Connection Pool (`*sql.DB`) of the Database Handle
+	Pool, PoolError := Instance.DB()
+	if PoolError != nil {
+		return PoolError
+	}
+	Pool.SetMaxOpenConns(Settings.MaxOpenConnections)
+	Pool.SetMaxIdleConns(Settings.MaxIdleConnections)
+	Pool.SetConnMaxLifetime(Settings.ConnectionMaxLifetime)
+	return nil
+}
+
+func DatabaseDSN() string {
+	// Returns Connection String of the Database, based on the `DATABASE_*` Environment Variables
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s",
+		DATABASE_HOST, DATABASE_PORT, DATABASE_USER, DATABASE_PASSWORD, DATABASE_NAME)
+}
+
+func ConnectDatabase() (*gorm.DB, error) {
+	// Connects to the Database, Configured by the Environment Variables (See `ConnectDatabaseWithDSN`)
+	return ConnectDatabaseWithDSN(DatabaseDSN())
+}
+
+func ConnectDatabaseWithDSN(DSN string) (*gorm.DB, error) {
+	// Connects to the Database, Retrying up to `DatabaseConnectAttempts` Times with the Exponential Backoff
+	// If every Attempt Fails, the Errors of all of them are Returned along with the Handle of the Last Attempt (As `gorm.Open` does),
+	// the Handle Reconnects Lazily, so Queries start Working, once the Database becomes Available
+	// Configuration Errors (Invalid Driver or Credentials) are not Retried
+
+	var Instance *gorm.DB
+	var Failures []string
+	Backoff := DatabaseConnectBackoff
+
+	for Attempt := 1; Attempt <= DatabaseConnectAttempts; Attempt++ {
+		DatabaseInstance, ConnectionError := gorm.Open(postgres.New(postgres.Config{DSN: DSN}))
+		if DatabaseInstance != nil {
+			Instance = DatabaseInstance
+			if PoolError := ConfigureConnectionPool(Instance, NewConnectionPoolSettings()); PoolError != nil && Logger != nil {
+				Logger.Error("Failed to Configure Database Connection Pool", zap.Error(PoolError))
+			}
+			if TracingError := tracing.RegisterGormCallbacks(Instance); TracingError != nil && Logger != nil {
+				Logger.Error("Failed to Register Tracing Callbacks of the Database", zap.Error(TracingError))
+			}
+		}
+		if ConnectionError == nil {
+			return Instance, nil
+		}
+		Failures = append(Failures, fmt.Sprintf("Attempt #%d: %s", Attempt, ConnectionError))
+
+		if isConfigurationError(ConnectionError) {
+			return Instance, fmt.Errorf("Invalid Database Configuration: %w", ConnectionError)
+		}
+		if Attempt == DatabaseConnectAttempts {
+			return Instance, fmt.Errorf("Failed to Connect to the Database after %d Attempts (%s): %w",
+				Attempt, strings.Join(Failures, "; "), ConnectionError)
+		}
+		if Logger != nil {
+			Logger.Warn("Failed to Connect to the Database, Retrying", zap.Int("Attempt", Attempt),
+				zap.Duration("Backoff", Backoff), zap.Error(ConnectionError))
+		}
+		time.Sleep(Backoff)
+		Backoff *= 2
+	}
+	return Instance, errors.New("Database Connection has not been Attempted")
+}
+
+func PingDatabase(Context context.Context) error {
+	// Checks, that the Database is Reachable (For the Readiness Probes), without Running any Query,
+	// Error Contains the Configured Host, so it is clear, which Database is Unavailable
+	if Database == nil {
+		return fmt.Errorf("Database `%s` is not Connected", DATABASE_HOST)
+	}
+	Pool, PoolError := Database.DB()
+	if PoolError != nil {
+		return fmt.Errorf("Database `%s` is not Available: %w", DATABASE_HOST, PoolError)
+	}
+	if PingError := Pool.PingContext(Context); PingError != nil {
+		return fmt.Errorf("Database `%s` is not Reachable: %w", DATABASE_HOST, PingError)
+	}
+	return nil
+}
+
+func isConfigurationError(ConnectionError error) bool {
+	// Returns True if the Error is Caused by the Configuration, so Retrying does not Help
+	return errors.Is(ConnectionError, gorm.ErrInvalidDB) || errors.Is(ConnectionError, gorm.ErrUnsupportedDriver) ||
+		errors.Is(ConnectionError, gorm.ErrNotImplemented)
+}
+
+func WithTransaction(Operation func(Transaction *gorm.DB) error) error {
+	// Runs the Operation within the Single Transaction, that is Committed only if the Operation Succeeds,
+	// Use it for the Operations, that Touch several Tables, so they never Leave Orphaned Rows behind
+	return Database.Transaction(Operation)
+}
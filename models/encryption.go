@@ -0,0 +1,139 @@
+package models
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var (
+	// Base64 Encoded AES Key (16, 24 or 32 Bytes), the `EncryptedString` Columns are being Encrypted with
+	SECRETS_ENCRYPTION_KEY = os.Getenv("SECRETS_ENCRYPTION_KEY")
+)
+
+var (
+	ErrEncryptionKeyNotConfigured = errors.New("`SECRETS_ENCRYPTION_KEY` is not Set")
+	ErrInvalidEncryptionKey       = errors.New("`SECRETS_ENCRYPTION_KEY` should be Base64 Encoded AES Key of 16, 24 or 32 Bytes")
+	ErrInvalidCiphertext          = errors.New("Failed to Decrypt the Encrypted Column")
+)
+
+var fieldCipher cipher.AEAD // Cipher of the `EncryptedString` Columns, Nil until the Key is Loaded
+
+func LoadEncryptionKey() error {
+	// Loads the Key of the `EncryptedString` Columns from the `SECRETS_ENCRYPTION_KEY`,
+	// Should be Checked at the Startup, so the Server does not Run without the ability to Read the Secrets
+	Key, KeyError := DecodeEncryptionKey()
+	if KeyError != nil {
+		return KeyError
+	}
+	return InitializeFieldEncryption(Key)
+}
+
+func DecodeEncryptionKey() ([]byte, error) {
+	// Returns the Key, Decoded from the `SECRETS_ENCRYPTION_KEY`, it is Shared by every Encrypted Secret of the Project
+	if len(SECRETS_ENCRYPTION_KEY) == 0 {
+		return nil, ErrEncryptionKeyNotConfigured
+	}
+	Key, DecodeError := base64.StdEncoding.DecodeString(SECRETS_ENCRYPTION_KEY)
+	if DecodeError != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEncryptionKey, DecodeError)
+	}
+	return Key, nil
+}
+
+func InitializeFieldEncryption(Key []byte) error {
+	// Sets the AES Key of the `EncryptedString` Columns
+	Cipher, CipherError := NewSecretCipher(Key)
+	if CipherError != nil {
+		return CipherError
+	}
+	fieldCipher = Cipher
+	return nil
+}
+
+func NewSecretCipher(Key []byte) (cipher.AEAD, error) {
+	// Returns AES-GCM Cipher of the Key, `ErrInvalidEncryptionKey` is Returned, if it is not of 16, 24 or 32 Bytes
+	switch len(Key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("%w, got %d Bytes", ErrInvalidEncryptionKey, len(Key))
+	}
+	Block, KeyError := aes.NewCipher(Key)
+	if KeyError != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEncryptionKey, KeyError)
+	}
+	return cipher.NewGCM(Block)
+}
+
+func EncryptSecret(Cipher cipher.AEAD, Plaintext []byte, AdditionalData []byte) ([]byte, error) {
+	// Returns `Nonce | Ciphertext`, Every Call uses the new Nonce, so same Values have different Ciphertexts,
+	// Additional Data (Optional) has to be Passed to the `DecryptSecret` as well, e.g to Bind the Secret to its Owner
+	Nonce := make([]byte, Cipher.NonceSize())
+	if _, RandomError := io.ReadFull(rand.Reader, Nonce); RandomError != nil {
+		return nil, RandomError
+	}
+	return Cipher.Seal(Nonce, Nonce, Plaintext, AdditionalData), nil
+}
+
+func DecryptSecret(Cipher cipher.AEAD, Ciphertext []byte, AdditionalData []byte) ([]byte, error) {
+	// Returns Plaintext of the `EncryptSecret` Result, `ErrInvalidCiphertext` is Returned, if it has been Tampered
+	// or Encrypted with another Key or Additional Data
+	NonceSize := Cipher.NonceSize()
+	if len(Ciphertext) < NonceSize {
+		return nil, ErrInvalidCiphertext
+	}
+	Plaintext, DecryptError := Cipher.Open(nil, Ciphertext[:NonceSize], Ciphertext[NonceSize:], AdditionalData)
+	if DecryptError != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return Plaintext, nil
+}
+
+type EncryptedString string // String, that is Kept Encrypted with AES-GCM in the Database and Decrypted on Read
+
+func (this EncryptedString) Value() (driver.Value, error) {
+	// Returns Base64 Encoded `Nonce | Ciphertext` (See `EncryptSecret`)
+	if fieldCipher == nil {
+		return nil, ErrEncryptionKeyNotConfigured
+	}
+	Ciphertext, EncryptError := EncryptSecret(fieldCipher, []byte(this), nil)
+	if EncryptError != nil {
+		return nil, EncryptError
+	}
+	return base64.StdEncoding.EncodeToString(Ciphertext), nil
+}
+
+func (this *EncryptedString) Scan(Source interface{}) error {
+	var Encoded string
+	switch Source := Source.(type) {
+	case nil:
+		*this = ""
+		return nil
+	case string:
+		Encoded = Source
+	case []byte:
+		Encoded = string(Source)
+	default:
+		return fmt.Errorf("Unsupported Type `%T` of the Encrypted Column", Source)
+	}
+	if fieldCipher == nil {
+		return ErrEncryptionKeyNotConfigured
+	}
+
+	Ciphertext, DecodeError := base64.StdEncoding.DecodeString(Encoded)
+	if DecodeError != nil {
+		return ErrInvalidCiphertext
+	}
+	Plaintext, DecryptError := DecryptSecret(fieldCipher, Ciphertext, nil)
+	if DecryptError != nil {
+		return DecryptError
+	}
+	*this = EncryptedString(Plaintext)
+	return nil
+}
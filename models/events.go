@@ -0,0 +1,130 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/options"
+	"go.uber.org/zap"
+)
+
+// Virtual Machine Event Types, that are shown on the Virtual Machine Activity Feed
+
+const EventCreated = "Created"
+const EventConfigured = "Configured"
+const EventPoweredOn = "PoweredOn"
+const EventPoweredOff = "PoweredOff"
+const EventRebooted = "Rebooted"
+const EventResized = "Resized"
+const EventSnapshotTaken = "SnapshotTaken"
+const EventDestroyed = "Destroyed"
+const EventUnmanaged = "Unmanaged" // Virtual Machine is no longer Managed, but Keeps Running in vSphere
+
+const resizeEventDetailFormat = "%d CPUs, %d MB (was %d CPUs, %d MB)"
+
+func ResizeEventDetail(NumCPU int32, MemoryMB int64, PreviousNumCPU int32, PreviousMemoryMB int64) string {
+	// Returns Detail of the `EventResized`, it Keeps both the New and Previous Size, so the Size of the Virtual Machine
+	// at any Moment can be Restored from the Timeline
+	return fmt.Sprintf(resizeEventDetailFormat, NumCPU, MemoryMB, PreviousNumCPU, PreviousMemoryMB)
+}
+
+const EventQueueSize = 1000 // Max Amount of the Events, waiting to be Written to the Database
+
+var (
+	eventQueue = make(chan VMEvent, EventQueueSize)
+)
+
+type VMEvent struct {
+	// Represents Single Entry of the Virtual Machine Timeline
+	ID               int
+	VirtualMachineID int       `json:"VirtualMachineID" xml:"VirtualMachineID" gorm:"<-:create;not null;index;"`
+	Type             string    `json:"Type" xml:"Type" gorm:"<-:create;type:varchar(50);not null;"`
+	Detail           string    `json:"Detail" xml:"Detail" gorm:"<-:create;type:text;"`
+	CreatedAt        time.Time `json:"CreatedAt" xml:"CreatedAt" gorm:"<-:create;not null;index;"`
+}
+
+func NewVMEvent(VirtualMachineID int, Type string, Detail string) *VMEvent {
+	return &VMEvent{
+		VirtualMachineID: VirtualMachineID,
+		Type:             Type,
+		Detail:           Detail,
+		CreatedAt:        time.Now(),
+	}
+}
+
+func RecordVMEvent(VirtualMachineID int, Type string, Detail string) {
+	// Appends new Event to the Virtual Machine Timeline
+	// The Write is being performed in Background, so it does not slow down the Operation,
+	// If the Queue is full, Event is being dropped and logged
+	// Billable Events are never Queued, they are Written right away (See `RecordBillableVMEvent`)
+
+	if IsBillableEvent(Type) {
+		if RecordError := RecordBillableVMEvent(VirtualMachineID, Type, Detail); RecordError != nil {
+			Logger.Error("Failed to Save Billable Virtual Machine Event", zap.Int("Virtual Machine ID", VirtualMachineID),
+				zap.String("Type", Type), zap.Error(RecordError))
+		}
+		return
+	}
+
+	select {
+	case eventQueue <- *NewVMEvent(VirtualMachineID, Type, Detail):
+	default:
+		Logger.Error("Event Queue is Full, Dropping Virtual Machine Event",
+			zap.Int("Virtual Machine ID", VirtualMachineID), zap.String("Type", Type))
+	}
+}
+
+func IsBillableEvent(Type string) bool {
+	// Returns True for the Events, the Usage of the Virtual Machine is Computed from (See `ComputeResourceHours`)
+	switch Type {
+	case EventPoweredOn, EventPoweredOff, EventResized, EventDestroyed:
+		return true
+	default:
+		return false
+	}
+}
+
+func RecordBillableVMEvent(VirtualMachineID int, Type string, Detail string, Options ...options.OperationOption) error {
+	// Writes the Event to the Timeline Synchronously, Retrying on Failures, so it is not Lost, unlike the Queued ones
+	Operation := options.NewOperationOptions(DefaultQueryTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	Event := NewVMEvent(VirtualMachineID, Type, Detail)
+	return Operation.Retry(TimeoutContext, func() error {
+		Event.ID = 0
+		return Database.WithContext(TimeoutContext).Create(Event).Error
+	})
+}
+
+func RecordVMEventByUUID(UUID string, Type string, Detail string) {
+	// Appends new Event to the Timeline of the Virtual Machine with the vSphere UUID (`config.uuid`),
+	// for the Operations, that only have the vSphere Object, Virtual Machines without the Record are being Skipped
+	VirtualMachine, LookupError := GetVirtualMachineByUUID(UUID)
+	if LookupError != nil {
+		Logger.Warn("Event of the Unknown Virtual Machine has not been Recorded", zap.String("UUID", UUID),
+			zap.String("Type", Type), zap.Error(LookupError))
+		return
+	}
+	RecordVMEvent(VirtualMachine.ID, Type, Detail)
+}
+
+func runEventWriter() {
+	// Writes Queued Virtual Machine Events to the Database
+	for Event := range eventQueue {
+		Event := Event
+		if Created := Database.Create(&Event); Created.Error != nil {
+			Logger.Error("Failed to Save Virtual Machine Event",
+				zap.Int("Virtual Machine ID", Event.VirtualMachineID),
+				zap.String("Type", Event.Type), zap.Error(Created.Error))
+		}
+	}
+}
+
+func GetVMTimeline(VirtualMachineID int, Limit int) ([]VMEvent, error) {
+	// Returns Recent Events of the Virtual Machine, Newest First
+	Events := []VMEvent{}
+	Gorm := Database.Model(&VMEvent{}).Where("virtual_machine_id = ?", VirtualMachineID).Order(
+		"created_at DESC, id DESC").Limit(Limit).Find(&Events)
+	return Events, Gorm.Error
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql"
+
+	"go.uber.org/zap"
+)
+
+// Maintenance Tools, that are Checking the Relations between the Tables,
+// Which are not being Enforced by the Database Foreign Keys (yet)
+
+type IntegrityReport struct {
+	DanglingKeyIDs            []int `json:"DanglingKeyIDs" xml:"DanglingKeyIDs"`                       // Keys, Referencing Nonexistent Virtual Machines
+	DanglingKeyCount          int   `json:"DanglingKeyCount" xml:"DanglingKeyCount"`                   // Amount of the Dangling Keys
+	MissingKeyVirtualMachines []int `json:"MissingKeyVirtualMachines" xml:"MissingKeyVirtualMachines"` // Virtual Machines, Configured to use Certificate, but without any Key
+	MissingKeyCount           int   `json:"MissingKeyCount" xml:"MissingKeyCount"`                     // Amount of the Virtual Machines without Keys
+	RepairedKeyCount          int64 `json:"RepairedKeyCount" xml:"RepairedKeyCount"`                   // Amount of the Deleted Dangling Keys
+}
+
+func CheckSSHKeyIntegrity() (*IntegrityReport, error) {
+	// Finds SSH Keys, whose Virtual Machine does not Exist,
+	// And Virtual Machines, that use Certificate SSH Method, but don't have any Key
+
+	Report := &IntegrityReport{DanglingKeyIDs: []int{}, MissingKeyVirtualMachines: []int{}}
+
+	if Gorm := Database.Model(&SSHPublicKey{}).Where("virtual_machine_id NOT IN (?)",
+		Database.Model(&VirtualMachine{}).Select("id")).Order("id").Pluck("id", &Report.DanglingKeyIDs); Gorm.Error != nil {
+		Logger.Error("Failed to Find Dangling SSH Keys", zap.Error(Gorm.Error))
+		return nil, Gorm.Error
+	}
+
+	// Ssh Info is being Decoded manually, because it might be Null for some Virtual Machines
+	var VirtualMachines []struct {
+		ID     int
+		SshKey sql.NullString
+	}
+	if Gorm := Database.Model(&VirtualMachine{}).Select("id", "ssh_key").Where("id NOT IN (?)",
+		Database.Model(&SSHPublicKey{}).Select("virtual_machine_id")).Order("id").Scan(&VirtualMachines); Gorm.Error != nil {
+		Logger.Error("Failed to Find Virtual Machines without SSH Keys", zap.Error(Gorm.Error))
+		return nil, Gorm.Error
+	}
+	for _, VirtualMachine := range VirtualMachines {
+		var SshInfo SSHConfiguration
+		if !VirtualMachine.SshKey.Valid || SshInfo.Scan(VirtualMachine.SshKey.String) != nil {
+			continue
+		}
+		if SshInfo.Type == TypeByRootCertificate {
+			Report.MissingKeyVirtualMachines = append(Report.MissingKeyVirtualMachines, VirtualMachine.ID)
+		}
+	}
+
+	Report.DanglingKeyCount = len(Report.DanglingKeyIDs)
+	Report.MissingKeyCount = len(Report.MissingKeyVirtualMachines)
+	return Report, nil
+}
+
+func RepairSSHKeyIntegrity(DryRun bool) (*IntegrityReport, error) {
+	// Deletes Dangling SSH Keys, Found by the `CheckSSHKeyIntegrity`
+	// If `DryRun` is True, only Reports what is going to be Deleted
+	// NOTE: Virtual Machines without Keys can't be Repaired automatically, they are only Reported
+
+	Report, CheckError := CheckSSHKeyIntegrity()
+	if CheckError != nil || DryRun || Report.DanglingKeyCount == 0 {
+		return Report, CheckError
+	}
+
+	Deleted := Database.Unscoped().Where("id IN ?", Report.DanglingKeyIDs).Delete(&SSHPublicKey{})
+	if Deleted.Error != nil {
+		Logger.Error("Failed to Delete Dangling SSH Keys", zap.Error(Deleted.Error))
+		return Report, Deleted.Error
+	}
+	Report.RepairedKeyCount = Deleted.RowsAffected
+	Logger.Debug("Dangling SSH Keys has been Deleted", zap.Ints("Key IDs", Report.DanglingKeyIDs))
+	return Report, nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidPagination = errors.New("Invalid Pagination")
+)
+
+func (this ListOptions) Validate() error {
+	// Checks, that the Limit is within (0, `MaxListLimit`] and the Offset is not Negative,
+	// Unlike the `Normalize`, Out of Bounds Values are Rejected, instead of being Adjusted
+	if this.Limit <= 0 || this.Limit > MaxListLimit {
+		return fmt.Errorf("%w: Limit should be between 1 and %d", ErrInvalidPagination, MaxListLimit)
+	}
+	if this.Offset < 0 {
+		return fmt.Errorf("%w: Offset can't be Negative", ErrInvalidPagination)
+	}
+	return nil
+}
+
+func ListVirtualMachines(Limit int, Offset int) ([]VirtualMachine, int64, error) {
+	// Returns the Page of the Virtual Machines, Ordered by ID, along with the Total Amount of them
+	if ValidationError := (ListOptions{Limit: Limit, Offset: Offset}).Validate(); ValidationError != nil {
+		return nil, 0, ValidationError
+	}
+
+	var Total int64
+	if Gorm := Database.Model(&VirtualMachine{}).Count(&Total); Gorm.Error != nil {
+		Logger.Error("Failed to Count Virtual Machines", zap.Error(Gorm.Error))
+		return nil, 0, Gorm.Error
+	}
+	VirtualMachines := []VirtualMachine{}
+	if Gorm := Database.Order("id").Offset(Offset).Limit(Limit).Find(&VirtualMachines); Gorm.Error != nil {
+		Logger.Error("Failed to List Virtual Machines", zap.Error(Gorm.Error))
+		return nil, 0, Gorm.Error
+	}
+	return VirtualMachines, Total, nil
+}
+
+func ListCustomers(Limit int, Offset int) ([]Customer, int64, error) {
+	// Returns the Page of the Customers, Ordered by ID, along with the Total Amount of them,
+	// Password Hashes are not being Loaded
+	if ValidationError := (ListOptions{Limit: Limit, Offset: Offset}).Validate(); ValidationError != nil {
+		return nil, 0, ValidationError
+	}
+
+	var Total int64
+	if Gorm := Database.Model(&Customer{}).Count(&Total); Gorm.Error != nil {
+		Logger.Error("Failed to Count Customers", zap.Error(Gorm.Error))
+		return nil, 0, Gorm.Error
+	}
+	Customers := []Customer{}
+	if Gorm := Database.Omit("password").Order("id").Offset(Offset).Limit(Limit).Find(&Customers); Gorm.Error != nil {
+		Logger.Error("Failed to List Customers", zap.Error(Gorm.Error))
+		return nil, 0, Gorm.Error
+	}
+	return Customers, Total, nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+)
+
+const ContentTypeJSON = "application/json"
+const ContentTypeXML = "application/xml"
+
+var (
+	ErrNotAcceptable = errors.New("None of the Accepted Media Types is Supported, Supported are JSON and XML")
+)
+
+func MarshalResponse(Accept string, Value interface{}) ([]byte, string, error) {
+	// Serializes the Value to the First Supported Media Type of the `Accept` Header (JSON or XML) and Returns it
+	// along with the Content Type, JSON is Used, if Nothing is Specified or any Type is Accepted
+	// Quality Values (`;q=`) are not being Weighed, Media Types are Taken in the Order, they are Listed in
+
+	if len(strings.TrimSpace(Accept)) == 0 {
+		Accept = ContentTypeJSON
+	}
+	for _, MediaType := range strings.Split(Accept, ",") {
+		MediaType = strings.ToLower(strings.TrimSpace(strings.SplitN(MediaType, ";", 2)[0]))
+		switch MediaType {
+		case ContentTypeJSON, "application/*", "*/*":
+			Serialized, MarshalError := json.Marshal(Value)
+			return Serialized, ContentTypeJSON, MarshalError
+		case ContentTypeXML, "text/xml":
+			Serialized, MarshalError := xml.Marshal(Value)
+			return Serialized, ContentTypeXML, MarshalError
+		}
+	}
+	return nil, "", ErrNotAcceptable
+}
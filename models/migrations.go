@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Migrations, that `AutoMigrate` can't Apply by itself, they are Idempotent and Run before it on every Startup
+//
+// `virtual_machines.owner_id` used to be the Unique `varchar` Column, so a Customer could Own only one Virtual Machine.
+// It is now the `integer` Foreign Key to the `customers.id`, Indexed, but not Unique.
+// Rows, that Reference Customers, who do not Exist anymore, Prevent the Foreign Key from being Created,
+// they are Reported on Startup and should be Deleted (or Reassigned) by Hand:
+//
+//	SELECT id, owner_id FROM virtual_machines WHERE owner_id NOT IN (SELECT id FROM customers);
+
+func migrateVirtualMachineOwner(Database *gorm.DB) error {
+	// Drops the Unique Constraint of the `owner_id` and Converts it to the `integer`, so the Foreign Key can be Created
+
+	Migrator := Database.Migrator()
+	if !Migrator.HasTable(&VirtualMachine{}) {
+		return nil
+	}
+	if Dropped := Database.Exec("ALTER TABLE virtual_machines DROP CONSTRAINT IF EXISTS virtual_machines_owner_id_key"); Dropped.Error != nil {
+		return Dropped.Error
+	}
+
+	ColumnTypes, TypesError := Migrator.ColumnTypes(&VirtualMachine{})
+	if TypesError != nil {
+		return TypesError
+	}
+	for _, ColumnType := range ColumnTypes {
+		if ColumnType.Name() != "owner_id" || !strings.Contains(strings.ToLower(ColumnType.DatabaseTypeName()), "char") {
+			continue
+		}
+		// AutoMigrate Alters the Type without the `USING` Clause, which Postgres Rejects for `varchar` -> `integer`
+		if Altered := Database.Exec("ALTER TABLE virtual_machines ALTER COLUMN owner_id TYPE integer USING owner_id::integer"); Altered.Error != nil {
+			return Altered.Error
+		}
+		Logger.Info("Owners of the Virtual Machines have been Converted to the Integer Column")
+	}
+
+	var Orphaned int64
+	if Counted := Database.Unscoped().Model(&VirtualMachine{}).Where(
+		"owner_id NOT IN (SELECT id FROM customers)").Count(&Orphaned); Counted.Error == nil && Orphaned != 0 {
+		Logger.Error("Virtual Machines Reference Customers, who do not Exist, Foreign Key of the Owner can't be Created",
+			zap.Int64("Virtual Machines", Orphaned))
+	}
+	return nil
+}
+
+func migrateEmptyIPAddresses(Database *gorm.DB) error {
+	// Replaces the Empty IP Addresses with NULL, `ip_address` is Unique, so only one Virtual Machine could have the Empty one
+	if !Database.Migrator().HasTable(&VirtualMachine{}) {
+		return nil
+	}
+	Updated := Database.Exec("UPDATE virtual_machines SET ip_address = NULL WHERE ip_address = ''")
+	if Updated.Error == nil && Updated.RowsAffected != 0 {
+		Logger.Info("Empty IP Addresses of the Virtual Machines have been Replaced with NULL", zap.Int64("Virtual Machines", Updated.RowsAffected))
+	}
+	return Updated.Error
+}
+
+func migrateSshKeyFingerprints(Database *gorm.DB) error {
+	// Fills the Fingerprints of the SSH Keys, Added before the Column, Runs after the `AutoMigrate`, which Adds it.
+	// Keys, that Duplicate another Key of the same Virtual Machine, are Left without the Fingerprint and Reported
+	var Keys []SSHPublicKey
+	if Gorm := Database.Unscoped().Where("fingerprint IS NULL").Order("id").Find(&Keys); Gorm.Error != nil {
+		return Gorm.Error
+	}
+	for Index := range Keys {
+		Fingerprint, FingerprintError := Keys[Index].Fingerprint()
+		if FingerprintError != nil {
+			continue
+		}
+		Keys[Index].KeyFingerprint = Fingerprint
+		Updated := Database.Unscoped().Model(&SSHPublicKey{}).Where("id = ?", Keys[Index].ID).Update("fingerprint", Fingerprint)
+		if UpdateError := translateDuplicateSshKey(Updated.Error, &Keys[Index]); errors.Is(UpdateError, ErrDuplicateKey) {
+			Logger.Warn("SSH Key Duplicates another Key of the Virtual Machine, it should be Deleted by Hand",
+				zap.Int("SSH Key ID", Keys[Index].ID), zap.Int("Virtual Machine ID", Keys[Index].VirtualMachineID))
+		} else if UpdateError != nil {
+			return UpdateError
+		}
+	}
+	return nil
+}
@@ -1,34 +1,64 @@
 package models
 
 import (
+	"context"
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/mail"
+	"strings"
 	"time"
 
 	"os"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/LovePelmeni/Infrastructure/logging"
+	"github.com/LovePelmeni/Infrastructure/options"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/driver/postgres"
-
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 var (
-	Logger *zap.Logger
+	Logger  *zap.Logger
+	logFile *os.File // Log File of the Current Logger, Closed by the `CloseLogger`
 )
 
 var (
 	Database *gorm.DB
 )
 
+const DefaultQueryTimeout = time.Second * 10 // Default Timeout of the Model Methods, See `options.OperationOptions`
+
+var (
+	// Returned by the Single Record Lookups, when there is no Row matching the Query,
+	// Wraps `gorm.ErrRecordNotFound`, so both of them can be checked via `errors.Is`
+	ErrNotFound = fmt.Errorf("Record Not Found: %w", gorm.ErrRecordNotFound)
+)
+
+func TranslateNotFound(Error error) error {
+	// Translates `gorm.ErrRecordNotFound` into the Package's `ErrNotFound`,
+	// Any other Error is being returned as it is
+	if errors.Is(Error, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return Error
+}
+
 const StatusNotReady = "NotReady" // Defines the Status of the Virtual Machine Availability
 const StatusReady = "Ready"       // Defines the Status of Virtual Machine Availability
 
+// Provisioning Statuses of the Virtual Machine
+const StatusPending = "Pending"           // Virtual Machine is waiting to be Provisioned
+const StatusProvisioning = "Provisioning" // Virtual Machine is being Provisioned
+const StatusFailed = "Failed"             // Provisioning of the Virtual Machine has Failed
+
 var (
 	DATABASE_NAME     = os.Getenv("DATABASE_NAME")
 	DATABASE_HOST     = os.Getenv("DATABASE_HOST")
@@ -37,101 +67,342 @@ var (
 	DATABASE_PASSWORD = os.Getenv("DATABASE_PASSWORD")
 )
 
-func InitializeProductionLogger() {
+func InitializeLogger() error {
+	// Initializes Logger, Configured by the Environment (See `logging.NewLogger`), `ModelsLog.json` is the Default Log File
+	// Returns Error if the Log File can't be Opened, the Current Logger is Left Untouched in that Case
 
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("ModelsLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
+	NewLogger, file, LoggerError := logging.NewLogger("ModelsLog.json")
+	if LoggerError != nil {
+		return LoggerError
+	}
 
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Previous Logger is Flushed and its File is Closed, so Re-Initialization does not Leak File Handles
+	CloseLogger()
+	Logger = NewLogger
+	logFile = file
+	return nil
 }
 
-func init() {
-	DatabaseInstance, ConnectionError := gorm.Open(postgres.New(postgres.Config{
-		DSN: fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s",
-			DATABASE_HOST, DATABASE_PORT, DATABASE_USER, DATABASE_PASSWORD, DATABASE_NAME),
-	}))
-
-	switch ConnectionError {
+func InitializeProductionLogger() {
+	// Initializes Logger (See `InitializeLogger`), if the Log File can't be Opened, Logs are Written to the Stderr,
+	// so they are not Lost
+	if LoggerError := InitializeLogger(); LoggerError != nil {
+		CloseLogger()
+		Logger = logging.NewStderrLogger()
+		Logger.Error("Failed to Initialize Logger, Stderr is being Used", zap.Error(LoggerError))
+	}
+}
 
-	case gorm.ErrInvalidDB:
-		panic("Please Setup Correct Credentials for your PostgreSQL Database: Host, Port, User, Password, DbName")
+func CloseLogger() error {
+	// Flushes Buffered Logs and Closes the Log File, Called by the `Shutdown`
+	// Logger is Replaced with the No-op one, so the Late Logs are Dropped instead of being Written to the Closed File
+	if Logger != nil {
+		Logger.Sync()
+	}
+	if logFile == nil {
+		return nil
+	}
+	CloseError := logFile.Close()
+	logFile = nil
+	Logger = zap.NewNop()
+	return CloseError
+}
 
-	case gorm.ErrUnsupportedDriver:
-		panic("Invalid Database Driver")
+func init() {
+	InitializeProductionLogger()
 
-	case gorm.ErrNotImplemented:
-		panic("Please Setup Credentials for the Database, " +
-			"so it knows where to connect, go to `env` " +
-			"directory and fill up `project.env` file with new Database Credentials")
+	// Server Refuses to Start without the Key (See `main`), here it is only Reported, so the Package stays Usable in Tools and Tests
+	if KeyError := LoadEncryptionKey(); KeyError != nil {
+		Logger.Error("Encrypted Columns can't be Read or Written", zap.Error(KeyError))
 	}
 
+	DatabaseInstance, ConnectionError := ConnectDatabase()
 	Database = DatabaseInstance
-	Database.AutoMigrate(&Customer{}, &VirtualMachine{})
-	InitializeProductionLogger()
+	if ConnectionError != nil {
+		Logger.Error("Failed to Connect to the Database, Please Setup Correct Credentials for your PostgreSQL Database: "+
+			"Host, Port, User, Password, DbName (See `env/project.env`)", zap.Error(ConnectionError))
+	} else {
+		// Columns, AutoMigrate can't Convert by itself, are Migrated first (See `migrateVirtualMachineOwner`)
+		if MigrationError := migrateVirtualMachineOwner(Database); MigrationError != nil {
+			Logger.Error("Failed to Migrate Owners of the Virtual Machines", zap.Error(MigrationError))
+		}
+		if MigrationError := migrateEmptyIPAddresses(Database); MigrationError != nil {
+			Logger.Error("Failed to Migrate Empty IP Addresses of the Virtual Machines", zap.Error(MigrationError))
+		}
+		Database.AutoMigrate(&Customer{}, &VirtualMachine{}, &VMEvent{}, &SSHPublicKey{}, &BackfillCheckpoint{}, &VirtualMachineTag{}, &VirtualMachineSecret{}, &PowerSchedule{}, &CustomerDefaults{}, &ProvisioningRequest{}, &PasswordResetToken{}, &AuditLog{})
+		if MigrationError := migrateSshKeyFingerprints(Database); MigrationError != nil {
+			Logger.Error("Failed to Migrate Fingerprints of the SSH Keys", zap.Error(MigrationError))
+		}
+	}
+	go runEventWriter()
 }
 
 type Customer struct {
 	// Customer Database ORM Model
-	ID       int
-	Username string `json:"Username" gorm:"<-:create;type:varchar(100); not null; unique;"`
-	Email    string `json:"Email" gorm:"<-:create;type:varchar(100); not null; unique;"`
-	Password string `json:"Password" gorm:"type:varchar(100); not null;"`
+	ID       int    `json:"ID" xml:"ID"`
+	Username string `json:"Username" xml:"Username" gorm:"<-:create;type:varchar(100); not null; unique;"`
+	Email    string `json:"Email" xml:"Email" gorm:"type:varchar(100); not null; unique;"` // Can be Changed by the `UpdateEmail`
+	Password string `json:"Password" xml:"Password" gorm:"type:varchar(100); not null;"`
 
 	City    string `json:"City" xml:"City" gorm:"varchar(100); not null;"`
 	Country string `json:"Country" xml:"Country" gorm:"type:varchar(100); not null;"`
 	ZipCode string `json:"ZipCode" xml:"ZipCode" gorm:"type:varchar(100); not null;"`
 	Street  string `json:"Street" xml:"Street" gorm:"type:varchar(100); not null;"`
+
+	DeletedAt gorm.DeletedAt `json:"-" xml:"-" gorm:"index;"` // Set by the `SoftDelete`, such Customers are Hidden from the Queries
 }
 
-func NewCustomer(Username string, Password string, Email string, City string, Country string, ZipCode string, Street string) *Customer {
+const MinPasswordLength = 12
+
+var (
+	ErrWeakPassword = errors.New("Password is too Weak")
+	ErrInvalidEmail = errors.New("Invalid Email Address")
+)
+
+func ValidateEmail(Email string) error {
+	// Checks, that the Email is the Bare Address (Like `customer@example.com`), without the Display Name
+	Address, ParseError := mail.ParseAddress(Email)
+	if ParseError != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidEmail, ParseError)
+	}
+	if Address.Address != strings.TrimSpace(Email) {
+		return fmt.Errorf("%w: only the Address itself is Allowed", ErrInvalidEmail)
+	}
+	return nil
+}
+
+func ValidatePassword(Password string) error {
+	// Checks, that the Password is at least `MinPasswordLength` Characters long
+	// and has at least one Digit, one Uppercase Letter and one Special Character
+	if utf8.RuneCountInString(Password) < MinPasswordLength {
+		return fmt.Errorf("%w: it should be at least %d Characters long", ErrWeakPassword, MinPasswordLength)
+	}
+	var HasDigit, HasUpper, HasSpecial bool
+	for _, Character := range Password {
+		switch {
+		case unicode.IsDigit(Character):
+			HasDigit = true
+		case unicode.IsUpper(Character):
+			HasUpper = true
+		case !unicode.IsLetter(Character) && !unicode.IsSpace(Character):
+			HasSpecial = true
+		}
+	}
+	switch {
+	case !HasDigit:
+		return fmt.Errorf("%w: it should contain at least one Digit", ErrWeakPassword)
+	case !HasUpper:
+		return fmt.Errorf("%w: it should contain at least one Uppercase Letter", ErrWeakPassword)
+	case !HasSpecial:
+		return fmt.Errorf("%w: it should contain at least one Special Character", ErrWeakPassword)
+	}
+	return nil
+}
+
+func NormalizeEmail(Email string) string {
+	// Returns Email in the Form it is Stored in, so the Addresses, that Differ only by the Case, Belong to the same Account
+	return strings.ToLower(strings.TrimSpace(Email))
+}
+
+func NewCustomer(Username string, Password string, Email string, City string, Country string, ZipCode string, Street string) (*Customer, error) {
+	// Returns New Customer with the Hashed Password and Normalized Email,
+	// `ErrInvalidEmail` or `ErrWeakPassword` is Returned, if the Email or Password does not pass the Validation
+	if ValidationError := ValidateEmail(Email); ValidationError != nil {
+		return nil, ValidationError
+	}
+	if ValidationError := ValidatePassword(Password); ValidationError != nil {
+		return nil, ValidationError
+	}
 	PasswordHash, HashError := bcrypt.GenerateFromPassword([]byte(Password), 14)
 	if HashError != nil {
-		return nil
+		return nil, HashError
 	}
 	return &Customer{
 		Username: Username,
-		Email:    Email,
+		Email:    NormalizeEmail(Email),
 		Password: string(PasswordHash),
 		City:     City,
 		Country:  Country,
 		ZipCode:  ZipCode,
 		Street:   Street,
+	}, nil
+}
+
+func GetCustomerByUsername(Username string) (*Customer, error) {
+	// Returns Customer with the Username, `ErrNotFound` if there is no such Customer
+	Customer := &Customer{}
+	Gorm := Database.Where("username = ?", Username).First(Customer)
+	if Gorm.Error != nil {
+		return nil, TranslateNotFound(Gorm.Error)
 	}
+	return Customer, nil
 }
 
-func (this *Customer) Create() (*gorm.DB, error) {
-	// Creates New Customer Profile
+func (this *Customer) VerifyPassword(Plaintext string) bool {
+	// Returns True if the Plaintext Password Matches the Stored Hash of the Customer,
+	// Empty or Corrupted Hash never Matches
+	if len(this.Password) == 0 {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(this.Password), []byte(Plaintext)) == nil
+}
+
+func (this *Customer) UpdateEmail(NewEmail string) error {
+	// Changes Email of the Customer, `ErrInvalidEmail` is Returned, if the New Email is Malformed
+	if ValidationError := ValidateEmail(NewEmail); ValidationError != nil {
+		return ValidationError
+	}
+	NewEmail = NormalizeEmail(NewEmail)
+	if UpdateError := this.updateColumn("email", NewEmail); UpdateError != nil {
+		return UpdateError
+	}
+	this.Email = NewEmail
+	return nil
+}
 
-	PasswordHash, _ := bcrypt.GenerateFromPassword([]byte(this.Password), 14)
+func (this *Customer) UpdatePassword(NewPlaintext string) error {
+	// Changes Password of the Customer, `ErrWeakPassword` is Returned, if the New Password does not pass the Validation
+	if ValidationError := ValidatePassword(NewPlaintext); ValidationError != nil {
+		return ValidationError
+	}
+	PasswordHash, HashError := bcrypt.GenerateFromPassword([]byte(NewPlaintext), 14)
+	if HashError != nil {
+		return HashError
+	}
+	if UpdateError := this.updateColumn("password", string(PasswordHash)); UpdateError != nil {
+		return UpdateError
+	}
 	this.Password = string(PasswordHash)
+	return nil
+}
+
+func (this *Customer) updateColumn(Column string, Value interface{}) error {
+	// Updates the Single Mutable Column of the Customer, `ErrNotFound` is Returned, if there is no such Customer
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer CancelFunc()
 
-	CreatedCustomer := Database.Model(&Customer{}).Create(this)
-	return CreatedCustomer, CreatedCustomer.Error
+	Updated := Database.WithContext(TimeoutContext).Model(&Customer{}).Where("id = ?", this.ID).Update(Column, Value)
+	if Updated.Error != nil {
+		Logger.Error("Failed to Update Customer", zap.Int("Customer ID", this.ID),
+			zap.String("Column", Column), zap.Error(Updated.Error))
+		return Updated.Error
+	}
+	if Updated.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
-func (this *Customer) Delete(UserId int) (*gorm.DB, error) {
+func (this *Customer) Create(Options ...options.OperationOption) (*gorm.DB, error) {
+	// Creates New Customer Profile
+
+	// Password of the Customer, Returned by the `NewCustomer`, is Already Hashed, so it is not Hashed Twice
+	if _, CostError := bcrypt.Cost([]byte(this.Password)); CostError != nil {
+		PasswordHash, HashError := bcrypt.GenerateFromPassword([]byte(this.Password), 14)
+		if HashError != nil {
+			return Database, fmt.Errorf("Failed to Hash the Password of the Customer: %w", HashError)
+		}
+		this.Password = string(PasswordHash)
+	}
+
+	Operation := options.NewOperationOptions(DefaultQueryTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	CreatedCustomer := Database
+	CreateError := Operation.Retry(TimeoutContext, func() error {
+		CreatedCustomer = Database.WithContext(TimeoutContext).Model(&Customer{}).Create(this)
+		return CreatedCustomer.Error
+	})
+	return CreatedCustomer, CreateError
+}
+
+func (this *Customer) CreateContext(Context context.Context, Options ...options.OperationOption) (*gorm.DB, error) {
+	// Creates New Customer Profile within the Context (See `Create`)
+	return this.Create(append(Options, options.WithContext(Context))...)
+}
+
+func (this *Customer) Delete(UserId int, Force bool, Options ...options.OperationOption) (*gorm.DB, error) {
 	// Deletes Customer Profile
-	DeletedCustomer := Database.Where("id = ?", UserId).Delete(&Customer{})
-	Database.Unscoped().Where("id = ?", UserId).Delete(&Customer{})
-	return DeletedCustomer, DeletedCustomer.Error
+	// Customer, who still has Virtual Machines, is not Deleted (`ErrCustomerHasResources`), unless `Force` is Set,
+	// In that case Database Records of the Virtual Machines are being Deleted along with the Profile
+	// NOTE: Virtual Machines themselves should be Destroyed in vSphere by the Caller before the Forced Deletion,
+	// Rows of the Virtual Machines are Removed Permanently, so the Usage of the Customer should be Billed before it
+	// If there is no such Customer, `ErrNotFound` is Returned, so the Caller can tell it from the Successful Deletion
+	// Customer Row is Locked for the Check and the Deletion, so no Virtual Machine can be Created for it in between
+	// (Insertion of the Virtual Machine Row Waits for the Lock because of the Owner Foreign Key)
+
+	Operation := options.NewOperationOptions(DefaultQueryTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	var DeletedCustomer *gorm.DB
+	DeleteError := Operation.Retry(TimeoutContext, func() error {
+		return Database.WithContext(TimeoutContext).Transaction(func(Transaction *gorm.DB) error {
+			var Locked Customer
+			if Found := Transaction.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where(
+				"id = ?", UserId).First(&Locked); Found.Error != nil {
+				return TranslateNotFound(Found.Error)
+			}
+			if !Force {
+				var Count int64
+				if Counted := Transaction.Model(&VirtualMachine{}).Where("owner_id = ?", UserId).Count(&Count); Counted.Error != nil {
+					return Counted.Error
+				}
+				if Count != 0 {
+					return fmt.Errorf("%w: Customer Owns %d Virtual Machine(s), that should be Deleted first", ErrCustomerHasResources, Count)
+				}
+			}
+
+			// Without the Force only the Soft-Deleted Virtual Machines are Left,
+			// their Rows still Reference the Customer, so they are Removed along with it
+			var VirtualMachineIDs []int
+			if Selected := Transaction.Unscoped().Model(&VirtualMachine{}).Where("owner_id = ?", UserId).Pluck(
+				"id", &VirtualMachineIDs); Selected.Error != nil {
+				return Selected.Error
+			}
+			if _, CleanupError := deleteVirtualMachineRecords(Transaction, VirtualMachineIDs); CleanupError != nil {
+				return CleanupError
+			}
+			if Deleted := Transaction.Where("customer_id = ?", UserId).Delete(&PasswordResetToken{}); Deleted.Error != nil {
+				return Deleted.Error
+			}
+			DeletedCustomer = Transaction.Unscoped().Where("id = ?", UserId).Delete(&Customer{})
+			if DeletedCustomer.Error == nil && DeletedCustomer.RowsAffected == 0 {
+				return ErrNotFound
+			}
+			return DeletedCustomer.Error
+		})
+	})
+	if DeletedCustomer == nil {
+		return Database, DeleteError
+	}
+	return DeletedCustomer, DeleteError
+}
+
+func (this *Customer) DeleteContext(Context context.Context, UserId int, Force bool, Options ...options.OperationOption) (*gorm.DB, error) {
+	// Deletes Customer Profile within the Context (See `Delete`)
+	return this.Delete(UserId, Force, append(Options, options.WithContext(Context))...)
 }
 
 // NOTE: Going to support SSL soon
 
 type VirtualMachine struct {
-	ID                 int
-	State              string                      `json:"State" xml:"State" gorm:"type:varchar(10); not null;"`
+	ID                 int                         `json:"ID" xml:"ID"`
+	State              string                      `json:"State" xml:"State" gorm:"type:varchar(20); not null;"`
 	SshInfo            SSHConfiguration            `json:"sshKey" xml:"sshKey" gorm:"column:ssh_key;type:text;default:null;"`
 	Configuration      VirtualMachineConfiguration `json:"Configuration" xml:"Configuration" gorm:"column:configuration;type:text;default:null;"`
-	OwnerId            int                         `json:"OwnerId" xml:"OwnerId" gorm:"<-:create;type:varchar(100);not null;unique;"`
-	VirtualMachineName string                      `json:"VirtualMachineName" xml:"VirtualMachineName" gorm:"type:varchar(15);not null;"`
+	OwnerId            int                         `json:"OwnerId" xml:"OwnerId" gorm:"<-:create;not null;index;"` // Customer can Own many Virtual Machines
+	Owner              *Customer                   `json:"-" xml:"-" gorm:"foreignKey:OwnerId;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	VirtualMachineName string                      `json:"VirtualMachineName" xml:"VirtualMachineName" gorm:"type:varchar(80);not null;"`
 	ItemPath           string                      `json:"ItemPath" xml:"ItemPath" gorm:"<-:create;type:varchar(100);not null;"`
-	IPAddress          string                      `json:"IPAddress" xml:"IPAddress" gorm:"<-:create;type:varchar(100);not null;unique;"`
+	IPAddress          string                      `json:"IPAddress" xml:"IPAddress" gorm:"type:varchar(100);unique;default:null;"` // Empty until the Guest Reports it (e.g right after the Clone)
+	UUID               string                      `json:"UUID" xml:"UUID" gorm:"column:uuid;type:varchar(36);default:null;"`
+	IsTemplate         bool                        `json:"IsTemplate" xml:"IsTemplate" gorm:"not null;default:false;"`
+	Encrypted          bool                        `json:"Encrypted" xml:"Encrypted" gorm:"not null;default:false;"`                    // vSphere VM Encryption is Enabled
+	HardwareVersion    string                      `json:"HardwareVersion" xml:"HardwareVersion" gorm:"type:varchar(10);default:null;"` // e.g `vmx-19`
 	CreatedAt          time.Time                   `json:"CreatedAt" xml:"CreatedAt" gorm:"<-:create; default:"`
+	DeletedAt          gorm.DeletedAt              `json:"-" xml:"-" gorm:"index;"` // Set by the `SoftDelete`, such Virtual Machines are Hidden from the Queries
 }
 
 func NewVirtualMachine(
@@ -155,29 +426,212 @@ func NewVirtualMachine(
 	}
 }
 
-func (this *VirtualMachine) Save() (*gorm.DB, error) {
+func GetVirtualMachineByID(VirtualMachineID string) (*VirtualMachine, error) {
+	// Returns Virtual Machine with the ID, `ErrNotFound` (Wraps `gorm.ErrRecordNotFound`) if there is no such Virtual Machine
+	VirtualMachine := &VirtualMachine{}
+	Gorm := Database.Where("id = ?", VirtualMachineID).First(VirtualMachine)
+	if Gorm.Error != nil {
+		return nil, TranslateNotFound(Gorm.Error)
+	}
+	return VirtualMachine, nil
+}
+
+func GetVirtualMachineByUUID(UUID string) (*VirtualMachine, error) {
+	// Returns Virtual Machine with the vSphere UUID (`config.uuid`), `ErrNotFound` if there is no such Virtual Machine
+	if len(UUID) == 0 {
+		return nil, ErrNotFound
+	}
+	VirtualMachine := &VirtualMachine{}
+	Gorm := Database.Where("uuid = ?", UUID).First(VirtualMachine)
+	if Gorm.Error != nil {
+		return nil, TranslateNotFound(Gorm.Error)
+	}
+	return VirtualMachine, nil
+}
+
+func SetVirtualMachineIPAddress(VirtualMachineID int, IPAddress string) error {
+	// Updates IP Address of the Virtual Machine Row, e.g after the Guest has Reported the New one, Empty Address is Stored as NULL
+	// `ErrNotFound` is Returned, if there is no such Virtual Machine
+	Updated := Database.Model(&VirtualMachine{}).Where("id = ?", VirtualMachineID).Update("ip_address", gorm.Expr("NULLIF(?, '')", IPAddress))
+	if Updated.Error != nil {
+		return Updated.Error
+	}
+	if Updated.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func RenameVirtualMachineRecord(UUID string, ItemPath string, Name string, NewItemPath string) error {
+	// Updates Name and Inventory Path of the Virtual Machine Row after the Rename in vSphere within the Transaction,
+	// Row is Matched by the UUID, Rows without it (Not Backfilled yet) are Matched by the Current Inventory Path
+	// `ErrNotFound` is Returned, if there is no such Virtual Machine
+	return WithTransaction(func(Transaction *gorm.DB) error {
+		var Record VirtualMachine
+		Query := Transaction.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id")
+		if len(UUID) != 0 {
+			Query = Query.Where("uuid = ? OR (uuid IS NULL AND item_path = ?)", UUID, ItemPath)
+		} else {
+			Query = Query.Where("item_path = ?", ItemPath)
+		}
+		if Found := Query.First(&Record); Found.Error != nil {
+			return TranslateNotFound(Found.Error)
+		}
+		// `item_path` is Create-Only for the Model (So `Save` never Overwrites it), Gorm Drops it from the `Updates`,
+		// so the Row is Updated by the Raw Query
+		return Transaction.Exec("UPDATE virtual_machines SET virtual_machine_name = ?, item_path = ? WHERE id = ?",
+			Name, NewItemPath, Record.ID).Error
+	})
+}
+
+func ResizeVirtualMachineRecord(UUID string, NumCPU int32, MemoryMB int64) error {
+	// Updates Resources of the Virtual Machine Configuration after the Resize in vSphere and Appends the `EventResized`
+	// to its Timeline within the same Transaction, so the Billing never Sees the Size without the Event (See `ResizeEventDetail`)
+	// `ErrNotFound` is Returned, if there is no Virtual Machine with the UUID
+	if len(UUID) == 0 {
+		return ErrNotFound
+	}
+	return WithTransaction(func(Transaction *gorm.DB) error {
+		// Configuration is being Decoded manually, because it might be Null
+		var Record struct {
+			ID            int
+			Configuration sql.NullString
+		}
+		Found := Transaction.Model(&VirtualMachine{}).Clauses(clause.Locking{Strength: "UPDATE"}).Select(
+			"id", "configuration").Where("uuid = ?", UUID).Limit(1).Scan(&Record)
+		if Found.Error != nil {
+			return Found.Error
+		}
+		if Found.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		var Configuration VirtualMachineConfiguration
+		if Record.Configuration.Valid {
+			if DecodeError := json.Unmarshal([]byte(Record.Configuration.String), &Configuration); DecodeError != nil {
+				return DecodeError
+			}
+		}
+		Detail := ResizeEventDetail(NumCPU, MemoryMB, Configuration.Resources.CpuNum, Configuration.Resources.MemoryInMegabytes)
+		Configuration.Resources.CpuNum = NumCPU
+		Configuration.Resources.MemoryInMegabytes = MemoryMB
+
+		Serialized, EncodeError := json.Marshal(Configuration)
+		if EncodeError != nil {
+			return EncodeError
+		}
+		if Updated := Transaction.Exec("UPDATE virtual_machines SET configuration = ? WHERE id = ?",
+			string(Serialized), Record.ID); Updated.Error != nil {
+			return Updated.Error
+		}
+		return Transaction.Create(NewVMEvent(Record.ID, EventResized, Detail)).Error
+	})
+}
+
+func (this *Customer) VirtualMachines() ([]VirtualMachine, error) {
+	// Returns every Virtual Machine, the Customer Owns, Ordered by ID
+	return GetVirtualMachinesByOwner(strconv.Itoa(this.ID))
+}
+
+func GetVirtualMachinesByOwner(OwnerID string) ([]VirtualMachine, error) {
+	// Returns every Virtual Machine of the Customer, Ordered by ID, Customer without Virtual Machines gets an Empty List
+	VirtualMachines := []VirtualMachine{}
+	Gorm := Database.Where("owner_id = ?", OwnerID).Order("id").Find(&VirtualMachines)
+	if Gorm.Error != nil {
+		return nil, Gorm.Error
+	}
+	return VirtualMachines, nil
+}
+
+func (this *VirtualMachine) Save(Options ...options.OperationOption) (*gorm.DB, error) {
 	// Saved the Current Virtual Machine Object
-	Saved := Database.Save(this)
+
+	Operation := options.NewOperationOptions(DefaultQueryTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	// IP Address is Unique, so the Unknown one is kept NULL, instead of being Overwritten with the Empty String
+	Query := Database.WithContext(TimeoutContext)
+	if len(this.IPAddress) == 0 {
+		Query = Query.Omit("ip_address")
+	}
+
+	var Saved *gorm.DB
+	Operation.Retry(TimeoutContext, func() error {
+		Saved = Query.Save(this)
+		return Saved.Error
+	})
 	return Saved, Saved.Error
 }
 
-func (this *VirtualMachine) Create() (*gorm.DB, error) {
+func (this *VirtualMachine) SaveContext(Context context.Context, Options ...options.OperationOption) (*gorm.DB, error) {
+	// Saves the Current Virtual Machine Object within the Context (See `Save`)
+	return this.Save(append(Options, options.WithContext(Context))...)
+}
+
+func (this *VirtualMachine) Create(Strategy ...NameConflictStrategy) (*gorm.DB, error) {
 	// Creates New Virtual Machine Object
+	// If the Name is already taken, it is being Suffixed using the Strategy (Default one, if not Specified)
+	return this.CreateContext(context.Background(), Strategy...)
+}
+
+func (this *VirtualMachine) CreateContext(Context context.Context, Strategy ...NameConflictStrategy) (*gorm.DB, error) {
+	// Creates New Virtual Machine Object, Query is Cancelled along with the Context (See `Create`)
+
+	if ContextError := Context.Err(); ContextError != nil {
+		return Database, ContextError
+	}
+
+	var ConflictStrategy NameConflictStrategy
+	if len(Strategy) != 0 {
+		ConflictStrategy = Strategy[0]
+	}
+	UniqueName, NameError := GenerateUniqueVirtualMachineName(this.VirtualMachineName, ConflictStrategy)
+	if NameError != nil {
+		Logger.Error("Failed to Generate Unique Virtual Machine Name", zap.Error(NameError))
+		return Database, NameError
+	}
+	this.VirtualMachineName = UniqueName
 
-	Created := Database.Clauses(clause.OnConflict{Columns: []clause.Column{
-		{Table: "VirtualMachine", Name: "VirtualMachineName"}},
-		DoUpdates: clause.Assignments(map[string]interface{}{
-			"VirtualMachineName": gorm.Expr("virtual_machine_name + _ + uuid_generate_v3()"),
-		})}).Create(&this)
+	Created := Database.WithContext(Context).Create(this)
+	AuditOperation(Context, AuditActionVirtualMachineCreated, this.auditTarget(), Created.Error)
 	return Created, Created.Error
 }
 
-func (this *VirtualMachine) Delete() (*gorm.DB, error) {
-	// Deletes the Virtual Machine ORM Object....
+func (this *VirtualMachine) auditTarget() string {
+	// Returns Target of the Audit Entries of the Virtual Machine: Inventory Path, if it is Known, the Name otherwise
+	if len(this.ItemPath) != 0 {
+		return this.ItemPath
+	}
+	return this.VirtualMachineName
+}
+
+func (this *VirtualMachine) Delete(Options ...options.OperationOption) (*gorm.DB, error) {
+	// Deletes the Virtual Machine ORM Object Permanently along with every Dependent Row (Keys, Tags, Schedules, Secrets)
+	// within the Single Transaction (Database Only, See `DeleteVirtualMachineRecords` for the Options)
+	// Amount of the Deleted Rows is in the `RowsAffected` of the Result, `ErrNotFound` is Returned, if there were None
+
+	Operation := options.NewOperationOptions(DefaultQueryTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	Deleted := Database
+	DeleteError := Operation.Retry(TimeoutContext, func() error {
+		return WithTransaction(func(Transaction *gorm.DB) error {
+			var DeleteError error
+			Deleted, DeleteError = deleteVirtualMachineRecords(Transaction.WithContext(TimeoutContext), []int{this.ID})
+			return DeleteError
+		})
+	})
+	if DeleteError == nil && Deleted.RowsAffected == 0 {
+		DeleteError = ErrNotFound
+	}
+	AuditOperation(Operation.Context, AuditActionVirtualMachineDeleted, strconv.Itoa(this.ID), DeleteError)
+	return Deleted, DeleteError
+}
 
-	Deleted := Database.Clauses(clause.OnConflict{DoNothing: true}).Delete(&this)
-	Database.Model(&VirtualMachine{}).Unscoped().Delete(&this)
-	return Deleted, Deleted.Error
+func (this *VirtualMachine) DeleteContext(Context context.Context, Options ...options.OperationOption) (*gorm.DB, error) {
+	// Deletes the Virtual Machine ORM Object within the Context (See `Delete`)
+	return this.Delete(append(Options, options.WithContext(Context))...)
 }
 
 type VirtualMachineConfiguration struct {
@@ -261,7 +715,18 @@ func NewVirtualMachineConfiguration(SerializedConfiguration []byte) (*VirtualMac
 // Sql Methods for managing Encoding and Decoding of the SQL Model
 
 func (this *VirtualMachineConfiguration) Scan(source interface{}) error {
-	return json.Unmarshal(source.([]byte), &this)
+	// Column is Nullable, and the Driver can Return Text as the String
+	switch Serialized := source.(type) {
+	case nil:
+		*this = VirtualMachineConfiguration{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(Serialized), this)
+	case []byte:
+		return json.Unmarshal(Serialized, this)
+	default:
+		return fmt.Errorf("Unsupported Type `%T` of the Virtual Machine Configuration Column", source)
+	}
 }
 
 func (this *VirtualMachineConfiguration) Value() (driver.Value, error) {
@@ -307,6 +772,7 @@ type SSHConfiguration struct {
 	SshPublicKeyMethod   SshPublicKeyInfo   `json:"SshCredentialsInfo" xml:"SshCredentialsInfo"`
 	SshCredentialsMethod SshCredentialsInfo `json:"SshPublicKeyInfo" xml:"SshPublicKeyInfo"`
 	VirtualMachineId     int                `json:"VirtualMachineId" xml:"VirtualMachineId"`
+	Port                 int                `json:"Port,omitempty" xml:"Port"` // SSH Port, 22 is used if not Specified
 }
 
 func NewSshConfiguration(Type string, SshCredentialsMethod *SshCredentialsInfo, SshPublicKeyMethod *SshPublicKeyInfo, VirtualMachineId int) *SSHConfiguration {
@@ -318,11 +784,56 @@ func NewSshConfiguration(Type string, SshCredentialsMethod *SshCredentialsInfo,
 	}
 }
 
+type storedSshConfiguration struct {
+	// Form of the SSH Configuration in the Column, the Key Content is Kept Encrypted (See `EncryptedString`),
+	// Rows, Written before the Encryption, have the Plain Content and no `EncryptedContent`
+	SSHConfiguration
+	EncryptedContent string `json:"EncryptedContent,omitempty"`
+}
+
 func (this *SSHConfiguration) Scan(inter interface{}) error {
-	return json.Unmarshal(inter.([]byte), this)
+	// Column is Nullable (Virtual Machines without the Configured SSH), and the Driver can Return Text as the String
+	var Serialized []byte
+	switch Source := inter.(type) {
+	case nil:
+		*this = SSHConfiguration{}
+		return nil
+	case string:
+		Serialized = []byte(Source)
+	case []byte:
+		Serialized = Source
+	default:
+		return fmt.Errorf("Unsupported Type `%T` of the SSH Configuration Column", inter)
+	}
+
+	var Stored storedSshConfiguration
+	if DecodeError := json.Unmarshal(Serialized, &Stored); DecodeError != nil {
+		return DecodeError
+	}
+	if len(Stored.EncryptedContent) != 0 {
+		var Content EncryptedString
+		if DecryptError := Content.Scan(Stored.EncryptedContent); DecryptError != nil {
+			return DecryptError
+		}
+		Stored.SshPublicKeyMethod.Content = []byte(Content)
+	}
+	*this = Stored.SSHConfiguration
+	return nil
 }
 
-func (this *SSHConfiguration) Value() ([]byte, error) {
-	Serialized, Error := json.Marshal(this)
-	return Serialized, Error
+func (this SSHConfiguration) Value() (driver.Value, error) {
+	// Root Password is not being Serialized into the Column, Passwords are Kept Encrypted in the Secret Store
+	// (See `VirtualMachineSecret`), so it is never Stored as the Plain Text, neither is the Key Content
+	this.SshCredentialsMethod.RootPassword = ""
+	Stored := storedSshConfiguration{SSHConfiguration: this}
+	if len(this.SshPublicKeyMethod.Content) != 0 {
+		Encrypted, EncryptError := EncryptedString(this.SshPublicKeyMethod.Content).Value()
+		if EncryptError != nil {
+			return nil, EncryptError
+		}
+		Stored.EncryptedContent = Encrypted.(string)
+		Stored.SshPublicKeyMethod.Content = nil
+	}
+	Serialized, Error := json.Marshal(Stored)
+	return string(Serialized), Error
 }
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Strategies, that are used to make the Virtual Machine Name Unique,
+// When the Requested Name has been already taken by another Virtual Machine
+
+const MaxNameConflictAttempts = 100 // Max Amount of the Suffixed Names, that are being tried
+
+var (
+	// Strategy, that is used when the Caller does not Specify one
+	DefaultNameConflictStrategy NameConflictStrategy = NumericSuffix{}
+)
+
+type NameConflictStrategy interface {
+	// Returns Candidate Name for the Given Attempt (Attempts start from 1)
+	Next(BaseName string, Attempt int) string
+}
+
+type NumericSuffix struct{}
+
+func (this NumericSuffix) Next(BaseName string, Attempt int) string {
+	// Returns Names like `web-1`, `web-2`, ...
+	return fmt.Sprintf("%s-%d", BaseName, Attempt)
+}
+
+type UUIDSuffix struct {
+	Length int // Length of the Suffix, 8 is used by Default
+}
+
+func (this UUIDSuffix) Next(BaseName string, Attempt int) string {
+	// Returns Names like `web-1b4e28ba`
+	Length := this.Length
+	if Length <= 0 || Length > 32 {
+		Length = 8
+	}
+	Suffix := strings.ReplaceAll(uuid.NewString(), "-", "")[:Length]
+	return fmt.Sprintf("%s-%s", BaseName, Suffix)
+}
+
+func GenerateUniqueVirtualMachineName(BaseName string, Strategy NameConflictStrategy) (string, error) {
+	// Returns the Base Name, if it is not taken yet, otherwise the First free Name, produced by the Strategy
+
+	if Strategy == nil {
+		Strategy = DefaultNameConflictStrategy
+	}
+	Candidate := BaseName
+	for Attempt := 1; Attempt <= MaxNameConflictAttempts; Attempt++ {
+		var Taken int64
+		if Gorm := Database.Model(&VirtualMachine{}).Where(
+			"virtual_machine_name = ?", Candidate).Count(&Taken); Gorm.Error != nil {
+			return "", Gorm.Error
+		}
+		if Taken == 0 {
+			return Candidate, nil
+		}
+		Candidate = Strategy.Next(BaseName, Attempt)
+	}
+	return "", fmt.Errorf("Failed to Generate Unique Name for `%s`", BaseName)
+}
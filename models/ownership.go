@@ -0,0 +1,17 @@
+package models
+
+func GetVirtualMachineOwners(ItemPaths []string) (map[string]int, error) {
+	// Returns Owner ID of every Virtual Machine by its Item Path,
+	// Virtual Machines, that are not Managed by the Application, are not Included
+	var Rows []struct {
+		ItemPath string
+		OwnerId  int
+	}
+	Owners := map[string]int{}
+	Gorm := Database.Model(&VirtualMachine{}).Select("item_path", "owner_id").Where(
+		"item_path IN ?", ItemPaths).Scan(&Rows)
+	for _, Row := range Rows {
+		Owners[Row.ItemPath] = Row.OwnerId
+	}
+	return Owners, Gorm.Error
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const PasswordResetTokenTTL = time.Hour // Time, the Password Reset Token is Valid for
+
+var (
+	ErrInvalidResetToken = errors.New("Password Reset Token is Invalid")
+	ErrResetTokenExpired = errors.New("Password Reset Token has Expired")
+	ErrResetTokenUsed    = errors.New("Password Reset Token has already been Used")
+)
+
+type PasswordResetToken struct {
+	// Single-Use Token, the Customer Resets the Password with, only the Hash of the Token is Stored,
+	// so the Leaked Table can't be Used to Reset the Passwords
+	ID         int
+	TokenHash  string     `json:"-" xml:"-" gorm:"type:varchar(64);not null;unique;"` // Hex Encoded SHA-256 of the Token
+	CustomerID int        `json:"CustomerID" xml:"CustomerID" gorm:"not null;index;"`
+	ExpiresAt  time.Time  `json:"ExpiresAt" xml:"ExpiresAt" gorm:"not null;"`
+	UsedAt     *time.Time `json:"UsedAt,omitempty" xml:"UsedAt,omitempty" gorm:"default:null;"`
+	CreatedAt  time.Time  `json:"CreatedAt" xml:"CreatedAt"`
+}
+
+func hashResetToken(Token string) string {
+	// Returns Hex Encoded SHA-256 of the Token, Tokens are Random, so the Salt is not needed
+	Hash := sha256.Sum256([]byte(Token))
+	return hex.EncodeToString(Hash[:])
+}
+
+func GeneratePasswordResetToken(CustomerID uint) (string, error) {
+	// Returns new Password Reset Token of the Customer, Valid for the `PasswordResetTokenTTL`
+	// Token is Returned only once, `ErrNotFound` is Returned, if there is no such Customer
+
+	var Customers int64
+	if Gorm := Database.Model(&Customer{}).Where("id = ?", CustomerID).Count(&Customers); Gorm.Error != nil {
+		return "", Gorm.Error
+	}
+	if Customers == 0 {
+		return "", ErrNotFound
+	}
+
+	Random := make([]byte, 32)
+	if _, RandomError := rand.Read(Random); RandomError != nil {
+		return "", RandomError
+	}
+	Token := base64.RawURLEncoding.EncodeToString(Random)
+
+	if Created := Database.Create(&PasswordResetToken{
+		TokenHash:  hashResetToken(Token),
+		CustomerID: int(CustomerID),
+		ExpiresAt:  time.Now().Add(PasswordResetTokenTTL),
+	}); Created.Error != nil {
+		return "", Created.Error
+	}
+	return Token, nil
+}
+
+func ConsumePasswordResetToken(Token string) (*Customer, error) {
+	// Marks the Token as Used and Returns the Customer, the Password should be Reset for
+	// Token Row is Locked within the Transaction, so the Concurrent Requests can't Use the same Token Twice
+
+	var Owner Customer
+	ConsumeError := WithTransaction(func(Transaction *gorm.DB) error {
+		var ResetToken PasswordResetToken
+		Gorm := Transaction.Clauses(clause.Locking{Strength: "UPDATE"}).Where(
+			"token_hash = ?", hashResetToken(Token)).Limit(1).Find(&ResetToken)
+		if Gorm.Error != nil {
+			return Gorm.Error
+		}
+		switch {
+		case Gorm.RowsAffected == 0:
+			return ErrInvalidResetToken
+		case ResetToken.UsedAt != nil:
+			return ErrResetTokenUsed
+		case time.Now().After(ResetToken.ExpiresAt):
+			return ErrResetTokenExpired
+		}
+
+		if Updated := Transaction.Model(&PasswordResetToken{}).Where("id = ? AND used_at IS NULL",
+			ResetToken.ID).Update("used_at", time.Now()); Updated.Error != nil {
+			return Updated.Error
+		} else if Updated.RowsAffected == 0 {
+			return ErrResetTokenUsed
+		}
+		if Found := Transaction.Omit("password").Where("id = ?", ResetToken.CustomerID).First(&Owner); Found.Error != nil {
+			return TranslateNotFound(Found.Error)
+		}
+		return nil
+	})
+	if ConsumeError != nil {
+		return nil, ConsumeError
+	}
+	return &Owner, nil
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+const ProvisioningRequestTTL = time.Hour * 24 // Period, the Idempotency Key is being Remembered for
+
+// Period, the In Progress Request is Held for, Provisioning takes at most 5 Minutes,
+// so the Request, that is still In Progress after the Lease, has been Abandoned (e.g the Server has Crashed)
+// and can be Claimed again with the same Key
+const ProvisioningRequestLease = time.Minute * 15
+
+// Statuses of the Provisioning Request
+const ProvisioningInProgress = "InProgress"
+const ProvisioningCompleted = "Completed"
+
+var (
+	ErrProvisioningInProgress = errors.New("Provisioning with this Idempotency Key is still In Progress")
+)
+
+type ProvisioningRequest struct {
+	// Result of the Provisioning Request, Recorded by the Idempotency Key of the Client,
+	// So the Retried Request Returns the Original Result instead of Provisioning one more Virtual Machine
+	// Keys are Chosen by the Clients, so they are Unique only within the Customer
+	CustomerID        int       `json:"CustomerID" xml:"CustomerID" gorm:"primaryKey;autoIncrement:false;"`
+	IdempotencyKey    string    `json:"IdempotencyKey" xml:"IdempotencyKey" gorm:"type:varchar(255);primaryKey;"`
+	Status            string    `json:"Status" xml:"Status" gorm:"type:varchar(20);not null;"`
+	VirtualMachineRef string    `json:"VirtualMachineRef" xml:"VirtualMachineRef" gorm:"type:varchar(100);default:null;"` // Managed Object ID of the Provisioned VM
+	Error             string    `json:"Error" xml:"Error" gorm:"type:text;default:null;"`
+	CreatedAt         time.Time `json:"CreatedAt" xml:"CreatedAt" gorm:"<-:create;"`
+	ExpiresAt         time.Time `json:"ExpiresAt" xml:"ExpiresAt" gorm:"not null;index;"`
+}
+
+func ClaimProvisioningRequest(IdempotencyKey string, CustomerID int) (*ProvisioningRequest, bool, error) {
+	// Records the new Provisioning Request, Returns True if the Caller should Provision the Virtual Machine,
+	// If the Key has been Used already, the Original Request is Returned instead
+	// (`ErrProvisioningInProgress` if it has not Completed yet), Abandoned Requests are Taken over once their Lease has Expired
+
+	if ExpireError := ExpireProvisioningRequests(); ExpireError != nil {
+		return nil, false, ExpireError
+	}
+
+	Request := &ProvisioningRequest{
+		IdempotencyKey: IdempotencyKey,
+		CustomerID:     CustomerID,
+		Status:         ProvisioningInProgress,
+		ExpiresAt:      time.Now().Add(ProvisioningRequestLease),
+	}
+	Created := Database.Clauses(clause.OnConflict{DoNothing: true}).Create(Request)
+	if Created.Error != nil {
+		return nil, false, Created.Error
+	}
+	if Created.RowsAffected == 1 {
+		return Request, true, nil
+	}
+
+	Existing := &ProvisioningRequest{}
+	if Gorm := Database.Where("idempotency_key = ? AND customer_id = ?", IdempotencyKey, CustomerID).First(Existing); Gorm.Error != nil {
+		return nil, false, TranslateNotFound(Gorm.Error)
+	}
+	if Existing.Status == ProvisioningInProgress {
+		return Existing, false, ErrProvisioningInProgress
+	}
+	return Existing, false, nil
+}
+
+func (this *ProvisioningRequest) Complete(VirtualMachineRef string, ProvisionError error) error {
+	// Records the Result of the Provisioning Request, the Key is Remembered for the `ProvisioningRequestTTL` from now on
+	this.Status = ProvisioningCompleted
+	this.VirtualMachineRef = VirtualMachineRef
+	this.ExpiresAt = time.Now().Add(ProvisioningRequestTTL)
+	if ProvisionError != nil {
+		this.Error = ProvisionError.Error()
+	}
+	Updated := Database.Model(this).Select("status", "virtual_machine_ref", "error", "expires_at").Updates(this)
+	return Updated.Error
+}
+
+func (this *ProvisioningRequest) Release() error {
+	// Forgets the Provisioning Request, so the Client can Retry it with the same Key
+	// (e.g. Provisioning has Failed without Creating the Virtual Machine)
+	Deleted := Database.Where("customer_id = ? AND idempotency_key = ?", this.CustomerID,
+		this.IdempotencyKey).Delete(&ProvisioningRequest{})
+	return Deleted.Error
+}
+
+func ExpireProvisioningRequests() error {
+	// Deletes Provisioning Requests, which Keys have Expired, including the Abandoned In Progress ones
+	Deleted := Database.Where("expires_at < ?", time.Now()).Delete(&ProvisioningRequest{})
+	return Deleted.Error
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/options"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const DefaultPurgeTimeout = time.Minute * 10
+
+func PurgeSoftDeleted(OlderThan time.Duration, Options ...options.OperationOption) (int64, error) {
+	// Permanently Deletes SSH Keys, Virtual Machines and Customers, that have been Soft Deleted more than `OlderThan` ago,
+	// Returns the Total Amount of the Deleted Rows. Every Table is Purged within its own Transaction, so if one of them Fails,
+	// the Tables Purged before it Stay Purged and their Count is Returned along with the Error
+	// Virtual Machines are Purged along with their Dependent Rows (See `DeleteVirtualMachineRecords`),
+	// Customers, who still have Virtual Machine Rows (even Soft Deleted), are Kept until those are Purged
+	// NOTE: Destroyed Virtual Machines are Kept Soft Deleted for the Billing, so `OlderThan` should not be Shorter than the Billing Period
+
+	if OlderThan < 0 {
+		return 0, fmt.Errorf("Purge Threshold should not be Negative, got %s", OlderThan)
+	}
+	Operation := options.NewOperationOptions(DefaultPurgeTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	Threshold := time.Now().Add(-OlderThan)
+	Purges := []struct {
+		Table string
+		Purge func(Transaction *gorm.DB) (int64, error)
+	}{
+		{"ssh_public_keys", func(Transaction *gorm.DB) (int64, error) {
+			Deleted := Transaction.Unscoped().Where("deleted_at < ?", Threshold).Delete(&SSHPublicKey{})
+			return Deleted.RowsAffected, Deleted.Error
+		}},
+		{"virtual_machines", func(Transaction *gorm.DB) (int64, error) {
+			var VirtualMachineIDs []int
+			if Selected := Transaction.Unscoped().Model(&VirtualMachine{}).Where(
+				"deleted_at < ?", Threshold).Pluck("id", &VirtualMachineIDs); Selected.Error != nil {
+				return 0, Selected.Error
+			}
+			Deleted, DeleteError := deleteVirtualMachineRecords(Transaction, VirtualMachineIDs)
+			if DeleteError != nil {
+				return 0, DeleteError
+			}
+			return Deleted.RowsAffected, nil
+		}},
+		{"customers", func(Transaction *gorm.DB) (int64, error) {
+			var CustomerIDs []int
+			if Selected := Transaction.Unscoped().Model(&Customer{}).Where("deleted_at < ? AND NOT EXISTS "+
+				"(SELECT 1 FROM virtual_machines WHERE virtual_machines.owner_id = customers.id)", Threshold).Pluck(
+				"id", &CustomerIDs); Selected.Error != nil {
+				return 0, Selected.Error
+			}
+			if len(CustomerIDs) == 0 {
+				return 0, nil
+			}
+			if Deleted := Transaction.Where("customer_id IN ?", CustomerIDs).Delete(&PasswordResetToken{}); Deleted.Error != nil {
+				return 0, Deleted.Error
+			}
+			Deleted := Transaction.Unscoped().Where("id IN ?", CustomerIDs).Delete(&Customer{})
+			return Deleted.RowsAffected, Deleted.Error
+		}},
+	}
+
+	var Total int64
+	for _, Purge := range Purges {
+		var Purged int64
+		PurgeError := Database.WithContext(TimeoutContext).Transaction(func(Transaction *gorm.DB) (PurgeError error) {
+			Purged, PurgeError = Purge.Purge(Transaction)
+			return PurgeError
+		})
+		if PurgeError != nil {
+			Logger.Error("Failed to Purge Soft Deleted Rows", zap.String("Table", Purge.Table), zap.Error(PurgeError))
+			return Total, PurgeError
+		}
+		Total += Purged
+		Logger.Debug("Soft Deleted Rows have been Purged", zap.String("Table", Purge.Table), zap.Int64("Rows", Purged))
+	}
+	return Total, nil
+}
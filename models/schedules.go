@@ -0,0 +1,114 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+// Power Operations, that can be Scheduled
+const SchedulePowerOn = "PowerOn"
+const SchedulePowerOff = "PowerOff"
+
+// Outcomes of the Scheduled Power Operation
+const ScheduleOutcomeSucceeded = "Succeeded"
+const ScheduleOutcomeFailed = "Failed"
+
+type PowerSchedule struct {
+	// Recurring Power Operation of the Virtual Machine, like Stopping it every Night
+	ID               int
+	VirtualMachineID int        `json:"VirtualMachineID" xml:"VirtualMachineID" gorm:"not null;index;"`
+	CronExpression   string     `json:"CronExpression" xml:"CronExpression" gorm:"type:varchar(100);not null;"` // Standard 5 Fields Cron Expression
+	Action           string     `json:"Action" xml:"Action" gorm:"type:varchar(20);not null;"`
+	NextRunAt        time.Time  `json:"NextRunAt" xml:"NextRunAt" gorm:"not null;index;"`
+	LastRunAt        *time.Time `json:"LastRunAt" xml:"LastRunAt" gorm:"default:null;"`
+	LastOutcome      string     `json:"LastOutcome" xml:"LastOutcome" gorm:"type:text;default:null;"`
+	CreatedAt        time.Time  `json:"CreatedAt" xml:"CreatedAt" gorm:"<-:create;"`
+}
+
+func NewPowerSchedule(VirtualMachineID int, CronExpression string, Action string) (*PowerSchedule, error) {
+	Schedule := &PowerSchedule{
+		VirtualMachineID: VirtualMachineID,
+		CronExpression:   CronExpression,
+		Action:           Action,
+	}
+	if ValidationError := Schedule.Validate(); ValidationError != nil {
+		return nil, ValidationError
+	}
+	return Schedule, nil
+}
+
+func (this *PowerSchedule) Validate() error {
+	// Checks, that the Cron Expression can be Parsed and the Action is Supported
+	if this.Action != SchedulePowerOn && this.Action != SchedulePowerOff {
+		return fmt.Errorf("Unsupported Power Schedule Action `%s`", this.Action)
+	}
+	if _, ParseError := cron.ParseStandard(this.CronExpression); ParseError != nil {
+		return fmt.Errorf("Invalid Cron Expression `%s`: %w", this.CronExpression, ParseError)
+	}
+	return nil
+}
+
+func (this *PowerSchedule) ScheduleNext(After time.Time) error {
+	// Sets the Next Time, the Schedule is Due
+	Schedule, ParseError := cron.ParseStandard(this.CronExpression)
+	if ParseError != nil {
+		return ParseError
+	}
+	this.NextRunAt = Schedule.Next(After)
+	return nil
+}
+
+func (this *PowerSchedule) Create() (*gorm.DB, error) {
+	// Creates New Power Schedule
+	return this.CreateContext(context.Background())
+}
+
+func (this *PowerSchedule) CreateContext(Context context.Context) (*gorm.DB, error) {
+	// Creates New Power Schedule, Query is Cancelled along with the Context
+	if ValidationError := this.Validate(); ValidationError != nil {
+		return Database, ValidationError
+	}
+	this.ScheduleNext(time.Now())
+	Created := Database.WithContext(Context).Create(this)
+	return Created, Created.Error
+}
+
+func (this *PowerSchedule) Delete() (*gorm.DB, error) {
+	// Deletes the Power Schedule
+	return this.DeleteContext(context.Background())
+}
+
+func (this *PowerSchedule) DeleteContext(Context context.Context) (*gorm.DB, error) {
+	// Deletes the Power Schedule, Query is Cancelled along with the Context
+	Deleted := Database.WithContext(Context).Where("id = ?", this.ID).Delete(&PowerSchedule{})
+	return Deleted, Deleted.Error
+}
+
+func (this *PowerSchedule) RecordRun(RanAt time.Time, Outcome string) error {
+	// Saves the Outcome of the Run and Schedules the Next One
+	this.LastRunAt = &RanAt
+	this.LastOutcome = Outcome
+	if ScheduleError := this.ScheduleNext(RanAt); ScheduleError != nil {
+		return ScheduleError
+	}
+	Saved := Database.Model(this).Select("next_run_at", "last_run_at", "last_outcome").Updates(this)
+	return Saved.Error
+}
+
+func GetPowerSchedules(VirtualMachineID int) ([]PowerSchedule, error) {
+	// Returns Power Schedules of the Virtual Machine
+	Schedules := []PowerSchedule{}
+	Gorm := Database.Where("virtual_machine_id = ?", VirtualMachineID).Order("id").Find(&Schedules)
+	return Schedules, Gorm.Error
+}
+
+func GetDuePowerSchedules(Now time.Time) ([]PowerSchedule, error) {
+	// Returns Power Schedules, that should be Run by Now
+	Schedules := []PowerSchedule{}
+	Gorm := Database.Where("next_run_at <= ?", Now).Order("next_run_at, id").Find(&Schedules)
+	return Schedules, Gorm.Error
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const DefaultListLimit = 20
+const MaxListLimit = 100
+
+type ListOptions struct {
+	// Pagination Options of the List Queries
+	Offset int `json:"Offset" xml:"Offset"`
+	Limit  int `json:"Limit" xml:"Limit"`
+}
+
+func (this ListOptions) Normalize() ListOptions {
+	// Returns Options with the Default Limit, if it has not been Specified, and within the Allowed Bounds
+	if this.Limit <= 0 {
+		this.Limit = DefaultListLimit
+	}
+	if this.Limit > MaxListLimit {
+		this.Limit = MaxListLimit
+	}
+	if this.Offset < 0 {
+		this.Offset = 0
+	}
+	return this
+}
+
+var (
+	// Columns, the Search is Allowed to Match Against, Only these can get into the Query
+	customerSearchColumns       = []string{"username", "email"}
+	virtualMachineSearchColumns = []string{"virtual_machine_name", "ip_address"}
+)
+
+type CustomerSearchItem struct {
+	ID       int    `json:"ID" xml:"ID"`
+	Username string `json:"Username" xml:"Username"`
+	Email    string `json:"Email" xml:"Email"`
+}
+
+type VirtualMachineSearchItem struct {
+	ID                 int    `json:"ID" xml:"ID"`
+	VirtualMachineName string `json:"VirtualMachineName" xml:"VirtualMachineName"`
+	IPAddress          string `json:"IPAddress" xml:"IPAddress"`
+	OwnerId            int    `json:"OwnerId" xml:"OwnerId"`
+}
+
+type SearchResults struct {
+	Customers struct {
+		Total int64                `json:"Total" xml:"Total"`
+		Items []CustomerSearchItem `json:"Items" xml:"Items"`
+	} `json:"Customers" xml:"Customers"`
+
+	VirtualMachines struct {
+		Total int64                      `json:"Total" xml:"Total"`
+		Items []VirtualMachineSearchItem `json:"Items" xml:"Items"`
+	} `json:"VirtualMachines" xml:"VirtualMachines"`
+}
+
+func searchCondition(Columns []string) string {
+	// Returns `WHERE` Condition, that Matches the Pattern against any of the Columns
+	Conditions := make([]string, 0, len(Columns))
+	for _, Column := range Columns {
+		Conditions = append(Conditions, fmt.Sprintf("%s ILIKE @pattern ESCAPE '\\'", Column))
+	}
+	return strings.Join(Conditions, " OR ")
+}
+
+func searchPattern(Query string) string {
+	// Returns `ILIKE` Pattern, that Matches the Query as a Substring, Wildcards of the Query are being Escaped
+	Escaped := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(Query)
+	return "%" + Escaped + "%"
+}
+
+func Search(Query string, Options ListOptions) (*SearchResults, error) {
+	// Searches Customers by the Username / Email and Virtual Machines by the Name / IP Address
+	// Every Category is being Paginated Separately and Queried with a Single Query, which also Counts the Total
+
+	Query = strings.TrimSpace(Query)
+	if len(Query) == 0 {
+		return nil, errors.New("Search Query can't be Empty")
+	}
+	Options = Options.Normalize()
+	Pattern := map[string]interface{}{"pattern": searchPattern(Query)}
+
+	Results := &SearchResults{}
+	Results.Customers.Items = []CustomerSearchItem{}
+	Results.VirtualMachines.Items = []VirtualMachineSearchItem{}
+
+	var Customers []struct {
+		CustomerSearchItem
+		Total int64
+	}
+	if Gorm := Database.Model(&Customer{}).Select("id", "username", "email", "COUNT(*) OVER() AS total").Where(
+		searchCondition(customerSearchColumns), Pattern).Order("id").Offset(Options.Offset).Limit(
+		Options.Limit).Scan(&Customers); Gorm.Error != nil {
+		Logger.Error("Failed to Search Customers", zap.Error(Gorm.Error))
+		return nil, Gorm.Error
+	}
+	for _, Customer := range Customers {
+		Results.Customers.Total = Customer.Total
+		Results.Customers.Items = append(Results.Customers.Items, Customer.CustomerSearchItem)
+	}
+	if len(Customers) == 0 && Options.Offset != 0 {
+		// The Page is beyond the Results, so the Total has to be Counted Separately
+		Database.Model(&Customer{}).Where(searchCondition(customerSearchColumns), Pattern).Count(&Results.Customers.Total)
+	}
+
+	var VirtualMachines []struct {
+		VirtualMachineSearchItem
+		Total int64
+	}
+	if Gorm := Database.Model(&VirtualMachine{}).Select("id", "virtual_machine_name", "ip_address", "owner_id",
+		"COUNT(*) OVER() AS total").Where(searchCondition(virtualMachineSearchColumns), Pattern).Order(
+		"id").Offset(Options.Offset).Limit(Options.Limit).Scan(&VirtualMachines); Gorm.Error != nil {
+		Logger.Error("Failed to Search Virtual Machines", zap.Error(Gorm.Error))
+		return nil, Gorm.Error
+	}
+	for _, VirtualMachine := range VirtualMachines {
+		Results.VirtualMachines.Total = VirtualMachine.Total
+		Results.VirtualMachines.Items = append(Results.VirtualMachines.Items, VirtualMachine.VirtualMachineSearchItem)
+	}
+	if len(VirtualMachines) == 0 && Options.Offset != 0 {
+		Database.Model(&VirtualMachine{}).Where(searchCondition(virtualMachineSearchColumns), Pattern).Count(&Results.VirtualMachines.Total)
+	}
+	return Results, nil
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+)
+
+type VirtualMachineSecret struct {
+	// Encrypted Secret of the Virtual Machine, like the Root Password
+	VirtualMachineID string    `json:"VirtualMachineID" xml:"VirtualMachineID" gorm:"primaryKey;type:varchar(100);"`
+	Name             string    `json:"Name" xml:"Name" gorm:"primaryKey;type:varchar(50);"`
+	Ciphertext       []byte    `json:"-" xml:"-" gorm:"type:bytea;not null;"`
+	UpdatedAt        time.Time `json:"UpdatedAt" xml:"UpdatedAt"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	shutdownMutex sync.Mutex
+)
+
+func Shutdown(Context context.Context) error {
+	// Flushes Buffered Logs, Closes the Log File and the Database Connection Pool, should be Called once the Server has Stopped
+	// Safe to be Called several Times (e.g from the Signal Handler and the Deferred Cleanup), every next Call is a no-op
+	// If the Context is Done before the Connections are Closed, its Error is Returned and Closing goes on in the Background
+
+	shutdownMutex.Lock()
+	defer shutdownMutex.Unlock()
+
+	ShutdownError := CloseLogger()
+
+	if Database != nil {
+		SqlDatabase, DatabaseError := Database.DB()
+		if DatabaseError != nil {
+			return DatabaseError
+		}
+		// Close Waits for the Queries in Progress, so it is not Allowed to Block the Shutdown past the Deadline,
+		// `sql.DB.Close` itself is Idempotent
+		Closed := make(chan error, 1)
+		go func() { Closed <- SqlDatabase.Close() }()
+		select {
+		case CloseError := <-Closed:
+			if CloseError != nil {
+				ShutdownError = CloseError
+			}
+		case <-Context.Done():
+			ShutdownError = Context.Err()
+		}
+	}
+	return ShutdownError
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"context"
+
+	"github.com/LovePelmeni/Infrastructure/options"
+	"gorm.io/gorm"
+)
+
+// Customers, Virtual Machines and SSH Keys can be Deleted in Two ways:
+//
+//   - `SoftDelete` Sets the `DeletedAt` of the Row, so it is Hidden from every Query, but can be Brought back by the `Restore`
+//     (Use `Database.Unscoped()` to Query such Rows)
+//   - `HardDelete` (Same as `Delete`) Removes the Row Permanently
+
+func softDelete(Context context.Context, Model interface{}, ID int) (*gorm.DB, error) {
+	// Sets `DeletedAt` of the Row, `ErrNotFound` is Returned, if there is no such Row (or it is Already Deleted)
+	Deleted := Database.WithContext(Context).Where("id = ?", ID).Delete(Model)
+	if Deleted.Error == nil && Deleted.RowsAffected == 0 {
+		return Deleted, ErrNotFound
+	}
+	return Deleted, Deleted.Error
+}
+
+func restore(Context context.Context, Model interface{}, ID int) (*gorm.DB, error) {
+	// Clears `DeletedAt` of the Soft Deleted Row, `ErrNotFound` is Returned, if there is no such Soft Deleted Row
+	Restored := Database.WithContext(Context).Unscoped().Model(Model).Where(
+		"id = ? AND deleted_at IS NOT NULL", ID).Update("deleted_at", nil)
+	if Restored.Error == nil && Restored.RowsAffected == 0 {
+		return Restored, ErrNotFound
+	}
+	return Restored, Restored.Error
+}
+
+func (this *Customer) SoftDelete(Options ...options.OperationOption) (*gorm.DB, error) {
+	// Soft Deletes the Customer Profile, Virtual Machines of the Customer are Left Untouched
+	Operation := options.NewOperationOptions(DefaultQueryTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+	return softDelete(TimeoutContext, &Customer{}, this.ID)
+}
+
+func (this *Customer) HardDelete(Options ...options.OperationOption) (*gorm.DB, error) {
+	// Deletes the Customer Profile Permanently (See `Delete`)
+	return this.Delete(this.ID, false, Options...)
+}
+
+func (this *Customer) Restore(Options ...options.OperationOption) (*gorm.DB, error) {
+	// Restores the Soft Deleted Customer Profile
+	Operation := options.NewOperationOptions(DefaultQueryTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+	return restore(TimeoutContext, &Customer{}, this.ID)
+}
+
+func (this *VirtualMachine) SoftDelete(Options ...options.OperationOption) (*gorm.DB, error) {
+	// Soft Deletes the Virtual Machine ORM Object, its SSH Keys, Tags, etc... are Left Untouched
+	Operation := options.NewOperationOptions(DefaultQueryTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+	return softDelete(TimeoutContext, &VirtualMachine{}, this.ID)
+}
+
+func (this *VirtualMachine) HardDelete(Options ...options.OperationOption) (*gorm.DB, error) {
+	// Deletes the Virtual Machine ORM Object Permanently (See `Delete`)
+	return this.Delete(Options...)
+}
+
+func (this *VirtualMachine) Restore(Options ...options.OperationOption) (*gorm.DB, error) {
+	// Restores the Soft Deleted Virtual Machine ORM Object
+	Operation := options.NewOperationOptions(DefaultQueryTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+	return restore(TimeoutContext, &VirtualMachine{}, this.ID)
+}
+
+func (this *SSHPublicKey) SoftDelete() (*gorm.DB, error) {
+	// Soft Deletes the SSH Public Key Object
+	return softDelete(context.Background(), &SSHPublicKey{}, this.ID)
+}
+
+func (this *SSHPublicKey) HardDelete() (*gorm.DB, error) {
+	// Deletes the SSH Public Key Object Permanently (See `Delete`)
+	return this.Delete()
+}
+
+func (this *SSHPublicKey) Restore() (*gorm.DB, error) {
+	// Restores the Soft Deleted SSH Public Key Object
+	return restore(context.Background(), &SSHPublicKey{}, this.ID)
+}
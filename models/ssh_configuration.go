@@ -0,0 +1,48 @@
+package models
+
+import (
+	"fmt"
+)
+
+func GetSshConfiguration(VirtualMachineID string) (*SSHConfiguration, error) {
+	// Returns SSH Configuration of the Virtual Machine, `ErrNotFound` if there is no such Virtual Machine
+	// Empty Configuration is Returned for the Virtual Machines, that have not got the SSH Configured yet
+	VirtualMachine := &VirtualMachine{}
+	Gorm := Database.Select("id", "ssh_key").Where("id = ?", VirtualMachineID).First(VirtualMachine)
+	if Gorm.Error != nil {
+		return nil, TranslateNotFound(Gorm.Error)
+	}
+	return &VirtualMachine.SshInfo, nil
+}
+
+func UpdateSshConfiguration(VirtualMachineID int, Configuration SSHConfiguration) error {
+	// Replaces SSH Configuration of the Virtual Machine, `ErrNotFound` is Returned, if there is no such Virtual Machine
+
+	switch Configuration.Type {
+	case TypeByRootCredentials, TypeByRootCertificate:
+	default:
+		return fmt.Errorf("Unsupported SSH Configuration Type `%s`", Configuration.Type)
+	}
+	Configuration.VirtualMachineId = VirtualMachineID
+
+	Gorm := Database.Model(&VirtualMachine{}).Where("id = ?", VirtualMachineID).Update("ssh_key", Configuration)
+	if Gorm.Error != nil {
+		return Gorm.Error
+	}
+	if Gorm.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func ClearSshConfiguration(VirtualMachineID int) error {
+	// Removes SSH Configuration of the Virtual Machine, `ErrNotFound` is Returned, if there is no such Virtual Machine
+	Gorm := Database.Model(&VirtualMachine{}).Where("id = ?", VirtualMachineID).Update("ssh_key", nil)
+	if Gorm.Error != nil {
+		return Gorm.Error
+	}
+	if Gorm.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+)
+
+const DefaultSshPort = 22
+const DefaultSshUsername = "root"
+
+var (
+	ErrIPAddressUnknown = errors.New("IP Address of the Virtual Machine is not Known yet")
+)
+
+type SSHEndpoint struct {
+	// Everything, that is Required to Connect to the Virtual Machine over SSH
+	Host     string `json:"Host" xml:"Host"`
+	Port     int    `json:"Port" xml:"Port"`
+	AuthType string `json:"AuthType" xml:"AuthType"`
+	Username string `json:"Username" xml:"Username"`
+}
+
+func GetSSHEndpoint(VirtualMachineID int) (*SSHEndpoint, error) {
+	// Returns SSH Endpoint of the Virtual Machine, based on its IP Address and SSH Configuration
+
+	// Ssh Info is being Decoded manually, because it might be Null
+	var Row struct {
+		IPAddress string
+		SshKey    sql.NullString
+	}
+	Gorm := Database.Model(&VirtualMachine{}).Select("ip_address", "ssh_key").Where(
+		"id = ?", VirtualMachineID).Limit(1).Scan(&Row)
+	if Gorm.Error != nil {
+		return nil, Gorm.Error
+	}
+	if Gorm.RowsAffected == 0 {
+		return nil, ErrNotFound
+	}
+	if len(Row.IPAddress) == 0 {
+		return nil, ErrIPAddressUnknown
+	}
+
+	var Configuration SSHConfiguration
+	if Row.SshKey.Valid {
+		if DecodeError := Configuration.Scan(Row.SshKey.String); DecodeError != nil {
+			return nil, DecodeError
+		}
+	}
+
+	Endpoint := &SSHEndpoint{
+		Host:     Row.IPAddress,
+		Port:     Configuration.Port,
+		AuthType: Configuration.Type,
+		Username: Configuration.SshCredentialsMethod.RootUsername,
+	}
+	if Endpoint.Port == 0 {
+		Endpoint.Port = DefaultSshPort
+	}
+	if len(Endpoint.Username) == 0 {
+		Endpoint.Username = DefaultSshUsername
+	}
+	return Endpoint, nil
+}
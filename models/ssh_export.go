@@ -0,0 +1,143 @@
+package models
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Export and Import of the Virtual Machine SSH Setup, used for the Backups and Migrations
+// NOTE: Private Key Material and Root Password are never being Exported
+
+const SSHConfigurationExportVersion = 1
+
+type ExportedSshPublicKey struct {
+	Key       []byte    `json:"Key" xml:"Key"`
+	Filename  string    `json:"Filename" xml:"Filename"`
+	Label     string    `json:"Label,omitempty" xml:"Label,omitempty"`
+	Comment   string    `json:"Comment,omitempty" xml:"Comment,omitempty"`
+	CreatedAt time.Time `json:"CreatedAt" xml:"CreatedAt"`
+}
+
+type SSHConfigurationExport struct {
+	Version          int                    `json:"Version" xml:"Version"`
+	VirtualMachineID int                    `json:"VirtualMachineID" xml:"VirtualMachineID"`
+	ExportedAt       time.Time              `json:"ExportedAt" xml:"ExportedAt"`
+	Configuration    SSHConfiguration       `json:"Configuration" xml:"Configuration"`
+	PublicKeys       []ExportedSshPublicKey `json:"PublicKeys" xml:"PublicKeys"`
+}
+
+func isPrivateKey(Content []byte) bool {
+	// Returns True if the Content looks like PEM Encoded Private Key
+	return bytes.Contains(Content, []byte("PRIVATE KEY"))
+}
+
+func BuildSSHConfigurationExport(VirtualMachineID int, Configuration SSHConfiguration, Keys []SSHPublicKey) (*SSHConfigurationExport, error) {
+	// Returns Export of the SSH Setup with the Secrets being Stripped Out
+
+	Configuration.SshCredentialsMethod.RootPassword = ""
+	if isPrivateKey(Configuration.SshPublicKeyMethod.Content) {
+		return nil, errors.New("SSH Configuration contains Private Key, Refusing to Export it")
+	}
+
+	Export := &SSHConfigurationExport{
+		Version:          SSHConfigurationExportVersion,
+		VirtualMachineID: VirtualMachineID,
+		ExportedAt:       time.Now(),
+		Configuration:    Configuration,
+		PublicKeys:       []ExportedSshPublicKey{},
+	}
+	for _, Key := range Keys {
+		if isPrivateKey(Key.Key) {
+			return nil, fmt.Errorf("SSH Key `%s` contains Private Key, Refusing to Export it", Key.Filename)
+		}
+		Export.PublicKeys = append(Export.PublicKeys, ExportedSshPublicKey{
+			Key: Key.Key, Filename: Key.Filename, Label: Key.Label, Comment: Key.Comment, CreatedAt: Key.CreatedAt,
+		})
+	}
+	return Export, nil
+}
+
+func ExportSSHConfiguration(VirtualMachineID int) ([]byte, error) {
+	// Returns JSON Bundle with the SSH Configuration and Public Keys of the Virtual Machine
+
+	// Ssh Info is being Decoded manually, because it might be Null
+	var SshKey sql.NullString
+	Gorm := Database.Model(&VirtualMachine{}).Select("ssh_key").Where("id = ?", VirtualMachineID).Limit(1).Scan(&SshKey)
+	if Gorm.Error != nil {
+		return nil, Gorm.Error
+	}
+	if Gorm.RowsAffected == 0 {
+		return nil, ErrNotFound
+	}
+
+	var Configuration SSHConfiguration
+	if SshKey.Valid {
+		if DecodeError := Configuration.Scan(SshKey.String); DecodeError != nil {
+			return nil, DecodeError
+		}
+	}
+
+	var Keys []SSHPublicKey
+	if Gorm := Database.Where("virtual_machine_id = ?", VirtualMachineID).Order("id").Find(&Keys); Gorm.Error != nil {
+		return nil, Gorm.Error
+	}
+
+	Export, ExportError := BuildSSHConfigurationExport(VirtualMachineID, Configuration, Keys)
+	if ExportError != nil {
+		Logger.Error("Failed to Export SSH Configuration", zap.Int("Virtual Machine ID", VirtualMachineID), zap.Error(ExportError))
+		return nil, ExportError
+	}
+	return json.Marshal(Export)
+}
+
+func ImportSSHConfiguration(VirtualMachineID int, Data []byte) error {
+	// Restores SSH Configuration and Public Keys of the Virtual Machine from the Bundle,
+	// Made by the `ExportSSHConfiguration`, Existing Keys of the Virtual Machine are being Replaced
+
+	var Export SSHConfigurationExport
+	if DecodeError := json.Unmarshal(Data, &Export); DecodeError != nil {
+		return fmt.Errorf("Invalid SSH Configuration Export: %w", DecodeError)
+	}
+	if Export.Version != SSHConfigurationExportVersion {
+		return fmt.Errorf("Unsupported SSH Configuration Export Version %d", Export.Version)
+	}
+
+	Export.Configuration.VirtualMachineId = VirtualMachineID
+
+	return WithTransaction(func(Transaction *gorm.DB) error {
+		Updated := Transaction.Model(&VirtualMachine{}).Where("id = ?", VirtualMachineID).Update("ssh_key", Export.Configuration)
+		if Updated.Error != nil {
+			return Updated.Error
+		}
+		if Updated.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		if Deleted := Transaction.Unscoped().Where("virtual_machine_id = ?", VirtualMachineID).Delete(&SSHPublicKey{}); Deleted.Error != nil {
+			return Deleted.Error
+		}
+		for _, Key := range Export.PublicKeys {
+			if isPrivateKey(Key.Key) {
+				return fmt.Errorf("SSH Key `%s` contains Private Key, Refusing to Import it", Key.Filename)
+			}
+			if ValidationError := ValidateSshPublicKey(Key.Key); ValidationError != nil {
+				return fmt.Errorf("SSH Key `%s`: %w", Key.Filename, ValidationError)
+			}
+			if DuplicateError := checkDuplicateSshKey(Transaction, VirtualMachineID, Key.Key, 0); DuplicateError != nil {
+				return fmt.Errorf("SSH Key `%s`: %w", Key.Filename, DuplicateError)
+			}
+			NewKey := NewSshPublicKeyWithLabel(VirtualMachineID, Key.Key, Key.Filename, Key.Label, Key.Comment)
+			NewKey.CreatedAt = Key.CreatedAt
+			if Created := Transaction.Create(NewKey); Created.Error != nil {
+				return fmt.Errorf("SSH Key `%s`: %w", Key.Filename, translateDuplicateSshKey(Created.Error, NewKey))
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,281 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+)
+
+type SSHPublicKey struct {
+	// SSH Public Key, that has been Uploaded to the Virtual Machine Server
+	ID               int
+	VirtualMachineID int       `json:"VirtualMachineID" xml:"VirtualMachineID" gorm:"not null;index;uniqueIndex:idx_ssh_key_fingerprint,where:deleted_at IS NULL;"` // Keys are Deleted along with the Virtual Machine (See `VirtualMachine.Delete`)
+	Key              []byte    `json:"Key" xml:"Key" gorm:"type:bytea;not null;"`
+	Filename         string    `json:"Filename" xml:"Filename" gorm:"type:varchar(100);not null;"`
+	Label            string    `json:"Label" xml:"Label" gorm:"type:varchar(100);default:null;"`     // Human Readable Name, e.g `Laptop`
+	Comment          string    `json:"Comment" xml:"Comment" gorm:"type:varchar(255);default:null;"` // Trailing Comment of the Key Line, e.g `user@host`
+	CreatedAt        time.Time `json:"CreatedAt" xml:"CreatedAt" gorm:"<-:create;"`
+
+	// SHA256 Fingerprint of the Key (See `Fingerprint`), Unique per Virtual Machine, so the same Key can't be Added Twice Concurrently
+	KeyFingerprint string `json:"-" xml:"-" gorm:"column:fingerprint;type:varchar(100);default:null;uniqueIndex:idx_ssh_key_fingerprint,where:deleted_at IS NULL;"`
+
+	DeletedAt gorm.DeletedAt `json:"-" xml:"-" gorm:"index;"` // Set by the `SoftDelete`, such Keys are Hidden from the Queries
+}
+
+func NewSshPublicKey(VirtualMachineID int, Key []byte, Filename string) *SSHPublicKey {
+	return NewSshPublicKeyWithLabel(VirtualMachineID, Key, Filename, "", "")
+}
+
+func NewSshPublicKeyWithLabel(VirtualMachineID int, Key []byte, Filename string, Label string, Comment string) *SSHPublicKey {
+	// Returns SSH Public Key with the Label, if the Comment is not Specified, the one of the Key Line is being Used
+	if len(Comment) == 0 {
+		Comment = parseSshKeyComment(Key)
+	}
+	PublicKey := &SSHPublicKey{
+		VirtualMachineID: VirtualMachineID,
+		Key:              Key,
+		Filename:         Filename,
+		Label:            Label,
+		Comment:          Comment,
+	}
+	PublicKey.KeyFingerprint, _ = PublicKey.Fingerprint()
+	return PublicKey
+}
+
+func parseSshKeyComment(Key []byte) string {
+	// Returns Trailing Comment of the Key in the `authorized_keys` Format, Empty String if it has None or the Key is Invalid
+	_, Comment, _, _, ParseError := ssh.ParseAuthorizedKey(Key)
+	if ParseError != nil {
+		return ""
+	}
+	return Comment
+}
+
+var (
+	ErrInvalidSshPublicKey = errors.New("Invalid SSH Public Key")
+	ErrDuplicateKey        = errors.New("Virtual Machine already has the SSH Public Key with the same Fingerprint")
+)
+
+const uniqueViolationCode = "23505" // SQL State of the Unique Constraint Violation (See `translateDuplicateSshKey`)
+
+func ValidateSshPublicKey(Key []byte) error {
+	// Checks, that the Key is the Public Key in the `authorized_keys` Format (Like `ssh-ed25519 AAAA... user@host`)
+	if _, _, _, _, ParseError := ssh.ParseAuthorizedKey(Key); ParseError != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSshPublicKey, ParseError)
+	}
+	return nil
+}
+
+func (this *SSHPublicKey) Fingerprint() (string, error) {
+	// Returns SHA256 Fingerprint of the Key (Like `SHA256:...`), the same one `ssh-keygen -l` Shows
+	PublicKey, _, _, _, ParseError := ssh.ParseAuthorizedKey(this.Key)
+	if ParseError != nil {
+		return "", fmt.Errorf("%w `%s`: %s", ErrInvalidSshPublicKey, this.Filename, ParseError)
+	}
+	return ssh.FingerprintSHA256(PublicKey), nil
+}
+
+func (this *SSHPublicKey) Create() (*gorm.DB, error) {
+	// Creates New SSH Public Key Object
+	return this.CreateContext(context.Background())
+}
+
+func (this *SSHPublicKey) CreateContext(Context context.Context) (*gorm.DB, error) {
+	// Creates New SSH Public Key Object, Query is Cancelled along with the Context
+	// Key, that is not a Valid Public Key, is Rejected with the `ErrInvalidSshPublicKey`
+	if ValidationError := ValidateSshPublicKey(this.Key); ValidationError != nil {
+		return Database, fmt.Errorf("Key `%s`: %w", this.Filename, ValidationError)
+	}
+	if len(this.Comment) == 0 {
+		this.Comment = parseSshKeyComment(this.Key)
+	}
+	this.KeyFingerprint, _ = this.Fingerprint()
+
+	// Check and Insert are Made within the Single Transaction, Concurrent Uploads of the same Key,
+	// that both Pass the Check, are Rejected by the `idx_ssh_key_fingerprint` Unique Index
+	Created := Database
+	CreateError := WithTransaction(func(Transaction *gorm.DB) error {
+		Transaction = Transaction.WithContext(Context)
+		if DuplicateError := checkDuplicateSshKey(Transaction, this.VirtualMachineID, this.Key, 0); DuplicateError != nil {
+			return DuplicateError
+		}
+		Created = Transaction.Create(this)
+		return translateDuplicateSshKey(Created.Error, this)
+	})
+	return Created, CreateError
+}
+
+func translateDuplicateSshKey(Error error, Key *SSHPublicKey) error {
+	// Returns `ErrDuplicateKey` for the Unique Violation of the Fingerprint, other Errors are Returned as is
+	var PgError interface{ SQLState() string }
+	if errors.As(Error, &PgError) && PgError.SQLState() == uniqueViolationCode {
+		return fmt.Errorf("%w: `%s` (%s)", ErrDuplicateKey, Key.Filename, Key.KeyFingerprint)
+	}
+	return Error
+}
+
+func GetSshKeyByFingerprint(VirtualMachineID int, Fingerprint string) (*SSHPublicKey, error) {
+	// Returns SSH Public Key of the Virtual Machine by its SHA256 Fingerprint (See `Fingerprint`),
+	// `ErrNotFound` is Returned, if the Virtual Machine has no such Key
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer CancelFunc()
+	return getSshKeyByFingerprint(Database.WithContext(TimeoutContext), VirtualMachineID, Fingerprint)
+}
+
+func GetSshKeyByID(ID uint) (*SSHPublicKey, error) {
+	// Returns SSH Public Key by its own ID, `ErrNotFound` is Returned, if there is no such Key (or it has been Soft Deleted)
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer CancelFunc()
+
+	Key := &SSHPublicKey{}
+	if Gorm := Database.WithContext(TimeoutContext).Where("id = ?", ID).First(Key); Gorm.Error != nil {
+		return nil, TranslateNotFound(Gorm.Error)
+	}
+	return Key, nil
+}
+
+func (this *SSHPublicKey) Get() error {
+	// Reloads this SSH Public Key Object from the Database by its ID, the Fields are Left Untouched on Error
+	if this.ID <= 0 {
+		return ErrNotFound
+	}
+	Key, LookupError := GetSshKeyByID(uint(this.ID))
+	if LookupError != nil {
+		return LookupError
+	}
+	*this = *Key
+	return nil
+}
+
+func getSshKeyByFingerprint(Session *gorm.DB, VirtualMachineID int, Fingerprint string) (*SSHPublicKey, error) {
+	// Fingerprints are not Stored, so they are Computed for every Key of the Virtual Machine (There are only a few of them)
+	var Keys []SSHPublicKey
+	if Gorm := Session.Where("virtual_machine_id = ?", VirtualMachineID).Order("id").Find(&Keys); Gorm.Error != nil {
+		return nil, Gorm.Error
+	}
+	for Index := range Keys {
+		if KeyFingerprint, FingerprintError := Keys[Index].Fingerprint(); FingerprintError == nil && KeyFingerprint == Fingerprint {
+			return &Keys[Index], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func checkDuplicateSshKey(Session *gorm.DB, VirtualMachineID int, Key []byte, ExceptID int) error {
+	// Returns `ErrDuplicateKey`, if another Key of the Virtual Machine (Except the one with the `ExceptID`) has the same Fingerprint
+	Fingerprint, FingerprintError := (&SSHPublicKey{Key: Key}).Fingerprint()
+	if FingerprintError != nil {
+		return FingerprintError
+	}
+	var Keys []SSHPublicKey
+	if Gorm := Session.Where("virtual_machine_id = ? AND id <> ?", VirtualMachineID, ExceptID).Order("id").Find(&Keys); Gorm.Error != nil {
+		return Gorm.Error
+	}
+	for _, Existing := range Keys {
+		if ExistingFingerprint, _ := Existing.Fingerprint(); ExistingFingerprint == Fingerprint {
+			return fmt.Errorf("%w: `%s` (%s)", ErrDuplicateKey, Existing.Filename, Fingerprint)
+		}
+	}
+	return nil
+}
+
+func (this *SSHPublicKey) Update(NewSshKey []byte, Filename ...string) (*gorm.DB, error) {
+	// Replaces the Key (and the File Name, if it is Specified) of this SSH Public Key Object,
+	// Row is Matched by the Primary Key, so other Keys of the same Virtual Machine are Left Untouched,
+	// `ErrDuplicateKey` is Returned, if one of them is the same Key
+	if ValidationError := ValidateSshPublicKey(NewSshKey); ValidationError != nil {
+		return Database, ValidationError
+	}
+	Fingerprint, _ := (&SSHPublicKey{Key: NewSshKey}).Fingerprint()
+	Columns := map[string]interface{}{"key": NewSshKey, "fingerprint": Fingerprint}
+	if len(Filename) != 0 && len(Filename[0]) != 0 {
+		Columns["filename"] = Filename[0]
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer CancelFunc()
+
+	// Key must not Duplicate another Key of the same Virtual Machine, the Key itself is Excluded from the Check
+	var Updated *gorm.DB
+	UpdateError := Database.WithContext(TimeoutContext).Transaction(func(Transaction *gorm.DB) error {
+		var Stored SSHPublicKey
+		if Found := Transaction.Select("id", "virtual_machine_id").Where("id = ?", this.ID).First(&Stored); Found.Error != nil {
+			Updated = Found
+			return TranslateNotFound(Found.Error)
+		}
+		if DuplicateError := checkDuplicateSshKey(Transaction, Stored.VirtualMachineID, NewSshKey, this.ID); DuplicateError != nil {
+			return DuplicateError
+		}
+		Updated = Transaction.Model(&SSHPublicKey{}).Where("id = ?", this.ID).Updates(Columns)
+		return translateDuplicateSshKey(Updated.Error, &SSHPublicKey{Filename: this.Filename, KeyFingerprint: Fingerprint})
+	})
+	if UpdateError != nil {
+		if Updated == nil {
+			Updated = Database
+		}
+		return Updated, UpdateError
+	}
+	this.Key = NewSshKey
+	this.KeyFingerprint = Fingerprint
+	if FileName, Changed := Columns["filename"]; Changed {
+		this.Filename = FileName.(string)
+	}
+	return Updated, nil
+}
+
+func (this *SSHPublicKey) Delete() (*gorm.DB, error) {
+	// Deletes the SSH Public Key Object Permanently, `ErrNotFound` is Returned, if there was no such Key
+	return this.DeleteContext(context.Background())
+}
+
+func (this *SSHPublicKey) DeleteContext(Context context.Context) (*gorm.DB, error) {
+	// Deletes the SSH Public Key Object, Query is Cancelled along with the Context
+	Deleted := Database.WithContext(Context).Unscoped().Where("id = ?", this.ID).Delete(&SSHPublicKey{})
+	if Deleted.Error == nil && Deleted.RowsAffected == 0 {
+		return Deleted, ErrNotFound
+	}
+	return Deleted, Deleted.Error
+}
+
+func RevokeSshKeys(VirtualMachineID int) (int64, error) {
+	// Deletes every SSH Public Key of the Virtual Machine, Returns Amount of the Deleted Keys
+	Deleted := Database.Unscoped().Where("virtual_machine_id = ?", VirtualMachineID).Delete(&SSHPublicKey{})
+	return Deleted.RowsAffected, Deleted.Error
+}
+
+func ReplaceSshKeys(VirtualMachineID int, Key *SSHPublicKey) error {
+	// Replaces every SSH Public Key of the Virtual Machine with the Key within the Single Transaction,
+	// so the Virtual Machine is never Left without the Keys or with both the Old and New ones
+	if ValidationError := ValidateSshPublicKey(Key.Key); ValidationError != nil {
+		return fmt.Errorf("Key `%s`: %w", Key.Filename, ValidationError)
+	}
+	return WithTransaction(func(Transaction *gorm.DB) error {
+		if Deleted := Transaction.Unscoped().Where("virtual_machine_id = ?", VirtualMachineID).Delete(&SSHPublicKey{}); Deleted.Error != nil {
+			return Deleted.Error
+		}
+		Key.ID = 0
+		Key.VirtualMachineID = VirtualMachineID
+		Key.KeyFingerprint, _ = Key.Fingerprint()
+		if DuplicateError := checkDuplicateSshKey(Transaction, VirtualMachineID, Key.Key, 0); DuplicateError != nil {
+			return DuplicateError
+		}
+		return translateDuplicateSshKey(Transaction.Create(Key).Error, Key)
+	})
+}
+
+func (this *VirtualMachine) ListSshKeys() ([]SSHPublicKey, error) {
+	// Returns every SSH Public Key of the Virtual Machine, Newest first,
+	// Virtual Machine without Keys gets an Empty List
+	Keys := []SSHPublicKey{}
+	if Gorm := Database.Where("virtual_machine_id = ?", this.ID).Order(
+		"created_at DESC").Order("id DESC").Find(&Keys); Gorm.Error != nil {
+		Logger.Error("Failed to List SSH Keys", zap.Int("Virtual Machine ID", this.ID), zap.Error(Gorm.Error))
+		return nil, Gorm.Error
+	}
+	return Keys, nil
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+type VirtualMachineTag struct {
+	// Key-Value Tag of the Virtual Machine, like `env=production` or `team=billing`
+	ID               int
+	VirtualMachineID int    `json:"VirtualMachineID" xml:"VirtualMachineID" gorm:"not null;uniqueIndex:idx_vm_tag_key;"`
+	Key              string `json:"Key" xml:"Key" gorm:"type:varchar(100);not null;uniqueIndex:idx_vm_tag_key;index:idx_tag_key_value;"`
+	Value            string `json:"Value" xml:"Value" gorm:"type:varchar(255);not null;default:'';index:idx_tag_key_value;"`
+}
+
+func NewVirtualMachineTag(VirtualMachineID int, Key string, Value string) *VirtualMachineTag {
+	return &VirtualMachineTag{
+		VirtualMachineID: VirtualMachineID,
+		Key:              Key,
+		Value:            Value,
+	}
+}
+
+func TagVirtualMachine(VirtualMachineID int, Key string, Value string) error {
+	// Sets the Tag of the Virtual Machine, Replaces the Value, if the Tag with the same Key already Exists
+	Tag := NewVirtualMachineTag(VirtualMachineID, Key, Value)
+	Created := Database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "virtual_machine_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value"}),
+	}).Create(Tag)
+	return Created.Error
+}
+
+func UntagVirtualMachine(VirtualMachineID int, Key string) error {
+	// Removes the Tag from the Virtual Machine
+	Deleted := Database.Where("virtual_machine_id = ? AND key = ?", VirtualMachineID, Key).Delete(&VirtualMachineTag{})
+	return Deleted.Error
+}
+
+type TagFilter struct {
+	Key   string `json:"Key" xml:"Key"`
+	Value string `json:"Value" xml:"Value"` // Empty Value Matches any Value of the Key
+}
+
+func GetVirtualMachinesByTags(Filters []TagFilter, MatchAll bool) ([]VirtualMachine, error) {
+	// Returns Virtual Machines, that have All (`MatchAll`) or Any of the Tags, Matching the Filters
+	// Done within a Single Query: Tags are being Filtered, Grouped by the Virtual Machine
+	// And the Amount of the Matched Filters is being Compared in the `HAVING` Clause
+	// Filters are being Counted instead of the Keys, because the Wildcard and Exact Filters of the same Key Match the same Tag
+
+	if len(Filters) == 0 {
+		return nil, errors.New("At least one Tag Filter is Required")
+	}
+
+	Filters = uniqueFilters(Filters)
+	Conditions := make([]string, 0, len(Filters))
+	Matches := make([]string, 0, len(Filters))
+	Arguments := make([]interface{}, 0, len(Filters)*2)
+	for _, Filter := range Filters {
+		Condition := "(virtual_machine_tags.key = ? AND virtual_machine_tags.value = ?)"
+		if len(Filter.Value) == 0 {
+			Condition = "(virtual_machine_tags.key = ?)"
+			Arguments = append(Arguments, Filter.Key)
+		} else {
+			Arguments = append(Arguments, Filter.Key, Filter.Value)
+		}
+		Conditions = append(Conditions, Condition)
+		Matches = append(Matches, fmt.Sprintf("(CASE WHEN %s THEN 1 ELSE 0 END)", Condition))
+	}
+
+	// Keys are Unique per Virtual Machine, so every Filter Matches at most one Tag of the Virtual Machine,
+	// and it matches All the Filters, Only if the Sum of the Filters, Matched by its Tags, equals to the Amount of the Filters
+	Required := 1
+	if MatchAll {
+		Required = len(Filters)
+	}
+	HavingArguments := append(append([]interface{}{}, Arguments...), Required)
+
+	var VirtualMachines []VirtualMachine
+	Gorm := Database.Model(&VirtualMachine{}).Select(
+		"virtual_machines.id", "virtual_machines.state", "virtual_machines.owner_id",
+		"virtual_machines.virtual_machine_name", "virtual_machines.item_path",
+		"virtual_machines.ip_address", "virtual_machines.uuid", "virtual_machines.created_at").Joins(
+		"JOIN virtual_machine_tags ON virtual_machine_tags.virtual_machine_id = virtual_machines.id").Where(
+		strings.Join(Conditions, " OR "), Arguments...).Group("virtual_machines.id").Having(
+		fmt.Sprintf("SUM(%s) >= ?", strings.Join(Matches, " + ")), HavingArguments...).Order("virtual_machines.id").Find(&VirtualMachines)
+	if Gorm.Error != nil {
+		return nil, fmt.Errorf("Failed to Query Virtual Machines by Tags: %w", Gorm.Error)
+	}
+	return VirtualMachines, nil
+}
+
+func uniqueFilters(Filters []TagFilter) []TagFilter {
+	// Returns Filters without Duplicates
+	Seen := map[TagFilter]bool{}
+	Unique := make([]TagFilter, 0, len(Filters))
+	for _, Filter := range Filters {
+		if !Seen[Filter] {
+			Seen[Filter] = true
+			Unique = append(Unique, Filter)
+		}
+	}
+	return Unique
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Metered Billing, Resource-Hours are being Computed from the Power and Resize Events of the Virtual Machine Timeline
+// Virtual Machine is being Charged by the Size it had at every Moment (See `ResizeEventDetail`), Virtual Machines,
+// that have been Deleted within the Window, are Charged until the Deletion
+
+type VirtualMachineUsage struct {
+	VirtualMachineID   int     `json:"VirtualMachineID" xml:"VirtualMachineID"`
+	VirtualMachineName string  `json:"VirtualMachineName" xml:"VirtualMachineName"`
+	RunningHours       float64 `json:"RunningHours" xml:"RunningHours"`
+	CpuHours           float64 `json:"CpuHours" xml:"CpuHours"`
+	MemoryGBHours      float64 `json:"MemoryGBHours" xml:"MemoryGBHours"`
+}
+
+type UsageReport struct {
+	OwnerID            string                `json:"OwnerID" xml:"OwnerID"`
+	Start              time.Time             `json:"Start" xml:"Start"`
+	End                time.Time             `json:"End" xml:"End"`
+	VirtualMachines    []VirtualMachineUsage `json:"VirtualMachines" xml:"VirtualMachines"`
+	TotalCpuHours      float64               `json:"TotalCpuHours" xml:"TotalCpuHours"`
+	TotalMemoryGBHours float64               `json:"TotalMemoryGBHours" xml:"TotalMemoryGBHours"`
+}
+
+type ResourceSize struct {
+	// Size of the Virtual Machine, it is being Charged by
+	NumCPU   int32 `json:"NumCPU" xml:"NumCPU"`
+	MemoryMB int64 `json:"MemoryMB" xml:"MemoryMB"`
+}
+
+func ParseResizeEventDetail(Detail string) (ResourceSize, ResourceSize, error) {
+	// Returns the New and the Previous Size of the Virtual Machine, Recorded by the `EventResized`
+	var Size, Previous ResourceSize
+	if _, ScanError := fmt.Sscanf(Detail, resizeEventDetailFormat,
+		&Size.NumCPU, &Size.MemoryMB, &Previous.NumCPU, &Previous.MemoryMB); ScanError != nil {
+		return Size, Previous, fmt.Errorf("Invalid Resize Event Detail `%s`: %w", Detail, ScanError)
+	}
+	return Size, Previous, nil
+}
+
+func RunningDuration(Events []VMEvent, Start time.Time, End time.Time) time.Duration {
+	// Returns how long the Virtual Machine has been Running within the Window,
+	// Events should be Sorted by the Time, Oldest First, and include the Events, that happened before the Window
+	return MeterResources(Events, Start, End, ResourceSize{}).Running
+}
+
+type ResourceUsage struct {
+	// Resources, the Virtual Machine has Consumed within the Window
+	Running       time.Duration
+	CpuHours      float64
+	MemoryGBHours float64
+}
+
+func MeterResources(Events []VMEvent, Start time.Time, End time.Time, Current ResourceSize) ResourceUsage {
+	// Returns Running Time and Resource-Hours of the Virtual Machine within the Window, every Running Interval is
+	// Charged by the Size, the Virtual Machine had at that Moment: the Size before the First Resize is Taken from its Event,
+	// the Current Size is Used only if the Virtual Machine has never been Resized
+	// Events should be Sorted by the Time, Oldest First, and include the Events, that happened before and after the Window
+
+	Size := Current
+	for _, Event := range Events {
+		if Event.Type != EventResized {
+			continue
+		}
+		if _, Previous, ParseError := ParseResizeEventDetail(Event.Detail); ParseError == nil {
+			Size = Previous
+			break
+		}
+	}
+
+	var Usage ResourceUsage
+	var RunningSince *time.Time
+	Charge := func(To time.Time) {
+		Duration := overlap(*RunningSince, To, Start, End)
+		Usage.Running += Duration
+		Usage.CpuHours += Duration.Hours() * float64(Size.NumCPU)
+		Usage.MemoryGBHours += Duration.Hours() * float64(Size.MemoryMB) / 1024
+	}
+
+	for _, Event := range Events {
+		if !Event.CreatedAt.Before(End) {
+			break
+		}
+		switch Event.Type {
+		case EventPoweredOn:
+			if RunningSince == nil {
+				Since := Event.CreatedAt
+				RunningSince = &Since
+			}
+		case EventPoweredOff, EventDestroyed:
+			if RunningSince != nil {
+				Charge(Event.CreatedAt)
+				RunningSince = nil
+			}
+		case EventResized:
+			NewSize, _, ParseError := ParseResizeEventDetail(Event.Detail)
+			if ParseError != nil {
+				Logger.Warn("Resize Event is Ignored", zap.Int("Virtual Machine ID", Event.VirtualMachineID), zap.Error(ParseError))
+				continue
+			}
+			if RunningSince != nil {
+				// Running Interval is Split, so the Part before the Resize is Charged by the Previous Size
+				Charge(Event.CreatedAt)
+				Since := Event.CreatedAt
+				RunningSince = &Since
+			}
+			Size = NewSize
+		}
+	}
+	if RunningSince != nil {
+		Charge(End)
+	}
+	return Usage
+}
+
+func overlap(From time.Time, To time.Time, Start time.Time, End time.Time) time.Duration {
+	// Returns Duration of the Intersection of the [From, To) and [Start, End) Intervals
+	if From.Before(Start) {
+		From = Start
+	}
+	if To.After(End) {
+		To = End
+	}
+	if !To.After(From) {
+		return 0
+	}
+	return To.Sub(From)
+}
+
+func ComputeResourceHours(OwnerID string, Start time.Time, End time.Time) (*UsageReport, error) {
+	// Returns CPU-Hours and GB-RAM-Hours of every Virtual Machine of the Customer within the Window
+
+	if Now := time.Now(); End.After(Now) {
+		End = Now // Future can't be Charged
+	}
+	Report := &UsageReport{OwnerID: OwnerID, Start: Start, End: End, VirtualMachines: []VirtualMachineUsage{}}
+
+	// Configuration is being Decoded manually, because it might be Null for some Virtual Machines,
+	// Virtual Machines, Deleted within the Window, are Included as well (See `DeleteVirtualMachineRecords`)
+	var VirtualMachines []struct {
+		ID                 int
+		VirtualMachineName string
+		Configuration      sql.NullString
+		DeletedAt          sql.NullTime
+	}
+	if Gorm := Database.Unscoped().Model(&VirtualMachine{}).Select("id", "virtual_machine_name", "configuration", "deleted_at").Where(
+		"owner_id = ? AND (deleted_at IS NULL OR deleted_at > ?)", OwnerID, Start).Order("id").Scan(&VirtualMachines); Gorm.Error != nil {
+		Logger.Error("Failed to Load Customer Virtual Machines", zap.String("Owner ID", OwnerID), zap.Error(Gorm.Error))
+		return nil, Gorm.Error
+	}
+
+	for _, VirtualMachine := range VirtualMachines {
+		// Resize Events after the Window are Loaded as well, they Tell the Size, the Virtual Machine had within it
+		var Events []VMEvent
+		if Gorm := Database.Model(&VMEvent{}).Where("virtual_machine_id = ? AND type IN ?",
+			VirtualMachine.ID, []string{EventPoweredOn, EventPoweredOff, EventDestroyed, EventResized}).Order(
+			"created_at ASC, id ASC").Find(&Events); Gorm.Error != nil {
+			Logger.Error("Failed to Load Virtual Machine Events", zap.Int("Virtual Machine ID", VirtualMachine.ID), zap.Error(Gorm.Error))
+			return nil, Gorm.Error
+		}
+
+		var Configuration VirtualMachineConfiguration
+		if VirtualMachine.Configuration.Valid {
+			json.Unmarshal([]byte(VirtualMachine.Configuration.String), &Configuration)
+		}
+
+		ChargedUntil := End
+		if VirtualMachine.DeletedAt.Valid && VirtualMachine.DeletedAt.Time.Before(End) {
+			ChargedUntil = VirtualMachine.DeletedAt.Time
+		}
+		Metered := MeterResources(Events, Start, ChargedUntil, ResourceSize{
+			NumCPU: Configuration.Resources.CpuNum, MemoryMB: Configuration.Resources.MemoryInMegabytes})
+		Usage := VirtualMachineUsage{
+			VirtualMachineID:   VirtualMachine.ID,
+			VirtualMachineName: VirtualMachine.VirtualMachineName,
+			RunningHours:       Metered.Running.Hours(),
+			CpuHours:           Metered.CpuHours,
+			MemoryGBHours:      Metered.MemoryGBHours,
+		}
+		Report.VirtualMachines = append(Report.VirtualMachines, Usage)
+		Report.TotalCpuHours += Usage.CpuHours
+		Report.TotalMemoryGBHours += Usage.MemoryGBHours
+	}
+	return Report, nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+func FindStuckProvisioning(Threshold time.Duration) ([]VirtualMachine, error) {
+	// Returns Virtual Machines, that are being Pending or Provisioned for longer than the Threshold
+
+	var VirtualMachines []VirtualMachine
+	Gorm := Database.Model(&VirtualMachine{}).Select("id", "state", "item_path", "virtual_machine_name", "created_at").Where(
+		"state IN ? AND created_at < ?", []string{StatusPending, StatusProvisioning}, time.Now().Add(-Threshold)).Order(
+		"id").Find(&VirtualMachines)
+	return VirtualMachines, Gorm.Error
+}
+
+func UpdateVirtualMachineState(VirtualMachineID int, State string) error {
+	// Transitions Virtual Machine to the New State
+	Updated := Database.Model(&VirtualMachine{}).Where("id = ?", VirtualMachineID).Update("state", State)
+	return Updated.Error
+}
+
+func CompleteProvisioning(VirtualMachineID int, UUID string, IPAddress string) error {
+	// Transitions Provisioned Virtual Machine to the `Ready` State along with its vSphere UUID,
+	// UUID and IP Address are Updated only if they are Known, `ErrNotFound` is Returned, if there is no such Virtual Machine
+	Updates := map[string]interface{}{"state": StatusReady}
+	if len(UUID) != 0 {
+		Updates["uuid"] = UUID
+	}
+	if len(IPAddress) != 0 {
+		Updates["ip_address"] = IPAddress
+	}
+	Updated := Database.Model(&VirtualMachine{}).Where("id = ?", VirtualMachineID).Updates(Updates)
+	if Updated.Error != nil {
+		return Updated.Error
+	}
+	if Updated.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func SetVirtualMachineTemplateFlag(ItemPath string, IsTemplate bool) error {
+	// Marks the Virtual Machine Row as a Template or as a Regular Virtual Machine
+	// `ErrNotFound` is Returned, if there is no Virtual Machine with such Item Path
+	Updated := Database.Model(&VirtualMachine{}).Where("item_path = ?", ItemPath).Update("is_template", IsTemplate)
+	if Updated.Error != nil {
+		return Updated.Error
+	}
+	if Updated.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func SetVirtualMachineEncryptedFlag(ItemPath string, Encrypted bool) error {
+	// Marks the Virtual Machine Row as Encrypted or as Not Encrypted
+	Updated := Database.Model(&VirtualMachine{}).Where("item_path = ?", ItemPath).Update("encrypted", Encrypted)
+	return Updated.Error
+}
+
+func SetVirtualMachineHardwareVersion(ItemPath string, Version string) error {
+	// Updates Hardware Version of the Virtual Machine Row, e.g after the Upgrade
+	Updated := Database.Model(&VirtualMachine{}).Where("item_path = ?", ItemPath).Update("hardware_version", Version)
+	return Updated.Error
+}
@@ -0,0 +1,235 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/datacenter"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+const DefaultAdapterType = "vmxnet3"
+const MaxNetworkAdapters = 10 // vSphere Limit of the Network Adapters per Virtual Machine
+
+var (
+	SupportedAdapterTypes = []string{"vmxnet3", "e1000e", "e1000", "vmxnet2", "pcnet32"}
+)
+
+var (
+	ErrTooManyNetworkAdapters  = errors.New("Too many Network Adapters for the Virtual Machine")
+	ErrAdapterTypeMismatch     = errors.New("Adapter Type of the existing Network Adapter can't be Changed")
+	ErrUnsupportedGuestAdapter = errors.New("Adapter Type is not Supported by the Guest OS")
+)
+
+type NetworkAdapterSpec struct {
+	// Specification of the Single Network Adapter (NIC) of the Virtual Machine
+	Network     string `json:"Network" xml:"Network"`                             // Inventory Path or Name of the Port Group
+	AdapterType string `json:"AdapterType,omitempty" xml:"AdapterType,omitempty"` // One of the `SupportedAdapterTypes`
+}
+
+func ValidateNetworkAdapters(Specs []NetworkAdapterSpec) error {
+	// Checks that every Adapter has the Network and Supported Type, and that Adapters are Distinct
+	// (Two Adapters on the same Port Group do not give any Redundancy)
+
+	if len(Specs) > MaxNetworkAdapters {
+		return fmt.Errorf("%w, Max %d are Supported", ErrTooManyNetworkAdapters, MaxNetworkAdapters)
+	}
+	Networks := map[string]int{}
+	for Index, Spec := range Specs {
+		if len(Spec.Network) == 0 {
+			return fmt.Errorf("Network of the Adapter #%d is Required", Index)
+		}
+		if len(Spec.AdapterType) != 0 && !isSupportedAdapterType(Spec.AdapterType) {
+			return fmt.Errorf("Unsupported Adapter Type `%s` of the Adapter #%d", Spec.AdapterType, Index)
+		}
+		if Previous, Exists := Networks[Spec.Network]; Exists {
+			return fmt.Errorf("Adapters #%d and #%d are Attached to the same Network `%s`", Previous, Index, Spec.Network)
+		}
+		Networks[Spec.Network] = Index
+	}
+	return nil
+}
+
+func isSupportedAdapterType(AdapterType string) bool {
+	for _, Supported := range SupportedAdapterTypes {
+		if Supported == AdapterType {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *VirtualMachinePrivateNetworkManager) AttachNetworkAdapters(VirtualMachine *object.VirtualMachine, Specs []NetworkAdapterSpec) error {
+	// Attaches Network Adapters to the Virtual Machine, Adapter #N of the Specs is the N-th NIC of the VM
+	// Adapters, that are Already Attached to the right Network are Left as is, so the Call can be Safely Repeated
+
+	if ValidationError := ValidateNetworkAdapters(Specs); ValidationError != nil {
+		return ValidationError
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+	defer CancelFunc()
+
+	if GuestError := this.checkGuestAdapterTypes(TimeoutContext, VirtualMachine, Specs); GuestError != nil {
+		return GuestError
+	}
+
+	for Index, Spec := range Specs {
+		if AttachError := this.AttachNetworkAdapter(VirtualMachine, Index, Spec); AttachError != nil {
+			return AttachError
+		}
+	}
+	return nil
+}
+
+func (this *VirtualMachinePrivateNetworkManager) AttachNetworkAdapter(VirtualMachine *object.VirtualMachine, Index int, Spec NetworkAdapterSpec) error {
+	// Makes the Network Adapter #Index of the Virtual Machine to be Attached to the Network of the Spec
+	// If the Adapter does not Exist yet, it is being Added (Only the next Index is Allowed, so there is no Gaps),
+	// If it Exists, but Attached to another Network, it is being Moved, Otherwise Nothing Happens
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+	defer CancelFunc()
+
+	Finder := find.NewFinder(&this.Client)
+	if Datacenter, DatacenterError := datacenter.GetDatacenterResolver(this.Client).Resolve(
+		TimeoutContext, datacenter.DefaultDatacenterName); DatacenterError == nil {
+		Finder.SetDatacenter(Datacenter)
+	}
+	Network, NetworkError := Finder.Network(TimeoutContext, Spec.Network)
+	if NetworkError != nil {
+		Logger.Error("Failed to Find Network", zap.String("Network", Spec.Network), zap.Error(NetworkError))
+		return NetworkError
+	}
+	Backing, BackingError := Network.EthernetCardBackingInfo(TimeoutContext)
+	if BackingError != nil {
+		return BackingError
+	}
+
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	Devices, DeviceError := VirtualMachine.Device(TimeoutContext)
+	if DeviceError != nil {
+		return DeviceError
+	}
+	Cards := Devices.SelectByType((*types.VirtualEthernetCard)(nil))
+
+	switch {
+	case Index < 0 || Index > len(Cards):
+		return fmt.Errorf("Network Adapter #%d can't be Attached, Virtual Machine has %d Adapters", Index, len(Cards))
+
+	case Index == len(Cards):
+		AdapterType := Spec.AdapterType
+		if len(AdapterType) == 0 {
+			AdapterType = DefaultAdapterType
+		}
+		Card, CardError := Devices.CreateEthernetCard(AdapterType, Backing)
+		if CardError != nil {
+			return CardError
+		}
+		if AddError := VirtualMachine.AddDevice(TimeoutContext, Card); AddError != nil {
+			Logger.Error("Failed to Add Network Adapter", zap.String("Network", Spec.Network), zap.Error(AddError))
+			return AddError
+		}
+		Logger.Debug("Network Adapter has been Added", zap.String("Virtual Machine", VirtualMachine.InventoryPath),
+			zap.Int("Index", Index), zap.String("Network", Spec.Network))
+		return nil
+
+	default:
+		Card := Cards[Index]
+		if CurrentType := adapterType(Devices, Card); len(Spec.AdapterType) != 0 && CurrentType != Spec.AdapterType {
+			return fmt.Errorf("%w: Adapter #%d is `%s`", ErrAdapterTypeMismatch, Index, CurrentType)
+		}
+		if sameNetworkBacking(Card.GetVirtualDevice().Backing, Backing) {
+			return nil
+		}
+		Card.GetVirtualDevice().Backing = Backing
+		if EditError := VirtualMachine.EditDevice(TimeoutContext, Card); EditError != nil {
+			Logger.Error("Failed to Move Network Adapter", zap.String("Network", Spec.Network), zap.Error(EditError))
+			return EditError
+		}
+		return nil
+	}
+}
+
+func adapterType(Devices object.VirtualDeviceList, Card types.BaseVirtualDevice) string {
+	// Returns Adapter Type of the Network Adapter in the Format of the `SupportedAdapterTypes`, e.g `VirtualVmxnet3` -> `vmxnet3`
+	return strings.ToLower(strings.TrimPrefix(Devices.TypeName(Card), "Virtual"))
+}
+
+func sameNetworkBacking(Current types.BaseVirtualDeviceBackingInfo, Desired types.BaseVirtualDeviceBackingInfo) bool {
+	// Returns True if both Backings Point to the same Network
+	switch Desired := Desired.(type) {
+	case *types.VirtualEthernetCardNetworkBackingInfo:
+		Current, Matches := Current.(*types.VirtualEthernetCardNetworkBackingInfo)
+		return Matches && Current.DeviceName == Desired.DeviceName
+	case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+		Current, Matches := Current.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo)
+		return Matches && Current.Port.PortgroupKey == Desired.Port.PortgroupKey
+	case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+		Current, Matches := Current.(*types.VirtualEthernetCardOpaqueNetworkBackingInfo)
+		return Matches && Current.OpaqueNetworkId == Desired.OpaqueNetworkId
+	default:
+		return false
+	}
+}
+
+func (this *VirtualMachinePrivateNetworkManager) checkGuestAdapterTypes(Context context.Context, VirtualMachine *object.VirtualMachine, Specs []NetworkAdapterSpec) error {
+	// Checks that the Guest OS of the Virtual Machine Supports every Adapter Type of the Specs,
+	// If the Guest OS Descriptor does not List Supported Adapters, Check is being Skipped
+
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(Context, VirtualMachine.Reference(),
+		[]string{"environmentBrowser", "config.guestId"}, &MoVirtualMachine); RetrieveError != nil {
+		return RetrieveError
+	}
+	if MoVirtualMachine.Config == nil {
+		return nil
+	}
+
+	Response, QueryError := methods.QueryConfigOptionEx(Context, &this.Client, &types.QueryConfigOptionEx{
+		This: MoVirtualMachine.EnvironmentBrowser,
+		Spec: &types.EnvironmentBrowserConfigOptionQuerySpec{GuestId: []string{MoVirtualMachine.Config.GuestId}},
+	})
+	if QueryError != nil {
+		Logger.Error("Failed to Query Guest OS Descriptor", zap.Error(QueryError))
+		return QueryError
+	}
+
+	SupportedTypes := map[string]bool{}
+	if Response.Returnval != nil {
+		for _, Descriptor := range Response.Returnval.GuestOSDescriptor {
+			if Descriptor.Id != MoVirtualMachine.Config.GuestId {
+				continue
+			}
+			for _, CardType := range Descriptor.SupportedEthernetCard {
+				SupportedTypes[strings.ToLower(strings.TrimPrefix(CardType, "Virtual"))] = true
+			}
+		}
+	}
+	if len(SupportedTypes) == 0 {
+		return nil
+	}
+
+	for Index, Spec := range Specs {
+		if len(Spec.AdapterType) != 0 && !SupportedTypes[Spec.AdapterType] {
+			return fmt.Errorf("%w: Adapter #%d of Type `%s` is not Supported by the Guest OS `%s`",
+				ErrUnsupportedGuestAdapter, Index, Spec.AdapterType, MoVirtualMachine.Config.GuestId)
+		}
+	}
+	return nil
+}
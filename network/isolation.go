@@ -0,0 +1,123 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/datacenter"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrPortGroupShared = errors.New("Port Group is Shared with the Virtual Machines of another Owner")
+)
+
+func (this *VirtualMachinePrivateNetworkManager) IsolateVMNetwork(VirtualMachine *object.VirtualMachine, PortGroupName string) error {
+	// Moves every Network Adapter of the Virtual Machine to the Dedicated Port Group
+	// Port Group should not have any Virtual Machine of another Owner (or Virtual Machine, that is not Managed by us),
+	// Otherwise `ErrPortGroupShared` is being Returned and Nothing is Changed
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+	defer CancelFunc()
+
+	Finder := find.NewFinder(&this.Client)
+	if Datacenter, DatacenterError := datacenter.GetDatacenterResolver(this.Client).Resolve(
+		TimeoutContext, datacenter.DefaultDatacenterName); DatacenterError == nil {
+		Finder.SetDatacenter(Datacenter)
+	}
+	PortGroup, PortGroupError := Finder.Network(TimeoutContext, PortGroupName)
+	if PortGroupError != nil {
+		Logger.Error("Failed to Find Port Group", zap.String("Port Group", PortGroupName), zap.Error(PortGroupError))
+		return PortGroupError
+	}
+
+	if SharedError := this.checkPortGroupOwnership(TimeoutContext, Finder, PortGroup, VirtualMachine); SharedError != nil {
+		return SharedError
+	}
+
+	Backing, BackingError := PortGroup.EthernetCardBackingInfo(TimeoutContext)
+	if BackingError != nil {
+		return BackingError
+	}
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	Devices, DeviceError := VirtualMachine.Device(TimeoutContext)
+	if DeviceError != nil {
+		return DeviceError
+	}
+	Cards := Devices.SelectByType((*types.VirtualEthernetCard)(nil))
+	if len(Cards) == 0 {
+		return errors.New("Virtual Machine does not have any Network Adapter")
+	}
+	for _, Card := range Cards {
+		Card.GetVirtualDevice().Backing = Backing
+	}
+
+	if EditError := VirtualMachine.EditDevice(TimeoutContext, Cards...); EditError != nil {
+		Logger.Error("Failed to Move Virtual Machine to the Isolated Port Group",
+			zap.String("Port Group", PortGroupName), zap.Error(EditError))
+		return EditError
+	}
+	Logger.Debug("Virtual Machine has been Isolated", zap.String("Virtual Machine", VirtualMachine.InventoryPath),
+		zap.String("Port Group", PortGroupName))
+	return nil
+}
+
+func (this *VirtualMachinePrivateNetworkManager) checkPortGroupOwnership(Context context.Context, Finder *find.Finder, PortGroup object.NetworkReference, VirtualMachine *object.VirtualMachine) error {
+	// Returns `ErrPortGroupShared` if the Port Group has Virtual Machines of another Owner, than the Virtual Machine has
+
+	var MoNetwork mo.Network
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(Context, PortGroup.Reference(), []string{"vm"}, &MoNetwork); RetrieveError != nil {
+		return RetrieveError
+	}
+
+	// Resolving Inventory Paths, so the Virtual Machines can be Matched with the Database Rows
+	if len(VirtualMachine.InventoryPath) == 0 {
+		Element, ElementError := Finder.ObjectReference(Context, VirtualMachine.Reference())
+		if ElementError != nil {
+			return ElementError
+		}
+		VirtualMachine.InventoryPath = Element.(*object.VirtualMachine).InventoryPath
+	}
+	ItemPaths := []string{VirtualMachine.InventoryPath}
+	for _, Reference := range MoNetwork.Vm {
+		if Reference == VirtualMachine.Reference() {
+			continue
+		}
+		Element, ElementError := Finder.ObjectReference(Context, Reference)
+		if ElementError != nil {
+			return ElementError
+		}
+		ItemPaths = append(ItemPaths, Element.(*object.VirtualMachine).InventoryPath)
+	}
+
+	Owners, OwnersError := models.GetVirtualMachineOwners(ItemPaths)
+	if OwnersError != nil {
+		return OwnersError
+	}
+	Owner, Managed := Owners[VirtualMachine.InventoryPath]
+	if !Managed {
+		return fmt.Errorf("Virtual Machine `%s` is not Managed", VirtualMachine.InventoryPath)
+	}
+	for _, ItemPath := range ItemPaths[1:] {
+		if AnotherOwner, Exists := Owners[ItemPath]; !Exists || AnotherOwner != Owner {
+			Logger.Error("Port Group is Shared across the Owners", zap.String("Virtual Machine", ItemPath))
+			return fmt.Errorf("%w: `%s`", ErrPortGroupShared, ItemPath)
+		}
+	}
+	return nil
+}
@@ -3,7 +3,6 @@ package network
 import (
 	"context"
 	"errors"
-	"os"
 	"time"
 
 	"reflect"
@@ -17,10 +16,11 @@ import (
 	"github.com/vmware/govmomi/vim25/types"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
 )
 
 var (
@@ -28,15 +28,8 @@ var (
 )
 
 func InitializeProductionLogger() {
-
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("NetworkLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("NetworkLog.json")
 }
 
 func init() {
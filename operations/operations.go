@@ -0,0 +1,117 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Package consists of the Registry of the Long-Running Operations (Clones, Relocations, Certificate Rotations, etc...),
+// So the Operations, that are In Flight, can be Listed (Dashboards) and Awaited before the Restart
+
+// Types of the Tracked Operations
+const OperationClone = "Clone"
+const OperationRelocation = "Relocation"
+const OperationCertificateRotation = "CertificateRotation"
+const OperationSshKeyRotation = "SshKeyRotation"
+const OperationToolsUpgrade = "ToolsUpgrade"
+
+var (
+	ErrShutdownDeadline = errors.New("Operations are still Running after the Shutdown Deadline")
+)
+
+var (
+	DefaultRegistry = NewOperationRegistry()
+)
+
+type OperationInfo struct {
+	// Single In-Flight Operation
+	ID        uint64    `json:"ID" xml:"ID"`
+	Type      string    `json:"Type" xml:"Type"`
+	Target    string    `json:"Target" xml:"Target"` // Virtual Machine (or Host), the Operation is Performed on
+	StartedAt time.Time `json:"StartedAt" xml:"StartedAt"`
+}
+
+type OperationRegistry struct {
+	mutex      sync.Mutex
+	nextID     uint64
+	operations map[uint64]OperationInfo
+	changed    chan struct{} // Closed and Replaced every Time an Operation Completes
+}
+
+func NewOperationRegistry() *OperationRegistry {
+	return &OperationRegistry{
+		operations: map[uint64]OperationInfo{},
+		changed:    make(chan struct{}),
+	}
+}
+
+func (this *OperationRegistry) Track(Type string, Target string) func() {
+	// Registers the Operation as In Flight, the Returned Function should be Called, once the Operation is Completed
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.nextID++
+	ID := this.nextID
+	this.operations[ID] = OperationInfo{ID: ID, Type: Type, Target: Target, StartedAt: time.Now()}
+
+	var Once sync.Once
+	return func() {
+		Once.Do(func() {
+			this.mutex.Lock()
+			defer this.mutex.Unlock()
+			delete(this.operations, ID)
+			close(this.changed)
+			this.changed = make(chan struct{})
+		})
+	}
+}
+
+func (this *OperationRegistry) List() []OperationInfo {
+	// Returns In-Flight Operations, from the Oldest one
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	Operations := make([]OperationInfo, 0, len(this.operations))
+	for _, Operation := range this.operations {
+		Operations = append(Operations, Operation)
+	}
+	sort.Slice(Operations, func(I, J int) bool { return Operations[I].ID < Operations[J].ID })
+	return Operations
+}
+
+func (this *OperationRegistry) Shutdown(Context context.Context) ([]OperationInfo, error) {
+	// Waits until every In-Flight Operation is Completed, or the Context is Done,
+	// In the Latter case Operations, that are still Running, are Returned along with the `ErrShutdownDeadline`
+	for {
+		this.mutex.Lock()
+		Remaining, Changed := len(this.operations), this.changed
+		this.mutex.Unlock()
+
+		if Remaining == 0 {
+			return nil, nil
+		}
+		select {
+		case <-Context.Done():
+			return this.List(), ErrShutdownDeadline
+		case <-Changed:
+		}
+	}
+}
+
+func Track(Type string, Target string) func() {
+	// Registers the Operation within the Default Registry
+	return DefaultRegistry.Track(Type, Target)
+}
+
+func ListActiveOperations() []OperationInfo {
+	// Returns Operations, that are In Flight right now
+	return DefaultRegistry.List()
+}
+
+func Shutdown(Context context.Context) ([]OperationInfo, error) {
+	// Waits for the In-Flight Operations of the Default Registry
+	return DefaultRegistry.Shutdown(Context)
+}
@@ -0,0 +1,117 @@
+package options
+
+import (
+	"context"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Package consists of the Shared Options of the Operations (vSphere Calls, Database Queries, etc...)
+// So the Cancellation, Deadlines and Retries are being Configured the same way across the Project
+//
+// Every Operation has its own Default Timeout, that can be Overridden by the Project-Wide Policy
+// (See `SetDefaultOptions`), which in turn can be Overridden by the Options, Passed to the Call:
+//
+//	VirtualMachineManager.StartVirtualMachine(VirtualMachine, options.WithTimeout(time.Minute), options.WithRetryAttempts(2))
+
+const DefaultRetryInterval = time.Second // Delay before the First Retry, Doubled after every next Attempt
+
+var (
+	defaultOptions = []OperationOption{}
+)
+
+type OperationOptions struct {
+	// Options of the Single Operation
+	Context       context.Context // Parent Context of the Operation, Background by default
+	Timeout       time.Duration   // Deadline of the Whole Operation (Including Retries)
+	RetryAttempts int             // Number of Retries after the First Failed Attempt, 0 by default
+	FailIfBusy    bool            // Fail Immediately, if another Operation is Running on the same VM, Instead of Waiting
+}
+
+type OperationOption func(Options *OperationOptions)
+
+func WithContext(Context context.Context) OperationOption {
+	// Runs the Operation within the Context, so it gets Cancelled along with it
+	return func(Options *OperationOptions) {
+		Options.Context = Context
+	}
+}
+
+func WithTimeout(Timeout time.Duration) OperationOption {
+	// Overrides Default Timeout of the Operation
+	return func(Options *OperationOptions) {
+		Options.Timeout = Timeout
+	}
+}
+
+func WithRetryAttempts(RetryAttempts int) OperationOption {
+	// Retries the Failed Operation up to `RetryAttempts` Times
+	return func(Options *OperationOptions) {
+		Options.RetryAttempts = RetryAttempts
+	}
+}
+
+func WithFailIfBusy() OperationOption {
+	// Fails the Operation with `vm_lock.ErrVMBusy`, if another Operation is Running on the same Virtual Machine
+	return func(Options *OperationOptions) {
+		Options.FailIfBusy = true
+	}
+}
+
+func SetDefaultOptions(Options ...OperationOption) {
+	// Sets Project-Wide Policy, that is Applied to every Operation before the Options of the Call
+	// Should be Called once at the Startup, before any Operation is Running
+	defaultOptions = Options
+}
+
+func NewOperationOptions(DefaultTimeout time.Duration, Options ...OperationOption) *OperationOptions {
+	// Returns Options of the Operation, `DefaultTimeout` is the one the Operation is going to use,
+	// if neither the Project-Wide Policy, nor the Call Options Specify it
+	Operation := &OperationOptions{
+		Context: context.Background(),
+		Timeout: DefaultTimeout,
+	}
+	for _, Option := range append(append([]OperationOption{}, defaultOptions...), Options...) {
+		Option(Operation)
+	}
+	if Operation.Context == nil {
+		Operation.Context = context.Background()
+	}
+	if Operation.RetryAttempts < 0 {
+		Operation.RetryAttempts = 0
+	}
+	return Operation
+}
+
+func (this *OperationOptions) NewContext() (context.Context, context.CancelFunc) {
+	// Returns Context of the Operation with the Deadline Applied (If the Timeout is Positive)
+	if this.Timeout <= 0 {
+		return context.WithCancel(this.Context)
+	}
+	return context.WithTimeout(this.Context, this.Timeout)
+}
+
+func (this *OperationOptions) Retry(Context context.Context, Operation func() error) error {
+	// Runs the Operation, Retrying it on Failure with the Exponential Backoff,
+	// Stops as soon as the Context is Done and Returns the Last Error of the Operation
+	Interval := DefaultRetryInterval
+	OperationError := Operation()
+	for Attempt := 0; OperationError != nil && Attempt < this.RetryAttempts; Attempt++ {
+		select {
+		case <-Context.Done():
+			return OperationError
+		case <-time.After(Interval):
+		}
+		Interval *= 2
+		OperationError = Operation()
+	}
+	return OperationError
+}
+
+func (this *OperationOptions) LockVirtualMachine(Context context.Context, Reference types.ManagedObjectReference) (func(), error) {
+	// Acquires the Lock of the Virtual Machine, so no other Mutating Operation Runs on it at the same Time,
+	// Waits for the Lock, unless the `FailIfBusy` is Set
+	return vm_lock.Acquire(Context, Reference, !this.FailIfBusy)
+}
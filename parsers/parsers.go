@@ -7,12 +7,10 @@ import (
 	"errors"
 	"time"
 
-	"os"
-
 	"github.com/LovePelmeni/Infrastructure/host_system"
+	"github.com/LovePelmeni/Infrastructure/logging"
 	"github.com/LovePelmeni/Infrastructure/models"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"github.com/LovePelmeni/Infrastructure/network"
 	resource_config "github.com/LovePelmeni/Infrastructure/resource_config"
@@ -33,18 +31,8 @@ var (
 )
 
 func InitializeProductionLogger() {
-
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, Error := os.OpenFile("ConfigurationParsersLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if Error != nil {
-		panic(Error)
-	}
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("ConfigurationParsersLog.json")
 }
 
 func init() {
@@ -185,9 +173,9 @@ func (this *VirtualMachineCustomSpec) GetDiskStorageConfig(Client vim25.Client)
 		defer CancelFunc()
 
 		Gorm := models.Database.Model(&models.VirtualMachine{}).Where("id = ? AND owner_id = ?",
-			this.Metadata.VirtualMachineId, this.Metadata.VmOwnerId).Find(&Vm)
+			this.Metadata.VirtualMachineId, this.Metadata.VmOwnerId).First(&Vm)
 		if Gorm.Error != nil {
-			return nil, Gorm.Error
+			return nil, models.TranslateNotFound(Gorm.Error)
 		}
 
 		VirtualMachine, FindError := object.NewSearchIndex(&Client).FindByInventoryPath(TimeoutContext, Vm.ItemPath)
@@ -223,13 +211,17 @@ func (this *VirtualMachineCustomSpec) ApplySshConfig(Client vim25.Client, Virtua
 	// Returns SSH Support Configuration for the Virtual Machine, based on the Config
 	// That Customer Has Specified
 	switch {
-	case this.Ssh.Type == models.TypeByRootCredentials:
+	case this.Ssh.Type == models.TypeByRootCertificate:
 		newCertificateManager := ssh_config.NewVirtualMachineSshCertificateManager(Client)
 		PublicKey, SslCertificateError := newCertificateManager.GenerateSshKeys(VirtualMachine, this.Metadata.VirtualMachineId)
 		return PublicKey, SslCertificateError
 
-	case this.Ssh.Type == models.TypeByRootCertificate:
+	case this.Ssh.Type == models.TypeByRootCredentials:
+		// The Generated Root Password is Set in the Guest first, so the Returned Credentials are Accepted by it
 		newRootCredentialsManager := ssh_config.NewVirtualMachineSshRootCredentialsManager(Client)
+		if ProvisionError := newRootCredentialsManager.ProvisionRootPassword(VirtualMachine); ProvisionError != nil {
+			return nil, ProvisionError
+		}
 		RootCredentials, SslRootError := newRootCredentialsManager.GetSshRootCredentials(VirtualMachine)
 		return RootCredentials, SslRootError
 	default:
@@ -0,0 +1,202 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/operations"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrVirtualMachineNameTaken = errors.New("Virtual Machine with this Name already Exists in the Folder")
+)
+
+type CloneSpec struct {
+	// Specification of the Virtual Machine, Cloned from the Source one (Usually the Template)
+	Name         string `json:"Name" xml:"Name"`
+	ResourcePool string `json:"ResourcePool" xml:"ResourcePool"`               // Inventory Path of the Resource Pool
+	Datastore    string `json:"Datastore,omitempty" xml:"Datastore,omitempty"` // Inventory Path of the Datastore, Same as the Source, if not Specified
+	Folder       string `json:"Folder" xml:"Folder"`                           // Inventory Path of the VM Folder
+	OwnerID      int    `json:"OwnerID" xml:"OwnerID"`                         // ID of the Customer, the Database Record is Created for
+}
+
+type VirtualMachineProvisionManager struct {
+	Client vim25.Client
+}
+
+func NewVirtualMachineProvisionManager(Client vim25.Client) *VirtualMachineProvisionManager {
+	return &VirtualMachineProvisionManager{
+		Client: Client,
+	}
+}
+
+func (this *VirtualMachineProvisionManager) Clone(Context context.Context, Source *object.VirtualMachine, Spec CloneSpec) (*object.VirtualMachine, error) {
+	// Clones the Source Virtual Machine and Creates the Database Record of the Clone,
+	// The Record goes through the `Pending` and `Provisioning` States and ends up `Ready` or `Failed`
+	// `ErrVirtualMachineNameTaken` is Returned, if the Folder already has the Virtual Machine with the same Name
+	// If the Record can't be Completed, the Clone is Returned along with the Error, so the Caller can Clean it up
+
+	if len(Spec.Name) == 0 {
+		return nil, errors.New("Name of the Clone is Required")
+	}
+
+	Finder := find.NewFinder(&this.Client)
+	Folder, FolderError := Finder.Folder(Context, Spec.Folder)
+	if FolderError != nil {
+		return nil, FolderError
+	}
+	ResourcePool, PoolError := Finder.ResourcePool(Context, Spec.ResourcePool)
+	if PoolError != nil {
+		return nil, PoolError
+	}
+
+	// vSphere Reports the Duplicate only after the Task has Started, so the Name is Checked in Advance
+	Existing, SearchError := object.NewSearchIndex(&this.Client).FindChild(Context, Folder, Spec.Name)
+	if SearchError != nil {
+		return nil, SearchError
+	}
+	if Existing != nil {
+		return nil, fmt.Errorf("%w: `%s`", ErrVirtualMachineNameTaken, Spec.Name)
+	}
+
+	PoolReference := ResourcePool.Reference()
+	VirtualMachineCloneSpec := types.VirtualMachineCloneSpec{
+		Location: types.VirtualMachineRelocateSpec{Pool: &PoolReference},
+	}
+	if len(Spec.Datastore) != 0 {
+		Datastore, DatastoreError := Finder.Datastore(Context, Spec.Datastore)
+		if DatastoreError != nil {
+			return nil, DatastoreError
+		}
+		DatastoreReference := Datastore.Reference()
+		VirtualMachineCloneSpec.Location.Datastore = &DatastoreReference
+	}
+
+	// Every Attempt to Create the Virtual Machine is being Audited on behalf of the Actor of the Context
+	InventoryPath := path.Join(Folder.InventoryPath, Spec.Name)
+	Audited := func(OperationError error) error {
+		models.AuditOperation(Context, models.AuditActionVirtualMachineCreated, InventoryPath, OperationError)
+		return OperationError
+	}
+
+	defer operations.Track(operations.OperationClone, Spec.Name)()
+
+	// Record is Created in the `Pending` State before the Clone, so the Clone, that got Stuck (e.g the Process has Crashed
+	// in the Middle), is Reconciled by the `ReconcileStuckProvisioning`, Creation of the Record is being Audited by the
+	// `models.VirtualMachine.CreateContext` itself
+	Record, RecordError := this.createVirtualMachineRecord(Context, Spec, InventoryPath)
+	if RecordError != nil {
+		Logger.Error("Failed to Create Database Record of the Clone", zap.String("Name", Spec.Name), zap.Error(RecordError))
+		return nil, RecordError
+	}
+	Failed := func(OperationError error) error {
+		this.setRecordState(Record, models.StatusFailed)
+		return Audited(OperationError)
+	}
+
+	// Source is Locked for the Clone, so it is not Reconfigured or Cloned by another Operation in the Middle
+	Release, LockError := vm_lock.Acquire(Context, Source.Reference(), true)
+	if LockError != nil {
+		return nil, Failed(LockError)
+	}
+	defer Release()
+
+	this.setRecordState(Record, models.StatusProvisioning)
+	CloneTask, CloneError := Source.Clone(Context, Folder, Spec.Name, VirtualMachineCloneSpec)
+	if CloneError != nil {
+		Logger.Error("Failed to Clone Virtual Machine", zap.String("Name", Spec.Name), zap.Error(CloneError))
+		return nil, Failed(CloneError)
+	}
+	TaskInfo, WaitError := CloneTask.WaitForResult(Context, nil)
+	if WaitError != nil {
+		Logger.Error("Failed to Clone Virtual Machine", zap.String("Name", Spec.Name), zap.Error(WaitError))
+		if isDuplicateNameFault(WaitError) {
+			return nil, Failed(fmt.Errorf("%w: `%s`", ErrVirtualMachineNameTaken, Spec.Name))
+		}
+		return nil, Failed(WaitError)
+	}
+	Release()
+
+	VirtualMachine := object.NewVirtualMachine(&this.Client, TaskInfo.Result.(types.ManagedObjectReference))
+	VirtualMachine.InventoryPath = InventoryPath
+
+	// Record, that can't be Completed, is left `Provisioning`, so it is Transitioned by the `ReconcileStuckProvisioning`
+	if CompleteError := this.completeVirtualMachineRecord(Context, VirtualMachine, Record); CompleteError != nil {
+		Logger.Error("Failed to Complete Database Record of the Clone", zap.String("Name", Spec.Name), zap.Error(CompleteError))
+		return VirtualMachine, CompleteError
+	}
+	Logger.Debug("Virtual Machine has been Cloned", zap.String("Source", Source.Reference().Value),
+		zap.String("Virtual Machine Name", Spec.Name))
+	return VirtualMachine, nil
+}
+
+func (this *VirtualMachineProvisionManager) createVirtualMachineRecord(Context context.Context, Spec CloneSpec, InventoryPath string) (*models.VirtualMachine, error) {
+	// Creates the Database Record of the Virtual Machine, that is going to be Cloned, in the `Pending` State
+	Record := &models.VirtualMachine{
+		State:              models.StatusPending,
+		OwnerId:            Spec.OwnerID,
+		VirtualMachineName: Spec.Name,
+		ItemPath:           InventoryPath,
+	}
+	if _, CreateError := Record.CreateContext(Context); CreateError != nil {
+		return nil, CreateError
+	}
+	return Record, nil
+}
+
+func (this *VirtualMachineProvisionManager) completeVirtualMachineRecord(Context context.Context, VirtualMachine *object.VirtualMachine, Record *models.VirtualMachine) error {
+	// Transitions the Record of the Cloned Virtual Machine to `Ready`, IP Address is Filled only if the Guest has Reported it already
+
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(Context, VirtualMachine.Reference(),
+		[]string{"config.uuid", "guest.ipAddress"}, &MoVirtualMachine); RetrieveError != nil {
+		return RetrieveError
+	}
+
+	var UUID, IPAddress string
+	if MoVirtualMachine.Config != nil {
+		UUID = MoVirtualMachine.Config.Uuid
+	}
+	if MoVirtualMachine.Guest != nil {
+		IPAddress = MoVirtualMachine.Guest.IpAddress
+	}
+	if CompleteError := models.CompleteProvisioning(Record.ID, UUID, IPAddress); CompleteError != nil {
+		return CompleteError
+	}
+	models.RecordVMEvent(Record.ID, models.EventCreated, "Virtual Machine has been Cloned")
+	return nil
+}
+
+func (this *VirtualMachineProvisionManager) setRecordState(Record *models.VirtualMachine, State string) {
+	// Transitions the Record of the Clone to the State, Failure is only Logged, the Watchdog Reconciles such Records
+	if UpdateError := models.UpdateVirtualMachineState(Record.ID, State); UpdateError != nil {
+		Logger.Error("Failed to Update State of the Clone", zap.Int("Virtual Machine ID", Record.ID),
+			zap.String("State", State), zap.Error(UpdateError))
+	}
+}
+
+func isDuplicateNameFault(Error error) bool {
+	// Returns True if the Clone Task has Failed, because the Name (or the Files of the VM) is Already Taken
+	var TaskError task.Error
+	if !errors.As(Error, &TaskError) {
+		return false
+	}
+	switch TaskError.Fault().(type) {
+	case *types.DuplicateName, *types.FileAlreadyExists:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,408 @@
+package provision
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/network"
+	"github.com/LovePelmeni/Infrastructure/operations"
+	"github.com/LovePelmeni/Infrastructure/reconfigure"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"go.uber.org/zap"
+)
+
+var (
+	Logger *zap.Logger
+)
+
+var (
+	// Path to the JSON File, that contains Blessed Templates the VM's are being Cloned From
+	PROVISION_TEMPLATES_CONFIG = os.Getenv("PROVISION_TEMPLATES_CONFIG")
+)
+
+var (
+	// Default Template Registry, Loaded from the `PROVISION_TEMPLATES_CONFIG` File
+	Registry *TemplateRegistry
+)
+
+func InitializeProductionLogger() {
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("ProvisionLog.json")
+}
+
+func init() {
+	InitializeProductionLogger()
+
+	Registry = NewTemplateRegistry()
+	if len(PROVISION_TEMPLATES_CONFIG) != 0 {
+		LoadedRegistry, LoadError := LoadTemplateRegistry(PROVISION_TEMPLATES_CONFIG)
+		if LoadError != nil {
+			Logger.Error("Failed to Load Provisioning Templates", zap.Error(LoadError))
+			return
+		}
+		Registry = LoadedRegistry
+	}
+}
+
+// Package consists of API, that Provisions new Virtual Machine Servers by Cloning them
+// From the Blessed Templates with the Standardized Specifications
+
+type ProvisionSpec struct {
+	// Specification of the Virtual Machine, that is going to be Provisioned
+	// Zero Values are treated as "Not Specified"
+
+	Name              string `json:"Name,omitempty" xml:"Name"`                           // Name of the New Virtual Machine
+	CpuNum            int32  `json:"CpuNum,omitempty" xml:"CpuNum"`                       // Number of the Virtual CPU's
+	MemoryInMegabytes int64  `json:"MemoryInMegabytes,omitempty" xml:"MemoryInMegabytes"` // Memory in Megabytes
+	DiskCapacityInKB  int64  `json:"DiskCapacityInKB,omitempty" xml:"DiskCapacityInKB"`   // Capacity of the Primary Disk
+	Network           string `json:"Network,omitempty" xml:"Network"`                     // Inventory Path of the Network
+	Folder            string `json:"Folder,omitempty" xml:"Folder"`                       // Inventory Path of the Folder
+	ResourcePool      string `json:"ResourcePool,omitempty" xml:"ResourcePool"`           // Inventory Path of the Resource Pool
+	Datastore         string `json:"Datastore,omitempty" xml:"Datastore"`                 // Inventory Path of the Datastore
+
+	// Network Adapters of the Virtual Machine in the Order they are Attached (Used Instead of the Single `Network`)
+	NetworkAdapters []network.NetworkAdapterSpec `json:"NetworkAdapters,omitempty" xml:"NetworkAdapters"`
+}
+
+func (this ProvisionSpec) Merge(Overrides ProvisionSpec) ProvisionSpec {
+	// Returns new Specification, where every Non-Zero Field of the Overrides
+	// Is being put over the Current Specification Values
+
+	Merged := this
+	if len(Overrides.Name) != 0 {
+		Merged.Name = Overrides.Name
+	}
+	if Overrides.CpuNum != 0 {
+		Merged.CpuNum = Overrides.CpuNum
+	}
+	if Overrides.MemoryInMegabytes != 0 {
+		Merged.MemoryInMegabytes = Overrides.MemoryInMegabytes
+	}
+	if Overrides.DiskCapacityInKB != 0 {
+		Merged.DiskCapacityInKB = Overrides.DiskCapacityInKB
+	}
+	if len(Overrides.Network) != 0 {
+		Merged.Network = Overrides.Network
+	}
+	if len(Overrides.Folder) != 0 {
+		Merged.Folder = Overrides.Folder
+	}
+	if len(Overrides.ResourcePool) != 0 {
+		Merged.ResourcePool = Overrides.ResourcePool
+	}
+	if len(Overrides.Datastore) != 0 {
+		Merged.Datastore = Overrides.Datastore
+	}
+	if len(Overrides.NetworkAdapters) != 0 {
+		// Network Adapters Replace the Single Network, Inherited from the Current Specification
+		Merged.NetworkAdapters = Overrides.NetworkAdapters
+		Merged.Network = Overrides.Network
+	}
+	return Merged
+}
+
+func NewProvisionSpecFromCustomerDefaults(Defaults models.CustomerDefaults) ProvisionSpec {
+	// Returns Specification, that consists of the Default Provisioning Settings of the Customer
+	return ProvisionSpec{
+		CpuNum:            Defaults.CpuNum,
+		MemoryInMegabytes: Defaults.MemoryInMegabytes,
+		DiskCapacityInKB:  Defaults.DiskCapacityInKB,
+		Network:           Defaults.Network,
+		Folder:            Defaults.Folder,
+	}
+}
+
+func (this ProvisionSpec) Validate() error {
+	// Checks that the Specification has Everything, that is Required to Clone the VM
+	switch {
+	case len(this.Name) == 0:
+		return errors.New("Virtual Machine Name is Required")
+	case this.CpuNum < 0 || this.MemoryInMegabytes < 0 || this.DiskCapacityInKB < 0:
+		return errors.New("Resources can't be Negative")
+	case len(this.Folder) == 0:
+		return errors.New("Folder is Required")
+	case len(this.ResourcePool) == 0:
+		return errors.New("Resource Pool is Required")
+	case len(this.Network) != 0 && len(this.NetworkAdapters) != 0:
+		return errors.New("Either Network or Network Adapters should be Specified, not Both")
+	default:
+		return network.ValidateNetworkAdapters(this.NetworkAdapters)
+	}
+}
+
+type VirtualMachineTemplate struct {
+	// Blessed Template, New Virtual Machines are being Cloned From
+	ItemPath string        `json:"ItemPath" xml:"ItemPath"` // Inventory Path of the Template VM
+	Defaults ProvisionSpec `json:"Defaults" xml:"Defaults"` // Standard Specification of the Template
+}
+
+type TemplateRegistry struct {
+	// Registry of the Named Templates
+	Templates map[string]VirtualMachineTemplate `json:"Templates" xml:"Templates"`
+}
+
+func NewTemplateRegistry() *TemplateRegistry {
+	return &TemplateRegistry{
+		Templates: map[string]VirtualMachineTemplate{},
+	}
+}
+
+func LoadTemplateRegistry(ConfigPath string) (*TemplateRegistry, error) {
+	// Loads Template Registry from the JSON Config File
+	// Config Format: {"Templates": {"ubuntu-small": {"ItemPath": "...", "Defaults": {...}}}}
+
+	Content, ReadError := os.ReadFile(ConfigPath)
+	if ReadError != nil {
+		return nil, ReadError
+	}
+	Loaded := NewTemplateRegistry()
+	if DecodeError := json.Unmarshal(Content, Loaded); DecodeError != nil {
+		return nil, DecodeError
+	}
+	return Loaded, nil
+}
+
+func (this *TemplateRegistry) Register(TemplateName string, Template VirtualMachineTemplate) {
+	// Adds (or Replaces) the Named Template
+	this.Templates[TemplateName] = Template
+}
+
+func (this *TemplateRegistry) GetTemplate(TemplateName string) (*VirtualMachineTemplate, error) {
+	// Returns Template by its name, if it has been Registered
+	Template, Exists := this.Templates[TemplateName]
+	if !Exists {
+		return nil, fmt.Errorf("Template `%s` does not Exist", TemplateName)
+	}
+	return &Template, nil
+}
+
+func ProvisionFromTemplate(Client vim25.Client, TemplateName string, Overrides ProvisionSpec) (*object.VirtualMachine, error) {
+	// Clones new Virtual Machine from the Registered Template,
+	// Overrides are being Merged over the Template Defaults
+
+	Template, TemplateError := Registry.GetTemplate(TemplateName)
+	if TemplateError != nil {
+		return nil, TemplateError
+	}
+
+	Spec := Template.Defaults.Merge(Overrides)
+	if ValidationError := Spec.Validate(); ValidationError != nil {
+		return nil, ValidationError
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*5)
+	defer CancelFunc()
+
+	Finder := find.NewFinder(&Client)
+	Source, FindError := Finder.VirtualMachine(TimeoutContext, Template.ItemPath)
+	if FindError != nil {
+		Logger.Error("Failed to Find Template", zap.String("Template", TemplateName), zap.Error(FindError))
+		return nil, fmt.Errorf("Template `%s` Virtual Machine does not Exist", TemplateName)
+	}
+
+	Folder, FolderError := Finder.Folder(TimeoutContext, Spec.Folder)
+	if FolderError != nil {
+		return nil, FolderError
+	}
+	ResourcePool, PoolError := Finder.ResourcePool(TimeoutContext, Spec.ResourcePool)
+	if PoolError != nil {
+		return nil, PoolError
+	}
+
+	PoolReference := ResourcePool.Reference()
+	CloneSpec := types.VirtualMachineCloneSpec{
+		Location: types.VirtualMachineRelocateSpec{Pool: &PoolReference},
+		Config: &types.VirtualMachineConfigSpec{
+			NumCPUs:  Spec.CpuNum,
+			MemoryMB: Spec.MemoryInMegabytes,
+		},
+	}
+
+	if len(Spec.Datastore) != 0 {
+		Datastore, DatastoreError := Finder.Datastore(TimeoutContext, Spec.Datastore)
+		if DatastoreError != nil {
+			return nil, DatastoreError
+		}
+		DatastoreReference := Datastore.Reference()
+		CloneSpec.Location.Datastore = &DatastoreReference
+	}
+
+	// Applying Disk and Network Specification to the Devices, Inherited from the Template
+	DeviceChanges, DeviceError := getDeviceChanges(TimeoutContext, Finder, Source, Spec)
+	if DeviceError != nil {
+		return nil, DeviceError
+	}
+	CloneSpec.Config.DeviceChange = DeviceChanges
+
+	defer operations.Track(operations.OperationClone, Spec.Name)()
+
+	// Template is Locked for the Clone, so it is not Reconfigured or Cloned by another Operation in the Middle
+	Release, LockError := vm_lock.Acquire(TimeoutContext, Source.Reference(), true)
+	if LockError != nil {
+		return nil, LockError
+	}
+	defer Release()
+
+	CloneTask, CloneError := Source.Clone(TimeoutContext, Folder, Spec.Name, CloneSpec)
+	if CloneError != nil {
+		Logger.Error("Failed to Clone Virtual Machine", zap.String("Template", TemplateName), zap.Error(CloneError))
+		return nil, CloneError
+	}
+
+	TaskInfo, WaitError := CloneTask.WaitForResult(TimeoutContext, nil)
+	if WaitError != nil {
+		Logger.Error("Failed to Clone Virtual Machine", zap.String("Template", TemplateName), zap.Error(WaitError))
+		return nil, WaitError
+	}
+	Release()
+
+	VirtualMachine := object.NewVirtualMachine(&Client, TaskInfo.Result.(types.ManagedObjectReference))
+	if len(Spec.NetworkAdapters) != 0 {
+		// Virtual Machine is Returned along with the Error, so the Caller can Clean it up
+		NetworkManager := network.NewVirtualMachinePrivateNetworkManager(Client)
+		if AttachError := NetworkManager.AttachNetworkAdapters(VirtualMachine, Spec.NetworkAdapters); AttachError != nil {
+			Logger.Error("Failed to Attach Network Adapters", zap.String("Template", TemplateName), zap.Error(AttachError))
+			return VirtualMachine, AttachError
+		}
+	}
+
+	Logger.Debug("Virtual Machine has been Provisioned from Template",
+		zap.String("Template", TemplateName), zap.String("Virtual Machine Name", Spec.Name))
+	return VirtualMachine, nil
+}
+
+func ProvisionFromTemplateForCustomer(Client vim25.Client, CustomerID int, TemplateName string, Overrides ProvisionSpec, IdempotencyKey string) (*object.VirtualMachine, error) {
+	// Clones new Virtual Machine from the Registered Template for the Customer,
+	// Specification is being Merged in the Order: Template Defaults < Customer Defaults < Overrides
+	// If the Idempotency Key is Specified, Repeated Request with the same Key Returns the Original Result,
+	// instead of Provisioning one more Virtual Machine
+
+	if len(IdempotencyKey) == 0 {
+		return provisionFromTemplateForCustomer(Client, CustomerID, TemplateName, Overrides)
+	}
+
+	Request, Claimed, ClaimError := models.ClaimProvisioningRequest(IdempotencyKey, CustomerID)
+	if ClaimError != nil {
+		Logger.Error("Failed to Claim Provisioning Request", zap.Int("Customer ID", CustomerID),
+			zap.String("Idempotency Key", IdempotencyKey), zap.Error(ClaimError))
+		return nil, ClaimError
+	}
+	if !Claimed {
+		return previousProvisioningResult(Client, Request)
+	}
+
+	VirtualMachine, ProvisionError := provisionFromTemplateForCustomer(Client, CustomerID, TemplateName, Overrides)
+	if VirtualMachine == nil {
+		// Nothing has been Provisioned, so the Client is Allowed to Retry with the same Key
+		if ReleaseError := Request.Release(); ReleaseError != nil {
+			Logger.Error("Failed to Release Provisioning Request", zap.String("Idempotency Key", IdempotencyKey), zap.Error(ReleaseError))
+		}
+		return nil, ProvisionError
+	}
+	if CompleteError := Request.Complete(VirtualMachine.Reference().Value, ProvisionError); CompleteError != nil {
+		Logger.Error("Failed to Record Provisioning Result", zap.String("Idempotency Key", IdempotencyKey), zap.Error(CompleteError))
+	}
+	return VirtualMachine, ProvisionError
+}
+
+func previousProvisioningResult(Client vim25.Client, Request *models.ProvisioningRequest) (*object.VirtualMachine, error) {
+	// Returns the Result of the Completed Provisioning Request
+	var VirtualMachine *object.VirtualMachine
+	if len(Request.VirtualMachineRef) != 0 {
+		VirtualMachine = object.NewVirtualMachine(&Client, types.ManagedObjectReference{
+			Type: "VirtualMachine", Value: Request.VirtualMachineRef})
+	}
+	if len(Request.Error) != 0 {
+		return VirtualMachine, errors.New(Request.Error)
+	}
+	return VirtualMachine, nil
+}
+
+func provisionFromTemplateForCustomer(Client vim25.Client, CustomerID int, TemplateName string, Overrides ProvisionSpec) (*object.VirtualMachine, error) {
+	// Returns the Virtual Machine, Provisioned with the Customer Defaults Applied
+
+	Defaults, DefaultsError := models.GetCustomerDefaults(CustomerID)
+	switch {
+	case errors.Is(DefaultsError, models.ErrNotFound):
+	case DefaultsError != nil:
+		Logger.Error("Failed to Get Customer Defaults", zap.Int("Customer ID", CustomerID), zap.Error(DefaultsError))
+		return nil, DefaultsError
+	default:
+		Overrides = NewProvisionSpecFromCustomerDefaults(*Defaults).Merge(Overrides)
+	}
+
+	VirtualMachine, ProvisionError := ProvisionFromTemplate(Client, TemplateName, Overrides)
+	if ProvisionError != nil {
+		return VirtualMachine, ProvisionError
+	}
+	// Exposing the Owner to the Operators, who use vCenter directly, Failure is not Critical
+	if OwnerError := reconfigure.NewVirtualMachineReconfigureManager(Client).SetOwnerCustomAttribute(
+		VirtualMachine, strconv.Itoa(CustomerID)); OwnerError != nil {
+		Logger.Error("Failed to Set Owner Custom Attribute", zap.Int("Customer ID", CustomerID), zap.Error(OwnerError))
+	}
+	return VirtualMachine, nil
+}
+
+func getDeviceChanges(Context context.Context, Finder *find.Finder, Source *object.VirtualMachine, Spec ProvisionSpec) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	// Returns Device Changes, that Resizes the Primary Disk and Attaches the Primary NIC to the Specified Network
+
+	var DeviceChanges []types.BaseVirtualDeviceConfigSpec
+	if Spec.DiskCapacityInKB == 0 && len(Spec.Network) == 0 {
+		return DeviceChanges, nil
+	}
+
+	Devices, DeviceError := Source.Device(Context)
+	if DeviceError != nil {
+		return nil, DeviceError
+	}
+
+	if Spec.DiskCapacityInKB != 0 {
+		Disks := Devices.SelectByType((*types.VirtualDisk)(nil))
+		if len(Disks) == 0 {
+			return nil, errors.New("Template does not have any Disk to Resize")
+		}
+		Disk := Disks[0].(*types.VirtualDisk)
+		if Spec.DiskCapacityInKB < Disk.CapacityInKB {
+			return nil, errors.New("Disk Capacity can't be Smaller, than the Template's one")
+		}
+		Disk.CapacityInKB = Spec.DiskCapacityInKB
+		DeviceChanges = append(DeviceChanges, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    Disk,
+		})
+	}
+
+	if len(Spec.Network) != 0 {
+		Network, NetworkError := Finder.Network(Context, Spec.Network)
+		if NetworkError != nil {
+			return nil, NetworkError
+		}
+		Backing, BackingError := Network.EthernetCardBackingInfo(Context)
+		if BackingError != nil {
+			return nil, BackingError
+		}
+		Cards := Devices.SelectByType((*types.VirtualEthernetCard)(nil))
+		if len(Cards) == 0 {
+			return nil, errors.New("Template does not have any Network Adapter")
+		}
+		Card := Cards[0].GetVirtualDevice()
+		Card.Backing = Backing
+		DeviceChanges = append(DeviceChanges, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    Cards[0],
+		})
+	}
+	return DeviceChanges, nil
+}
@@ -0,0 +1,95 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+var (
+	// Virtual Machines, which are being Provisioned longer than that, are considered Stuck
+	StuckProvisioningThreshold = time.Minute * 30
+)
+
+func ReconcileStuckProvisioning(Client vim25.Client) error {
+	// Checks the Actual vSphere State of the Stuck Virtual Machines and Transitions them
+	// To `Ready`, if the Virtual Machine Exists and has no Running Tasks, or to `Failed`, if it does not Exist
+	// Virtual Machines with the Tasks, that are still Running, are being left as they are
+
+	StuckVirtualMachines, FindError := models.FindStuckProvisioning(StuckProvisioningThreshold)
+	if FindError != nil {
+		Logger.Error("Failed to Find Stuck Virtual Machines", zap.Error(FindError))
+		return FindError
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*5)
+	defer CancelFunc()
+
+	Finder := find.NewFinder(&Client)
+	Collector := property.DefaultCollector(&Client)
+
+	for _, VirtualMachine := range StuckVirtualMachines {
+		State := models.StatusReady
+
+		Object, ObjectError := Finder.VirtualMachine(TimeoutContext, VirtualMachine.ItemPath)
+		var NotFound *find.NotFoundError
+		switch {
+		case errors.As(ObjectError, &NotFound):
+			State = models.StatusFailed
+		case ObjectError != nil:
+			Logger.Error("Failed to Find Stuck Virtual Machine",
+				zap.Int("Virtual Machine ID", VirtualMachine.ID), zap.Error(ObjectError))
+			continue
+		default:
+			InProgress, TaskError := hasTasksInProgress(TimeoutContext, Collector, Object.Reference())
+			if TaskError != nil {
+				Logger.Error("Failed to Check Tasks of the Stuck Virtual Machine",
+					zap.Int("Virtual Machine ID", VirtualMachine.ID), zap.Error(TaskError))
+				continue
+			}
+			if InProgress {
+				continue
+			}
+		}
+
+		if UpdateError := models.UpdateVirtualMachineState(VirtualMachine.ID, State); UpdateError != nil {
+			Logger.Error("Failed to Update State of the Stuck Virtual Machine",
+				zap.Int("Virtual Machine ID", VirtualMachine.ID), zap.Error(UpdateError))
+			continue
+		}
+		Logger.Info("Stuck Virtual Machine has been Reconciled",
+			zap.Int("Virtual Machine ID", VirtualMachine.ID), zap.String("State", State))
+	}
+	return nil
+}
+
+func hasTasksInProgress(Context context.Context, Collector *property.Collector, Reference types.ManagedObjectReference) (bool, error) {
+	// Returns True if the Virtual Machine has any Queued or Running Task
+
+	var MoVirtualMachine mo.VirtualMachine
+	if RetrieveError := Collector.RetrieveOne(Context, Reference, []string{"recentTask"}, &MoVirtualMachine); RetrieveError != nil {
+		return false, RetrieveError
+	}
+	if len(MoVirtualMachine.RecentTask) == 0 {
+		return false, nil
+	}
+
+	var Tasks []mo.Task
+	if RetrieveError := Collector.Retrieve(Context, MoVirtualMachine.RecentTask, []string{"info.state"}, &Tasks); RetrieveError != nil {
+		return false, RetrieveError
+	}
+	for _, Task := range Tasks {
+		if Task.Info.State == types.TaskInfoStateQueued || Task.Info.State == types.TaskInfoStateRunning {
+			return true, nil
+		}
+	}
+	return false, nil
+}
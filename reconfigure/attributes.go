@@ -0,0 +1,83 @@
+package reconfigure
+
+import (
+	"context"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+// Custom Attributes of the Virtual Machines, so the Operators, who use vCenter directly,
+// Can see the Ownership Info of the Database in the vSphere UI
+
+const OwnerAttributeName = "Owner"
+
+func (this *VirtualMachineReconfigureManager) getOrCreateCustomField(Context context.Context, Manager *object.CustomFieldsManager, Name string) (int32, error) {
+	// Returns Key of the Virtual Machine Custom Field, Creates the Field, if it does not Exist yet
+	Key, FindError := Manager.FindKey(Context, Name)
+	if FindError == nil {
+		return Key, nil
+	}
+	if FindError != object.ErrKeyNameNotFound {
+		return 0, FindError
+	}
+	Field, AddError := Manager.Add(Context, Name, "VirtualMachine", nil, nil)
+	if AddError != nil {
+		Logger.Error("Failed to Create Custom Field", zap.String("Name", Name), zap.Error(AddError))
+		return 0, AddError
+	}
+	return Field.Key, nil
+}
+
+func (this *VirtualMachineReconfigureManager) SetOwnerCustomAttribute(VirtualMachine *object.VirtualMachine, OwnerID string) error {
+	// Sets `Owner` Custom Attribute of the Virtual Machine to the ID of the Customer, who Owns it
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer CancelFunc()
+
+	Manager, ManagerError := object.GetCustomFieldsManager(&this.Client)
+	if ManagerError != nil {
+		return ManagerError
+	}
+	Key, FieldError := this.getOrCreateCustomField(TimeoutContext, Manager, OwnerAttributeName)
+	if FieldError != nil {
+		return FieldError
+	}
+	if SetError := Manager.Set(TimeoutContext, VirtualMachine.Reference(), Key, OwnerID); SetError != nil {
+		Logger.Error("Failed to Set Owner Custom Attribute", zap.String("Owner", OwnerID), zap.Error(SetError))
+		return SetError
+	}
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) GetOwnerCustomAttribute(VirtualMachine *object.VirtualMachine) (string, error) {
+	// Returns `Owner` Custom Attribute of the Virtual Machine, Empty String if it has not been Set
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer CancelFunc()
+
+	Manager, ManagerError := object.GetCustomFieldsManager(&this.Client)
+	if ManagerError != nil {
+		return "", ManagerError
+	}
+	Key, FindError := Manager.FindKey(TimeoutContext, OwnerAttributeName)
+	if FindError == object.ErrKeyNameNotFound {
+		return "", nil
+	}
+	if FindError != nil {
+		return "", FindError
+	}
+
+	MoVirtualMachine, RetrieveError := this.retrieveProperties(VirtualMachine, []string{"customValue"})
+	if RetrieveError != nil {
+		return "", RetrieveError
+	}
+	for _, Value := range MoVirtualMachine.CustomValue {
+		if StringValue, IsString := Value.(*types.CustomFieldStringValue); IsString && StringValue.Key == Key {
+			return StringValue.Value, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,150 @@
+package reconfigure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	// Extra Config Keys, which are Unique for every Virtual Machine, so they are Ignored by Default
+	instanceSpecificExtraConfig = []string{"uuid", "generatedAddress", "vc.uuid", "sched.swap.derivedName"}
+)
+
+type CompareOptions struct {
+	IncludeInstanceSpecific bool // Compare UUIDs, MAC Addresses and Disk Files as well
+}
+
+type FieldDifference struct {
+	Field string `json:"Field" xml:"Field"`
+	A     string `json:"A" xml:"A"` // Value of the First Virtual Machine, Empty if the Field is Missing
+	B     string `json:"B" xml:"B"` // Value of the Second Virtual Machine, Empty if the Field is Missing
+}
+
+type ConfigDiff struct {
+	Differences []FieldDifference `json:"Differences" xml:"Differences"`
+}
+
+func (this *ConfigDiff) Equal() bool {
+	return len(this.Differences) == 0
+}
+
+func CompareVMConfigs(A *object.VirtualMachine, B *object.VirtualMachine, Options ...CompareOptions) (*ConfigDiff, error) {
+	// Returns Differences between the Configurations of the Virtual Machines:
+	// CPU, Memory, Disks, Network Adapters, Firmware and Extra Config
+	// Instance Specific Fields (UUIDs, MAC Addresses, Disk Files) are Ignored, unless the Option says otherwise
+
+	var Option CompareOptions
+	if len(Options) != 0 {
+		Option = Options[0]
+	}
+
+	ConfigA, AError := flattenConfig(A, Option)
+	if AError != nil {
+		return nil, AError
+	}
+	ConfigB, BError := flattenConfig(B, Option)
+	if BError != nil {
+		return nil, BError
+	}
+
+	Fields := map[string]bool{}
+	for Field := range ConfigA {
+		Fields[Field] = true
+	}
+	for Field := range ConfigB {
+		Fields[Field] = true
+	}
+	SortedFields := make([]string, 0, len(Fields))
+	for Field := range Fields {
+		SortedFields = append(SortedFields, Field)
+	}
+	sort.Strings(SortedFields)
+
+	Diff := &ConfigDiff{Differences: []FieldDifference{}}
+	for _, Field := range SortedFields {
+		if ConfigA[Field] != ConfigB[Field] {
+			Diff.Differences = append(Diff.Differences, FieldDifference{Field: Field, A: ConfigA[Field], B: ConfigB[Field]})
+		}
+	}
+	return Diff, nil
+}
+
+func flattenConfig(VirtualMachine *object.VirtualMachine, Option CompareOptions) (map[string]string, error) {
+	// Returns Configuration of the Virtual Machine as a Flat Map of the Comparable Fields
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer CancelFunc()
+
+	var MoVirtualMachine mo.VirtualMachine
+	if RetrieveError := VirtualMachine.Properties(TimeoutContext,
+		VirtualMachine.Reference(), []string{"config"}, &MoVirtualMachine); RetrieveError != nil {
+		return nil, RetrieveError
+	}
+	if MoVirtualMachine.Config == nil {
+		return nil, fmt.Errorf("Virtual Machine `%s` does not have Configuration", VirtualMachine.Reference().Value)
+	}
+	Config := MoVirtualMachine.Config
+
+	Fields := map[string]string{
+		"cpu.num":            fmt.Sprint(Config.Hardware.NumCPU),
+		"cpu.coresPerSocket": fmt.Sprint(Config.Hardware.NumCoresPerSocket),
+		"memory.mb":          fmt.Sprint(Config.Hardware.MemoryMB),
+		"firmware":           Config.Firmware,
+		"guestId":            Config.GuestId,
+		"version":            Config.Version,
+	}
+	if Option.IncludeInstanceSpecific {
+		Fields["uuid"] = Config.Uuid
+		Fields["instanceUuid"] = Config.InstanceUuid
+	}
+
+	for _, Device := range object.VirtualDeviceList(Config.Hardware.Device) {
+		Label := fmt.Sprint(Device.GetVirtualDevice().Key)
+		if Info := Device.GetVirtualDevice().DeviceInfo; Info != nil {
+			Label = Info.GetDescription().Label
+		}
+
+		switch Device := Device.(type) {
+		case *types.VirtualDisk:
+			Fields[fmt.Sprintf("disk[%s].capacityInKB", Label)] = fmt.Sprint(Device.CapacityInKB)
+			if Backing, IsFile := Device.Backing.(types.BaseVirtualDeviceFileBackingInfo); IsFile && Option.IncludeInstanceSpecific {
+				Fields[fmt.Sprintf("disk[%s].file", Label)] = Backing.GetVirtualDeviceFileBackingInfo().FileName
+			}
+		case types.BaseVirtualEthernetCard:
+			Card := Device.GetVirtualEthernetCard()
+			Fields[fmt.Sprintf("nic[%s].type", Label)] = fmt.Sprintf("%T", Device)
+			if Backing, IsNetwork := Card.Backing.(*types.VirtualEthernetCardNetworkBackingInfo); IsNetwork {
+				Fields[fmt.Sprintf("nic[%s].network", Label)] = Backing.DeviceName
+			}
+			if Option.IncludeInstanceSpecific {
+				Fields[fmt.Sprintf("nic[%s].mac", Label)] = Card.MacAddress
+			}
+		}
+	}
+
+	for _, ExtraOption := range Config.ExtraConfig {
+		Value := ExtraOption.GetOptionValue()
+		if !Option.IncludeInstanceSpecific && isInstanceSpecific(Value.Key) {
+			continue
+		}
+		Fields[fmt.Sprintf("extraConfig[%s]", Value.Key)] = fmt.Sprint(Value.Value)
+	}
+	return Fields, nil
+}
+
+func isInstanceSpecific(Key string) bool {
+	// Returns True if the Extra Config Key is Unique for every Virtual Machine
+	for _, Part := range instanceSpecificExtraConfig {
+		if strings.Contains(Key, Part) {
+			return true
+		}
+	}
+	return false
+}
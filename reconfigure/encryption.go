@@ -0,0 +1,146 @@
+package reconfigure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ID of the Key Provider (KMS Cluster), that is Used, if the Caller does not Specify one
+	VM_ENCRYPTION_KEY_PROVIDER = os.Getenv("VM_ENCRYPTION_KEY_PROVIDER")
+)
+
+var (
+	ErrKeyProviderNotConfigured = errors.New("No Key Provider (KMS) is Configured for the Virtual Machine Encryption")
+	ErrKeyProviderUnavailable   = errors.New("Key Provider is not Available")
+)
+
+func (this *VirtualMachineReconfigureManager) EnableVMEncryption(VirtualMachine *object.VirtualMachine, ProviderID string) error {
+	// Encrypts the Virtual Machine (Home Files and Disks) with the new Key of the Key Provider,
+	// If the Provider is not Specified, the `VM_ENCRYPTION_KEY_PROVIDER` one is Used, Virtual Machine should be Powered Off
+
+	if len(ProviderID) == 0 {
+		ProviderID = VM_ENCRYPTION_KEY_PROVIDER
+	}
+	if len(ProviderID) == 0 {
+		return ErrKeyProviderNotConfigured
+	}
+
+	Disks, DisksError := this.poweredOffDisks(VirtualMachine)
+	if DisksError != nil {
+		return DisksError
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*30)
+	defer CancelFunc()
+
+	Key, KeyError := this.generateEncryptionKey(TimeoutContext, ProviderID)
+	if KeyError != nil {
+		return KeyError
+	}
+
+	Spec := types.VirtualMachineConfigSpec{Crypto: &types.CryptoSpecEncrypt{CryptoKeyId: *Key}}
+	for _, Disk := range Disks {
+		Spec.DeviceChange = append(Spec.DeviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    Disk,
+			Backing:   &types.VirtualDeviceConfigSpecBackingSpec{Crypto: &types.CryptoSpecEncrypt{CryptoKeyId: *Key}},
+		})
+	}
+	if ApplyError := this.applyConfigSpec(VirtualMachine, Spec); ApplyError != nil {
+		return ApplyError
+	}
+	this.setEncryptedFlag(VirtualMachine, true)
+	Logger.Debug("Virtual Machine has been Encrypted", zap.String("Item Path", VirtualMachine.InventoryPath),
+		zap.String("Key Provider", ProviderID))
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) DisableVMEncryption(VirtualMachine *object.VirtualMachine) error {
+	// Decrypts the Virtual Machine (Home Files and Disks), Virtual Machine should be Powered Off
+
+	Disks, DisksError := this.poweredOffDisks(VirtualMachine)
+	if DisksError != nil {
+		return DisksError
+	}
+
+	Spec := types.VirtualMachineConfigSpec{Crypto: &types.CryptoSpecDecrypt{}}
+	for _, Disk := range Disks {
+		Spec.DeviceChange = append(Spec.DeviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    Disk,
+			Backing:   &types.VirtualDeviceConfigSpecBackingSpec{Crypto: &types.CryptoSpecDecrypt{}},
+		})
+	}
+	if ApplyError := this.applyConfigSpec(VirtualMachine, Spec); ApplyError != nil {
+		return ApplyError
+	}
+	this.setEncryptedFlag(VirtualMachine, false)
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) poweredOffDisks(VirtualMachine *object.VirtualMachine) ([]types.BaseVirtualDevice, error) {
+	// Returns Disks of the Virtual Machine, if it is Powered Off
+	MoVirtualMachine, RetrieveError := this.retrieveProperties(VirtualMachine, []string{"runtime.powerState", "config.hardware.device"})
+	if RetrieveError != nil {
+		return nil, RetrieveError
+	}
+	if MoVirtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		return nil, ErrVirtualMachinePoweredOn
+	}
+	if MoVirtualMachine.Config == nil {
+		return nil, nil
+	}
+	return object.VirtualDeviceList(MoVirtualMachine.Config.Hardware.Device).SelectByType((*types.VirtualDisk)(nil)), nil
+}
+
+func (this *VirtualMachineReconfigureManager) generateEncryptionKey(Context context.Context, ProviderID string) (*types.CryptoKeyId, error) {
+	// Checks, that the Key Provider is Registered in vCenter and Generates new Key with it
+	CryptoManager := this.Client.ServiceContent.CryptoManager
+	if CryptoManager == nil {
+		return nil, ErrKeyProviderNotConfigured
+	}
+
+	Clusters, ListError := methods.ListKmsClusters(Context, &this.Client, &types.ListKmsClusters{This: *CryptoManager})
+	if ListError != nil {
+		Logger.Error("Failed to List Key Providers", zap.Error(ListError))
+		return nil, fmt.Errorf("%w: %s", ErrKeyProviderUnavailable, ListError)
+	}
+	Registered := false
+	for _, Cluster := range Clusters.Returnval {
+		Registered = Registered || Cluster.ClusterId.Id == ProviderID
+	}
+	if !Registered {
+		return nil, fmt.Errorf("%w: `%s` is not Registered", ErrKeyProviderUnavailable, ProviderID)
+	}
+
+	Generated, GenerateError := methods.GenerateKey(Context, &this.Client, &types.GenerateKey{
+		This: *CryptoManager, KeyProvider: &types.KeyProviderId{Id: ProviderID}})
+	if GenerateError != nil {
+		Logger.Error("Failed to Generate Encryption Key", zap.String("Key Provider", ProviderID), zap.Error(GenerateError))
+		return nil, fmt.Errorf("%w: %s", ErrKeyProviderUnavailable, GenerateError)
+	}
+	if !Generated.Returnval.Success {
+		return nil, fmt.Errorf("%w: %s", ErrKeyProviderUnavailable, Generated.Returnval.Reason)
+	}
+	return &Generated.Returnval.KeyId, nil
+}
+
+func (this *VirtualMachineReconfigureManager) setEncryptedFlag(VirtualMachine *object.VirtualMachine, Encrypted bool) {
+	// Reflects the Encryption State on the Database Row,
+	// vSphere is the Source of Truth, so the Failure is only being Logged
+	if UpdateError := models.SetVirtualMachineEncryptedFlag(VirtualMachine.InventoryPath, Encrypted); UpdateError != nil {
+		Logger.Error("Failed to Update Encrypted Flag of the Virtual Machine",
+			zap.String("Item Path", VirtualMachine.InventoryPath), zap.Error(UpdateError))
+	}
+}
@@ -0,0 +1,135 @@
+package reconfigure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"go.uber.org/zap"
+)
+
+const HardwareVersionPrefix = "vmx-" // Hardware Versions are Named like `vmx-19` by the vSphere
+
+var (
+	ErrInvalidHardwareVersion     = errors.New("Invalid Hardware Version, Expected the `vmx-<Number>` Format")
+	ErrHardwareVersionNotNewer    = errors.New("Target Hardware Version should be Newer, than the Current one")
+	ErrHardwareVersionUnsupported = errors.New("Target Hardware Version is not Supported by the Host")
+)
+
+func ParseHardwareVersion(Version string) (int, error) {
+	// Returns the Number of the Hardware Version, e.g `vmx-19` -> 19
+	Number, ParseError := strconv.Atoi(strings.TrimPrefix(Version, HardwareVersionPrefix))
+	if !strings.HasPrefix(Version, HardwareVersionPrefix) || ParseError != nil || Number <= 0 {
+		return 0, fmt.Errorf("%w: `%s`", ErrInvalidHardwareVersion, Version)
+	}
+	return Number, nil
+}
+
+func (this *VirtualMachineReconfigureManager) GetHardwareVersion(VirtualMachine *object.VirtualMachine) (string, error) {
+	// Returns Hardware Version of the Virtual Machine (`config.version`), e.g `vmx-19`
+	MoVirtualMachine, RetrieveError := this.retrieveProperties(VirtualMachine, []string{"config.version"})
+	if RetrieveError != nil {
+		return "", RetrieveError
+	}
+	if MoVirtualMachine.Config == nil {
+		return "", fmt.Errorf("Configuration of the Virtual Machine is not Available")
+	}
+	return MoVirtualMachine.Config.Version, nil
+}
+
+func (this *VirtualMachineReconfigureManager) UpgradeHardwareVersion(VirtualMachine *object.VirtualMachine, TargetVersion string) error {
+	// Upgrades Hardware Version of the Virtual Machine to the Target one, Virtual Machine should be Powered Off,
+	// Target Version should be Newer, than the Current one and Supported by the Host of the Virtual Machine
+	// The Upgrade can't be Reverted, so the Caller is Expected to Snapshot the Virtual Machine before, if needed
+
+	Target, TargetError := ParseHardwareVersion(TargetVersion)
+	if TargetError != nil {
+		return TargetError
+	}
+
+	MoVirtualMachine, RetrieveError := this.retrieveProperties(VirtualMachine,
+		[]string{"runtime.powerState", "runtime.host", "config.version", "environmentBrowser"})
+	if RetrieveError != nil {
+		return RetrieveError
+	}
+	if MoVirtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		return ErrVirtualMachinePoweredOn
+	}
+	if MoVirtualMachine.Config == nil {
+		return fmt.Errorf("Configuration of the Virtual Machine is not Available")
+	}
+	if Current, CurrentError := ParseHardwareVersion(MoVirtualMachine.Config.Version); CurrentError == nil && Target <= Current {
+		return fmt.Errorf("%w: `%s` is not Newer, than `%s`", ErrHardwareVersionNotNewer, TargetVersion, MoVirtualMachine.Config.Version)
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*5)
+	defer CancelFunc()
+
+	if SupportError := this.checkHardwareVersionSupported(TimeoutContext,
+		MoVirtualMachine.EnvironmentBrowser, MoVirtualMachine.Runtime.Host, TargetVersion); SupportError != nil {
+		return SupportError
+	}
+
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	UpgradeTask, UpgradeError := VirtualMachine.UpgradeVM(TimeoutContext, TargetVersion)
+	if UpgradeError != nil {
+		Logger.Error("Failed to Upgrade Hardware Version", zap.String("Target Version", TargetVersion), zap.Error(UpgradeError))
+		return UpgradeError
+	}
+	if WaitError := UpgradeTask.Wait(TimeoutContext); WaitError != nil {
+		Logger.Error("Failed to Upgrade Hardware Version", zap.String("Target Version", TargetVersion), zap.Error(WaitError))
+		return WaitError
+	}
+
+	// vSphere is the Source of Truth, so the Failure to Update the Row is only being Logged
+	if UpdateError := models.SetVirtualMachineHardwareVersion(VirtualMachine.InventoryPath, TargetVersion); UpdateError != nil {
+		Logger.Error("Failed to Update Hardware Version of the Virtual Machine",
+			zap.String("Item Path", VirtualMachine.InventoryPath), zap.Error(UpdateError))
+	}
+	Logger.Debug("Hardware Version of the Virtual Machine has been Upgraded",
+		zap.String("Item Path", VirtualMachine.InventoryPath), zap.String("From", MoVirtualMachine.Config.Version),
+		zap.String("To", TargetVersion))
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) checkHardwareVersionSupported(Context context.Context,
+	EnvironmentBrowser types.ManagedObjectReference, Host *types.ManagedObjectReference, TargetVersion string) error {
+	// Checks, that the Upgrade to the Target Hardware Version is Supported by the Host of the Virtual Machine
+
+	Response, QueryError := methods.QueryConfigOptionDescriptor(Context, &this.Client,
+		&types.QueryConfigOptionDescriptor{This: EnvironmentBrowser})
+	if QueryError != nil {
+		Logger.Error("Failed to Query Supported Hardware Versions", zap.Error(QueryError))
+		return QueryError
+	}
+
+	for _, Descriptor := range Response.Returnval {
+		if Descriptor.Key != TargetVersion || Descriptor.UpgradeSupported == nil || !*Descriptor.UpgradeSupported {
+			continue
+		}
+		// Descriptor without Hosts is Supported by every Host of the Compute Resource
+		if len(Descriptor.Host) == 0 || Host == nil {
+			return nil
+		}
+		for _, Supported := range Descriptor.Host {
+			if Supported == *Host {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: `%s`", ErrHardwareVersionUnsupported, TargetVersion)
+}
@@ -0,0 +1,109 @@
+package reconfigure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/datacenter"
+	"github.com/LovePelmeni/Infrastructure/network"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNetworkNotFound        = errors.New("Network has not been Found")
+	ErrNetworkAdapterNotFound = errors.New("Virtual Machine has no Network Adapter with this MAC Address")
+)
+
+func (this *VirtualMachineReconfigureManager) AddNetworkAdapter(VirtualMachine *object.VirtualMachine, NetworkName string) error {
+	// Adds new Network Adapter of the `network.DefaultAdapterType`, Attached to the Network with the Name (or Inventory Path)
+	// `ErrNetworkNotFound` is Returned, if there is no such Network in the Datacenter
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+	defer CancelFunc()
+
+	Finder := find.NewFinder(&this.Client)
+	if Datacenter, DatacenterError := datacenter.GetDatacenterResolver(this.Client).Resolve(
+		TimeoutContext, datacenter.DefaultDatacenterName); DatacenterError == nil {
+		Finder.SetDatacenter(Datacenter)
+	}
+	Network, NetworkError := Finder.Network(TimeoutContext, NetworkName)
+	if NetworkError != nil {
+		var NotFound *find.NotFoundError
+		if errors.As(NetworkError, &NotFound) {
+			return fmt.Errorf("%w: `%s`", ErrNetworkNotFound, NetworkName)
+		}
+		Logger.Error("Failed to Find Network", zap.String("Network", NetworkName), zap.Error(NetworkError))
+		return NetworkError
+	}
+	Backing, BackingError := Network.EthernetCardBackingInfo(TimeoutContext)
+	if BackingError != nil {
+		return BackingError
+	}
+
+	Devices, DeviceError := VirtualMachine.Device(TimeoutContext)
+	if DeviceError != nil {
+		return DeviceError
+	}
+	Card, CardError := Devices.CreateEthernetCard(network.DefaultAdapterType, Backing)
+	if CardError != nil {
+		return CardError
+	}
+
+	if ApplyError := this.applyConfigSpec(VirtualMachine, types.VirtualMachineConfigSpec{
+		DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+			&types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationAdd,
+				Device:    Card,
+			},
+		},
+	}); ApplyError != nil {
+		return ApplyError
+	}
+	Logger.Debug("Network Adapter has been Added", zap.String("Virtual Machine", VirtualMachine.InventoryPath),
+		zap.String("Network", NetworkName))
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) RemoveNetworkAdapter(VirtualMachine *object.VirtualMachine, MacAddress string) error {
+	// Removes Network Adapter with the MAC Address (Case Insensitive) from the Virtual Machine
+	// `ErrNetworkAdapterNotFound` is Returned, if the Virtual Machine has no such Adapter
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+	defer CancelFunc()
+
+	Devices, DeviceError := VirtualMachine.Device(TimeoutContext)
+	if DeviceError != nil {
+		return DeviceError
+	}
+
+	var Card types.BaseVirtualDevice
+	for _, Device := range Devices.SelectByType((*types.VirtualEthernetCard)(nil)) {
+		if strings.EqualFold(Device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress, MacAddress) {
+			Card = Device
+			break
+		}
+	}
+	if Card == nil {
+		return fmt.Errorf("%w: `%s`", ErrNetworkAdapterNotFound, MacAddress)
+	}
+
+	if ApplyError := this.applyConfigSpec(VirtualMachine, types.VirtualMachineConfigSpec{
+		DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+			&types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationRemove,
+				Device:    Card,
+			},
+		},
+	}); ApplyError != nil {
+		return ApplyError
+	}
+	Logger.Debug("Network Adapter has been Removed", zap.String("Virtual Machine", VirtualMachine.InventoryPath),
+		zap.String("MAC Address", MacAddress))
+	return nil
+}
@@ -0,0 +1,256 @@
+package reconfigure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"go.uber.org/zap"
+)
+
+var (
+	Logger *zap.Logger
+)
+
+func InitializeProductionLogger() {
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("ReconfigureLog.json")
+}
+
+func init() {
+	InitializeProductionLogger()
+}
+
+// Package consists of API, that Reconfigures Already Existing Virtual Machine Servers
+
+var (
+	ErrFullMemoryReservationRequired = errors.New("High Latency Sensitivity requires Full Memory Reservation")
+	ErrVirtualMachinePoweredOn       = errors.New("Virtual Machine should be Powered Off")
+)
+
+type VirtualMachineReconfigureManager struct {
+	// Manager Class, that Applies Configuration Changes to the Existing Virtual Machine
+	Client vim25.Client
+}
+
+func NewVirtualMachineReconfigureManager(Client vim25.Client) *VirtualMachineReconfigureManager {
+	return &VirtualMachineReconfigureManager{
+		Client: Client,
+	}
+}
+
+func (this *VirtualMachineReconfigureManager) retrieveProperties(VirtualMachine *object.VirtualMachine, Properties []string) (*mo.VirtualMachine, error) {
+	// Returns Mo Entity of the Virtual Machine with the Requested Properties only
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer CancelFunc()
+
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(TimeoutContext,
+		VirtualMachine.Reference(), Properties, &MoVirtualMachine); RetrieveError != nil {
+		Logger.Error("Failed to Retrieve Virtual Machine Properties",
+			zap.Strings("Properties", Properties), zap.Error(RetrieveError))
+		return nil, RetrieveError
+	}
+	return &MoVirtualMachine, nil
+}
+
+func (this *VirtualMachineReconfigureManager) applyConfigSpec(VirtualMachine *object.VirtualMachine, Spec types.VirtualMachineConfigSpec) error {
+	// Applies Configuration Specification to the Virtual Machine and Waits for the Reconfigure Task
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*5)
+	defer CancelFunc()
+
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+	return this.reconfigure(TimeoutContext, VirtualMachine, Spec)
+}
+
+func (this *VirtualMachineReconfigureManager) reconfigure(Context context.Context, VirtualMachine *object.VirtualMachine, Spec types.VirtualMachineConfigSpec) error {
+	// Applies Configuration Specification without Locking, the Caller should already Hold the Lock of the Virtual Machine
+
+	ReconfigureTask, ReconfigureError := VirtualMachine.Reconfigure(Context, Spec)
+	if ReconfigureError != nil {
+		Logger.Error("Failed to Reconfigure Virtual Machine", zap.Error(ReconfigureError))
+		return ReconfigureError
+	}
+	if WaitError := ReconfigureTask.Wait(Context); WaitError != nil {
+		Logger.Error("Failed to Reconfigure Virtual Machine", zap.Error(WaitError))
+		return WaitError
+	}
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) GetLatencySensitivity(VirtualMachine *object.VirtualMachine) (types.LatencySensitivitySensitivityLevel, error) {
+	// Returns Latency Sensitivity Level of the Virtual Machine
+
+	MoVirtualMachine, RetrieveError := this.retrieveProperties(VirtualMachine, []string{"config.latencySensitivity"})
+	if RetrieveError != nil {
+		return "", RetrieveError
+	}
+	if MoVirtualMachine.Config == nil || MoVirtualMachine.Config.LatencySensitivity == nil {
+		return types.LatencySensitivitySensitivityLevelNormal, nil
+	}
+	return MoVirtualMachine.Config.LatencySensitivity.Level, nil
+}
+
+func (this *VirtualMachineReconfigureManager) SetLatencySensitivity(VirtualMachine *object.VirtualMachine, Level types.LatencySensitivitySensitivityLevel) error {
+	// Sets Latency Sensitivity Level of the Virtual Machine
+	// NOTE: `high` Level requires the Memory of the VM to be fully Reserved, so
+	// the Reservation should be Set before Calling this Method
+
+	switch Level {
+	case types.LatencySensitivitySensitivityLevelLow,
+		types.LatencySensitivitySensitivityLevelNormal,
+		types.LatencySensitivitySensitivityLevelMedium,
+		types.LatencySensitivitySensitivityLevelHigh:
+	default:
+		return fmt.Errorf("Unsupported Latency Sensitivity Level `%s`", Level)
+	}
+
+	if Level == types.LatencySensitivitySensitivityLevelHigh {
+		MoVirtualMachine, RetrieveError := this.retrieveProperties(VirtualMachine,
+			[]string{"config.hardware.memoryMB", "config.memoryAllocation"})
+		if RetrieveError != nil {
+			return RetrieveError
+		}
+
+		var Reservation int64
+		MemoryMB := int64(MoVirtualMachine.Config.Hardware.MemoryMB)
+		if MoVirtualMachine.Config.MemoryAllocation != nil && MoVirtualMachine.Config.MemoryAllocation.Reservation != nil {
+			Reservation = *MoVirtualMachine.Config.MemoryAllocation.Reservation
+		}
+		if Reservation < MemoryMB {
+			return fmt.Errorf("%w, Set Memory Reservation to %d MB first (Current Reservation is %d MB)",
+				ErrFullMemoryReservationRequired, MemoryMB, Reservation)
+		}
+	}
+
+	return this.applyConfigSpec(VirtualMachine, types.VirtualMachineConfigSpec{
+		LatencySensitivity: &types.LatencySensitivity{Level: Level},
+	})
+}
+
+func validateShares(Shares types.SharesInfo) error {
+	// Checks that the Shares have Supported Level and Positive Count, if the Level is Custom
+	switch Shares.Level {
+	case types.SharesLevelLow, types.SharesLevelNormal, types.SharesLevelHigh:
+		return nil
+	case types.SharesLevelCustom:
+		if Shares.Shares <= 0 {
+			return fmt.Errorf("Custom Shares should be Positive, got %d", Shares.Shares)
+		}
+		return nil
+	default:
+		return fmt.Errorf("Unsupported Shares Level `%s`", Shares.Level)
+	}
+}
+
+func (this *VirtualMachineReconfigureManager) GetShares(VirtualMachine *object.VirtualMachine) (types.SharesInfo, types.SharesInfo, error) {
+	// Returns CPU and Memory Shares of the Virtual Machine
+
+	MoVirtualMachine, RetrieveError := this.retrieveProperties(VirtualMachine,
+		[]string{"config.cpuAllocation", "config.memoryAllocation"})
+	if RetrieveError != nil {
+		return types.SharesInfo{}, types.SharesInfo{}, RetrieveError
+	}
+
+	var CpuShares, MemoryShares types.SharesInfo
+	if MoVirtualMachine.Config != nil {
+		if MoVirtualMachine.Config.CpuAllocation != nil && MoVirtualMachine.Config.CpuAllocation.Shares != nil {
+			CpuShares = *MoVirtualMachine.Config.CpuAllocation.Shares
+		}
+		if MoVirtualMachine.Config.MemoryAllocation != nil && MoVirtualMachine.Config.MemoryAllocation.Shares != nil {
+			MemoryShares = *MoVirtualMachine.Config.MemoryAllocation.Shares
+		}
+	}
+	return CpuShares, MemoryShares, nil
+}
+
+func (this *VirtualMachineReconfigureManager) SetShares(VirtualMachine *object.VirtualMachine, CpuShares types.SharesInfo, MemoryShares types.SharesInfo) error {
+	// Sets CPU and Memory Shares of the Virtual Machine, that define its Relative Priority under the Contention
+	// Named Levels (`low`, `normal`, `high`) or `custom` Level with the Positive Share Count are Accepted
+
+	if ValidationError := validateShares(CpuShares); ValidationError != nil {
+		return fmt.Errorf("Invalid CPU Shares: %w", ValidationError)
+	}
+	if ValidationError := validateShares(MemoryShares); ValidationError != nil {
+		return fmt.Errorf("Invalid Memory Shares: %w", ValidationError)
+	}
+
+	return this.applyConfigSpec(VirtualMachine, types.VirtualMachineConfigSpec{
+		CpuAllocation:    &types.ResourceAllocationInfo{Shares: &CpuShares},
+		MemoryAllocation: &types.ResourceAllocationInfo{Shares: &MemoryShares},
+	})
+}
+
+func (this *VirtualMachineReconfigureManager) MarkAsTemplate(VirtualMachine *object.VirtualMachine) error {
+	// Converts the Virtual Machine into the Template, Virtual Machine should be Powered Off
+
+	MoVirtualMachine, RetrieveError := this.retrieveProperties(VirtualMachine, []string{"runtime.powerState"})
+	if RetrieveError != nil {
+		return RetrieveError
+	}
+	if MoVirtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		return ErrVirtualMachinePoweredOn
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*30)
+	defer CancelFunc()
+
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	if MarkError := VirtualMachine.MarkAsTemplate(TimeoutContext); MarkError != nil {
+		Logger.Error("Failed to Mark Virtual Machine as Template", zap.Error(MarkError))
+		return MarkError
+	}
+	this.setTemplateFlag(VirtualMachine, true)
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) MarkAsVirtualMachine(VirtualMachine *object.VirtualMachine, ResourcePool *object.ResourcePool) error {
+	// Converts the Template back into the Virtual Machine, that is going to Run within the Resource Pool
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*30)
+	defer CancelFunc()
+
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	if MarkError := VirtualMachine.MarkAsVirtualMachine(TimeoutContext, *ResourcePool, nil); MarkError != nil {
+		Logger.Error("Failed to Mark Template as Virtual Machine", zap.Error(MarkError))
+		return MarkError
+	}
+	this.setTemplateFlag(VirtualMachine, false)
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) setTemplateFlag(VirtualMachine *object.VirtualMachine, IsTemplate bool) {
+	// Reflects the Template State on the Database Row,
+	// vSphere is the Source of Truth, so the Failure is only being Logged
+	if UpdateError := models.SetVirtualMachineTemplateFlag(VirtualMachine.InventoryPath, IsTemplate); UpdateError != nil {
+		Logger.Error("Failed to Update Template Flag of the Virtual Machine",
+			zap.String("Item Path", VirtualMachine.InventoryPath), zap.Error(UpdateError))
+	}
+}
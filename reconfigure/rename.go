@@ -0,0 +1,115 @@
+package reconfigure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"go.uber.org/zap"
+)
+
+const MaxVirtualMachineNameLength = 80 // vSphere Limit of the Virtual Machine Name
+
+var (
+	ErrInvalidVirtualMachineName = errors.New("Invalid Virtual Machine Name")
+)
+
+var (
+	// vSphere Escapes `%`, `/` and `\` in the Names, so only the Characters, that are Safe as the Host Name, are Allowed
+	virtualMachineNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+)
+
+func ValidateVirtualMachineName(Name string) error {
+	// Checks that the Name is from 1 to `MaxVirtualMachineNameLength` Characters long and Consists of Letters, Digits, `.`, `_` and `-`
+	if len(Name) == 0 || len(Name) > MaxVirtualMachineNameLength {
+		return fmt.Errorf("%w: Name should be from 1 to %d Characters long", ErrInvalidVirtualMachineName, MaxVirtualMachineNameLength)
+	}
+	if !virtualMachineNamePattern.MatchString(Name) {
+		return fmt.Errorf("%w: `%s` should Start with the Letter or Digit and Contain only Letters, Digits, `.`, `_` and `-`",
+			ErrInvalidVirtualMachineName, Name)
+	}
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) Rename(VirtualMachine *object.VirtualMachine, NewName string) error {
+	// Renames the Virtual Machine in vSphere and then its Database Row, so they do not Drift apart
+	// If the Row can't be Updated, the Virtual Machine is being Renamed back, Virtual Machines without the Row are only Renamed in vSphere
+
+	if ValidationError := ValidateVirtualMachineName(NewName); ValidationError != nil {
+		return ValidationError
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+	defer CancelFunc()
+
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	MoVirtualMachine, RetrieveError := this.retrieveProperties(VirtualMachine, []string{"name", "config.uuid"})
+	if RetrieveError != nil {
+		return RetrieveError
+	}
+	var UUID string
+	if MoVirtualMachine.Config != nil {
+		UUID = MoVirtualMachine.Config.Uuid
+	}
+	OldName := MoVirtualMachine.Name
+	if OldName == NewName {
+		return nil
+	}
+	OldItemPath := VirtualMachine.InventoryPath
+	if len(OldItemPath) == 0 {
+		InventoryPath, PathError := find.InventoryPath(TimeoutContext, &this.Client, VirtualMachine.Reference())
+		if PathError != nil {
+			return PathError
+		}
+		OldItemPath = InventoryPath
+	}
+	NewItemPath := path.Join(path.Dir(OldItemPath), NewName)
+
+	if RenameError := this.renameInventoryItem(TimeoutContext, VirtualMachine, NewName); RenameError != nil {
+		return RenameError
+	}
+
+	RecordError := models.RenameVirtualMachineRecord(UUID, OldItemPath, NewName, NewItemPath)
+	switch {
+	case RecordError == nil:
+	case errors.Is(RecordError, models.ErrNotFound):
+		Logger.Debug("Renamed Virtual Machine has no Database Record", zap.String("Name", NewName))
+	default:
+		Logger.Error("Failed to Rename Database Record of the Virtual Machine, Renaming it back",
+			zap.String("Name", NewName), zap.Error(RecordError))
+		if RevertError := this.renameInventoryItem(TimeoutContext, VirtualMachine, OldName); RevertError != nil {
+			Logger.Error("Failed to Rename Virtual Machine back", zap.String("Name", OldName), zap.Error(RevertError))
+		}
+		return RecordError
+	}
+
+	VirtualMachine.InventoryPath = NewItemPath
+	Logger.Debug("Virtual Machine has been Renamed", zap.String("From", OldName), zap.String("To", NewName))
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) renameInventoryItem(Context context.Context, VirtualMachine *object.VirtualMachine, Name string) error {
+	// Runs the Rename Task of the Virtual Machine and Waits until it is Done
+	RenameTask, RenameError := VirtualMachine.Rename(Context, Name)
+	if RenameError != nil {
+		Logger.Error("Failed to Rename Virtual Machine", zap.String("Name", Name), zap.Error(RenameError))
+		return RenameError
+	}
+	if WaitError := RenameTask.Wait(Context); WaitError != nil {
+		Logger.Error("Failed to Rename Virtual Machine", zap.String("Name", Name), zap.Error(WaitError))
+		return WaitError
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package reconfigure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+const (
+	MaxResizeCPUs     = 128         // Max Number of the Virtual CPUs, the Virtual Machine can be Resized to
+	MaxResizeMemoryMB = 1024 * 1024 // Max Memory (1 TB), the Virtual Machine can be Resized to
+)
+
+var (
+	ErrInvalidResize       = errors.New("Invalid Number of CPUs or Memory Size")
+	ErrHotAddNotEnabled    = errors.New("Hot Add is not Enabled for the Powered On Virtual Machine")
+	ErrHotRemoveNotAllowed = errors.New("CPUs and Memory of the Powered On Virtual Machine can't be Decreased")
+	ErrResizeNotPersisted  = errors.New("Virtual Machine has been Resized, but its Database Record has not been Updated")
+)
+
+func ValidateResize(NumCPU int32, MemoryMB int64) error {
+	// Checks that the Number of CPUs and Memory Size are within the Supported Bounds
+	// Memory should be a Multiple of 4 MB, as vSphere Requires
+	if NumCPU <= 0 || NumCPU > MaxResizeCPUs {
+		return fmt.Errorf("%w: Number of CPUs should be from 1 to %d", ErrInvalidResize, MaxResizeCPUs)
+	}
+	if MemoryMB <= 0 || MemoryMB > MaxResizeMemoryMB {
+		return fmt.Errorf("%w: Memory should be from 4 to %d MB", ErrInvalidResize, MaxResizeMemoryMB)
+	}
+	if MemoryMB%4 != 0 {
+		return fmt.Errorf("%w: Memory should be a Multiple of 4 MB", ErrInvalidResize)
+	}
+	return nil
+}
+
+func (this *VirtualMachineReconfigureManager) Resize(VirtualMachine *object.VirtualMachine, NumCPU int32, MemoryMB int64) error {
+	// Changes Number of CPUs and Memory Size of the Virtual Machine, and then the Resources of its Database Record,
+	// along with the `EventResized` on its Timeline (Virtual Machines without the Record are only Resized in vSphere)
+	// Powered On Virtual Machine can only be Grown and only if the Hot Add of the Resource is Enabled,
+	// `ErrHotAddNotEnabled` or `ErrHotRemoveNotAllowed` is Returned Otherwise
+	// If the Record can't be Updated, `ErrResizeNotPersisted` is Returned (The Virtual Machine keeps the New Size)
+
+	if ValidationError := ValidateResize(NumCPU, MemoryMB); ValidationError != nil {
+		return ValidationError
+	}
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*5)
+	defer CancelFunc()
+
+	// Lock is Taken before the Checks, so the Power State or Size can't be Changed between them and the Reconfigure
+	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	MoVirtualMachine, RetrieveError := this.retrieveProperties(VirtualMachine, []string{"runtime.powerState", "config.uuid",
+		"config.hardware.numCPU", "config.hardware.memoryMB", "config.cpuHotAddEnabled", "config.memoryHotAddEnabled"})
+	if RetrieveError != nil {
+		return RetrieveError
+	}
+	if MoVirtualMachine.Config == nil {
+		return fmt.Errorf("Configuration of the Virtual Machine is not Available")
+	}
+	CurrentCPU := MoVirtualMachine.Config.Hardware.NumCPU
+	CurrentMemoryMB := int64(MoVirtualMachine.Config.Hardware.MemoryMB)
+
+	if MoVirtualMachine.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		if NumCPU < CurrentCPU || MemoryMB < CurrentMemoryMB {
+			return ErrHotRemoveNotAllowed
+		}
+		if NumCPU > CurrentCPU && !isEnabled(MoVirtualMachine.Config.CpuHotAddEnabled) {
+			return fmt.Errorf("%w: CPU Hot Add is Disabled", ErrHotAddNotEnabled)
+		}
+		if MemoryMB > CurrentMemoryMB && !isEnabled(MoVirtualMachine.Config.MemoryHotAddEnabled) {
+			return fmt.Errorf("%w: Memory Hot Add is Disabled", ErrHotAddNotEnabled)
+		}
+	}
+
+	if ApplyError := this.reconfigure(TimeoutContext, VirtualMachine, types.VirtualMachineConfigSpec{
+		NumCPUs:  NumCPU,
+		MemoryMB: MemoryMB,
+	}); ApplyError != nil {
+		return ApplyError
+	}
+
+	RecordError := models.ResizeVirtualMachineRecord(MoVirtualMachine.Config.Uuid, NumCPU, MemoryMB)
+	switch {
+	case RecordError == nil:
+	case errors.Is(RecordError, models.ErrNotFound):
+		Logger.Debug("Resized Virtual Machine has no Database Record", zap.String("Virtual Machine", VirtualMachine.InventoryPath))
+	default:
+		Logger.Error("Virtual Machine has been Resized, but its Database Record has not been Updated",
+			zap.String("Virtual Machine", VirtualMachine.InventoryPath), zap.Error(RecordError))
+		return fmt.Errorf("%w: %s", ErrResizeNotPersisted, RecordError)
+	}
+	Logger.Debug("Virtual Machine has been Resized", zap.String("Virtual Machine", VirtualMachine.InventoryPath),
+		zap.Int32("CPUs", NumCPU), zap.Int64("Memory MB", MemoryMB))
+	return nil
+}
+
+func isEnabled(Flag *bool) bool {
+	// Returns True if the Optional vSphere Flag is Set and Enabled
+	return Flag != nil && *Flag
+}
@@ -1,11 +1,10 @@
 package resource_config
 
 import (
-	"os"
-
 	"github.com/vmware/govmomi/vim25/types"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
 )
 
 var (
@@ -13,15 +12,8 @@ var (
 )
 
 func InitializeProductionLogger() {
-
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("ResourceConfigLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("ResourceConfigLog.json")
 }
 
 func init() {
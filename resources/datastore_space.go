@@ -0,0 +1,70 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/datacenter"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrDatastoreNotFound = errors.New("Datastore has not been Found")
+)
+
+func GetDatastoreFreeSpace(Context context.Context, Client *vim25.Client, DatastoreName string) (int64, error) {
+	// Returns Free Space of the Datastore (Name or Inventory Path within the Default Datacenter) in Bytes,
+	// So the Provisioning can be Rejected in Advance, instead of Failing in the Middle of the Clone Task
+
+	if len(DatastoreName) == 0 {
+		return 0, fmt.Errorf("%w: Name is Empty", ErrDatastoreNotFound)
+	}
+	Finder, FinderError := datacenter.GetDatacenterResolver(*Client).NewFinder(Context, datacenter.DefaultDatacenterName)
+	if FinderError != nil {
+		return 0, FinderError
+	}
+	Datastore, FindError := Finder.Datastore(Context, DatastoreName)
+	if FindError != nil {
+		var NotFound *find.NotFoundError
+		if errors.As(FindError, &NotFound) {
+			return 0, fmt.Errorf("%w: `%s`", ErrDatastoreNotFound, DatastoreName)
+		}
+		Logger.Error("Failed to Find Datastore", zap.String("Datastore", DatastoreName), zap.Error(FindError))
+		return 0, FindError
+	}
+
+	var MoDatastore mo.Datastore
+	Collector := property.DefaultCollector(Client)
+	if RetrieveError := Collector.RetrieveOne(Context, Datastore.Reference(),
+		[]string{"summary.freeSpace"}, &MoDatastore); RetrieveError != nil {
+		Logger.Error("Failed to Retrieve Free Space of the Datastore",
+			zap.String("Datastore", DatastoreName), zap.Error(RetrieveError))
+		return 0, RetrieveError
+	}
+	return MoDatastore.Summary.FreeSpace, nil
+}
+
+func (this *DatastoreResourceManager) HasSufficientSpace(DatastoreName string, RequiredBytes int64) (bool, error) {
+	// Returns True if the Datastore has at least `RequiredBytes` of Free Space
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*30)
+	defer CancelFunc()
+
+	FreeSpace, SpaceError := GetDatastoreFreeSpace(TimeoutContext, &this.Client, DatastoreName)
+	if SpaceError != nil {
+		return false, SpaceError
+	}
+	if FreeSpace < RequiredBytes {
+		Logger.Debug("Datastore does not Have Enough Free Space", zap.String("Datastore", DatastoreName),
+			zap.Int64("Free Bytes", FreeSpace), zap.Int64("Required Bytes", RequiredBytes))
+		return false, nil
+	}
+	return true, nil
+}
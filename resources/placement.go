@@ -0,0 +1,69 @@
+package resources
+
+import (
+	"context"
+	"path"
+
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+
+	"go.uber.org/zap"
+)
+
+type Placement struct {
+	// Location of the Virtual Machine within the Inventory
+	HostName         string `json:"HostName" xml:"HostName"`                 // Empty, if the VM is not Placed on any Host (e.g Template)
+	ClusterName      string `json:"ClusterName" xml:"ClusterName"`           // Empty, if the Host is Standalone
+	ResourcePoolName string `json:"ResourcePoolName" xml:"ResourcePoolName"` // Empty for the Templates
+	ResourcePoolPath string `json:"ResourcePoolPath" xml:"ResourcePoolPath"` // Empty for the Templates
+	IsTemplate       bool   `json:"IsTemplate" xml:"IsTemplate"`
+}
+
+func GetVMPlacement(Context context.Context, Client *vim25.Client, VirtualMachine *object.VirtualMachine) (Placement, error) {
+	// Returns the Host, Cluster and Resource Pool, the Virtual Machine currently Runs on, so the Scheduling
+	// and Capacity Planning can take it into Account. Cluster is Empty for the Virtual Machines on the Standalone Hosts
+
+	Collector := property.DefaultCollector(Client)
+
+	var MoVirtualMachine mo.VirtualMachine
+	if RetrieveError := Collector.RetrieveOne(Context, VirtualMachine.Reference(),
+		[]string{"runtime.host", "resourcePool", "config.template"}, &MoVirtualMachine); RetrieveError != nil {
+		Logger.Error("Failed to Retrieve Virtual Machine Placement", zap.Error(RetrieveError))
+		return Placement{}, RetrieveError
+	}
+
+	VirtualMachinePlacement := Placement{IsTemplate: MoVirtualMachine.Config != nil && MoVirtualMachine.Config.Template}
+
+	if Host := MoVirtualMachine.Runtime.Host; Host != nil {
+		var MoHost mo.HostSystem
+		if RetrieveError := Collector.RetrieveOne(Context, *Host, []string{"name", "parent"}, &MoHost); RetrieveError != nil {
+			Logger.Error("Failed to Retrieve Host of the Virtual Machine", zap.Error(RetrieveError))
+			return Placement{}, RetrieveError
+		}
+		VirtualMachinePlacement.HostName = MoHost.Name
+
+		// Standalone Host has its own `ComputeResource` Parent, so only the Cluster one is Reported
+		if Parent := MoHost.Parent; Parent != nil && Parent.Type == "ClusterComputeResource" {
+			var MoCluster mo.ClusterComputeResource
+			if RetrieveError := Collector.RetrieveOne(Context, *Parent, []string{"name"}, &MoCluster); RetrieveError != nil {
+				Logger.Error("Failed to Retrieve Cluster of the Virtual Machine", zap.Error(RetrieveError))
+				return Placement{}, RetrieveError
+			}
+			VirtualMachinePlacement.ClusterName = MoCluster.Name
+		}
+	}
+
+	if ResourcePool := MoVirtualMachine.ResourcePool; ResourcePool != nil {
+		ResourcePoolPath, PathError := find.InventoryPath(Context, Client, *ResourcePool)
+		if PathError != nil {
+			Logger.Error("Failed to Resolve Resource Pool Path", zap.Error(PathError))
+			return Placement{}, PathError
+		}
+		VirtualMachinePlacement.ResourcePoolName = path.Base(ResourcePoolPath)
+		VirtualMachinePlacement.ResourcePoolPath = ResourcePoolPath
+	}
+	return VirtualMachinePlacement, nil
+}
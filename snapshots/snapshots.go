@@ -0,0 +1,329 @@
+package snapshots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"go.uber.org/zap"
+)
+
+var (
+	Logger *zap.Logger
+)
+
+func InitializeProductionLogger() {
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("SnapshotsLog.json")
+}
+
+func init() {
+	InitializeProductionLogger()
+}
+
+// Package consists of API, that Takes Snapshots of the Virtual Machines and Reverts them back,
+// So the Customer can Roll back the Risky Changes. Snapshots are Identified by the Name, which is Unique per Virtual Machine
+// (vSphere itself Allows Duplicates, but then the Name can't be used to Pick the Snapshot)
+
+const DefaultSnapshotTimeout = time.Minute * 10 // Snapshot with the Memory of the Large VM takes a While
+
+var (
+	ErrSnapshotNameRequired = errors.New("Name of the Snapshot is Required")
+	ErrSnapshotNameTaken    = errors.New("Virtual Machine already has the Snapshot with this Name")
+	ErrSnapshotNotFound     = errors.New("Snapshot has not been Found")
+)
+
+type SnapshotInfo struct {
+	// Single Snapshot of the Virtual Machine, Snapshots of the Tree are Listed Parents first
+	Name        string    `json:"Name" xml:"Name"`
+	Description string    `json:"Description" xml:"Description"`
+	Parent      string    `json:"Parent,omitempty" xml:"Parent,omitempty"` // Name of the Parent Snapshot, Empty for the Root one
+	CreatedAt   time.Time `json:"CreatedAt" xml:"CreatedAt"`
+	PowerState  string    `json:"PowerState" xml:"PowerState"` // Power State of the Virtual Machine at the Moment of the Snapshot
+	Quiesced    bool      `json:"Quiesced" xml:"Quiesced"`
+	Current     bool      `json:"Current" xml:"Current"` // True for the Snapshot, the Virtual Machine is Currently Running from
+}
+
+type VirtualMachineSnapshotManager struct {
+	// Manager Class, that Creates, Lists and Reverts Snapshots of the Virtual Machine
+	Client vim25.Client
+}
+
+func NewVirtualMachineSnapshotManager(Client vim25.Client) *VirtualMachineSnapshotManager {
+	return &VirtualMachineSnapshotManager{
+		Client: Client,
+	}
+}
+
+func (this *VirtualMachineSnapshotManager) CreateSnapshot(VirtualMachine *object.VirtualMachine, Name string, Description string, Memory bool, Quiesce bool, Options ...options.OperationOption) error {
+	// Takes the Snapshot of the Virtual Machine and Waits until it is Done,
+	// `Memory` Includes the Memory of the Running VM, `Quiesce` Flushes the Guest File System through the VMware Tools
+	// `ErrSnapshotNameTaken` is Returned, if the Virtual Machine already has the Snapshot with the same Name
+
+	if len(Name) == 0 {
+		return ErrSnapshotNameRequired
+	}
+
+	Operation := options.NewOperationOptions(DefaultSnapshotTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	Release, LockError := Operation.LockVirtualMachine(TimeoutContext, VirtualMachine.Reference())
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	// Name is being Checked under the Lock, so two Concurrent Calls can't Create the Duplicates
+	Snapshots, ListError := this.listSnapshots(TimeoutContext, VirtualMachine)
+	if ListError != nil {
+		return ListError
+	}
+	for _, Snapshot := range Snapshots {
+		if Snapshot.Name == Name {
+			return fmt.Errorf("%w: `%s`", ErrSnapshotNameTaken, Name)
+		}
+	}
+
+	CreateError := Operation.Retry(TimeoutContext, func() error {
+		SnapshotTask, TaskError := VirtualMachine.CreateSnapshot(TimeoutContext, Name, Description, Memory, Quiesce)
+		if TaskError != nil {
+			return TaskError
+		}
+		return SnapshotTask.Wait(TimeoutContext)
+	})
+	if CreateError != nil {
+		Logger.Error("Failed to Create Snapshot", zap.String("Name", Name), zap.Error(CreateError))
+		return CreateError
+	}
+	this.recordSnapshotEvent(TimeoutContext, VirtualMachine, Name)
+	Logger.Debug("Snapshot has been Created", zap.String("Virtual Machine", VirtualMachine.InventoryPath),
+		zap.String("Name", Name))
+	return nil
+}
+
+func (this *VirtualMachineSnapshotManager) ListSnapshots(VirtualMachine *object.VirtualMachine) ([]SnapshotInfo, error) {
+	// Returns all Snapshots of the Virtual Machine, Empty List if it has None
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*30)
+	defer CancelFunc()
+
+	Snapshots, ListError := this.listSnapshots(TimeoutContext, VirtualMachine)
+	if ListError != nil {
+		return nil, ListError
+	}
+	Infos := make([]SnapshotInfo, 0, len(Snapshots))
+	for _, Snapshot := range Snapshots {
+		Infos = append(Infos, Snapshot.SnapshotInfo)
+	}
+	return Infos, nil
+}
+
+func (this *VirtualMachineSnapshotManager) RevertToSnapshot(VirtualMachine *object.VirtualMachine, Name string, Options ...options.OperationOption) error {
+	// Reverts the Virtual Machine to the Snapshot with the Name and Waits until it is Done,
+	// Running Virtual Machine is being Powered On back only if the Snapshot has Included its Memory
+	// `ErrSnapshotNotFound` is Returned, if the Virtual Machine has no Snapshot with the Name
+
+	if len(Name) == 0 {
+		return ErrSnapshotNameRequired
+	}
+
+	Operation := options.NewOperationOptions(DefaultSnapshotTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	Release, LockError := Operation.LockVirtualMachine(TimeoutContext, VirtualMachine.Reference())
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	Reference, FindError := this.findSnapshot(TimeoutContext, VirtualMachine, Name)
+	if FindError != nil {
+		return FindError
+	}
+
+	RevertError := Operation.Retry(TimeoutContext, func() error {
+		Response, TaskError := methods.RevertToSnapshot_Task(TimeoutContext, &this.Client, &types.RevertToSnapshot_Task{
+			This:            Reference,
+			SuppressPowerOn: types.NewBool(false),
+		})
+		if TaskError != nil {
+			return TaskError
+		}
+		return object.NewTask(&this.Client, Response.Returnval).Wait(TimeoutContext)
+	})
+	if RevertError != nil {
+		Logger.Error("Failed to Revert to Snapshot", zap.String("Name", Name), zap.Error(RevertError))
+		return RevertError
+	}
+	Logger.Debug("Virtual Machine has been Reverted to Snapshot", zap.String("Virtual Machine", VirtualMachine.InventoryPath),
+		zap.String("Name", Name))
+	return nil
+}
+
+func (this *VirtualMachineSnapshotManager) DeleteSnapshot(VirtualMachine *object.VirtualMachine, Name string, RemoveChildren bool, Options ...options.OperationOption) error {
+	// Deletes the Snapshot with the Name and Consolidates its Disks into the Parent,
+	// Children of the Snapshot are being Deleted as well, if `RemoveChildren` is Set, Otherwise they are Re-Parented
+	// `ErrSnapshotNotFound` is Returned, if the Virtual Machine has no Snapshot with the Name
+
+	if len(Name) == 0 {
+		return ErrSnapshotNameRequired
+	}
+
+	Operation := options.NewOperationOptions(DefaultSnapshotTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	Release, LockError := Operation.LockVirtualMachine(TimeoutContext, VirtualMachine.Reference())
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	Reference, FindError := this.findSnapshot(TimeoutContext, VirtualMachine, Name)
+	if FindError != nil {
+		return FindError
+	}
+
+	DeleteError := Operation.Retry(TimeoutContext, func() error {
+		Response, TaskError := methods.RemoveSnapshot_Task(TimeoutContext, &this.Client, &types.RemoveSnapshot_Task{
+			This:           Reference,
+			RemoveChildren: RemoveChildren,
+			Consolidate:    types.NewBool(true),
+		})
+		if TaskError != nil {
+			return TaskError
+		}
+		return object.NewTask(&this.Client, Response.Returnval).Wait(TimeoutContext)
+	})
+	if DeleteError != nil {
+		Logger.Error("Failed to Delete Snapshot", zap.String("Name", Name), zap.Error(DeleteError))
+		return DeleteError
+	}
+	Logger.Debug("Snapshot has been Deleted", zap.String("Virtual Machine", VirtualMachine.InventoryPath),
+		zap.String("Name", Name), zap.Bool("Remove Children", RemoveChildren))
+	return nil
+}
+
+func (this *VirtualMachineSnapshotManager) ConsolidateDisks(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) error {
+	// Merges the Leftover Delta Disks of the Virtual Machine (e.g after the Failed Snapshot Deletion) back into the Base Disks,
+	// Nothing Happens, if the vSphere does not Report the Consolidation as Needed
+
+	Operation := options.NewOperationOptions(DefaultSnapshotTimeout, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	Release, LockError := Operation.LockVirtualMachine(TimeoutContext, VirtualMachine.Reference())
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(TimeoutContext, VirtualMachine.Reference(),
+		[]string{"runtime.consolidationNeeded"}, &MoVirtualMachine); RetrieveError != nil {
+		return RetrieveError
+	}
+	if MoVirtualMachine.Runtime.ConsolidationNeeded == nil || !*MoVirtualMachine.Runtime.ConsolidationNeeded {
+		return nil
+	}
+
+	ConsolidateError := Operation.Retry(TimeoutContext, func() error {
+		Response, TaskError := methods.ConsolidateVMDisks_Task(TimeoutContext, &this.Client, &types.ConsolidateVMDisks_Task{
+			This: VirtualMachine.Reference(),
+		})
+		if TaskError != nil {
+			return TaskError
+		}
+		return object.NewTask(&this.Client, Response.Returnval).Wait(TimeoutContext)
+	})
+	if ConsolidateError != nil {
+		Logger.Error("Failed to Consolidate Disks", zap.String("Virtual Machine", VirtualMachine.InventoryPath),
+			zap.Error(ConsolidateError))
+		return ConsolidateError
+	}
+	Logger.Debug("Disks have been Consolidated", zap.String("Virtual Machine", VirtualMachine.InventoryPath))
+	return nil
+}
+
+func (this *VirtualMachineSnapshotManager) findSnapshot(Context context.Context, VirtualMachine *object.VirtualMachine, Name string) (types.ManagedObjectReference, error) {
+	// Returns Reference of the Snapshot with the Name, `ErrSnapshotNotFound` if there is None
+	Snapshots, ListError := this.listSnapshots(Context, VirtualMachine)
+	if ListError != nil {
+		return types.ManagedObjectReference{}, ListError
+	}
+	for _, Snapshot := range Snapshots {
+		if Snapshot.Name == Name {
+			return Snapshot.Reference, nil
+		}
+	}
+	return types.ManagedObjectReference{}, fmt.Errorf("%w: `%s`", ErrSnapshotNotFound, Name)
+}
+
+func (this *VirtualMachineSnapshotManager) recordSnapshotEvent(Context context.Context, VirtualMachine *object.VirtualMachine, Name string) {
+	// Appends the `EventSnapshotTaken` to the Timeline of the Virtual Machine, Failure is only being Logged,
+	// because the Snapshot has already been Taken
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(Context, VirtualMachine.Reference(),
+		[]string{"config.uuid"}, &MoVirtualMachine); RetrieveError != nil || MoVirtualMachine.Config == nil {
+		Logger.Error("Failed to Record Snapshot Event, UUID of the Virtual Machine is not Available",
+			zap.String("Name", Name), zap.Error(RetrieveError))
+		return
+	}
+	models.RecordVMEventByUUID(MoVirtualMachine.Config.Uuid, models.EventSnapshotTaken, fmt.Sprintf("Snapshot `%s`", Name))
+}
+
+type snapshot struct {
+	SnapshotInfo
+	Reference types.ManagedObjectReference
+}
+
+func (this *VirtualMachineSnapshotManager) listSnapshots(Context context.Context, VirtualMachine *object.VirtualMachine) ([]snapshot, error) {
+	// Returns the Snapshot Tree of the Virtual Machine, Flattened Parents first, along with the References
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(Context, VirtualMachine.Reference(),
+		[]string{"snapshot"}, &MoVirtualMachine); RetrieveError != nil {
+		Logger.Error("Failed to Retrieve Snapshots of the Virtual Machine", zap.Error(RetrieveError))
+		return nil, RetrieveError
+	}
+	Snapshots := []snapshot{}
+	if MoVirtualMachine.Snapshot == nil {
+		return Snapshots, nil
+	}
+	var walk func(Tree []types.VirtualMachineSnapshotTree, Parent string)
+	walk = func(Tree []types.VirtualMachineSnapshotTree, Parent string) {
+		for _, Node := range Tree {
+			Snapshots = append(Snapshots, snapshot{
+				SnapshotInfo: SnapshotInfo{
+					Name:        Node.Name,
+					Description: Node.Description,
+					Parent:      Parent,
+					CreatedAt:   Node.CreateTime,
+					PowerState:  string(Node.State),
+					Quiesced:    Node.Quiesced,
+					Current: MoVirtualMachine.Snapshot.CurrentSnapshot != nil &&
+						MoVirtualMachine.Snapshot.CurrentSnapshot.Value == Node.Snapshot.Value,
+				},
+				Reference: Node.Snapshot,
+			})
+			walk(Node.ChildSnapshotList, Node.Name)
+		}
+	}
+	walk(MoVirtualMachine.Snapshot.RootSnapshotList, "")
+	return Snapshots, nil
+}
@@ -0,0 +1,73 @@
+package ssh_config
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/LovePelmeni/Infrastructure/tracing"
+	"github.com/vmware/govmomi/object"
+	"go.uber.org/zap"
+)
+
+const DefaultUploadConcurrency = 5 // Max Amount of the Virtual Machines, the Key is being Uploaded to at the same time
+
+func (this *VirtualMachineSshCertificateManager) UploadSshKeysToMany(VirtualMachines []*object.VirtualMachine, Key SshCertificateCredentials, Concurrency int, CustomerID int, Options ...options.OperationOption) map[string]error {
+	// Uploads the same SSH Key to many Virtual Machines at once, at most `Concurrency` Uploads are Running at the same time,
+	// so the vCenter is not Overwhelmed (`DefaultUploadConcurrency` is Used, if it is not Positive)
+	// Failure of the Single Virtual Machine does not Stop the others, Returns Errors of the Failed ones by the Virtual Machine Name
+	// Options are Applied to every Single Upload, whose Spans are Nested under the Span of the whole Batch
+	// The Batch is Counted as a Single Operation of the Customer by the Rate Limiter, if it is Exceeded, every Upload Fails with `ErrRateLimited`
+
+	if Concurrency <= 0 {
+		Concurrency = DefaultUploadConcurrency
+	}
+
+	SpanContext, Span := tracing.StartSpan(options.NewOperationOptions(0, Options...).Context,
+		"ssh_config.UploadSshKeysToMany", tracing.AttributeOperation.String("UploadSshKeysToMany"))
+	UploadOptions := append(append([]options.OperationOption{}, Options...), options.WithContext(SpanContext))
+	LimitError := this.checkRateLimit(CustomerID)
+
+	Errors := map[string]error{}
+	var ErrorsMutex sync.Mutex
+	Semaphore := make(chan struct{}, Concurrency)
+	var Group sync.WaitGroup
+
+	for _, VirtualMachine := range VirtualMachines {
+		Group.Add(1)
+		Semaphore <- struct{}{}
+		go func(VirtualMachine *object.VirtualMachine) {
+			defer Group.Done()
+			defer func() { <-Semaphore }()
+
+			UploadError := LimitError
+			if UploadError == nil {
+				UploadError = this.tracedUploadSshKeys(options.NewOperationOptions(this.Timeouts.Upload, UploadOptions...), VirtualMachine, Key)
+			}
+			if UploadError != nil {
+				ErrorsMutex.Lock()
+				defer ErrorsMutex.Unlock()
+				Errors[virtualMachineName(VirtualMachine)] = UploadError
+			}
+		}(VirtualMachine)
+	}
+	Group.Wait()
+
+	var BatchError error
+	if len(Errors) != 0 {
+		BatchError = fmt.Errorf("Failed to Upload SSH Key to %d of %d Virtual Machines", len(Errors), len(VirtualMachines))
+	}
+	tracing.EndSpan(Span, BatchError)
+
+	Logger.Info("SSH Key has been Uploaded to the Virtual Machines", zap.String("Key", Key.FileName),
+		zap.Int("Virtual Machines", len(VirtualMachines)), zap.Int("Failed", len(Errors)))
+	return Errors
+}
+
+func virtualMachineName(VirtualMachine *object.VirtualMachine) string {
+	// Returns Name of the Virtual Machine from its Inventory Path, or its Reference, if the Path is Unknown
+	if len(VirtualMachine.InventoryPath) == 0 {
+		return VirtualMachine.Reference().Value
+	}
+	return VirtualMachine.Name()
+}
@@ -0,0 +1,92 @@
+package ssh_config
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrCertificateNotInstalled = errors.New("No Certificate is Installed on the Host System")
+	ErrInvalidHostCertificate  = errors.New("Certificate of the Host System is not a Valid PEM")
+)
+
+func (this *VirtualMachineSshCertificateManager) ExportPublicCertificatePEM(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) ([]byte, string, error) {
+	// Returns PEM-Encoded Certificate of the Host, the Virtual Machine is Running on, along with the Suggested File Name,
+	// so it can be Streamed by the Rest Controller as the File Download
+
+	TimeoutContext, CancelFunc := options.NewOperationOptions(this.Timeouts.Certificate, Options...).NewContext()
+	defer CancelFunc()
+
+	Block, HostName, CertificateError := this.hostCertificate(TimeoutContext, VirtualMachine)
+	if CertificateError != nil {
+		return nil, "", CertificateError
+	}
+	return pem.EncodeToMemory(Block), SanitizeFileName(HostName) + ".pem", nil
+}
+
+func (this *VirtualMachineSshCertificateManager) GetCertificateExpiry(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) (time.Time, error) {
+	// Returns the Time, the Certificate of the Host, the Virtual Machine is Running on, Expires at
+
+	TimeoutContext, CancelFunc := options.NewOperationOptions(this.Timeouts.Certificate, Options...).NewContext()
+	defer CancelFunc()
+
+	Block, _, CertificateError := this.hostCertificate(TimeoutContext, VirtualMachine)
+	if CertificateError != nil {
+		return time.Time{}, CertificateError
+	}
+	Certificate, ParseError := x509.ParseCertificate(Block.Bytes)
+	if ParseError != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidHostCertificate, ParseError)
+	}
+	return Certificate.NotAfter, nil
+}
+
+func (this *VirtualMachineSshCertificateManager) IsCertificateExpiringWithin(VirtualMachine *object.VirtualMachine, Duration time.Duration, Options ...options.OperationOption) (bool, error) {
+	// Returns True if the Certificate of the Host Expires within the Duration (or has Expired already),
+	// so it can be Rotated Ahead of Time
+	NotAfter, ExpiryError := this.GetCertificateExpiry(VirtualMachine, Options...)
+	if ExpiryError != nil {
+		return false, ExpiryError
+	}
+	return NotAfter.Before(time.Now().Add(Duration)), nil
+}
+
+func (this *VirtualMachineSshCertificateManager) hostCertificate(Context context.Context, VirtualMachine *object.VirtualMachine) (*pem.Block, string, error) {
+	// Returns PEM Block of the Certificate of the Host, the Virtual Machine is Running on, along with the Host Name
+	// `ErrCertificateNotInstalled` or `ErrInvalidHostCertificate` is Returned, if the Host has no Valid Certificate
+
+	HostSystem, FindError := VirtualMachine.HostSystem(Context)
+	if FindError != nil {
+		Logger.Error("Failed to Get Host System of the Virtual Machine", zap.Error(FindError))
+		return nil, "", FindError
+	}
+
+	var MoHostSystem mo.HostSystem
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(Context, HostSystem.Reference(),
+		[]string{"name", "config.certificate"}, &MoHostSystem); RetrieveError != nil {
+		Logger.Error("Failed to Retrieve Certificate of the Host System",
+			zap.String("Host", HostSystem.Reference().Value), zap.Error(RetrieveError))
+		return nil, "", RetrieveError
+	}
+	if MoHostSystem.Config == nil || len(MoHostSystem.Config.Certificate) == 0 {
+		return nil, "", ErrCertificateNotInstalled
+	}
+
+	// Only the Certificate Block is Used, so the Extra Data around it, Returned by the Host, is Ignored
+	Block, _ := pem.Decode(MoHostSystem.Config.Certificate)
+	if Block == nil || Block.Type != "CERTIFICATE" {
+		return nil, "", ErrInvalidHostCertificate
+	}
+	return Block, MoHostSystem.Name, nil
+}
@@ -0,0 +1,67 @@
+package ssh_config
+
+import (
+	"errors"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"go.uber.org/zap"
+)
+
+var (
+	// Returned, if the Guest has not Reported the IP Address yet (e.g VMware Tools are not Running)
+	ErrGuestIPAddressNotReported = errors.New("Guest has not Reported the IP Address of the Virtual Machine")
+)
+
+func (this *VirtualMachineSshCertificateManager) GetGuestIPAddress(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) (string, error) {
+	// Returns Current IP Address of the Virtual Machine, Reported by the Guest OS, unlike the Stored `IPAddress` Column,
+	// which can be Stale, Empty String and `ErrGuestIPAddressNotReported` is Returned, if there is no one yet
+
+	TimeoutContext, CancelFunc := options.NewOperationOptions(this.Timeouts.Query, Options...).NewContext()
+	defer CancelFunc()
+
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(TimeoutContext, VirtualMachine.Reference(),
+		[]string{"guest.ipAddress"}, &MoVirtualMachine); RetrieveError != nil {
+		Logger.Error("Failed to Retrieve Guest IP Address", zap.String("Virtual Machine",
+			VirtualMachine.Reference().Value), zap.Error(RetrieveError))
+		return "", RetrieveError
+	}
+	if MoVirtualMachine.Guest == nil || len(MoVirtualMachine.Guest.IpAddress) == 0 {
+		return "", ErrGuestIPAddressNotReported
+	}
+	return MoVirtualMachine.Guest.IpAddress, nil
+}
+
+func (this *VirtualMachineSshCertificateManager) SyncGuestIPAddress(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) (string, error) {
+	// Saves Current IP Address, Reported by the Guest OS, to the Database Record of the Virtual Machine and Returns it
+	// Record is Left Untouched, if the Guest has not Reported the IP Address yet
+
+	IPAddress, AddressError := this.GetGuestIPAddress(VirtualMachine, Options...)
+	if AddressError != nil {
+		return "", AddressError
+	}
+
+	TimeoutContext, CancelFunc := options.NewOperationOptions(this.Timeouts.Query, Options...).NewContext()
+	defer CancelFunc()
+
+	Record, RecordError := this.getVirtualMachineRecord(TimeoutContext, VirtualMachine)
+	if RecordError != nil {
+		return "", RecordError
+	}
+	if Record.IPAddress == IPAddress {
+		return IPAddress, nil
+	}
+	if UpdateError := models.SetVirtualMachineIPAddress(Record.ID, IPAddress); UpdateError != nil {
+		Logger.Error("Failed to Update IP Address of the Virtual Machine", zap.Int("Virtual Machine ID", Record.ID),
+			zap.String("IP Address", IPAddress), zap.Error(UpdateError))
+		return "", UpdateError
+	}
+	Logger.Debug("IP Address of the Virtual Machine has been Synced", zap.Int("Virtual Machine ID", Record.ID),
+		zap.String("From", Record.IPAddress), zap.String("To", IPAddress))
+	return IPAddress, nil
+}
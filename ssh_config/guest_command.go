@@ -0,0 +1,58 @@
+package ssh_config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/LovePelmeni/Infrastructure/guest"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+
+	guestops "github.com/vmware/govmomi/guest"
+)
+
+var (
+	ErrGuestProgramRequired = errors.New("Path of the Guest Program is Required")
+)
+
+func (this *VirtualMachineSshRootCredentialsManager) RunGuestCommand(VirtualMachine *object.VirtualMachine, Auth *types.NamePasswordAuthentication, Program string, Arguments string, Options ...options.OperationOption) (int64, error) {
+	// Starts the Program inside the Guest OS of the Virtual Machine via VMware Tools and Returns its PID,
+	// Program is not being Waited for, so the Caller can Track it by the PID, if needed
+	// Usually the Credentials of `GetSshRootCredentials` are Used, `guest.ErrToolsNotRunning` is Returned if the Tools are Down
+
+	if len(Program) == 0 {
+		return 0, ErrGuestProgramRequired
+	}
+	if Auth == nil {
+		return 0, errors.New("Guest Credentials are Required")
+	}
+
+	Running, ToolsError := guest.NewVirtualMachineGuestManager(this.Client).IsToolsRunning(VirtualMachine)
+	if ToolsError != nil {
+		return 0, ToolsError
+	}
+	if !Running {
+		return 0, fmt.Errorf("%w, `%s` can't be Started", guest.ErrToolsNotRunning, Program)
+	}
+
+	TimeoutContext, CancelFunc := options.NewOperationOptions(this.Timeouts.Guest, Options...).NewContext()
+	defer CancelFunc()
+
+	OperationsManager := guestops.NewOperationsManager(&this.Client, VirtualMachine.Reference())
+	ProcessManager, ManagerError := OperationsManager.ProcessManager(TimeoutContext)
+	if ManagerError != nil {
+		return 0, ManagerError
+	}
+	ProcessID, StartError := ProcessManager.StartProgram(TimeoutContext, Auth, &types.GuestProgramSpec{
+		ProgramPath: Program,
+		Arguments:   Arguments,
+	})
+	if StartError != nil {
+		Logger.Error("Failed to Start Guest Program", zap.String("Program", Program), zap.Error(StartError))
+		return 0, StartError
+	}
+	Logger.Debug("Guest Program has been Started", zap.String("Program", Program), zap.Int64("PID", ProcessID))
+	return ProcessID, nil
+}
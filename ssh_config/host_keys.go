@@ -0,0 +1,106 @@
+package ssh_config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/LovePelmeni/Infrastructure/guest"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+
+	guestops "github.com/vmware/govmomi/guest"
+)
+
+var (
+	// Public Host Keys, that are being Checked in the Order of the Preference
+	HostKeyFiles = []string{
+		"/etc/ssh/ssh_host_ed25519_key.pub",
+		"/etc/ssh/ssh_host_ecdsa_key.pub",
+		"/etc/ssh/ssh_host_rsa_key.pub",
+	}
+)
+
+const maxHostKeySize = 16 * 1024
+
+func (this *VirtualMachineSshRootCredentialsManager) GetHostKeyFingerprint(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) (string, error) {
+	// Returns SHA256 Fingerprint of the SSH Host Key of the Virtual Machine (Like `SHA256:...`), so it can be put into `known_hosts`
+	// The Public Host Key is being Read from the Guest File System via VMware Tools, using the Root Credentials
+
+	Running, ToolsError := guest.NewVirtualMachineGuestManager(this.Client).IsToolsRunning(VirtualMachine)
+	if ToolsError != nil {
+		return "", ToolsError
+	}
+	if !Running {
+		return "", guest.ErrToolsNotRunning
+	}
+
+	Credentials, CredentialsError := this.GetSshRootCredentials(VirtualMachine, Options...)
+	if CredentialsError != nil {
+		return "", CredentialsError
+	}
+
+	TimeoutContext, CancelFunc := options.NewOperationOptions(this.Timeouts.Guest, Options...).NewContext()
+	defer CancelFunc()
+
+	OperationsManager := guestops.NewOperationsManager(&this.Client, VirtualMachine.Reference())
+	FileManager, ManagerError := OperationsManager.FileManager(TimeoutContext)
+	if ManagerError != nil {
+		return "", ManagerError
+	}
+
+	for _, HostKeyFile := range HostKeyFiles {
+		Content, ReadError := this.readGuestFile(TimeoutContext, FileManager, Credentials, HostKeyFile, maxHostKeySize)
+		if ReadError != nil {
+			Logger.Debug("Failed to Read Host Key", zap.String("File", HostKeyFile), zap.Error(ReadError))
+			continue
+		}
+		PublicKey, _, _, _, ParseError := ssh.ParseAuthorizedKey(Content)
+		if ParseError != nil {
+			return "", fmt.Errorf("Host Key `%s` is Malformed: %w", HostKeyFile, ParseError)
+		}
+		return ssh.FingerprintSHA256(PublicKey), nil
+	}
+	return "", errors.New("Virtual Machine does not have any SSH Host Key")
+}
+
+func (this *VirtualMachineSshRootCredentialsManager) readGuestFile(Context context.Context, FileManager *guestops.FileManager, Credentials *types.NamePasswordAuthentication, Path string, Limit int64) ([]byte, error) {
+	// Downloads Small File from the Guest File System, Content beyond the Limit is not being Read
+
+	TransferInfo, TransferError := FileManager.InitiateFileTransferFromGuest(Context, Credentials, Path)
+	if TransferError != nil {
+		return nil, TransferError
+	}
+	URL, URLError := FileManager.TransferURL(Context, TransferInfo.Url)
+	if URLError != nil {
+		return nil, URLError
+	}
+	Reader, _, DownloadError := this.Client.Download(Context, URL, &soap.DefaultDownload)
+	if DownloadError != nil {
+		return nil, DownloadError
+	}
+	defer Reader.Close()
+	return io.ReadAll(io.LimitReader(Reader, Limit))
+}
+
+func (this *VirtualMachineSshRootCredentialsManager) writeGuestFile(Context context.Context, FileManager *guestops.FileManager, Credentials *types.NamePasswordAuthentication, Path string, Content []byte) error {
+	// Uploads Small File to the Guest File System, Readable only by its Owner, the Existing File is Overwritten
+	TransferURL, TransferError := FileManager.InitiateFileTransferToGuest(Context, Credentials, Path,
+		&types.GuestPosixFileAttributes{Permissions: 0600}, int64(len(Content)), true)
+	if TransferError != nil {
+		return TransferError
+	}
+	URL, URLError := FileManager.TransferURL(Context, TransferURL)
+	if URLError != nil {
+		return URLError
+	}
+	Upload := soap.DefaultUpload
+	Upload.ContentLength = int64(len(Content))
+	return this.Client.Upload(Context, bytes.NewReader(Content), URL, &Upload)
+}
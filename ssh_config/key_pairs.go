@@ -0,0 +1,121 @@
+package ssh_config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	KeyAlgorithmEd25519 = "ed25519"
+	KeyAlgorithmRSA     = "rsa"
+)
+
+const RSAKeySize = 3072 // Bits of the Generated RSA Keys
+
+var (
+	ErrUnsupportedKeyAlgorithm = errors.New("Unsupported SSH Key Algorithm")
+)
+
+func (this *VirtualMachineSshCertificateManager) GenerateSshKeyPair(Algorithm string) (*SshCertificateCredentials, error) {
+	// Generates new SSH Key Pair of the Algorithm (`ed25519` or `rsa`), Unlike the `GenerateSshKeys`, which Returns
+	// the Certificate Signing Request of the Host, the Key Pair can be Used by the Customer Directly:
+	// `Content` is the Public Key in the `authorized_keys` Format and `PrivateKey` is the PEM Encoded Private Key
+	return generateSshKeyPair(Algorithm)
+}
+
+func generateSshKeyPair(Algorithm string) (*SshCertificateCredentials, error) {
+	// Generates new SSH Key Pair of the Algorithm (See `GenerateSshKeyPair`)
+
+	var PublicKey interface{}
+	var PrivateKey *pem.Block
+
+	switch strings.ToLower(Algorithm) {
+	case KeyAlgorithmEd25519:
+		Public, Private, GenerateError := ed25519.GenerateKey(rand.Reader)
+		if GenerateError != nil {
+			return nil, GenerateError
+		}
+		Block, MarshalError := marshalEd25519PrivateKey(Private)
+		if MarshalError != nil {
+			return nil, MarshalError
+		}
+		PublicKey, PrivateKey = Public, Block
+
+	case KeyAlgorithmRSA:
+		Private, GenerateError := rsa.GenerateKey(rand.Reader, RSAKeySize)
+		if GenerateError != nil {
+			return nil, GenerateError
+		}
+		PublicKey = &Private.PublicKey
+		PrivateKey = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(Private)}
+
+	default:
+		return nil, fmt.Errorf("%w: `%s`, Supported are `%s` and `%s`",
+			ErrUnsupportedKeyAlgorithm, Algorithm, KeyAlgorithmEd25519, KeyAlgorithmRSA)
+	}
+
+	SshPublicKey, PublicKeyError := ssh.NewPublicKey(PublicKey)
+	if PublicKeyError != nil {
+		Logger.Error("Failed to Encode SSH Public Key", zap.String("Algorithm", Algorithm), zap.Error(PublicKeyError))
+		return nil, PublicKeyError
+	}
+
+	Credentials := NewSshCertificateCredentials(ssh.MarshalAuthorizedKey(SshPublicKey),
+		fmt.Sprintf("id_%s.pub", strings.ToLower(Algorithm)))
+	Credentials.PrivateKey = pem.EncodeToMemory(PrivateKey)
+	return Credentials, nil
+}
+
+func marshalEd25519PrivateKey(Key ed25519.PrivateKey) (*pem.Block, error) {
+	// Returns Unencrypted Private Key in the OpenSSH Format (`openssh-key-v1`),
+	// Ed25519 Keys are not Accepted by the OpenSSH in the PKCS #1 / PKCS #8 Formats
+
+	var Check [4]byte
+	if _, RandomError := rand.Read(Check[:]); RandomError != nil {
+		return nil, RandomError
+	}
+	CheckValue := binary.BigEndian.Uint32(Check[:])
+	Public := Key.Public().(ed25519.PublicKey)
+
+	PrivateBlock := struct {
+		Check1  uint32
+		Check2  uint32
+		KeyType string
+		Public  []byte
+		Private []byte
+		Comment string
+		Padding []byte `ssh:"rest"`
+	}{CheckValue, CheckValue, ssh.KeyAlgoED25519, Public, Key, "", nil}
+
+	// Private Block is Padded to the Block Size of the Cipher (8 for the `none` one) with the 1, 2, 3, ... Bytes
+	for Index := 0; (len(ssh.Marshal(PrivateBlock)))%8 != 0; Index++ {
+		PrivateBlock.Padding = append(PrivateBlock.Padding, byte(Index+1))
+	}
+
+	Envelope := struct {
+		CipherName   string
+		KdfName      string
+		KdfOptions   string
+		NumberOfKeys uint32
+		PublicKey    []byte
+		PrivateBlock []byte
+	}{"none", "none", "", 1, ssh.Marshal(struct {
+		KeyType string
+		Public  []byte
+	}{ssh.KeyAlgoED25519, Public}), ssh.Marshal(PrivateBlock)}
+
+	return &pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte("openssh-key-v1\x00"), ssh.Marshal(Envelope)...),
+	}, nil
+}
@@ -0,0 +1,135 @@
+package ssh_config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/LovePelmeni/Infrastructure/guest"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+
+	guestops "github.com/vmware/govmomi/guest"
+)
+
+// Implementation of the `VirtualMachineSshManagerInterface` by both SSH Managers
+
+const RootAuthorizedKeysFile = "/root/.ssh/authorized_keys"
+const maxAuthorizedKeysSize = 1024 * 1024
+
+func (this *VirtualMachineSshCertificateManager) Type() string {
+	return models.TypeByRootCertificate
+}
+
+func (this *VirtualMachineSshCertificateManager) GenerateKeys(VirtualMachine *object.VirtualMachine, VirtualMachineId string) (*SshCertificateCredentials, error) {
+	// Returns Certificate Signing Request of the Host (See `GenerateSshKeys`)
+	return this.GenerateSshKeys(VirtualMachine, VirtualMachineId)
+}
+
+func (this *VirtualMachineSshCertificateManager) UploadKeys(VirtualMachine *object.VirtualMachine, Key SshCertificateCredentials, CustomerID int, Options ...options.OperationOption) error {
+	// Installs the Certificate on the Host (See `UploadSshKeys`)
+	return this.UploadSshKeys(VirtualMachine, Key, CustomerID, Options...)
+}
+
+func (this *VirtualMachineSshRootCredentialsManager) Type() string {
+	return models.TypeByRootCredentials
+}
+
+func (this *VirtualMachineSshRootCredentialsManager) GenerateKeys(VirtualMachine *object.VirtualMachine, VirtualMachineId string) (*SshCertificateCredentials, error) {
+	// Returns new Ed25519 Key Pair, the Public Key of which can be Added to the `authorized_keys` of the Root User
+	Key, GenerateError := generateSshKeyPair(KeyAlgorithmEd25519)
+	if GenerateError != nil {
+		return nil, GenerateError
+	}
+	Key.FileName = SshKeyFileName(VirtualMachine.Name())
+	return Key, nil
+}
+
+func (this *VirtualMachineSshRootCredentialsManager) UploadKeys(VirtualMachine *object.VirtualMachine, Key SshCertificateCredentials, CustomerID int, Options ...options.OperationOption) error {
+	// Appends the Public Key to the `authorized_keys` of the Root User via VMware Tools, using the Root Credentials
+	// Key, that is already Authorized, is not being Added Twice, Options are Applied to the Credentials Lookup as well
+
+	if CustomerID <= 0 {
+		return fmt.Errorf("%w: Customer %d", ErrCustomerRequired, CustomerID)
+	}
+	if ValidationError := models.ValidateSshPublicKey(Key.Content); ValidationError != nil {
+		return ValidationError
+	}
+	Running, ToolsError := guest.NewVirtualMachineGuestManager(this.Client).IsToolsRunning(VirtualMachine)
+	if ToolsError != nil {
+		return ToolsError
+	}
+	if !Running {
+		return guest.ErrToolsNotRunning
+	}
+	Credentials, CredentialsError := this.GetSshRootCredentials(VirtualMachine, Options...)
+	if CredentialsError != nil {
+		return CredentialsError
+	}
+
+	TimeoutContext, CancelFunc := options.NewOperationOptions(this.Timeouts.Upload, Options...).NewContext()
+	defer CancelFunc()
+
+	FileManager, ManagerError := guestops.NewOperationsManager(&this.Client, VirtualMachine.Reference()).FileManager(TimeoutContext)
+	if ManagerError != nil {
+		return ManagerError
+	}
+	if DirectoryError := FileManager.MakeDirectory(TimeoutContext, Credentials, "/root/.ssh", true); DirectoryError != nil &&
+		!isGuestFileAlreadyExists(DirectoryError) {
+		return DirectoryError
+	}
+
+	AuthorizedKeys, ReadError := this.readGuestFile(TimeoutContext, FileManager, Credentials, RootAuthorizedKeysFile, maxAuthorizedKeysSize)
+	if ReadError != nil && !isGuestFileNotFound(ReadError) {
+		return ReadError
+	}
+	if len(AuthorizedKeys) >= maxAuthorizedKeysSize {
+		return errors.New("`authorized_keys` of the Root User is too Large to be Updated")
+	}
+	PublicKey := bytes.TrimSpace(Key.Content)
+	for _, Line := range bytes.Split(AuthorizedKeys, []byte("\n")) {
+		if bytes.Equal(bytes.TrimSpace(Line), PublicKey) {
+			return nil
+		}
+	}
+	if len(AuthorizedKeys) != 0 && !bytes.HasSuffix(AuthorizedKeys, []byte("\n")) {
+		AuthorizedKeys = append(AuthorizedKeys, '\n')
+	}
+	AuthorizedKeys = append(append(AuthorizedKeys, PublicKey...), '\n')
+
+	if UploadError := this.writeGuestFile(TimeoutContext, FileManager, Credentials, RootAuthorizedKeysFile, AuthorizedKeys); UploadError != nil {
+		Logger.Error("Failed to Update `authorized_keys` of the Root User", zap.Error(UploadError))
+		return UploadError
+	}
+	Logger.Debug("SSH Key has been Authorized for the Root User", zap.String("Virtual Machine", VirtualMachine.Name()),
+		zap.String("Key", Key.FileName))
+	return nil
+}
+
+func isGuestFileNotFound(Error error) bool {
+	// Returns True if the Error is the `FileNotFound` Fault of the Guest File System
+	if !soap.IsSoapFault(Error) {
+		return false
+	}
+	switch soap.ToSoapFault(Error).VimFault().(type) {
+	case types.FileNotFound, *types.FileNotFound:
+		return true
+	}
+	return false
+}
+
+func isGuestFileAlreadyExists(Error error) bool {
+	// Returns True if the Error is the `FileAlreadyExists` Fault of the Guest File System
+	if !soap.IsSoapFault(Error) {
+		return false
+	}
+	switch soap.ToSoapFault(Error).VimFault().(type) {
+	case types.FileAlreadyExists, *types.FileAlreadyExists:
+		return true
+	}
+	return false
+}
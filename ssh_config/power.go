@@ -0,0 +1,135 @@
+package ssh_config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrAlreadyPoweredOn  = errors.New("Virtual Machine is Already Powered On")
+	ErrAlreadyPoweredOff = errors.New("Virtual Machine is Already Powered Off")
+	ErrNotPoweredOn      = errors.New("Virtual Machine is not Powered On")
+
+	// Returned, if the Virtual Machine Reference is no longer Valid (e.g the Virtual Machine has been Deleted)
+	ErrVirtualMachineNotFound = errors.New("Virtual Machine does not Exist")
+)
+
+type VirtualMachinePowerManager struct {
+	// Manager Class, that Controls the Power State of the Virtual Machine Server
+	Client   vim25.Client
+	Timeouts Timeouts
+}
+
+func NewVirtualMachinePowerManager(Client vim25.Client) *VirtualMachinePowerManager {
+	return &VirtualMachinePowerManager{
+		Client:   Client,
+		Timeouts: DefaultTimeouts(),
+	}
+}
+
+func (this *VirtualMachinePowerManager) GetPowerState(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) (types.VirtualMachinePowerState, error) {
+	// Returns Power State of the Virtual Machine (`runtime.powerState`)
+	TimeoutContext, CancelFunc := options.NewOperationOptions(this.Timeouts.Query, Options...).NewContext()
+	defer CancelFunc()
+	return this.getPowerState(TimeoutContext, VirtualMachine)
+}
+
+func (this *VirtualMachinePowerManager) getPowerState(Context context.Context, VirtualMachine *object.VirtualMachine) (types.VirtualMachinePowerState, error) {
+	// Returns Power State of the Virtual Machine, `ErrVirtualMachineNotFound` if the Reference is no longer Valid
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(Context, VirtualMachine.Reference(),
+		[]string{"runtime.powerState"}, &MoVirtualMachine); RetrieveError != nil {
+		if isManagedObjectNotFound(RetrieveError) {
+			return "", fmt.Errorf("%w: %s", ErrVirtualMachineNotFound, VirtualMachine.Reference().Value)
+		}
+		Logger.Error("Failed to Retrieve Power State", zap.String("Virtual Machine",
+			VirtualMachine.Reference().Value), zap.Error(RetrieveError))
+		return "", RetrieveError
+	}
+	return MoVirtualMachine.Runtime.PowerState, nil
+}
+
+func isManagedObjectNotFound(Error error) bool {
+	// Returns True if the Error is the `ManagedObjectNotFound` Fault of the vSphere
+	if !soap.IsSoapFault(Error) {
+		return false
+	}
+	_, NotFound := soap.ToSoapFault(Error).VimFault().(types.ManagedObjectNotFound)
+	return NotFound
+}
+
+func (this *VirtualMachinePowerManager) PowerOn(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) error {
+	// Powers On the Virtual Machine and Waits until the Task is Completed, `ErrAlreadyPoweredOn` if it is Running Already
+	return this.runPowerTask(VirtualMachine, "Power On", func(Context context.Context, State types.VirtualMachinePowerState) (*object.Task, error) {
+		if State == types.VirtualMachinePowerStatePoweredOn {
+			return nil, ErrAlreadyPoweredOn
+		}
+		return VirtualMachine.PowerOn(Context)
+	}, Options...)
+}
+
+func (this *VirtualMachinePowerManager) PowerOff(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) error {
+	// Powers Off the Virtual Machine (Without the Guest OS Shutdown) and Waits until the Task is Completed,
+	// `ErrAlreadyPoweredOff` if it is not Running Already
+	return this.runPowerTask(VirtualMachine, "Power Off", func(Context context.Context, State types.VirtualMachinePowerState) (*object.Task, error) {
+		if State == types.VirtualMachinePowerStatePoweredOff {
+			return nil, ErrAlreadyPoweredOff
+		}
+		return VirtualMachine.PowerOff(Context)
+	}, Options...)
+}
+
+func (this *VirtualMachinePowerManager) Reboot(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) error {
+	// Resets the Virtual Machine and Waits until the Task is Completed, Virtual Machine should be Powered On
+	// (See `guest.RebootGuest` for the Graceful Reboot through the VMware Tools)
+	return this.runPowerTask(VirtualMachine, "Reboot", func(Context context.Context, State types.VirtualMachinePowerState) (*object.Task, error) {
+		if State != types.VirtualMachinePowerStatePoweredOn {
+			return nil, ErrNotPoweredOn
+		}
+		return VirtualMachine.Reset(Context)
+	}, Options...)
+}
+
+func (this *VirtualMachinePowerManager) runPowerTask(VirtualMachine *object.VirtualMachine, Operation string,
+	Start func(Context context.Context, State types.VirtualMachinePowerState) (*object.Task, error), Options ...options.OperationOption) error {
+	// Starts the Power Task with the Current Power State of the Virtual Machine and Waits for it within the Timeout
+
+	Settings := options.NewOperationOptions(this.Timeouts.PowerTask, Options...)
+	TimeoutContext, CancelFunc := Settings.NewContext()
+	defer CancelFunc()
+
+	Release, LockError := Settings.LockVirtualMachine(TimeoutContext, VirtualMachine.Reference())
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
+	State, StateError := this.getPowerState(TimeoutContext, VirtualMachine)
+	if StateError != nil {
+		return StateError
+	}
+
+	Task, StartError := Start(TimeoutContext, State)
+	if StartError != nil {
+		Logger.Debug("Failed to Start Power Task", zap.String("Operation", Operation),
+			zap.String("Virtual Machine", VirtualMachine.Reference().Value), zap.Error(StartError))
+		return StartError
+	}
+	if WaitError := Task.Wait(TimeoutContext); WaitError != nil {
+		Logger.Error("Power Task has Failed", zap.String("Operation", Operation),
+			zap.String("Virtual Machine", VirtualMachine.Reference().Value), zap.Error(WaitError))
+		return WaitError
+	}
+	return nil
+}
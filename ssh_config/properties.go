@@ -0,0 +1,38 @@
+package ssh_config
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type PropertyErrors map[string]error // Errors of the Properties, that could not be Retrieved, by the Property Path
+
+func RetrievePartialProperties(Context context.Context, Client *vim25.Client, Reference types.ManagedObjectReference, Properties []string, Destination interface{}) (PropertyErrors, error) {
+	// Retrieves Properties of the Managed Object into the Destination (e.g `*mo.VirtualMachine`),
+	// Unlike `RetrieveOne`, Properties that are Missing or Inaccessible do not fail the whole Call,
+	// They are being Returned in the `PropertyErrors` instead, and the rest of the Properties are Loaded as usual
+	// Error is being Returned only if the Call itself has Failed
+
+	var Contents []types.ObjectContent
+	Collector := property.DefaultCollector(Client)
+	if RetrieveError := Collector.Retrieve(Context, []types.ManagedObjectReference{Reference}, Properties, &Contents); RetrieveError != nil {
+		return nil, RetrieveError
+	}
+
+	Missing := PropertyErrors{}
+	for Index := range Contents {
+		for _, MissingProperty := range Contents[Index].MissingSet {
+			Missing[MissingProperty.Path] = soap.WrapVimFault(MissingProperty.Fault.Fault)
+		}
+		Contents[Index].MissingSet = nil
+	}
+	if LoadError := mo.LoadObjectContent(Contents, Destination); LoadError != nil {
+		return nil, LoadError
+	}
+	return Missing, nil
+}
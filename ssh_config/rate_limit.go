@@ -0,0 +1,94 @@
+package ssh_config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// SSH Key Operations (Uploads, Rotations) are Limited per Customer by the Token Bucket,
+// so a Single Customer can't Flood the vCenter with Certificate Installations
+// Rate is Configured by the Environment: `SSH_KEY_OPERATIONS_PER_MINUTE` - Rate, the Bucket is Refilled with,
+// `SSH_KEY_OPERATIONS_BURST` - Max Amount of the Operations in a Row
+
+const DefaultSshKeyOperationsPerMinute = 10
+const DefaultSshKeyOperationsBurst = 5
+
+var (
+	ErrRateLimited      = errors.New("Too many SSH Key Operations, Try again Later")
+	ErrCustomerRequired = errors.New("SSH Key Operation has to be Performed on behalf of the Customer")
+)
+
+var (
+	DefaultRateLimiter = NewRateLimiterFromEnvironment()
+)
+
+type RateLimiter struct {
+	// Token Bucket Rate Limiter, that Keeps a Separate Bucket for every Customer
+	Limit rate.Limit
+	Burst int
+
+	mutex    sync.Mutex
+	limiters map[uint]*rate.Limiter
+}
+
+func NewRateLimiter(Limit rate.Limit, Burst int) *RateLimiter {
+	// Returns Rate Limiter, that Allows `Burst` Operations in a Row and `Limit` Operations per Second after that
+	return &RateLimiter{
+		Limit:    Limit,
+		Burst:    Burst,
+		limiters: map[uint]*rate.Limiter{},
+	}
+}
+
+func NewRateLimiterFromEnvironment() *RateLimiter {
+	// Returns Rate Limiter, Configured by the `SSH_KEY_OPERATIONS_PER_MINUTE` and `SSH_KEY_OPERATIONS_BURST`,
+	// Invalid or Missing Values are Replaced with the Defaults
+	PerMinute := DefaultSshKeyOperationsPerMinute
+	if Value, ParseError := strconv.Atoi(os.Getenv("SSH_KEY_OPERATIONS_PER_MINUTE")); ParseError == nil && Value > 0 {
+		PerMinute = Value
+	}
+	Burst := DefaultSshKeyOperationsBurst
+	if Value, ParseError := strconv.Atoi(os.Getenv("SSH_KEY_OPERATIONS_BURST")); ParseError == nil && Value > 0 {
+		Burst = Value
+	}
+	return NewRateLimiter(rate.Every(time.Minute/time.Duration(PerMinute)), Burst)
+}
+
+func (this *RateLimiter) CheckLimit(CustomerID uint) error {
+	// Takes the Token from the Bucket of the Customer, `ErrRateLimited` is Returned, if the Bucket is Empty
+	this.mutex.Lock()
+	Limiter, Exists := this.limiters[CustomerID]
+	if !Exists {
+		Limiter = rate.NewLimiter(this.Limit, this.Burst)
+		this.limiters[CustomerID] = Limiter
+	}
+	this.mutex.Unlock()
+
+	if !Limiter.Allow() {
+		return fmt.Errorf("%w: Customer %d", ErrRateLimited, CustomerID)
+	}
+	return nil
+}
+
+func (this *VirtualMachineSshCertificateManager) checkRateLimit(CustomerID int) error {
+	// Checks the Limit of the Customer, the Operation is Performed on behalf of,
+	// Operations without the Customer are Rejected with the `ErrCustomerRequired`, so nothing Bypasses the Limit
+	if CustomerID <= 0 {
+		return fmt.Errorf("%w: Customer %d", ErrCustomerRequired, CustomerID)
+	}
+	if this.RateLimiter == nil {
+		return nil
+	}
+	if LimitError := this.RateLimiter.CheckLimit(uint(CustomerID)); LimitError != nil {
+		Logger.Warn("SSH Key Operation has been Rate Limited", zap.Int("Customer ID", CustomerID))
+		return LimitError
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package ssh_config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/guest"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+
+	guestops "github.com/vmware/govmomi/guest"
+)
+
+// The Root Password is Generated by the Service, so the Guest OS does not know it until it is Set there:
+// `ProvisionRootPassword` Sets it with the Bootstrap Credentials of the Template (`chpasswd` via VMware Tools)
+// and only then Saves it to the Secret Store, `GetSshRootCredentials` Returns only the Passwords, that have been Set
+
+var (
+	ErrBootstrapCredentialsRequired = errors.New("Bootstrap Credentials of the Guest are not Configured")
+	ErrRootPasswordNotProvisioned   = errors.New("Root Password has not been Set in the Guest yet")
+	ErrRootPasswordNotSet           = errors.New("Failed to Set the Root Password in the Guest")
+)
+
+const guestProcessPollInterval = time.Second // Delay between the Checks of the Guest Process Status
+
+func NewBootstrapCredentialsFromEnvironment() *types.NamePasswordAuthentication {
+	// Returns Credentials of the Template Guest OS, Configured by the `GUEST_BOOTSTRAP_USERNAME` (`root` by default)
+	// and `GUEST_BOOTSTRAP_PASSWORD`, Nil if the Password is not Configured
+	Password := os.Getenv("GUEST_BOOTSTRAP_PASSWORD")
+	if len(Password) == 0 {
+		return nil
+	}
+	Username := os.Getenv("GUEST_BOOTSTRAP_USERNAME")
+	if len(Username) == 0 {
+		Username = "root"
+	}
+	return &types.NamePasswordAuthentication{Username: Username, Password: Password}
+}
+
+func (this *VirtualMachineSshRootCredentialsManager) ProvisionRootPassword(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) error {
+	// Generates new Root Password, Sets it in the Guest OS and Saves it to the Secret Store,
+	// The Password is Changed by the `chpasswd`, Started with the Bootstrap Credentials, so the Bootstrap User should be Privileged
+	// The Password is Passed through the Temporary File, so it does not Appear in the Arguments of the Guest Process
+	// Nothing is Saved, if the Password has not been Set, `ErrRootPasswordNotSet` is Returned if `chpasswd` has Failed
+
+	if this.Secrets == nil {
+		return ErrSecretStoreNotConfigured
+	}
+	if this.Bootstrap == nil {
+		return ErrBootstrapCredentialsRequired
+	}
+	Running, ToolsError := guest.NewVirtualMachineGuestManager(this.Client).IsToolsRunning(VirtualMachine)
+	if ToolsError != nil {
+		return ToolsError
+	}
+	if !Running {
+		return fmt.Errorf("%w, Root Password can't be Set", guest.ErrToolsNotRunning)
+	}
+
+	TimeoutContext, CancelFunc := options.NewOperationOptions(this.Timeouts.Guest, Options...).NewContext()
+	defer CancelFunc()
+
+	// Record is Resolved before the Password is Changed, so the Password is never Set, if it can't be Saved
+	SecretID, LookupError := virtualMachineSecretID(TimeoutContext, &this.Client, VirtualMachine)
+	if LookupError != nil {
+		return LookupError
+	}
+	Password, GenerateError := generateRootPassword()
+	if GenerateError != nil {
+		return GenerateError
+	}
+
+	Operations := guestops.NewOperationsManager(&this.Client, VirtualMachine.Reference())
+	FileManager, ManagerError := Operations.FileManager(TimeoutContext)
+	if ManagerError != nil {
+		return ManagerError
+	}
+	ProcessManager, ManagerError := Operations.ProcessManager(TimeoutContext)
+	if ManagerError != nil {
+		return ManagerError
+	}
+
+	PasswordFile, CreateError := FileManager.CreateTemporaryFile(TimeoutContext, this.Bootstrap, "root-password", "", "")
+	if CreateError != nil {
+		return CreateError
+	}
+	if WriteError := this.writeGuestFile(TimeoutContext, FileManager, this.Bootstrap, PasswordFile,
+		[]byte(fmt.Sprintf("root:%s\n", Password))); WriteError != nil {
+		if DeleteError := FileManager.DeleteFile(TimeoutContext, this.Bootstrap, PasswordFile); DeleteError != nil {
+			Logger.Error("Failed to Delete the Root Password File", zap.String("Path", PasswordFile), zap.Error(DeleteError))
+		}
+		return WriteError
+	}
+
+	// The File is Removed by the Command itself, whatever `chpasswd` Exits with
+	ProcessID, StartError := ProcessManager.StartProgram(TimeoutContext, this.Bootstrap, &types.GuestProgramSpec{
+		ProgramPath: "/bin/sh",
+		Arguments:   fmt.Sprintf(`-c "chpasswd < '%[1]s' && rm -f '%[1]s' || { rm -f '%[1]s'; exit 1; }"`, PasswordFile),
+	})
+	if StartError != nil {
+		return StartError
+	}
+	ExitCode, WaitError := waitForGuestProcess(TimeoutContext, ProcessManager, this.Bootstrap, ProcessID)
+	if WaitError != nil {
+		return WaitError
+	}
+	if ExitCode != 0 {
+		return fmt.Errorf("%w: `chpasswd` has Exited with %d", ErrRootPasswordNotSet, ExitCode)
+	}
+
+	if StoreError := this.Secrets.SetPassword(SecretID, Password); StoreError != nil {
+		Logger.Error("Root Password has been Set, but not Saved", zap.String("Virtual Machine", VirtualMachine.Reference().Value),
+			zap.Error(StoreError))
+		return StoreError
+	}
+	Logger.Debug("Root Password has been Set", zap.String("Virtual Machine", VirtualMachine.Reference().Value))
+	return nil
+}
+
+func waitForGuestProcess(Context context.Context, ProcessManager *guestops.ProcessManager, Credentials *types.NamePasswordAuthentication, ProcessID int64) (int32, error) {
+	// Returns Exit Code of the Guest Process, once it has Exited
+	for {
+		Processes, ListError := ProcessManager.ListProcesses(Context, Credentials, []int64{ProcessID})
+		if ListError != nil {
+			return 0, ListError
+		}
+		if len(Processes) != 0 && Processes[0].EndTime != nil {
+			return Processes[0].ExitCode, nil
+		}
+		select {
+		case <-Context.Done():
+			return 0, Context.Err()
+		case <-time.After(guestProcessPollInterval):
+		}
+	}
+}
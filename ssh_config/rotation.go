@@ -0,0 +1,85 @@
+package ssh_config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/operations"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"go.uber.org/zap"
+)
+
+var (
+	// Returned, if the Key has been Uploaded to the Host, but the Database still has the Old one
+	ErrSshKeyNotPersisted = errors.New("SSH Key has been Uploaded, but not Saved to the Database")
+)
+
+func (this *VirtualMachineSshCertificateManager) RotateSshKey(VirtualMachine *object.VirtualMachine, NewKey SshCertificateCredentials, CustomerID int, Options ...options.OperationOption) error {
+	// Replaces SSH Key of the Virtual Machine: Uploads the New Key to the Host and only then Replaces the Keys in the Database
+	// If the Upload Fails, the Database is not Changed, if the Database Update Fails, `ErrSshKeyNotPersisted` is Returned
+	// and the Virtual Machine has to be Reconciled Manually (The Host already Uses the New Key)
+	// Rotation is being Audited on behalf of the Customer (The IP Address is Taken from the Actor of the Context, See `models.WithAuditActor`)
+	// and is Counted as a Single SSH Key Operation of the Customer by the Rate Limiter
+
+	if ValidationError := models.ValidateSshPublicKey(NewKey.Content); ValidationError != nil {
+		return ValidationError
+	}
+
+	Operation := options.NewOperationOptions(this.Timeouts.Rotation, Options...)
+	if LimitError := this.checkRateLimit(CustomerID); LimitError != nil {
+		return LimitError
+	}
+	Actor := models.AuditActorFromContext(Operation.Context)
+	Actor.CustomerID = CustomerID
+	Operation.Context = models.WithAuditActor(Operation.Context, Actor)
+	defer operations.Track(operations.OperationSshKeyRotation, VirtualMachine.Reference().Value)()
+	TimeoutContext, CancelFunc := Operation.NewContext()
+	defer CancelFunc()
+
+	// Record is Resolved before the Upload, so the Key is not Uploaded for the Virtual Machine, that can't be Updated
+	Record, RecordError := this.getVirtualMachineRecord(TimeoutContext, VirtualMachine)
+	if RecordError != nil {
+		Logger.Error("Failed to Find Database Record of the Virtual Machine",
+			zap.String("Virtual Machine", VirtualMachine.Reference().Value), zap.Error(RecordError))
+		return RecordError
+	}
+	Audited := func(OperationError error) error {
+		models.AuditOperation(Operation.Context, models.AuditActionSshKeyRotated, strconv.Itoa(Record.ID), OperationError)
+		return OperationError
+	}
+
+	if UploadError := this.tracedUploadSshKeys(options.NewOperationOptions(this.Timeouts.Upload, Options...),
+		VirtualMachine, NewKey); UploadError != nil {
+		return Audited(UploadError)
+	}
+
+	if ReplaceError := models.ReplaceSshKeys(Record.ID, models.NewSshPublicKey(
+		Record.ID, NewKey.Content, NewKey.FileName)); ReplaceError != nil {
+		Logger.Error("SSH Key has been Uploaded, but the Database has not been Updated, Manual Reconciliation is Required",
+			zap.Int("Virtual Machine ID", Record.ID), zap.String("Key", NewKey.FileName), zap.Error(ReplaceError))
+		return Audited(fmt.Errorf("%w: %s", ErrSshKeyNotPersisted, ReplaceError))
+	}
+	Audited(nil)
+	Logger.Debug("SSH Key has been Rotated", zap.Int("Virtual Machine ID", Record.ID), zap.String("Key", NewKey.FileName))
+	return nil
+}
+
+func (this *VirtualMachineSshCertificateManager) getVirtualMachineRecord(Context context.Context, VirtualMachine *object.VirtualMachine) (*models.VirtualMachine, error) {
+	// Returns Database Record of the Virtual Machine, Matched by the vSphere UUID
+	var MoVirtualMachine mo.VirtualMachine
+	Collector := property.DefaultCollector(&this.Client)
+	if RetrieveError := Collector.RetrieveOne(Context, VirtualMachine.Reference(),
+		[]string{"config.uuid"}, &MoVirtualMachine); RetrieveError != nil {
+		return nil, RetrieveError
+	}
+	if MoVirtualMachine.Config == nil {
+		return nil, models.ErrNotFound
+	}
+	return models.GetVirtualMachineByUUID(MoVirtualMachine.Config.Uuid)
+}
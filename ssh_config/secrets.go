@@ -0,0 +1,123 @@
+package ssh_config
+
+import (
+	"context"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// Secret Store, that is used by the Managers by Default, Nil if the Encryption Key is not Configured
+	DefaultSecretStore SecretStore
+)
+
+var (
+	ErrSecretNotFound           = errors.New("Secret does not Exist")
+	ErrSecretStoreNotConfigured = errors.New("Secret Store is not Configured")
+	ErrInvalidSecretsEncryption = errors.New("Failed to Decrypt the Secret")
+)
+
+const rootPasswordSecret = "root-password"
+
+func initializeDefaultSecretStore() {
+	// Initializes Default Secret Store with the Key from the `SECRETS_ENCRYPTION_KEY` (See `models.DecodeEncryptionKey`)
+	Key, KeyError := models.DecodeEncryptionKey()
+	if KeyError != nil {
+		Logger.Error("Root Passwords can't be Stored", zap.Error(KeyError))
+		return
+	}
+	Store, StoreError := NewDatabaseSecretStore(Key)
+	if StoreError != nil {
+		Logger.Error("Failed to Initialize Secret Store", zap.Error(StoreError))
+		return
+	}
+	DefaultSecretStore = Store
+}
+
+func virtualMachineSecretID(Context context.Context, Client *vim25.Client, VirtualMachine *object.VirtualMachine) (string, error) {
+	// Returns ID of the Virtual Machine Record (Found by the `config.uuid`), the Secrets of the Virtual Machine are Stored by,
+	// so they are Deleted along with the Record (See `models.DeleteVirtualMachineRecords`)
+	var MoVirtualMachine mo.VirtualMachine
+	if RetrieveError := property.DefaultCollector(Client).RetrieveOne(Context, VirtualMachine.Reference(),
+		[]string{"config.uuid"}, &MoVirtualMachine); RetrieveError != nil {
+		return "", RetrieveError
+	}
+	if MoVirtualMachine.Config == nil {
+		return "", fmt.Errorf("%w: UUID of `%s` is not Known", models.ErrNotFound, VirtualMachine.Reference().Value)
+	}
+	Record, LookupError := models.GetVirtualMachineByUUID(MoVirtualMachine.Config.Uuid)
+	if LookupError != nil {
+		return "", fmt.Errorf("Record of the Virtual Machine `%s`: %w", VirtualMachine.Reference().Value, LookupError)
+	}
+	return strconv.Itoa(Record.ID), nil
+}
+
+type SecretStore interface {
+	// Storage of the Virtual Machine Secrets, can be Backed by the Database, Vault etc...
+	// Secrets are Stored by the ID of the Virtual Machine Record, not by its vSphere Reference
+	GetPassword(VirtualMachineId string) (string, error)
+	SetPassword(VirtualMachineId string, Password string) error
+}
+
+type DatabaseSecretStore struct {
+	// Secret Store, that keeps the Secrets in the Database, Encrypted with AES-GCM (See `models.EncryptSecret`)
+	Cipher cipher.AEAD
+}
+
+func NewDatabaseSecretStore(Key []byte) (*DatabaseSecretStore, error) {
+	Cipher, CipherError := models.NewSecretCipher(Key)
+	if CipherError != nil {
+		return nil, CipherError
+	}
+	return &DatabaseSecretStore{Cipher: Cipher}, nil
+}
+
+func (this *DatabaseSecretStore) GetPassword(VirtualMachineId string) (string, error) {
+	// Returns Decrypted Root Password of the Virtual Machine
+
+	var Secret models.VirtualMachineSecret
+	Gorm := models.Database.Where("virtual_machine_id = ? AND name = ?",
+		VirtualMachineId, rootPasswordSecret).Limit(1).Find(&Secret)
+	if Gorm.Error != nil {
+		return "", Gorm.Error
+	}
+	if Gorm.RowsAffected == 0 {
+		return "", ErrSecretNotFound
+	}
+
+	Password, DecryptError := models.DecryptSecret(this.Cipher, Secret.Ciphertext, []byte(VirtualMachineId))
+	if DecryptError != nil {
+		return "", ErrInvalidSecretsEncryption
+	}
+	return string(Password), nil
+}
+
+func (this *DatabaseSecretStore) SetPassword(VirtualMachineId string, Password string) error {
+	// Encrypts and Saves the Root Password of the Virtual Machine, Replaces the Previous One
+	// Virtual Machine ID is used as Additional Data, so the Ciphertext can't be Moved to another Virtual Machine
+
+	Ciphertext, EncryptError := models.EncryptSecret(this.Cipher, []byte(Password), []byte(VirtualMachineId))
+	if EncryptError != nil {
+		return EncryptError
+	}
+	Secret := models.VirtualMachineSecret{
+		VirtualMachineID: VirtualMachineId,
+		Name:             rootPasswordSecret,
+		Ciphertext:       Ciphertext,
+	}
+	Saved := models.Database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "virtual_machine_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"ciphertext", "updated_at"}),
+	}).Create(&Secret)
+	return Saved.Error
+}
@@ -2,18 +2,21 @@ package ssh_config
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 
 	"fmt"
 
 	"os"
-	"time"
+	"regexp"
+	"strings"
 
+	"github.com/LovePelmeni/Infrastructure/certificates"
+	"github.com/LovePelmeni/Infrastructure/logging"
 	"github.com/LovePelmeni/Infrastructure/models"
-	"github.com/google/uuid"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/LovePelmeni/Infrastructure/tracing"
 	"github.com/vmware/govmomi/object"
-	"github.com/vmware/govmomi/property"
-	"golang.org/x/crypto/bcrypt"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -23,23 +26,68 @@ import (
 )
 
 var (
-	Logger *zap.Logger
+	Logger  *zap.Logger
+	logFile *os.File // Log File of the Current Logger, Closed by the `CloseLogger`
 )
 
-func InitializeProductionLogger() {
+const DefaultLogFile = "Main.json"
+const DefaultLogLevel = logging.DefaultLogLevel
+const rootPasswordLength = 24 // Length of the Generated Root Passwords
 
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("Main.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
+var (
+	ErrInvalidLogLevel       = logging.ErrInvalidLogLevel
+	ErrUnsupportedSshManager = errors.New("Unsupported Type of the SSH Manager")
+)
+
+func ParseLogLevel(Level string) (zapcore.Level, error) {
+	// Returns Level of the Logger by its Name (See `logging.ParseLogLevel`)
+	return logging.ParseLogLevel(Level)
+}
 
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+func InitializeLogger() error {
+	// Initializes Logger, Configured by the Environment (See `logging.NewLogger`), `Main.json` is the Default Log File
+	// Returns Error if the Log File can't be Opened, the Current Logger is Left Untouched in that Case
+
+	NewLogger, file, LoggerError := logging.NewLogger(DefaultLogFile)
+	if LoggerError != nil {
+		return LoggerError
+	}
+
+	// Previous Logger is Flushed and its File is Closed, so Re-Initialization does not Leak File Handles
+	CloseLogger()
+	Logger = NewLogger
+	logFile = file
+	return nil
+}
+
+func InitializeProductionLogger() {
+	// Initializes Logger (See `InitializeLogger`), if the Log File can't be Opened, Logs are Written to the Stderr,
+	// so they are not Lost
+	if LoggerError := InitializeLogger(); LoggerError != nil {
+		CloseLogger()
+		Logger = logging.NewStderrLogger()
+		Logger.Error("Failed to Initialize Logger, Stderr is being Used", zap.Error(LoggerError))
+	}
+}
+
+func CloseLogger() error {
+	// Flushes Buffered Logs and Closes the Log File, should be Called on Shutdown
+	// Logger is Replaced with the No-op one, so the Late Logs are Dropped instead of being Written to the Closed File
+	if Logger != nil {
+		Logger.Sync()
+	}
+	if logFile == nil {
+		return nil
+	}
+	CloseError := logFile.Close()
+	logFile = nil
+	Logger = zap.NewNop()
+	return CloseError
 }
 
 func init() {
 	InitializeProductionLogger()
+	initializeDefaultSecretStore()
 }
 
 type SshCredentialsInterface interface {
@@ -61,8 +109,9 @@ func NewSshRootCredentials(Username string, Password string) *SshRootCredentials
 
 type SshCertificateCredentials struct {
 	// SSH Certificate Credentials for the Virtual Server
-	FileName string `json:"FileName" xml:"FileName"`
-	Content  []byte `json:"Content" xml:"Content"`
+	FileName   string `json:"FileName" xml:"FileName"`
+	Content    []byte `json:"Content" xml:"Content"`
+	PrivateKey []byte `json:"PrivateKey,omitempty" xml:"PrivateKey,omitempty"` // PEM Encoded, Set only for the Generated Key Pairs
 }
 
 func NewSshCertificateCredentials(Content []byte, FileName string) *SshCertificateCredentials {
@@ -73,36 +122,96 @@ func NewSshCertificateCredentials(Content []byte, FileName string) *SshCertifica
 }
 
 type VirtualMachineSshManagerInterface interface {
-	// Interface, represents base SSH Manager Interface for the Virtual Machine Server
+	// Interface, represents base SSH Manager Interface for the Virtual Machine Server,
+	// so the Way the Customer Connects to the Virtual Machine can be Chosen at Runtime (See `NewSshManager`)
+
+	// Returns Type of the SSH Connection, the Manager Sets up (`models.TypeByRootCertificate` or `models.TypeByRootCredentials`)
+	Type() string
+	// Generates new Key for the SSH Connection to the Virtual Machine
+	GenerateKeys(VirtualMachine *object.VirtualMachine, VirtualMachineId string) (*SshCertificateCredentials, error)
+	// Makes the Key Usable for the SSH Connection to the Virtual Machine on behalf of the Customer
+	// (`ErrCustomerRequired` is Returned, if the Customer is not Specified), Options are Applied to the Upload
+	UploadKeys(VirtualMachine *object.VirtualMachine, Key SshCertificateCredentials, CustomerID int, Options ...options.OperationOption) error
+}
+
+var (
+	_ VirtualMachineSshManagerInterface = (*VirtualMachineSshCertificateManager)(nil)
+	_ VirtualMachineSshManagerInterface = (*VirtualMachineSshRootCredentialsManager)(nil)
+)
+
+func NewSshManager(Kind string, Client vim25.Client) (VirtualMachineSshManagerInterface, error) {
+	// Returns SSH Manager of the Kind (`models.TypeByRootCertificate` or `models.TypeByRootCredentials`)
+	switch Kind {
+	case models.TypeByRootCertificate:
+		return NewVirtualMachineSshCertificateManager(Client), nil
+	case models.TypeByRootCredentials:
+		return NewVirtualMachineSshRootCredentialsManager(Client), nil
+	default:
+		return nil, fmt.Errorf("%w: `%s`", ErrUnsupportedSshManager, Kind)
+	}
 }
 
 type VirtualMachineSshCertificateManager struct {
-	VirtualMachineSshManagerInterface
-	Client vim25.Client
+	Client      vim25.Client
+	RateLimiter *RateLimiter // Limits Uploads and Rotations per Customer, Nothing is Limited if nil
+	Timeouts    Timeouts
+	Install     certificates.InstallOptions // How the PEM Certificates are Installed on the Host and Verified
 }
 
 func NewVirtualMachineSshCertificateManager(Client vim25.Client) *VirtualMachineSshCertificateManager {
 	return &VirtualMachineSshCertificateManager{
-		Client: Client,
+		Client:      Client,
+		RateLimiter: DefaultRateLimiter,
+		Timeouts:    DefaultTimeouts(),
+		Install:     certificates.NewInstallOptions(),
+	}
+}
+
+func (this *VirtualMachineSshCertificateManager) UploadSshKeys(VirtualMachine *object.VirtualMachine, Key SshCertificateCredentials, CustomerID int, Options ...options.OperationOption) error {
+	// Uploaded SSH Pem Key to the Virtual Machine Server on behalf of the Customer...
+	// The Upload is Traced, vSphere Calls it Makes are Nested under its Span
+	// `ErrRateLimited` is Returned, if the Customer has Exceeded the Limit of the SSH Key Operations,
+	// `ErrCustomerRequired`, if the Customer is not Specified
+
+	Operation := options.NewOperationOptions(this.Timeouts.Upload, Options...)
+	if LimitError := this.checkRateLimit(CustomerID); LimitError != nil {
+		return LimitError
 	}
+	return this.tracedUploadSshKeys(Operation, VirtualMachine, Key)
 }
 
-func (this *VirtualMachineSshCertificateManager) UploadSshKeys(VirtualMachine *object.VirtualMachine, Key SshCertificateCredentials) error {
-	// Uploaded SSH Pem Key to the Virtual Machine Server...
+func (this *VirtualMachineSshCertificateManager) tracedUploadSshKeys(Operation *options.OperationOptions, VirtualMachine *object.VirtualMachine, Key SshCertificateCredentials) error {
+	// Uploads the Key within the Span, without Checking the Rate Limit (it is Checked by the Entry Points)
+	SpanContext, Span := tracing.StartSpan(Operation.Context, "ssh_config.UploadSshKeys",
+		tracing.AttributeVirtualMachine.String(virtualMachineName(VirtualMachine)),
+		tracing.AttributeOperation.String("UploadSshKeys"))
+	Operation.Context = SpanContext
+
+	UploadError := this.uploadSshKeys(Operation, VirtualMachine, Key)
+	tracing.EndSpan(Span, UploadError)
+	return UploadError
+}
 
-	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+func (this *VirtualMachineSshCertificateManager) uploadSshKeys(Operation *options.OperationOptions, VirtualMachine *object.VirtualMachine, Key SshCertificateCredentials) error {
+	TimeoutContext, CancelFunc := Operation.NewContext()
 	defer CancelFunc()
 
+	Release, LockError := Operation.LockVirtualMachine(TimeoutContext, VirtualMachine.Reference())
+	if LockError != nil {
+		return LockError
+	}
+	defer Release()
+
 	// Initializing New SSH Certificate Manager
-	HostSystem, HostSystemError := VirtualMachine.HostSystem(TimeoutContext)
-	if HostSystemError != nil {
-		return errors.New("Failed to Get Host System Info")
+	SshManager, ManagerError := this.hostCertificateManager(TimeoutContext, VirtualMachine)
+	if ManagerError != nil {
+		return ManagerError
 	}
-	SshManager := object.NewHostCertificateManager(&this.Client,
-		*types.NewReference(this.Client.ServiceContent.RootFolder), HostSystem.Reference())
 
 	// Uploading SSL Certificate to the Host Machine
-	InstallationError := SshManager.InstallServerCertificate(TimeoutContext, string(Key.Content))
+	InstallationError := Operation.Retry(TimeoutContext, func() error {
+		return this.installKey(TimeoutContext, SshManager, Key)
+	})
 	switch InstallationError {
 	case nil:
 		Logger.Debug("SSH Key has been Successfully Uploaded to the VM with Name: %s",
@@ -110,89 +219,186 @@ func (this *VirtualMachineSshCertificateManager) UploadSshKeys(VirtualMachine *o
 		return nil
 
 	default:
-		Logger.Error("Failed to Upload SSH Key to the Remote VM's Host Machine")
-		return errors.New("Failed to Add SSH Support")
+		Logger.Error("Failed to Upload SSH Key to the Remote VM's Host Machine", zap.Error(InstallationError))
+		return fmt.Errorf("Failed to Add SSH Support: %w", InstallationError)
+	}
+}
+
+func (this *VirtualMachineSshCertificateManager) installKey(Context context.Context, Manager *object.HostCertificateManager, Key SshCertificateCredentials) error {
+	// Installs the Key on the Host, PEM Certificates are Installed through `certificates.InstallCertificate`,
+	// So the Host Services are Refreshed and the Certificate is Verified to be Served (See `this.Install`),
+	// Other Content (e.g. the Generated Signing Requests) can't be Verified and is only Installed
+	if _, ParseError := certificates.ParseCertificate(string(Key.Content)); ParseError != nil {
+		return Manager.InstallServerCertificate(Context, string(Key.Content))
 	}
+	_, InstallError := certificates.InstallCertificate(Context, Manager, string(Key.Content), this.Install)
+	return InstallError
 }
 
-func (this *VirtualMachineSshCertificateManager) GenerateSshKeys(VirtualMachine *object.VirtualMachine, VirtualMachineId string) (*SshCertificateCredentials, error) {
+func (this *VirtualMachineSshCertificateManager) GenerateSshKeys(VirtualMachine *object.VirtualMachine, VirtualMachineId string, FileName ...string) (*SshCertificateCredentials, error) {
+	// Returns Generated SSH Keys for the Virtual Machine Server (See `GenerateSshKeysContext`)
+	return this.GenerateSshKeysContext(context.Background(), VirtualMachine, VirtualMachineId, FileName...)
+}
 
-	// Returns Generated SSH Keys for the Virtual Machine Server
+func (this *VirtualMachineSshCertificateManager) GenerateSshKeysContext(Context context.Context, VirtualMachine *object.VirtualMachine, VirtualMachineId string, FileName ...string) (*SshCertificateCredentials, error) {
+
+	// Returns Generated SSH Keys for the Virtual Machine Server within the Context, `Timeouts.Generate` is Applied on top of it
+	// File Name of the Key is being Derived from the Virtual Machine Name (`<vmname>_ssh_key.pub`), unless it is Specified Explicitly
 
 	// Certificate will be Generated with the Specific Name and will be Stored on the Host System
 	// Of the Virtual Machine Server
 
-	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Minute*1)
+	TimeoutContext, CancelFunc := options.NewOperationOptions(this.Timeouts.Generate, options.WithContext(Context)).NewContext()
 	defer CancelFunc()
 
-	HostSystem, HostSystemError := VirtualMachine.HostSystem(TimeoutContext)
-	if HostSystemError != nil {
-		return nil, errors.New("Failed to Get OS Info")
-	}
 	// Initializing Manager for the SSH Management
-	Manager := object.NewHostCertificateManager(
-		&this.Client, *types.NewReference(this.Client.ServiceContent.RootFolder), HostSystem.Reference())
+	Manager, ManagerError := this.hostCertificateManager(TimeoutContext, VirtualMachine)
+	if ManagerError != nil {
+		return nil, ManagerError
+	}
 
 	SSLCertificateDistinguishName := fmt.Sprintf("%s-%s", VirtualMachine.Name(), VirtualMachineId)
-	Manager.Client().Certificate().Leaf.MaxPathLen = 30 // Initializing Max Path len for the Virtual Machine Server
 	GeneratedCertificate, GenerationError := Manager.GenerateCertificateSigningRequestByDn(TimeoutContext, SSLCertificateDistinguishName)
+	if GenerationError != nil {
+		Logger.Error("Failed to Generate Certificate Signing Request", zap.String("Distinguished Name",
+			SSLCertificateDistinguishName), zap.Error(GenerationError))
+		return nil, GenerationError
+	}
 
 	// Returning the Response
-	currentTime := time.Now()
+	KeyFileName := SshKeyFileName(VirtualMachine.Name())
+	if len(FileName) != 0 && len(FileName[0]) != 0 {
+		KeyFileName = SanitizeFileName(FileName[0])
+	}
 	return NewSshCertificateCredentials(
 		[]byte(GeneratedCertificate),
-		fmt.Sprintf("%s.%s.pub", VirtualMachineId, currentTime),
-	), GenerationError
+		KeyFileName,
+	), nil
+}
+
+func (this *VirtualMachineSshCertificateManager) hostCertificateManager(Context context.Context, VirtualMachine *object.VirtualMachine) (*object.HostCertificateManager, error) {
+	// Returns Certificate Manager of the Host, the Virtual Machine is Running on,
+	// Reference of the Manager is Taken from the `configManager.certificateManager` of the Host System
+	HostSystem, FindError := VirtualMachine.HostSystem(Context)
+	if FindError != nil {
+		Logger.Error("Failed to Get Host System of the Virtual Machine", zap.Error(FindError))
+		return nil, FindError
+	}
+	Manager, ManagerError := HostSystem.ConfigManager().CertificateManager(Context)
+	if ManagerError != nil {
+		Logger.Error("Failed to Get Certificate Manager of the Host System",
+			zap.String("Host", HostSystem.Reference().Value), zap.Error(ManagerError))
+		return nil, ManagerError
+	}
+	return Manager, nil
+}
+
+func (this *VirtualMachineSshCertificateManager) SaveSshKey(VirtualMachineId int, Key SshCertificateCredentials) (*models.SSHPublicKey, error) {
+	// Persists the Generated Public Key of the Virtual Machine under its File Name
+
+	PublicKey := models.NewSshPublicKey(VirtualMachineId, Key.Content, Key.FileName)
+	if _, CreateError := PublicKey.Create(); CreateError != nil {
+		Logger.Error("Failed to Save SSH Key", zap.Int("Virtual Machine ID", VirtualMachineId), zap.Error(CreateError))
+		return nil, CreateError
+	}
+	return PublicKey, nil
+}
+
+var (
+	unsafeFileNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+func SanitizeFileName(Name string) string {
+	// Returns File Name, that is Safe to be Downloaded: No Path Separators, Spaces or other Special Characters
+	Sanitized := strings.Trim(unsafeFileNameCharacters.ReplaceAllString(Name, "_"), "._")
+	if len(Sanitized) == 0 {
+		return "vm"
+	}
+	return Sanitized
+}
+
+func SshKeyFileName(VirtualMachineName string) string {
+	// Returns File Name of the Public Key of the Virtual Machine, like `web-1_ssh_key.pub`
+	return fmt.Sprintf("%s_ssh_key.pub", SanitizeFileName(VirtualMachineName))
 }
 
 type VirtualMachineSshRootCredentialsManager struct {
 	// SSH Manager Class, that performs Type of the SSH Connection
 	// Via Root Credentials
-	VirtualMachineSshManagerInterface
-	Client vim25.Client
+	Client    vim25.Client
+	Secrets   SecretStore                       // Storage, the Root Passwords are being Read From and Written To
+	Bootstrap *types.NamePasswordAuthentication // Credentials of the Template Guest, the Root Password is Set with (See `ProvisionRootPassword`)
+	Timeouts  Timeouts
 }
 
 func NewVirtualMachineSshRootCredentialsManager(Client vim25.Client) *VirtualMachineSshRootCredentialsManager {
 	return &VirtualMachineSshRootCredentialsManager{
-		Client: Client,
+		Client:    Client,
+		Secrets:   DefaultSecretStore,
+		Bootstrap: NewBootstrapCredentialsFromEnvironment(),
+		Timeouts:  DefaultTimeouts(),
 	}
 }
 
-func (this *VirtualMachineSshCertificateManager) GetSshRootUserCredentials(VirtualMachineId string) models.SSHConfiguration {
+func (this *VirtualMachineSshCertificateManager) GetSshRootUserCredentials(VirtualMachineId string) (*models.SSHConfiguration, error) {
 
 	// Returns Info about the Ssh Root Credentials of the Virtual Machine Server
 	// Is working only with the Vm's which has the `Root User Credentials` Type
+	// If the Virtual Machine does not exist, returns `models.ErrNotFound`
 
-	var VirtualMachine models.VirtualMachine
-	models.Database.Model(&models.VirtualMachine{}).Where(
-		"id = ?", VirtualMachineId).Find(&VirtualMachine)
-	return VirtualMachine.SshInfo
+	VirtualMachine, LookupError := models.GetVirtualMachineByID(VirtualMachineId)
+	if LookupError != nil {
+		Logger.Debug("Failed to Find Virtual Machine",
+			zap.String("Virtual Machine ID", VirtualMachineId), zap.Error(LookupError))
+		return nil, LookupError
+	}
+	return &VirtualMachine.SshInfo, nil
 }
 
-func (this *VirtualMachineSshRootCredentialsManager) GetSshRootCredentials(VirtualMachine *object.VirtualMachine) (*types.NamePasswordAuthentication, error) {
+func (this *VirtualMachineSshRootCredentialsManager) GetSshRootCredentials(VirtualMachine *object.VirtualMachine, Options ...options.OperationOption) (*types.NamePasswordAuthentication, error) {
 	// Parses Root Credentials of the OS Host System of the Customer's Virtual Machine Server
 	// The Returned object `types.GuestAuthentication` can be potentially used for making operations
 	// that requires this authentication
+	// `ErrRootPasswordNotProvisioned` is Returned, if the Password has not been Set in the Guest (See `ProvisionRootPassword`)
 
-	PasswordUuid := uuid.New()
-	GeneratedOsPassword, _ := bcrypt.GenerateFromPassword([]byte(PasswordUuid.String()), 15)
-	SshCredentials := types.NamePasswordAuthentication{
-		Username: "root",
-		Password: string(GeneratedOsPassword),
+	if this.Secrets == nil {
+		return nil, ErrSecretStoreNotConfigured
 	}
-	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
-	Manager := property.DefaultCollector(&this.Client)
+	Operation := options.NewOperationOptions(this.Timeouts.Query, Options...)
+	TimeoutContext, CancelFunc := Operation.NewContext()
 	defer CancelFunc()
 
-	// Receiving Virtual Machine Instance
+	SecretID, LookupError := virtualMachineSecretID(TimeoutContext, &this.Client, VirtualMachine)
+	if LookupError != nil {
+		return nil, LookupError
+	}
+	Password, PasswordError := this.Secrets.GetPassword(SecretID)
+	if errors.Is(PasswordError, ErrSecretNotFound) {
+		return nil, fmt.Errorf("%w: `%s`", ErrRootPasswordNotProvisioned, VirtualMachine.Reference().Value)
+	}
+	if PasswordError != nil {
+		Logger.Error("Failed to Get Root Password from the Secret Store", zap.Error(PasswordError))
+		return nil, PasswordError
+	}
+	return &types.NamePasswordAuthentication{
+		Username: "root",
+		Password: Password,
+	}, nil
+}
 
-	RetrieveError := Manager.RetrieveOne(TimeoutContext, VirtualMachine.Reference(),
-		[]string{"name", "guest"}, &VirtualMachine)
+func generateRootPassword() (string, error) {
+	// Returns Random Plain Text Password, that can be Set as the Root Password of the Guest OS
+	// (Unlike the Hash, it can actually be Used for the Login)
 
-	if RetrieveError != nil {
-		Logger.Debug(
-			"Failed to Get VirtualMachine Instance")
-		return nil, RetrieveError
+	const Alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	Random := make([]byte, rootPasswordLength)
+	if _, RandomError := rand.Read(Random); RandomError != nil {
+		return "", RandomError
+	}
+	Password := make([]byte, rootPasswordLength)
+	for Index, Byte := range Random {
+		// 256 is not Divisible by the Alphabet Size, the Bias is Negligible for the Password of this Length
+		Password[Index] = Alphabet[int(Byte)%len(Alphabet)]
 	}
-	return &SshCredentials, nil
+	return string(Password), nil
 }
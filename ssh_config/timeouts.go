@@ -0,0 +1,29 @@
+package ssh_config
+
+import "time"
+
+type Timeouts struct {
+	// Default Timeouts of the Operations of the SSH Managers, they can be Raised for the Slow Environments
+	// or Overridden per Call (See `options.WithTimeout`), the Deadline of the Caller Context is Respected either way
+	// Zero Timeout means no Deadline, except the one of the Caller Context
+	Upload      time.Duration // Installation of the Key on the Host or in the Guest
+	Generate    time.Duration // Generation of the Certificate Signing Request on the Host
+	Rotation    time.Duration // Database Part of the Key Rotation, the Upload has its own Timeout
+	Certificate time.Duration // Retrieval of the Host Certificate
+	Query       time.Duration // Single Property Collector Call (Power State, Guest IP Address, etc...)
+	Guest       time.Duration // Guest Operations via VMware Tools (Programs, Files)
+	PowerTask   time.Duration // Power On / Off and Reboot Tasks, including the Wait for them
+}
+
+func DefaultTimeouts() Timeouts {
+	// Returns Timeouts, the Managers are Created with
+	return Timeouts{
+		Upload:      time.Minute * 1,
+		Generate:    time.Minute * 1,
+		Rotation:    time.Second * 30,
+		Certificate: time.Second * 30,
+		Query:       time.Second * 10,
+		Guest:       time.Second * 30,
+		PowerTask:   time.Minute * 2,
+	}
+}
@@ -0,0 +1,106 @@
+package ssh_rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/LovePelmeni/Infrastructure/authentication"
+	"github.com/LovePelmeni/Infrastructure/deploy"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/LovePelmeni/Infrastructure/ssh_config"
+	"github.com/gin-gonic/gin"
+	"github.com/vmware/govmomi/object"
+	"go.uber.org/zap"
+)
+
+// SSH Key Operations are Performed on behalf of the Authorized Customer, whose ID is Taken from the Jwt Token,
+// so every Upload or Rotation is Counted by the Rate Limiter of that Customer (See `ssh_config.RateLimiter`)
+
+func UploadSshKeyRestController(RequestContext *gin.Context) {
+	// Rest Controller, that Uploads the SSH Public Key of the Customer to the Virtual Machine Server
+	// and Saves it along with the other Keys of the Virtual Machine
+
+	CustomerID, VirtualMachineID, VirtualMachine, Key, Ok := parseSshKeyRequest(RequestContext)
+	if !Ok {
+		return
+	}
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+	if UploadError := Manager.UploadSshKeys(VirtualMachine, *Key, CustomerID,
+		options.WithContext(RequestContext.Request.Context())); UploadError != nil {
+		respondSshKeyError(RequestContext, "Failed to Upload SSH Key", UploadError)
+		return
+	}
+	if _, SaveError := Manager.SaveSshKey(VirtualMachineID, *Key); SaveError != nil {
+		respondSshKeyError(RequestContext, "SSH Key has been Uploaded, but has not been Saved", SaveError)
+		return
+	}
+	RequestContext.JSON(http.StatusCreated, gin.H{"Filename": Key.FileName})
+}
+
+func RotateSshKeyRestController(RequestContext *gin.Context) {
+	// Rest Controller, that Replaces every SSH Key of the Virtual Machine Server with the New Public Key of the Customer
+	// (See `ssh_config.RotateSshKey`)
+
+	CustomerID, _, VirtualMachine, Key, Ok := parseSshKeyRequest(RequestContext)
+	if !Ok {
+		return
+	}
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+	if RotateError := Manager.RotateSshKey(VirtualMachine, *Key, CustomerID,
+		options.WithContext(RequestContext.Request.Context())); RotateError != nil {
+		respondSshKeyError(RequestContext, "Failed to Rotate SSH Key", RotateError)
+		return
+	}
+	RequestContext.JSON(http.StatusOK, gin.H{"Filename": Key.FileName})
+}
+
+func parseSshKeyRequest(RequestContext *gin.Context) (int, int, *object.VirtualMachine, *ssh_config.SshCertificateCredentials, bool) {
+	// Returns Customer of the Jwt Token, the Virtual Machine of the `VirtualMachineId` Query Parameter, Owned by that Customer,
+	// and the Public Key of the `PublicKey` and `Filename` Form Fields, the Error Response is Written, if any of them is Invalid
+
+	Credentials, JwtError := authentication.GetCustomerJwtCredentials(RequestContext.GetHeader("Authorization"))
+	if JwtError != nil || Credentials.UserId <= 0 {
+		RequestContext.JSON(http.StatusForbidden, gin.H{"Error": "You are Not Authorized"})
+		return 0, 0, nil, nil, false
+	}
+	VirtualMachineID, ConvertError := strconv.Atoi(RequestContext.Query("VirtualMachineId"))
+	if ConvertError != nil {
+		RequestContext.JSON(http.StatusBadRequest, gin.H{"Error": "Invalid Virtual Machine ID"})
+		return 0, 0, nil, nil, false
+	}
+	Key := ssh_config.NewSshCertificateCredentials([]byte(RequestContext.PostForm("PublicKey")),
+		ssh_config.SanitizeFileName(RequestContext.PostForm("Filename")))
+	if ValidationError := models.ValidateSshPublicKey(Key.Content); ValidationError != nil {
+		RequestContext.JSON(http.StatusBadRequest, gin.H{"Error": ValidationError.Error()})
+		return 0, 0, nil, nil, false
+	}
+	if Client == nil {
+		RequestContext.JSON(http.StatusServiceUnavailable, gin.H{"Error": "vSphere is not Available"})
+		return 0, 0, nil, nil, false
+	}
+
+	VirtualMachine, FindError := deploy.NewVirtualMachineManager(*Client.Client).GetVirtualMachine(
+		strconv.Itoa(VirtualMachineID), strconv.Itoa(Credentials.UserId))
+	if FindError != nil {
+		RequestContext.JSON(http.StatusNotFound, gin.H{"Error": "Virtual Machine Does Not Exist"})
+		return 0, 0, nil, nil, false
+	}
+	return Credentials.UserId, VirtualMachineID, VirtualMachine, Key, true
+}
+
+func respondSshKeyError(RequestContext *gin.Context, Message string, OperationError error) {
+	// Writes the Error Response of the SSH Key Operation, whose Status Depends on the Reason of the Failure
+	Logger.Error(Message, zap.Error(OperationError))
+	switch {
+	case errors.Is(OperationError, ssh_config.ErrRateLimited):
+		RequestContext.JSON(http.StatusTooManyRequests, gin.H{"Error": OperationError.Error()})
+	case errors.Is(OperationError, ssh_config.ErrCustomerRequired):
+		RequestContext.JSON(http.StatusForbidden, gin.H{"Error": "You are Not Authorized"})
+	case errors.Is(OperationError, models.ErrInvalidSshPublicKey), errors.Is(OperationError, models.ErrDuplicateKey):
+		RequestContext.JSON(http.StatusBadRequest, gin.H{"Error": OperationError.Error()})
+	default:
+		RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": Message})
+	}
+}
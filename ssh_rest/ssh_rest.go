@@ -1,13 +1,37 @@
 package ssh_rest
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"net/url"
 	"os"
+	"time"
 	"github.com/LovePelmeni/Infrastructure/authentication"
+	"github.com/LovePelmeni/Infrastructure/logging"
+	"github.com/LovePelmeni/Infrastructure/tracing"
+	"github.com/vmware/govmomi"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 	"github.com/LovePelmeni/Infrastructure/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	APIIp    = os.Getenv("VMWARE_SOURCE_IP")
+	Username = os.Getenv("VMWARE_SOURCE_USERNAME")
+	Password = os.Getenv("VMWARE_SOURCE_PASSWORD")
+
+	APIUrl = &url.URL{
+		Scheme: "https",
+		Path:   "/sdk/",
+		Host:   APIIp,
+		User:   url.UserPassword(Username, Password),
+	}
+)
+
+var (
+	Client *govmomi.Client // Used to Upload and Rotate the SSH Keys of the Virtual Machines
 )
 
 var (
@@ -15,19 +39,24 @@ var (
 )
 
 func InitializeProductionLogger() {
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("SshRestLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("SshRestLog.json")
 }
 
 func init() {
 	// Initializing Logger 
 	InitializeProductionLogger()
+
+	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer CancelFunc()
+
+	APIClient, ConnectionError := govmomi.NewClient(TimeoutContext, APIUrl, false)
+	if ConnectionError != nil {
+		Logger.Error("FAILED TO INITIALIZE CLIENT, DOES THE VMWARE HYPERVISOR ACTUALLY RUNNING?")
+		return
+	}
+	tracing.InstrumentClient(APIClient.Client)
+	Client = APIClient
 }
 
 func GetDownloadPublicSshCertificateRestController(Context *gin.Context) {
@@ -40,8 +69,18 @@ func GetDownloadPublicSshCertificateRestController(Context *gin.Context) {
 	// Retrieving Virtual Machine Model Record 
 
 	var VirtualMachine models.VirtualMachine 
-	models.Database.Model(&models.VirtualMachine{}).Where(
-	"id = ? AND owner_id = ?", VirtualMachineId, VirtualMachineOwnerId).Find(&VirtualMachine)
+	Gorm := models.Database.Model(&models.VirtualMachine{}).Where(
+	"id = ? AND owner_id = ?", VirtualMachineId, VirtualMachineOwnerId).First(&VirtualMachine)
+
+	if errors.Is(Gorm.Error, gorm.ErrRecordNotFound) {
+		Context.JSON(http.StatusNotFound, gin.H{"Error": "Virtual Machine Does Not Exist"})
+		return
+	}
+	if Gorm.Error != nil {
+		Logger.Error("Failed to Retrieve Virtual Machine", zap.Error(Gorm.Error))
+		Context.JSON(http.StatusBadGateway, gin.H{"Error": "Failed to Retrieve Virtual Machine"})
+		return
+	}
 
 
 	// Obtaining Info about the Initializing the SSH Certificates 
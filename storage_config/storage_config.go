@@ -1,12 +1,11 @@
 package storage_config
 
 import (
-	"os"
-
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
 )
 
 var (
@@ -14,15 +13,8 @@ var (
 )
 
 func InitializeProductionLogger() {
-	// Initializing ZAP Logger
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("StorageConfigLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("StorageConfigLog.json")
 }
 
 func init() {
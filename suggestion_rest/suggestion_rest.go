@@ -13,10 +13,11 @@ import (
 	_ "time"
 
 	"github.com/LovePelmeni/Infrastructure/host_system"
+	"github.com/LovePelmeni/Infrastructure/logging"
 	"github.com/LovePelmeni/Infrastructure/resources"
+	"github.com/LovePelmeni/Infrastructure/tracing"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"github.com/gin-gonic/gin"
 	"github.com/vmware/govmomi"
@@ -49,15 +50,8 @@ var (
 )
 
 func InitializeProductionLogger() {
-
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("SuggestionsRestLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("SuggestionsRestLog.json")
 }
 
 func init() {
@@ -73,6 +67,7 @@ func init() {
 		Logger.Error("FAILED TO INITIALIZE CLIENT, DOES THE VMWARE HYPERVISOR ACTUALLY RUNNING?")
 
 	case ConnectionError == nil:
+		tracing.InstrumentClient(APIClient.Client)
 		RestClient = rest.NewClient(APIClient.Client)
 		if FailedToLogin := RestClient.Login(TimeoutContext, APIUrl.User); FailedToLogin != nil {
 			Logger.Error("FAILED TO LOGIN TO THE VMWARE HYPERVISOR SERVER, ERROR: %s", zap.Error(FailedToLogin))
@@ -0,0 +1,68 @@
+package backfill_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/backfill"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"golang.org/x/time/rate"
+)
+
+type BackfillTestSuite struct {
+	suite.Suite
+}
+
+func TestBackfillSuite(t *testing.T) {
+	suite.Run(t, new(BackfillTestSuite))
+}
+
+func (this *BackfillTestSuite) TestBackfillVMFields() {
+	Simulator := simulator.VPX()
+	Simulator.Create()
+	Server := Simulator.Service.NewServer()
+	defer Simulator.Remove()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+
+	VirtualMachine, _ := find.NewFinder(Client.Client).VirtualMachine(context.Background(), "/DC0/vm/DC0_H0_VM0")
+	SimulatorVirtualMachine := simulator.Map.Get(VirtualMachine.Reference()).(*simulator.VirtualMachine)
+	CreateDate := time.Date(2021, time.March, 14, 9, 26, 53, 0, time.UTC)
+	SimulatorVirtualMachine.Config.CreateDate = &CreateDate
+
+	// Row without the Fields, the Backfill is going to Fill
+	var CustomerID int
+	Name := fmt.Sprintf("backfill-%d", time.Now().UnixNano())
+	models.Database.Raw("INSERT INTO customers (username, email, password, city, country, zip_code, street) "+
+		"VALUES (?, ?, '', '', '', '', '') RETURNING id", Name, Name+"@example.com").Scan(&CustomerID)
+	defer models.Database.Unscoped().Where("id = ?", CustomerID).Delete(&models.Customer{})
+	var VirtualMachineID int
+	models.Database.Raw("INSERT INTO virtual_machines (state, owner_id, virtual_machine_name, item_path) "+
+		"VALUES ('', ?, ?, ?) RETURNING id", CustomerID, Name, "/DC0/vm/DC0_H0_VM0").Scan(&VirtualMachineID)
+	defer models.Database.Unscoped().Where("id = ?", VirtualMachineID).Delete(&models.VirtualMachine{})
+	models.Database.Where("name = ?", backfill.VMFieldsCheckpoint).Delete(&models.BackfillCheckpoint{})
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Empty Fields should be Filled from the vSphere, including the Creation Date", func(t *testing.T) {
+				_, BackfillError := backfill.BackfillVMFields(context.Background(), *Client.Client, 100, rate.Inf)
+				assert.NoError(this.T(), BackfillError)
+
+				var Record models.VirtualMachine
+				assert.NoError(this.T(), models.Database.Unscoped().Where("id = ?", VirtualMachineID).First(&Record).Error)
+				assert.Equal(this.T(), SimulatorVirtualMachine.Config.Uuid, Record.UUID)
+				assert.NotEmpty(this.T(), Record.State)
+				assert.True(this.T(), CreateDate.Equal(Record.CreatedAt), "Creation Date should be Read back from the Database, got %s", Record.CreatedAt)
+			}},
+		})
+}
@@ -0,0 +1,132 @@
+package certificates_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/certificates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi/object"
+)
+
+type CertificatesTestSuite struct {
+	suite.Suite
+}
+
+func TestCertificatesSuite(t *testing.T) {
+	suite.Run(t, new(CertificatesTestSuite))
+}
+
+func (this *CertificatesTestSuite) newCertificate(NotBefore time.Time, NotAfter time.Time) *x509.Certificate {
+	// Returns Self-Signed Certificate with the Validity Period
+	Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "esxi-01.local"},
+		NotBefore:    NotBefore,
+		NotAfter:     NotAfter,
+	}
+	Raw, CreateError := x509.CreateCertificate(rand.Reader, Template, Template, &Key.PublicKey, Key)
+	assert.NoError(this.T(), CreateError)
+	Certificate, ParseError := x509.ParseCertificate(Raw)
+	assert.NoError(this.T(), ParseError)
+	return Certificate
+}
+
+func (this *CertificatesTestSuite) TestToCertificateSummary() {
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Valid Certificate should be Summarized with the Fingerprint", func(t *testing.T) {
+				Info := (&object.HostCertificateInfo{}).FromCertificate(
+					this.newCertificate(time.Now().Add(-time.Hour), time.Now().Add(time.Hour*24*365)))
+				Summary, Error := certificates.ToCertificateSummary(Info)
+				assert.NoError(this.T(), Error)
+				assert.Equal(this.T(), "CN=esxi-01.local", Summary.Subject)
+				assert.Equal(this.T(), "CN=esxi-01.local", Summary.Issuer)
+				assert.Len(this.T(), Summary.Fingerprint, 95)
+				assert.Equal(this.T(), "good", Summary.Status)
+			}},
+
+			{"Expired Certificate should have the Expired Status", func(t *testing.T) {
+				Info := (&object.HostCertificateInfo{}).FromCertificate(
+					this.newCertificate(time.Now().Add(-time.Hour*48), time.Now().Add(-time.Hour)))
+				Summary, Error := certificates.ToCertificateSummary(Info)
+				assert.NoError(this.T(), Error)
+				assert.Equal(this.T(), "expired", Summary.Status)
+			}},
+
+			{"Missing and Empty Certificates should be Reported within the Status", func(t *testing.T) {
+				Summary, Error := certificates.ToCertificateSummary(nil)
+				assert.NoError(this.T(), Error)
+				assert.Equal(this.T(), certificates.StatusMissing, Summary.Status)
+
+				Summary, Error = certificates.ToCertificateSummary(&object.HostCertificateInfo{})
+				assert.NoError(this.T(), Error)
+				assert.Equal(this.T(), certificates.StatusUnparseable, Summary.Status)
+			}},
+		})
+}
+
+func (this *CertificatesTestSuite) TestGetServerCertificate() {
+	Server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer Server.Close()
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Self-Signed Server Certificate should be Untrusted", func(t *testing.T) {
+				Summary, Error := certificates.GetServerCertificate(Server.URL)
+				assert.NoError(this.T(), Error)
+				assert.Equal(this.T(), certificates.StatusUntrusted, Summary.Status)
+				assert.NotEmpty(this.T(), Summary.Fingerprint)
+			}},
+		})
+}
+
+func (this *CertificatesTestSuite) delayedFetcher(Old *x509.Certificate, New *x509.Certificate, Delay int) certificates.CertificateFetcher {
+	// Returns Fetcher, that Reports the Old Certificate for the first `Delay` Calls, as the Host does until `hostd` Reloads
+	Calls := 0
+	return func(Context context.Context) (*object.HostCertificateInfo, error) {
+		Served := New
+		if Calls++; Calls <= Delay {
+			Served = Old
+		}
+		Info := (&object.HostCertificateInfo{}).FromCertificate(Served)
+		Info.Certificate = nil // vSphere does not Return the Certificate itself
+		return Info, nil
+	}
+}
+
+func (this *CertificatesTestSuite) TestVerifyCertificate() {
+	Old := this.newCertificate(time.Now().Add(-time.Hour*24), time.Now().Add(time.Hour*24*30))
+	New := this.newCertificate(time.Now().Add(-time.Hour), time.Now().Add(time.Hour*24*365))
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Delayed Certificate should be Verified within the Window", func(t *testing.T) {
+				Summary, Error := certificates.VerifyCertificate(context.Background(), this.delayedFetcher(Old, New, 2), New,
+					certificates.InstallOptions{VerificationWindow: time.Second, VerificationInterval: time.Millisecond * 10})
+				assert.NoError(this.T(), Error)
+				assert.Equal(this.T(), New.NotAfter.Unix(), Summary.NotAfter.Unix())
+			}},
+
+			{"Immediate Verification should Fail, if the Certificate is Delayed", func(t *testing.T) {
+				Summary, Error := certificates.VerifyCertificate(context.Background(), this.delayedFetcher(Old, New, 2), New,
+					certificates.InstallOptions{VerificationInterval: time.Millisecond * 10})
+				assert.ErrorIs(this.T(), Error, certificates.ErrCertificateNotEffective)
+				assert.Equal(this.T(), Old.NotAfter.Unix(), Summary.NotAfter.Unix(), "Summary should Describe the Served Certificate")
+			}},
+		})
+}
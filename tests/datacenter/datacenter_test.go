@@ -0,0 +1,140 @@
+package datacenter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LovePelmeni/Infrastructure/datacenter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type DatacenterTestSuite struct {
+	suite.Suite
+	Simulator *simulator.Model
+	Server    *simulator.Server
+	Client    *govmomi.Client
+}
+
+func TestDatacenterSuite(t *testing.T) {
+	suite.Run(t, new(DatacenterTestSuite))
+}
+
+func (this *DatacenterTestSuite) SetupTest() {
+	this.Simulator = simulator.VPX()
+	this.Simulator.Datacenter = 2
+	this.Simulator.Create()
+	this.Server = this.Simulator.Service.NewServer()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), this.Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	this.Client = Client
+}
+
+func (this *DatacenterTestSuite) TearDownTest() {
+	this.Server.Close()
+	this.Simulator.Remove()
+}
+
+func (this *DatacenterTestSuite) TestDatacenterResolver() {
+	Resolver := datacenter.NewDatacenterResolver(*this.Client.Client)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Datacenters should be Resolved and Cached by their Names", func(t *testing.T) {
+				First, FirstError := Resolver.Resolve(context.Background(), "DC0")
+				Second, SecondError := Resolver.Resolve(context.Background(), "DC1")
+				assert.NoError(this.T(), FirstError)
+				assert.NoError(this.T(), SecondError)
+				assert.NotEqual(this.T(), First.Reference(), Second.Reference())
+				assert.True(this.T(), Resolver.IsCached("DC0"))
+				assert.True(this.T(), Resolver.IsCached("DC1"))
+
+				Cached, _ := Resolver.Resolve(context.Background(), "DC0")
+				assert.Equal(this.T(), First.Reference(), Cached.Reference())
+				assert.Equal(this.T(), First.InventoryPath, Cached.InventoryPath)
+			}},
+
+			{"Default Datacenter can't be Resolved, if there are Multiple ones", func(t *testing.T) {
+				_, ResolveError := Resolver.Resolve(context.Background(), datacenter.DefaultDatacenterName)
+				assert.Error(this.T(), ResolveError)
+				assert.False(this.T(), Resolver.IsCached(datacenter.DefaultDatacenterName))
+			}},
+
+			{"Finder should Search within the Resolved Datacenter", func(t *testing.T) {
+				Finder, FinderError := Resolver.NewFinder(context.Background(), "DC1")
+				assert.NoError(this.T(), FinderError)
+				VirtualMachine, FindError := Finder.VirtualMachine(context.Background(), "DC1_H0_VM0")
+				assert.NoError(this.T(), FindError)
+				assert.NotNil(this.T(), VirtualMachine)
+			}},
+
+			{"Invalidated Datacenter should be Resolved again", func(t *testing.T) {
+				Resolver.Invalidate("DC0")
+				assert.False(this.T(), Resolver.IsCached("DC0"))
+				assert.True(this.T(), Resolver.IsCached("DC1"))
+				Resolver.InvalidateAll()
+				assert.False(this.T(), Resolver.IsCached("DC1"))
+			}},
+
+			{"Resolvers of the same vCenter should Share the Cache", func(t *testing.T) {
+				datacenter.GetDatacenterResolver(*this.Client.Client).Resolve(context.Background(), "DC0")
+				assert.True(this.T(), datacenter.GetDatacenterResolver(*this.Client.Client).IsCached("DC0"))
+			}},
+		})
+}
+
+func (this *DatacenterTestSuite) TestFindVirtualMachine() {
+	Client := this.Client.Client
+	Finder := find.NewFinder(Client)
+	Datacenter, _ := Finder.Datacenter(context.Background(), "DC0")
+	Finder.SetDatacenter(Datacenter)
+	Source, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+
+	// Virtual Machine with the same Name, but in another Folder
+	Folders, _ := Datacenter.Folders(context.Background())
+	Folder, _ := Folders.VmFolder.CreateFolder(context.Background(), "duplicates")
+	Pool, _ := Source.ResourcePool(context.Background())
+	PoolReference := Pool.Reference()
+	CloneTask, _ := Source.Clone(context.Background(), Folder, "DC0_H0_VM0",
+		types.VirtualMachineCloneSpec{Location: types.VirtualMachineRelocateSpec{Pool: &PoolReference}})
+	assert.NoError(this.T(), CloneTask.Wait(context.Background()))
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Virtual Machine should be Found by the Name", func(t *testing.T) {
+				VirtualMachine, FindError := datacenter.FindVirtualMachineByName(context.Background(), Client, "DC0", "DC0_H0_VM1")
+				assert.NoError(this.T(), FindError)
+				assert.Equal(this.T(), "/DC0/vm/DC0_H0_VM1", VirtualMachine.InventoryPath)
+			}},
+
+			{"Virtual Machine should be Found by the Path", func(t *testing.T) {
+				VirtualMachine, FindError := datacenter.FindVirtualMachineByPath(context.Background(), Client, "DC0", "duplicates/DC0_H0_VM0")
+				assert.NoError(this.T(), FindError)
+				assert.Equal(this.T(), "/DC0/vm/duplicates/DC0_H0_VM0", VirtualMachine.InventoryPath)
+			}},
+
+			{"Missing Virtual Machine should be Reported", func(t *testing.T) {
+				_, FindError := datacenter.FindVirtualMachineByName(context.Background(), Client, "DC0", "missing")
+				assert.ErrorIs(this.T(), FindError, datacenter.ErrVirtualMachineNotFound)
+
+				_, FindError = datacenter.FindVirtualMachineByPath(context.Background(), Client, "DC0", "/DC0/vm/missing")
+				assert.ErrorIs(this.T(), FindError, datacenter.ErrVirtualMachineNotFound)
+			}},
+
+			{"Several Matches should be Reported as Ambiguous", func(t *testing.T) {
+				_, FindError := datacenter.FindVirtualMachineByName(context.Background(), Client, "DC0", "DC0_H0_VM0")
+				assert.ErrorIs(this.T(), FindError, datacenter.ErrAmbiguousVirtualMachine)
+
+				_, FindError = datacenter.FindVirtualMachineByPath(context.Background(), Client, "DC0", "DC0_H0_*")
+				assert.ErrorIs(this.T(), FindError, datacenter.ErrAmbiguousVirtualMachine)
+				assert.NotErrorIs(this.T(), FindError, datacenter.ErrVirtualMachineNotFound)
+			}},
+		})
+}
@@ -0,0 +1,174 @@
+package drs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LovePelmeni/Infrastructure/drs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Simulator does not Implement the Manual DRS, so the Power On of the Datacenter and the Apply of the Recommendation
+// are Redirected by the Client to the Stand-In Objects, that Return the Placement Recommendations and Apply them,
+// the Datacenter and the Cluster of the Simulator itself are Left in Place, as the Simulator Expects their Original Types
+
+type manualDrsDatacenter struct {
+	Self       types.ManagedObjectReference
+	Datacenter *simulator.Datacenter
+	Cluster    types.ManagedObjectReference
+}
+
+func (this *manualDrsDatacenter) Reference() types.ManagedObjectReference {
+	return this.Self
+}
+
+func (this *manualDrsDatacenter) PowerOnMultiVMTask(ctx *simulator.Context, req *types.PowerOnMultiVM_Task) soap.HasFault {
+	Task := simulator.CreateTask(this.Datacenter, "powerOnMultiVM", func(*simulator.Task) (types.AnyType, types.BaseMethodFault) {
+		return types.ClusterPowerOnVmResult{Recommendations: []types.ClusterRecommendation{
+			{Key: "low", Rating: 1, Target: &this.Cluster},
+			{Key: "high", Rating: 5, Target: &this.Cluster},
+		}}, nil
+	})
+	return &methods.PowerOnMultiVM_TaskBody{Res: &types.PowerOnMultiVM_TaskResponse{Returnval: Task.Run(ctx)}}
+}
+
+type manualDrsCluster struct {
+	Self           types.ManagedObjectReference
+	VirtualMachine types.ManagedObjectReference
+	Applied        []string
+}
+
+func (this *manualDrsCluster) Reference() types.ManagedObjectReference {
+	return this.Self
+}
+
+func (this *manualDrsCluster) ApplyRecommendation(ctx *simulator.Context, req *types.ApplyRecommendation) soap.HasFault {
+	this.Applied = append(this.Applied, req.Key)
+	VirtualMachine := ctx.Map.Get(this.VirtualMachine).(*simulator.VirtualMachine)
+	ctx.WithLock(VirtualMachine, func() {
+		VirtualMachine.PowerOnVMTask(ctx, &types.PowerOnVM_Task{})
+	})
+	return &methods.ApplyRecommendationBody{Res: &types.ApplyRecommendationResponse{}}
+}
+
+type redirectingRoundTripper struct {
+	// Sends the DRS Calls of the Real Objects to their Stand-Ins, other Calls are Passed as is
+	soap.RoundTripper
+	Redirects map[types.ManagedObjectReference]types.ManagedObjectReference
+}
+
+func (this *redirectingRoundTripper) RoundTrip(ctx context.Context, Request, Response soap.HasFault) error {
+	switch Body := Request.(type) {
+	case *methods.PowerOnMultiVM_TaskBody:
+		Body.Req.This = this.redirect(Body.Req.This)
+	case *methods.ApplyRecommendationBody:
+		Body.Req.This = this.redirect(Body.Req.This)
+	}
+	return this.RoundTripper.RoundTrip(ctx, Request, Response)
+}
+
+func (this *redirectingRoundTripper) redirect(Reference types.ManagedObjectReference) types.ManagedObjectReference {
+	if Target, Redirected := this.Redirects[Reference]; Redirected {
+		return Target
+	}
+	return Reference
+}
+
+type DrsTestSuite struct {
+	suite.Suite
+	Simulator      *simulator.Model
+	Server         *simulator.Server
+	Client         *govmomi.Client
+	Cluster        *manualDrsCluster
+	VirtualMachine *object.VirtualMachine
+}
+
+func TestDrsSuite(t *testing.T) {
+	suite.Run(t, new(DrsTestSuite))
+}
+
+func (this *DrsTestSuite) SetupTest() {
+	this.Simulator = simulator.VPX()
+	this.Simulator.Create()
+	this.Server = this.Simulator.Service.NewServer()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), this.Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	this.Client = Client
+
+	VirtualMachine := simulator.Map.Any("VirtualMachine").(*simulator.VirtualMachine)
+	this.VirtualMachine = object.NewVirtualMachine(Client.Client, VirtualMachine.Reference())
+	PowerOffTask, _ := this.VirtualMachine.PowerOff(context.Background())
+	assert.NoError(this.T(), PowerOffTask.Wait(context.Background()))
+
+	Cluster := simulator.Map.Any("ClusterComputeResource").(*simulator.ClusterComputeResource)
+	Datacenter := simulator.Map.Any("Datacenter").(*simulator.Datacenter)
+	this.Cluster = &manualDrsCluster{VirtualMachine: VirtualMachine.Reference()}
+	simulator.Map.Put(this.Cluster)
+	StandInDatacenter := &manualDrsDatacenter{Datacenter: Datacenter, Cluster: Cluster.Reference()}
+	simulator.Map.Put(StandInDatacenter)
+
+	Client.Client.RoundTripper = &redirectingRoundTripper{RoundTripper: Client.Client.RoundTripper,
+		Redirects: map[types.ManagedObjectReference]types.ManagedObjectReference{
+			Datacenter.Reference(): StandInDatacenter.Reference(),
+			Cluster.Reference():    this.Cluster.Reference(),
+		}}
+}
+
+func (this *DrsTestSuite) TearDownTest() {
+	this.Server.Close()
+	this.Simulator.Remove()
+}
+
+func (this *DrsTestSuite) powerState() types.VirtualMachinePowerState {
+	PowerState, _ := this.VirtualMachine.PowerState(context.Background())
+	return PowerState
+}
+
+func (this *DrsTestSuite) TestRecommendationRequired() {
+	Manager := drs.NewVirtualMachinePowerOnManager(*this.Client.Client)
+	Manager.AutoApply = false
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Recommendations should be Returned to the Caller, if Auto Apply is Disabled", func(t *testing.T) {
+				Recommendations, PowerOnError := Manager.PowerOn(context.Background(), this.VirtualMachine)
+				assert.ErrorIs(this.T(), PowerOnError, drs.ErrRecommendationRequired)
+				assert.Len(this.T(), Recommendations, 2)
+				assert.Equal(this.T(), "high", Recommendations[0].Key)
+				assert.Empty(this.T(), this.Cluster.Applied)
+				assert.Equal(this.T(), types.VirtualMachinePowerStatePoweredOff, this.powerState())
+			}},
+
+			{"Chosen Recommendation should Power On the Virtual Machine", func(t *testing.T) {
+				Recommendations, _ := Manager.PowerOn(context.Background(), this.VirtualMachine)
+				assert.NoError(this.T(), Manager.ApplyRecommendation(context.Background(), this.VirtualMachine, Recommendations[1]))
+				assert.Equal(this.T(), []string{"low"}, this.Cluster.Applied)
+				assert.Equal(this.T(), types.VirtualMachinePowerStatePoweredOn, this.powerState())
+			}},
+		})
+}
+
+func (this *DrsTestSuite) TestAutoApplyRecommendation() {
+	Manager := drs.NewVirtualMachinePowerOnManager(*this.Client.Client)
+	Manager.AutoApply = true
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Top Rated Recommendation should be Applied Automatically", func(t *testing.T) {
+				_, PowerOnError := Manager.PowerOn(context.Background(), this.VirtualMachine)
+				assert.NoError(this.T(), PowerOnError)
+				assert.Equal(this.T(), []string{"high"}, this.Cluster.Applied)
+				assert.Equal(this.T(), types.VirtualMachinePowerStatePoweredOn, this.powerState())
+			}},
+		})
+}
@@ -0,0 +1,62 @@
+package guest_test
+
+import (
+	"testing"
+
+	"github.com/LovePelmeni/Infrastructure/guest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GuestTestSuite struct {
+	suite.Suite
+}
+
+func TestGuestSuite(t *testing.T) {
+	suite.Run(t, new(GuestTestSuite))
+}
+
+func (this *GuestTestSuite) TestGuestNetworkConfig() {
+	Config := guest.GuestNetworkConfig{Interface: "ens192", IPAddress: "10.0.0.5", PrefixLength: 24,
+		Gateway: "10.0.0.1", DNSServers: []string{"1.1.1.1", "8.8.8.8"}}
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Valid Configuration should pass the Validation", func(t *testing.T) {
+				assert.NoError(this.T(), Config.Validate())
+			}},
+
+			{"Invalid Inputs should be Rejected", func(t *testing.T) {
+				Invalid := []guest.GuestNetworkConfig{Config, Config, Config, Config, Config}
+				Invalid[0].IPAddress = "10.0.0.256"
+				Invalid[1].PrefixLength = 33
+				Invalid[2].Gateway = "192.168.1.1"
+				Invalid[3].DNSServers = []string{"not-an-ip"}
+				Invalid[4].Interface = "eth0; reboot"
+				for _, InvalidConfig := range Invalid {
+					assert.Error(this.T(), InvalidConfig.Validate())
+				}
+			}},
+
+			{"Guest Distribution should be Detected by the Guest ID", func(t *testing.T) {
+				Flavor, _ := guest.DetectNetworkFlavor("ubuntu64Guest")
+				assert.Equal(this.T(), guest.FlavorNetplan, Flavor)
+				Flavor, _ = guest.DetectNetworkFlavor("rhel8_64Guest")
+				assert.Equal(this.T(), guest.FlavorIfcfg, Flavor)
+				_, DetectError := guest.DetectNetworkFlavor("windows9Server64Guest")
+				assert.ErrorIs(this.T(), DetectError, guest.ErrUnsupportedGuestDistribution)
+			}},
+
+			{"Netplan Config should contain the Address, Gateway and DNS Servers", func(t *testing.T) {
+				Netplan := Config
+				Netplan.Flavor = guest.FlavorNetplan
+				Path, Content, _, RenderError := Netplan.Render()
+				assert.NoError(this.T(), RenderError)
+				assert.Equal(this.T(), "/etc/netplan/60-ens192-static.yaml", Path)
+				assert.Contains(this.T(), string(Content), "addresses: [10.0.0.5/24]")
+				assert.Contains(this.T(), string(Content), "via: 10.0.0.1")
+				assert.Contains(this.T(), string(Content), "addresses: [1.1.1.1, 8.8.8.8]")
+			}},
+		})
+}
@@ -0,0 +1,95 @@
+package guest_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/guest"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type SyncTestSuite struct {
+	suite.Suite
+}
+
+func TestSyncSuite(t *testing.T) {
+	suite.Run(t, new(SyncTestSuite))
+}
+
+func (this *SyncTestSuite) TestSyncStatesForOwner() {
+	Simulator := simulator.VPX()
+	Simulator.Create()
+	Server := Simulator.Service.NewServer()
+	defer Simulator.Remove()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Finder := find.NewFinder(Client.Client)
+
+	// Running Virtual Machine is Matched by the UUID, the Stopped one by the Full Inventory Path
+	Running, _ := Finder.VirtualMachine(context.Background(), "/DC0/vm/DC0_H0_VM0")
+	RunningVirtualMachine := simulator.Map.Get(Running.Reference()).(*simulator.VirtualMachine)
+	simulator.Map.Update(RunningVirtualMachine, []types.PropertyChange{
+		{Name: "guest.guestState", Val: string(types.VirtualMachineGuestStateRunning)},
+		{Name: "guest.toolsRunningStatus", Val: string(types.VirtualMachineToolsRunningStatusGuestToolsRunning)},
+	})
+	Stopped, _ := Finder.VirtualMachine(context.Background(), "/DC0/vm/DC0_H0_VM1")
+	simulator.Map.Update(simulator.Map.Get(Stopped.Reference()).(*simulator.VirtualMachine), []types.PropertyChange{
+		{Name: "runtime.powerState", Val: types.VirtualMachinePowerStatePoweredOff},
+	})
+
+	var CustomerID int
+	Name := fmt.Sprintf("sync-%d", time.Now().UnixNano())
+	models.Database.Raw("INSERT INTO customers (username, email, password, city, country, zip_code, street) "+
+		"VALUES (?, ?, '', '', '', '', '') RETURNING id", Name, Name+"@example.com").Scan(&CustomerID)
+	defer models.Database.Unscoped().Where("id = ?", CustomerID).Delete(&models.Customer{})
+
+	createVirtualMachine := func(Name string, ItemPath string, UUID string, State string) int {
+		var VirtualMachineID int
+		models.Database.Raw("INSERT INTO virtual_machines (state, owner_id, virtual_machine_name, item_path, uuid) "+
+			"VALUES (?, ?, ?, ?, ?) RETURNING id", State, CustomerID, Name, ItemPath, UUID).Scan(&VirtualMachineID)
+		return VirtualMachineID
+	}
+	RunningID := createVirtualMachine(Name+"-running", "/DC0/vm/Renamed", RunningVirtualMachine.Config.Uuid, models.StatusNotReady)
+	StoppedID := createVirtualMachine(Name+"-stopped", "/DC0/vm/DC0_H0_VM1", "", models.StatusReady)
+	// Same Name as the Existing Virtual Machine, but in the other Folder, it should not be Matched by the Name alone
+	MissingID := createVirtualMachine(Name+"-missing", "/DC0/vm/Other/DC0_H0_VM0", "", models.StatusReady)
+	defer models.Database.Unscoped().Where("id IN ?", []int{RunningID, StoppedID, MissingID}).Delete(&models.VirtualMachine{})
+
+	stateOf := func(VirtualMachineID int) string {
+		var Record models.VirtualMachine
+		models.Database.Where("id = ?", VirtualMachineID).First(&Record)
+		return Record.State
+	}
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"States should be Synced with the vSphere, Missing Virtual Machine should be Reported", func(t *testing.T) {
+				Errors, SyncError := guest.SyncStatesForOwner(*Client.Client, strconv.Itoa(CustomerID))
+				assert.NoError(this.T(), SyncError)
+				assert.Equal(this.T(), models.StatusReady, stateOf(RunningID))
+				assert.Equal(this.T(), models.StatusNotReady, stateOf(StoppedID))
+
+				assert.Len(this.T(), Errors, 1)
+				assert.ErrorIs(this.T(), Errors[strconv.Itoa(MissingID)], guest.ErrVirtualMachineNotFound)
+				assert.Equal(this.T(), models.StatusReady, stateOf(MissingID))
+			}},
+
+			{"Customer without Virtual Machines should have Nothing to Sync", func(t *testing.T) {
+				Errors, SyncError := guest.SyncStatesForOwner(*Client.Client, "-1")
+				assert.NoError(this.T(), SyncError)
+				assert.Empty(this.T(), Errors)
+			}},
+		})
+}
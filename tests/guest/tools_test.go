@@ -0,0 +1,86 @@
+package guest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/guest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type ToolsTestSuite struct {
+	suite.Suite
+	Simulator      *simulator.Model
+	Server         *simulator.Server
+	VirtualMachine *object.VirtualMachine
+}
+
+func TestToolsSuite(t *testing.T) {
+	suite.Run(t, new(ToolsTestSuite))
+}
+
+func (this *ToolsTestSuite) SetupTest() {
+	this.Simulator = simulator.VPX()
+	this.Simulator.Create()
+	this.Server = this.Simulator.Service.NewServer()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), this.Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	this.VirtualMachine, _ = find.NewFinder(Client.Client).VirtualMachine(context.Background(), "/DC0/vm/DC0_H0_VM0")
+	this.setToolsStatus(types.VirtualMachineToolsStatusToolsNotRunning)
+}
+
+func (this *ToolsTestSuite) TearDownTest() {
+	this.Server.Close()
+	this.Simulator.Remove()
+}
+
+func (this *ToolsTestSuite) setToolsStatus(Status types.VirtualMachineToolsStatus) {
+	// Changes the Tools Status of the Simulated Virtual Machine, as if the Guest has Started or Stopped them
+	VirtualMachine := simulator.Map.Get(this.VirtualMachine.Reference()).(*simulator.VirtualMachine)
+	simulator.Map.Update(VirtualMachine, []types.PropertyChange{{Name: "guest.toolsStatus", Val: Status}})
+}
+
+func (this *ToolsTestSuite) TestWaitForGuestTools() {
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Wait should Return, once the Tools have Started", func(t *testing.T) {
+				go func() {
+					time.Sleep(time.Millisecond * 1500)
+					this.setToolsStatus(types.VirtualMachineToolsStatusToolsOk)
+				}()
+				Started := time.Now()
+				assert.NoError(this.T(), guest.WaitForGuestTools(context.Background(), this.VirtualMachine, time.Second*10))
+				assert.GreaterOrEqual(this.T(), time.Since(Started), time.Millisecond*1500)
+			}},
+
+			{"Wait should Return Immediately, if the Tools are already Running", func(t *testing.T) {
+				Started := time.Now()
+				assert.NoError(this.T(), guest.WaitForGuestTools(context.Background(), this.VirtualMachine, time.Second*10))
+				assert.Less(this.T(), time.Since(Started), guest.ToolsPollInterval)
+			}},
+
+			{"Timeout Error should contain the Last Observed Status", func(t *testing.T) {
+				this.setToolsStatus(types.VirtualMachineToolsStatusToolsNotRunning)
+				WaitError := guest.WaitForGuestTools(context.Background(), this.VirtualMachine, time.Millisecond*1500)
+				assert.ErrorIs(this.T(), WaitError, guest.ErrToolsWaitTimeout)
+				assert.ErrorContains(this.T(), WaitError, string(types.VirtualMachineToolsStatusToolsNotRunning))
+			}},
+
+			{"Cancellation of the Caller Context should Stop the Wait", func(t *testing.T) {
+				Cancelled, CancelFunc := context.WithCancel(context.Background())
+				CancelFunc()
+				WaitError := guest.WaitForGuestTools(Cancelled, this.VirtualMachine, time.Second*10)
+				assert.ErrorIs(this.T(), WaitError, context.Canceled)
+				assert.NotErrorIs(this.T(), WaitError, guest.ErrToolsWaitTimeout)
+			}},
+		})
+}
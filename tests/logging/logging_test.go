@@ -0,0 +1,66 @@
+package logging_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+)
+
+type LoggingTestSuite struct {
+	suite.Suite
+}
+
+func TestLoggingSuite(t *testing.T) {
+	suite.Run(t, new(LoggingTestSuite))
+}
+
+func (this *LoggingTestSuite) TestNewLogger() {
+	Directory := this.T().TempDir()
+	DefaultLogFile := filepath.Join(Directory, "PackageLog.json")
+	defer os.Unsetenv("LOG_LEVEL")
+	defer os.Unsetenv("LOG_FILE")
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Package Log File and Debug Level should be Used by Default", func(t *testing.T) {
+				Logger, File, LoggerError := logging.NewLogger(DefaultLogFile)
+				assert.NoError(this.T(), LoggerError)
+				defer File.Close()
+				assert.Equal(this.T(), DefaultLogFile, File.Name())
+				assert.True(this.T(), Logger.Core().Enabled(zapcore.DebugLevel))
+			}},
+
+			{"Log Level and Log File should be Taken from the Environment", func(t *testing.T) {
+				os.Setenv("LOG_LEVEL", "error")
+				os.Setenv("LOG_FILE", filepath.Join(Directory, "Main.json"))
+				defer os.Unsetenv("LOG_LEVEL")
+				defer os.Unsetenv("LOG_FILE")
+
+				Logger, File, LoggerError := logging.NewLogger(DefaultLogFile)
+				assert.NoError(this.T(), LoggerError)
+				defer File.Close()
+				assert.Equal(this.T(), filepath.Join(Directory, "Main.json"), File.Name())
+				assert.False(this.T(), Logger.Core().Enabled(zapcore.WarnLevel))
+				assert.True(this.T(), Logger.Core().Enabled(zapcore.ErrorLevel))
+			}},
+
+			{"Unwritable Log File should Fall back to the Stderr", func(t *testing.T) {
+				MissingLogFile := filepath.Join(Directory, "missing", "PackageLog.json")
+				_, _, LoggerError := logging.NewLogger(MissingLogFile)
+				assert.Error(this.T(), LoggerError)
+
+				os.Setenv("LOG_LEVEL", "warn")
+				defer os.Unsetenv("LOG_LEVEL")
+				Logger := logging.NewProductionLogger(MissingLogFile)
+				assert.NotNil(this.T(), Logger)
+				assert.False(this.T(), Logger.Core().Enabled(zapcore.InfoLevel))
+				assert.True(this.T(), Logger.Core().Enabled(zapcore.WarnLevel))
+			}},
+		})
+}
@@ -0,0 +1,126 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LovePelmeni/Infrastructure/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type NetworkTestSuite struct {
+	suite.Suite
+	Simulator *simulator.Model
+	Server    *simulator.Server
+	Client    *govmomi.Client
+	Manager   *network.VirtualMachinePrivateNetworkManager
+}
+
+func TestNetworkSuite(t *testing.T) {
+	suite.Run(t, new(NetworkTestSuite))
+}
+
+func (this *NetworkTestSuite) SetupTest() {
+	this.Simulator = simulator.VPX()
+	this.Simulator.Create()
+	this.Server = this.Simulator.Service.NewServer()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), this.Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	this.Client = Client
+	this.Manager = network.NewVirtualMachinePrivateNetworkManager(*Client.Client)
+}
+
+func (this *NetworkTestSuite) TearDownTest() {
+	this.Server.Close()
+	this.Simulator.Remove()
+}
+
+func (this *NetworkTestSuite) createVirtualMachine(Name string) *object.VirtualMachine {
+	// Creates new Virtual Machine without any Network Adapter
+	Finder := find.NewFinder(this.Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	Folders, _ := Datacenter.Folders(context.Background())
+	ResourcePool, _ := Finder.DefaultResourcePool(context.Background())
+	if ResourcePool == nil {
+		Template, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+		ResourcePool, _ = Template.ResourcePool(context.Background())
+	}
+
+	CreateTask, _ := Folders.VmFolder.CreateVM(context.Background(), types.VirtualMachineConfigSpec{
+		Name:    Name,
+		GuestId: string(types.VirtualMachineGuestOsIdentifierOtherGuest),
+		Files:   &types.VirtualMachineFileInfo{VmPathName: "[LocalDS_0]"},
+	}, ResourcePool, nil)
+	TaskInfo, CreateError := CreateTask.WaitForResult(context.Background(), nil)
+	assert.NoError(this.T(), CreateError)
+	return object.NewVirtualMachine(this.Client.Client, TaskInfo.Result.(types.ManagedObjectReference))
+}
+
+func (this *NetworkTestSuite) networkAdapters(VirtualMachine *object.VirtualMachine) []types.BaseVirtualDevice {
+	Devices, _ := VirtualMachine.Device(context.Background())
+	return Devices.SelectByType((*types.VirtualEthernetCard)(nil))
+}
+
+func (this *NetworkTestSuite) TestAttachNetworkAdapters() {
+	VirtualMachine := this.createVirtualMachine("dual-homed")
+	Specs := []network.NetworkAdapterSpec{
+		{Network: "VM Network"},
+		{Network: "DC0_DVPG0", AdapterType: "e1000e"},
+	}
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Virtual Machine should get Network Adapter on every Network", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.AttachNetworkAdapters(VirtualMachine, Specs))
+
+				Cards := this.networkAdapters(VirtualMachine)
+				assert.Len(this.T(), Cards, 2)
+				assert.IsType(this.T(), &types.VirtualVmxnet3{}, Cards[0])
+				assert.IsType(this.T(), &types.VirtualEthernetCardNetworkBackingInfo{}, Cards[0].GetVirtualDevice().Backing)
+				assert.IsType(this.T(), &types.VirtualE1000e{}, Cards[1])
+				assert.IsType(this.T(), &types.VirtualEthernetCardDistributedVirtualPortBackingInfo{}, Cards[1].GetVirtualDevice().Backing)
+			}},
+
+			{"Attaching the same Adapters again should not Add new ones", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.AttachNetworkAdapters(VirtualMachine, Specs))
+				assert.Len(this.T(), this.networkAdapters(VirtualMachine), 2)
+			}},
+
+			{"Adapter can't be Attached with the Gap in the Indexes", func(t *testing.T) {
+				assert.Error(this.T(), this.Manager.AttachNetworkAdapter(VirtualMachine, 5, network.NetworkAdapterSpec{Network: "VM Network"}))
+			}},
+		})
+}
+
+func (this *NetworkTestSuite) TestValidateNetworkAdapters() {
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Adapters on the same Network should be Rejected", func(t *testing.T) {
+				assert.Error(this.T(), network.ValidateNetworkAdapters([]network.NetworkAdapterSpec{
+					{Network: "VM Network"}, {Network: "VM Network", AdapterType: "e1000"}}))
+			}},
+
+			{"Unsupported Adapter Type should be Rejected", func(t *testing.T) {
+				assert.Error(this.T(), network.ValidateNetworkAdapters([]network.NetworkAdapterSpec{
+					{Network: "VM Network", AdapterType: "token-ring"}}))
+			}},
+
+			{"More Adapters, than vSphere Supports, should be Rejected", func(t *testing.T) {
+				Specs := []network.NetworkAdapterSpec{}
+				for Index := 0; Index <= network.MaxNetworkAdapters; Index++ {
+					Specs = append(Specs, network.NetworkAdapterSpec{Network: string(rune('a' + Index))})
+				}
+				assert.ErrorIs(this.T(), network.ValidateNetworkAdapters(Specs), network.ErrTooManyNetworkAdapters)
+			}},
+		})
+}
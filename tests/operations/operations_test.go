@@ -0,0 +1,77 @@
+package operations_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OperationsTestSuite struct {
+	suite.Suite
+	Registry *operations.OperationRegistry
+}
+
+func TestOperationsSuite(t *testing.T) {
+	suite.Run(t, new(OperationsTestSuite))
+}
+
+func (this *OperationsTestSuite) SetupTest() {
+	this.Registry = operations.NewOperationRegistry()
+}
+
+func (this *OperationsTestSuite) TestActiveOperations() {
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Tracked Operations should be Listed until they are Completed", func(t *testing.T) {
+				DoneClone := this.Registry.Track(operations.OperationClone, "web-1")
+				DoneRotation := this.Registry.Track(operations.OperationCertificateRotation, "esxi-01")
+
+				Active := this.Registry.List()
+				if assert.Len(this.T(), Active, 2) {
+					assert.Equal(this.T(), operations.OperationClone, Active[0].Type)
+					assert.Equal(this.T(), "web-1", Active[0].Target)
+				}
+
+				DoneClone()
+				DoneClone()
+				assert.Len(this.T(), this.Registry.List(), 1)
+				DoneRotation()
+				assert.Empty(this.T(), this.Registry.List())
+			}},
+		})
+}
+
+func (this *OperationsTestSuite) TestShutdown() {
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Shutdown should Wait for the In-Flight Operations", func(t *testing.T) {
+				Done := this.Registry.Track(operations.OperationClone, "web-1")
+				go func() {
+					time.Sleep(time.Millisecond * 50)
+					Done()
+				}()
+				Running, Error := this.Registry.Shutdown(context.Background())
+				assert.NoError(this.T(), Error)
+				assert.Empty(this.T(), Running)
+			}},
+
+			{"Shutdown should Report Operations, that are still Running after the Deadline", func(t *testing.T) {
+				Done := this.Registry.Track(operations.OperationRelocation, "db-1")
+				defer Done()
+
+				Context, Cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+				defer Cancel()
+				Running, Error := this.Registry.Shutdown(Context)
+				assert.ErrorIs(this.T(), Error, operations.ErrShutdownDeadline)
+				if assert.Len(this.T(), Running, 1) {
+					assert.Equal(this.T(), "db-1", Running[0].Target)
+				}
+			}},
+		})
+}
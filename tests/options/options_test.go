@@ -0,0 +1,84 @@
+package options_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OptionsTestSuite struct {
+	suite.Suite
+}
+
+func TestOptionsSuite(t *testing.T) {
+	suite.Run(t, new(OptionsTestSuite))
+}
+
+func (this *OptionsTestSuite) TearDownTest() {
+	options.SetDefaultOptions()
+}
+
+func (this *OptionsTestSuite) TestOperationOptions() {
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Operation Default should be Used, if Nothing is Specified", func(t *testing.T) {
+				Operation := options.NewOperationOptions(time.Second * 10)
+				assert.Equal(this.T(), time.Second*10, Operation.Timeout)
+				assert.Zero(this.T(), Operation.RetryAttempts)
+				assert.NotNil(this.T(), Operation.Context)
+			}},
+
+			{"Call Options should take Precedence over the Project-Wide Policy", func(t *testing.T) {
+				options.SetDefaultOptions(options.WithTimeout(time.Minute), options.WithRetryAttempts(2))
+				defer options.SetDefaultOptions()
+
+				Operation := options.NewOperationOptions(time.Second*10, options.WithTimeout(time.Second))
+				assert.Equal(this.T(), time.Second, Operation.Timeout)
+				assert.Equal(this.T(), 2, Operation.RetryAttempts)
+			}},
+
+			{"Cancelled Parent Context should Cancel the Operation", func(t *testing.T) {
+				Parent, Cancel := context.WithCancel(context.Background())
+				Cancel()
+				TimeoutContext, CancelFunc := options.NewOperationOptions(time.Minute, options.WithContext(Parent)).NewContext()
+				defer CancelFunc()
+				assert.ErrorIs(this.T(), TimeoutContext.Err(), context.Canceled)
+			}},
+		})
+}
+
+func (this *OptionsTestSuite) TestRetry() {
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Failed Operation should be Retried until it Succeeds", func(t *testing.T) {
+				Attempts := 0
+				Operation := options.NewOperationOptions(time.Minute, options.WithRetryAttempts(3))
+				assert.NoError(this.T(), Operation.Retry(context.Background(), func() error {
+					if Attempts++; Attempts < 2 {
+						return errors.New("Temporary Failure")
+					}
+					return nil
+				}))
+				assert.Equal(this.T(), 2, Attempts)
+			}},
+
+			{"Operation should not be Retried after the Context is Done", func(t *testing.T) {
+				Attempts := 0
+				Context, Cancel := context.WithCancel(context.Background())
+				Cancel()
+				Operation := options.NewOperationOptions(time.Minute, options.WithRetryAttempts(5))
+				assert.Error(this.T(), Operation.Retry(Context, func() error {
+					Attempts++
+					return errors.New("Permanent Failure")
+				}))
+				assert.Equal(this.T(), 1, Attempts)
+			}},
+		})
+}
@@ -0,0 +1,189 @@
+package provision_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/network"
+	"github.com/LovePelmeni/Infrastructure/provision"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+)
+
+type ProvisionTestSuite struct {
+	suite.Suite
+}
+
+func TestProvisionSuite(t *testing.T) {
+	suite.Run(t, new(ProvisionTestSuite))
+}
+
+func (this *ProvisionTestSuite) TestSpecMerge() {
+	Template := provision.ProvisionSpec{CpuNum: 1, MemoryInMegabytes: 1024, Network: "VM Network",
+		Folder: "/DC0/vm", ResourcePool: "/DC0/host/DC0_C0/Resources"}
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Customer Defaults should be Applied under the Explicit Overrides", func(t *testing.T) {
+				Customer := provision.NewProvisionSpecFromCustomerDefaults(
+					*models.NewCustomerDefaults(1, 4, 8192, 0, "", "/DC0/vm/customer-1"))
+				Spec := Template.Merge(Customer.Merge(provision.ProvisionSpec{Name: "web", CpuNum: 2}))
+
+				assert.Equal(this.T(), int32(2), Spec.CpuNum, "Override should Win over the Customer Default")
+				assert.Equal(this.T(), int64(8192), Spec.MemoryInMegabytes, "Customer Default should Win over the Template")
+				assert.Equal(this.T(), "/DC0/vm/customer-1", Spec.Folder)
+				assert.Equal(this.T(), "VM Network", Spec.Network, "Unspecified Field should be Inherited from the Template")
+				assert.NoError(this.T(), Spec.Validate())
+			}},
+
+			{"Network Adapters should Replace the Inherited Network", func(t *testing.T) {
+				Spec := Template.Merge(provision.ProvisionSpec{Name: "web", NetworkAdapters: []network.NetworkAdapterSpec{
+					{Network: "VM Network"}, {Network: "DC0_DVPG0"}}})
+				assert.Empty(this.T(), Spec.Network)
+				assert.Len(this.T(), Spec.NetworkAdapters, 2)
+				assert.NoError(this.T(), Spec.Validate())
+			}},
+		})
+}
+
+func (this *ProvisionTestSuite) TestIdempotentProvisioning() {
+	Simulator := simulator.VPX()
+	Simulator.Create()
+	Server := Simulator.Service.NewServer()
+	defer Simulator.Remove()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+
+	provision.Registry.Register("idempotency-test", provision.VirtualMachineTemplate{
+		ItemPath: "/DC0/vm/DC0_H0_VM0",
+		Defaults: provision.ProvisionSpec{Folder: "/DC0/vm", ResourcePool: "/DC0/host/DC0_C0/Resources"},
+	})
+	CustomerID := int(time.Now().Unix())
+	IdempotencyKey := fmt.Sprintf("key-%d", time.Now().UnixNano())
+	Overrides := provision.ProvisionSpec{Name: "idempotent-web"}
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Repeated Request with the same Key should Provision only one Virtual Machine", func(t *testing.T) {
+				First, FirstError := provision.ProvisionFromTemplateForCustomer(*Client.Client, CustomerID,
+					"idempotency-test", Overrides, IdempotencyKey)
+				assert.NoError(this.T(), FirstError)
+				Second, SecondError := provision.ProvisionFromTemplateForCustomer(*Client.Client, CustomerID,
+					"idempotency-test", Overrides, IdempotencyKey)
+				assert.NoError(this.T(), SecondError)
+
+				if First != nil && Second != nil {
+					assert.Equal(this.T(), First.Reference(), Second.Reference())
+				}
+				VirtualMachines, _ := find.NewFinder(Client.Client).VirtualMachineList(context.Background(), "/DC0/vm/idempotent-web")
+				assert.Len(this.T(), VirtualMachines, 1)
+			}},
+
+			{"Abandoned In Progress Request should be Taken over once its Lease has Expired", func(t *testing.T) {
+				AbandonedKey := fmt.Sprintf("abandoned-key-%d", time.Now().UnixNano())
+				Request, Claimed, ClaimError := models.ClaimProvisioningRequest(AbandonedKey, CustomerID)
+				if !assert.NoError(this.T(), ClaimError) || !assert.NotNil(this.T(), Request) {
+					return
+				}
+				assert.True(this.T(), Claimed)
+				defer Request.Release()
+
+				_, Claimed, ClaimError = models.ClaimProvisioningRequest(AbandonedKey, CustomerID)
+				assert.ErrorIs(this.T(), ClaimError, models.ErrProvisioningInProgress)
+				assert.False(this.T(), Claimed)
+
+				// As if the Server has Crashed, before the Request has been Completed
+				models.Database.Exec("UPDATE provisioning_requests SET expires_at = ? WHERE customer_id = ? AND idempotency_key = ?",
+					time.Now().Add(-time.Second), CustomerID, AbandonedKey)
+				_, Claimed, ClaimError = models.ClaimProvisioningRequest(AbandonedKey, CustomerID)
+				assert.NoError(this.T(), ClaimError)
+				assert.True(this.T(), Claimed)
+			}},
+		})
+}
+
+func (this *ProvisionTestSuite) TestClone() {
+	Simulator := simulator.VPX()
+	Simulator.Create()
+	Server := Simulator.Service.NewServer()
+	defer Simulator.Remove()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := provision.NewVirtualMachineProvisionManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Source, _ := Finder.VirtualMachine(context.Background(), "/DC0/vm/DC0_H0_VM0")
+	// Owner of the Clone is the Foreign Key, so the Customer should Exist
+	var CustomerID int
+	Name := fmt.Sprintf("clone-owner-%d", time.Now().UnixNano())
+	models.Database.Raw("INSERT INTO customers (username, email, password, city, country, zip_code, street) "+
+		"VALUES (?, ?, '', '', '', '', '') RETURNING id", Name, Name+"@example.com").Scan(&CustomerID)
+	defer models.Database.Unscoped().Where("id = ?", CustomerID).Delete(&models.Customer{})
+
+	Spec := provision.CloneSpec{Name: "clone-web", Folder: "/DC0/vm",
+		ResourcePool: "/DC0/host/DC0_C0/Resources", OwnerID: CustomerID}
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Clone should be Created in vSphere along with its Database Record", func(t *testing.T) {
+				Clone, CloneError := Manager.Clone(context.Background(), Source, Spec)
+				assert.NoError(this.T(), CloneError)
+				if assert.NotNil(this.T(), Clone) {
+					defer models.Database.Unscoped().Where("item_path = ?", Clone.InventoryPath).Delete(&models.VirtualMachine{})
+					Found, FindError := Finder.VirtualMachine(context.Background(), "/DC0/vm/clone-web")
+					assert.NoError(this.T(), FindError)
+					assert.Equal(this.T(), Clone.Reference(), Found.Reference())
+				}
+
+				VirtualMachines, _ := models.GetVirtualMachinesByOwner(fmt.Sprintf("%d", Spec.OwnerID))
+				if assert.Len(this.T(), VirtualMachines, 1) {
+					assert.Equal(this.T(), models.StatusReady, VirtualMachines[0].State)
+					assert.NotEmpty(this.T(), VirtualMachines[0].UUID)
+				}
+			}},
+
+			{"Stuck Provisioning should be Reconciled by the Watchdog", func(t *testing.T) {
+				Threshold := provision.StuckProvisioningThreshold
+				provision.StuckProvisioningThreshold = -time.Hour
+				defer func() { provision.StuckProvisioningThreshold = Threshold }()
+
+				var Existing, Missing int
+				models.Database.Raw("INSERT INTO virtual_machines (state, owner_id, virtual_machine_name, item_path) "+
+					"VALUES (?, ?, ?, ?) RETURNING id", models.StatusProvisioning, CustomerID, "stuck", "/DC0/vm/DC0_H0_VM1").Scan(&Existing)
+				models.Database.Raw("INSERT INTO virtual_machines (state, owner_id, virtual_machine_name, item_path) "+
+					"VALUES (?, ?, ?, ?) RETURNING id", models.StatusPending, CustomerID, "lost", "/DC0/vm/lost").Scan(&Missing)
+				defer models.Database.Unscoped().Where("id IN ?", []int{Existing, Missing}).Delete(&models.VirtualMachine{})
+
+				assert.NoError(this.T(), provision.ReconcileStuckProvisioning(*Client.Client))
+				States := map[int]string{}
+				for _, ID := range []int{Existing, Missing} {
+					var State string
+					models.Database.Model(&models.VirtualMachine{}).Select("state").Where("id = ?", ID).Scan(&State)
+					States[ID] = State
+				}
+				assert.Equal(this.T(), models.StatusReady, States[Existing])
+				assert.Equal(this.T(), models.StatusFailed, States[Missing])
+			}},
+
+			{"Clone with the Name, that is Already Taken, should be Rejected", func(t *testing.T) {
+				Taken := Spec
+				Taken.Name = "DC0_H0_VM1"
+				Clone, CloneError := Manager.Clone(context.Background(), Source, Taken)
+				assert.ErrorIs(this.T(), CloneError, provision.ErrVirtualMachineNameTaken)
+				assert.Nil(this.T(), Clone)
+			}},
+		})
+}
@@ -0,0 +1,420 @@
+package reconfigure_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/reconfigure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type ReconfigureTestSuite struct {
+	suite.Suite
+	Simulator *simulator.Model
+	Server    *simulator.Server
+	Client    *govmomi.Client
+	Manager   *reconfigure.VirtualMachineReconfigureManager
+}
+
+func TestReconfigureSuite(t *testing.T) {
+	suite.Run(t, new(ReconfigureTestSuite))
+}
+
+func (this *ReconfigureTestSuite) SetupTest() {
+	this.Simulator = simulator.VPX()
+	this.Simulator.Create()
+	this.Server = this.Simulator.Service.NewServer()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), this.Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	this.Client = Client
+	this.Manager = reconfigure.NewVirtualMachineReconfigureManager(*Client.Client)
+}
+
+func (this *ReconfigureTestSuite) TearDownTest() {
+	this.Server.Close()
+	this.Simulator.Remove()
+}
+
+func (this *ReconfigureTestSuite) isTemplate(VirtualMachine *object.VirtualMachine) bool {
+	var MoVirtualMachine mo.VirtualMachine
+	VirtualMachine.Properties(context.Background(), VirtualMachine.Reference(), []string{"config.template"}, &MoVirtualMachine)
+	return MoVirtualMachine.Config.Template
+}
+
+func (this *ReconfigureTestSuite) TestTemplateConversion() {
+	Finder := find.NewFinder(this.Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	ResourcePool, _ := VirtualMachine.ResourcePool(context.Background())
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Powered On Virtual Machine can't be Marked as Template", func(t *testing.T) {
+				assert.ErrorIs(this.T(), this.Manager.MarkAsTemplate(VirtualMachine), reconfigure.ErrVirtualMachinePoweredOn)
+				assert.False(this.T(), this.isTemplate(VirtualMachine))
+			}},
+
+			{"Powered Off Virtual Machine should be Marked as Template", func(t *testing.T) {
+				PowerOffTask, _ := VirtualMachine.PowerOff(context.Background())
+				assert.NoError(this.T(), PowerOffTask.Wait(context.Background()))
+				assert.NoError(this.T(), this.Manager.MarkAsTemplate(VirtualMachine))
+				assert.True(this.T(), this.isTemplate(VirtualMachine))
+			}},
+
+			{"Template should be Marked back as Virtual Machine", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.MarkAsVirtualMachine(VirtualMachine, ResourcePool))
+				assert.False(this.T(), this.isTemplate(VirtualMachine))
+			}},
+		})
+}
+
+func (this *ReconfigureTestSuite) TestCompareVMConfigs() {
+	Finder := find.NewFinder(this.Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	First, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	Second, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM1")
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Clones should not Differ, if Instance Specific Fields are Ignored", func(t *testing.T) {
+				Diff, DiffError := reconfigure.CompareVMConfigs(First, Second)
+				assert.NoError(this.T(), DiffError)
+				assert.True(this.T(), Diff.Equal(), Diff.Differences)
+			}},
+
+			{"Instance Specific Fields should be Compared, if Requested", func(t *testing.T) {
+				Diff, _ := reconfigure.CompareVMConfigs(First, Second, reconfigure.CompareOptions{IncludeInstanceSpecific: true})
+				assert.Contains(this.T(), fieldNames(Diff), "uuid")
+			}},
+
+			{"Changed Memory should be Reported", func(t *testing.T) {
+				ReconfigureTask, _ := Second.Reconfigure(context.Background(), types.VirtualMachineConfigSpec{MemoryMB: 2048})
+				assert.NoError(this.T(), ReconfigureTask.Wait(context.Background()))
+
+				Diff, _ := reconfigure.CompareVMConfigs(First, Second)
+				assert.Equal(this.T(), []string{"memory.mb"}, fieldNames(Diff))
+				assert.Equal(this.T(), "2048", Diff.Differences[0].B)
+			}},
+		})
+}
+
+func fieldNames(Diff *reconfigure.ConfigDiff) []string {
+	Names := []string{}
+	for _, Difference := range Diff.Differences {
+		Names = append(Names, Difference.Field)
+	}
+	return Names
+}
+
+func (this *ReconfigureTestSuite) TestOwnerCustomAttribute() {
+	Finder := find.NewFinder(this.Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	First, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	Second, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM1")
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Virtual Machine without the Owner should have Empty Attribute", func(t *testing.T) {
+				Owner, Error := this.Manager.GetOwnerCustomAttribute(First)
+				assert.NoError(this.T(), Error)
+				assert.Empty(this.T(), Owner)
+			}},
+
+			{"Owner Field should be Created once and Set on every Virtual Machine", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.SetOwnerCustomAttribute(First, "1"))
+				assert.NoError(this.T(), this.Manager.SetOwnerCustomAttribute(Second, "2"))
+
+				FirstOwner, _ := this.Manager.GetOwnerCustomAttribute(First)
+				SecondOwner, _ := this.Manager.GetOwnerCustomAttribute(Second)
+				assert.Equal(this.T(), "1", FirstOwner)
+				assert.Equal(this.T(), "2", SecondOwner)
+			}},
+		})
+}
+
+// Simulator does not Implement the Key Providers, so the Crypto Manager with the Single Provider is Registered
+type fakeCryptoManager struct {
+	mo.CryptoManagerKmip
+	ProviderID string
+}
+
+func (this *fakeCryptoManager) ListKmsClusters(ctx *simulator.Context, req *types.ListKmsClusters) soap.HasFault {
+	return &methods.ListKmsClustersBody{Res: &types.ListKmsClustersResponse{
+		Returnval: []types.KmipClusterInfo{{ClusterId: types.KeyProviderId{Id: this.ProviderID}}}}}
+}
+
+func (this *fakeCryptoManager) GenerateKey(ctx *simulator.Context, req *types.GenerateKey) soap.HasFault {
+	return &methods.GenerateKeyBody{Res: &types.GenerateKeyResponse{Returnval: types.CryptoKeyResult{
+		KeyId: types.CryptoKeyId{KeyId: "generated-key", ProviderId: req.KeyProvider}, Success: true}}}
+}
+
+func (this *ReconfigureTestSuite) TestVMEncryption() {
+	Finder := find.NewFinder(this.Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+
+	CryptoManager := &fakeCryptoManager{ProviderID: "kms-1"}
+	CryptoManager.Self = *this.Client.ServiceContent.CryptoManager
+	simulator.Map.Put(CryptoManager)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Powered On Virtual Machine can't be Encrypted", func(t *testing.T) {
+				assert.ErrorIs(this.T(), this.Manager.EnableVMEncryption(VirtualMachine, "kms-1"), reconfigure.ErrVirtualMachinePoweredOn)
+			}},
+
+			{"Encryption without the Key Provider should Fail", func(t *testing.T) {
+				PowerOffTask, _ := VirtualMachine.PowerOff(context.Background())
+				assert.NoError(this.T(), PowerOffTask.Wait(context.Background()))
+				assert.ErrorIs(this.T(), this.Manager.EnableVMEncryption(VirtualMachine, ""), reconfigure.ErrKeyProviderNotConfigured)
+				assert.ErrorIs(this.T(), this.Manager.EnableVMEncryption(VirtualMachine, "unknown-kms"), reconfigure.ErrKeyProviderUnavailable)
+			}},
+
+			{"Powered Off Virtual Machine should be Encrypted and Decrypted", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.EnableVMEncryption(VirtualMachine, "kms-1"))
+				assert.NoError(this.T(), this.Manager.DisableVMEncryption(VirtualMachine))
+			}},
+		})
+}
+
+func (this *ReconfigureTestSuite) TestHardwareVersionUpgrade() {
+	Finder := find.NewFinder(this.Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	Folders, _ := Datacenter.Folders(context.Background())
+	Template, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	ResourcePool, _ := Template.ResourcePool(context.Background())
+
+	CreateTask, _ := Folders.VmFolder.CreateVM(context.Background(), types.VirtualMachineConfigSpec{
+		Name:    "legacy-hardware",
+		Version: "vmx-10",
+		GuestId: string(types.VirtualMachineGuestOsIdentifierOtherGuest),
+		Files:   &types.VirtualMachineFileInfo{VmPathName: "[LocalDS_0]"},
+	}, ResourcePool, nil)
+	TaskInfo, CreateError := CreateTask.WaitForResult(context.Background(), nil)
+	assert.NoError(this.T(), CreateError)
+	VirtualMachine := object.NewVirtualMachine(this.Client.Client, TaskInfo.Result.(types.ManagedObjectReference))
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Hardware Version of the Virtual Machine should be Read from the Config", func(t *testing.T) {
+				Version, VersionError := this.Manager.GetHardwareVersion(VirtualMachine)
+				assert.NoError(this.T(), VersionError)
+				assert.Equal(this.T(), "vmx-10", Version)
+			}},
+
+			{"Invalid, Older and Unsupported Target Versions should be Rejected", func(t *testing.T) {
+				assert.ErrorIs(this.T(), this.Manager.UpgradeHardwareVersion(VirtualMachine, "19"), reconfigure.ErrInvalidHardwareVersion)
+				assert.ErrorIs(this.T(), this.Manager.UpgradeHardwareVersion(VirtualMachine, "vmx-9"), reconfigure.ErrHardwareVersionNotNewer)
+				assert.ErrorIs(this.T(), this.Manager.UpgradeHardwareVersion(VirtualMachine, "vmx-99"), reconfigure.ErrHardwareVersionUnsupported)
+			}},
+
+			{"Powered On Virtual Machine can't be Upgraded", func(t *testing.T) {
+				PowerOnTask, _ := VirtualMachine.PowerOn(context.Background())
+				assert.NoError(this.T(), PowerOnTask.Wait(context.Background()))
+				assert.ErrorIs(this.T(), this.Manager.UpgradeHardwareVersion(VirtualMachine, "vmx-13"), reconfigure.ErrVirtualMachinePoweredOn)
+			}},
+
+			{"Powered Off Virtual Machine should be Upgraded to the Supported Version", func(t *testing.T) {
+				PowerOffTask, _ := VirtualMachine.PowerOff(context.Background())
+				assert.NoError(this.T(), PowerOffTask.Wait(context.Background()))
+				assert.NoError(this.T(), this.Manager.UpgradeHardwareVersion(VirtualMachine, "vmx-13"))
+
+				Version, _ := this.Manager.GetHardwareVersion(VirtualMachine)
+				assert.Equal(this.T(), "vmx-13", Version)
+			}},
+		})
+}
+
+func (this *ReconfigureTestSuite) networkAdapters(VirtualMachine *object.VirtualMachine) []types.BaseVirtualEthernetCard {
+	Devices, _ := VirtualMachine.Device(context.Background())
+	Cards := []types.BaseVirtualEthernetCard{}
+	for _, Device := range Devices.SelectByType((*types.VirtualEthernetCard)(nil)) {
+		Cards = append(Cards, Device.(types.BaseVirtualEthernetCard))
+	}
+	return Cards
+}
+
+func (this *ReconfigureTestSuite) TestNetworkAdapters() {
+	Finder := find.NewFinder(this.Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	Initial := len(this.networkAdapters(VirtualMachine))
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Adapter should be Added to the Network", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.AddNetworkAdapter(VirtualMachine, "DC0_DVPG0"))
+				Cards := this.networkAdapters(VirtualMachine)
+				assert.Len(this.T(), Cards, Initial+1)
+				assert.NotEmpty(this.T(), Cards[len(Cards)-1].GetVirtualEthernetCard().MacAddress)
+			}},
+
+			{"Adapter should be Removed by its MAC Address", func(t *testing.T) {
+				Cards := this.networkAdapters(VirtualMachine)
+				MacAddress := Cards[len(Cards)-1].GetVirtualEthernetCard().MacAddress
+
+				assert.NoError(this.T(), this.Manager.RemoveNetworkAdapter(VirtualMachine, strings.ToUpper(MacAddress)))
+				assert.Len(this.T(), this.networkAdapters(VirtualMachine), Initial)
+			}},
+
+			{"Missing Network should be Reported", func(t *testing.T) {
+				assert.ErrorIs(this.T(), this.Manager.AddNetworkAdapter(VirtualMachine, "missing-network"), reconfigure.ErrNetworkNotFound)
+			}},
+
+			{"Missing Adapter should be Reported", func(t *testing.T) {
+				assert.ErrorIs(this.T(), this.Manager.RemoveNetworkAdapter(VirtualMachine, "00:00:00:00:00:00"), reconfigure.ErrNetworkAdapterNotFound)
+			}},
+		})
+}
+
+func (this *ReconfigureTestSuite) TestResize() {
+	Finder := find.NewFinder(this.Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	SimulatorVM := simulator.Map.Get(VirtualMachine.Reference()).(*simulator.VirtualMachine)
+
+	Hardware := func() types.VirtualHardware {
+		var MoVirtualMachine mo.VirtualMachine
+		VirtualMachine.Properties(context.Background(), VirtualMachine.Reference(), []string{"config.hardware"}, &MoVirtualMachine)
+		return MoVirtualMachine.Config.Hardware
+	}
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Invalid Values should be Rejected", func(t *testing.T) {
+				assert.ErrorIs(this.T(), this.Manager.Resize(VirtualMachine, 0, 1024), reconfigure.ErrInvalidResize)
+				assert.ErrorIs(this.T(), this.Manager.Resize(VirtualMachine, 2, -1), reconfigure.ErrInvalidResize)
+				assert.ErrorIs(this.T(), this.Manager.Resize(VirtualMachine, reconfigure.MaxResizeCPUs+1, 1024), reconfigure.ErrInvalidResize)
+				assert.ErrorIs(this.T(), this.Manager.Resize(VirtualMachine, 2, 1025), reconfigure.ErrInvalidResize)
+			}},
+
+			{"Powered On Virtual Machine without Hot Add should not be Grown", func(t *testing.T) {
+				SimulatorVM.Config.CpuHotAddEnabled = types.NewBool(false)
+				assert.ErrorIs(this.T(), this.Manager.Resize(VirtualMachine, Hardware().NumCPU+1,
+					int64(Hardware().MemoryMB)), reconfigure.ErrHotAddNotEnabled)
+			}},
+
+			{"Powered On Virtual Machine should not be Shrunk", func(t *testing.T) {
+				assert.ErrorIs(this.T(), this.Manager.Resize(VirtualMachine, Hardware().NumCPU,
+					int64(Hardware().MemoryMB)-4), reconfigure.ErrHotRemoveNotAllowed)
+			}},
+
+			{"Powered On Virtual Machine with Hot Add should be Grown", func(t *testing.T) {
+				SimulatorVM.Config.CpuHotAddEnabled = types.NewBool(true)
+				SimulatorVM.Config.MemoryHotAddEnabled = types.NewBool(true)
+				NumCPU, MemoryMB := Hardware().NumCPU+1, int64(Hardware().MemoryMB)+1024
+
+				assert.NoError(this.T(), this.Manager.Resize(VirtualMachine, NumCPU, MemoryMB))
+				assert.Equal(this.T(), NumCPU, Hardware().NumCPU)
+				assert.Equal(this.T(), int32(MemoryMB), Hardware().MemoryMB)
+			}},
+
+			{"Powered Off Virtual Machine should be Resized", func(t *testing.T) {
+				PowerOffTask, _ := VirtualMachine.PowerOff(context.Background())
+				PowerOffTask.Wait(context.Background())
+
+				assert.NoError(this.T(), this.Manager.Resize(VirtualMachine, 1, 512))
+				assert.Equal(this.T(), int32(1), Hardware().NumCPU)
+				assert.Equal(this.T(), int32(512), Hardware().MemoryMB)
+			}},
+
+			{"Database Record should be Resized along with the Timeline Entry", func(t *testing.T) {
+				Name := fmt.Sprintf("resize-%d", time.Now().UnixNano())
+				var VirtualMachineID int
+				models.Database.Raw("INSERT INTO virtual_machines (state, owner_id, virtual_machine_name, item_path, ip_address, uuid) "+
+					"VALUES (?, ?, ?, ?, ?, ?) RETURNING id", models.StatusReady, 0, Name, "/DC0/vm/DC0_H0_VM0", Name,
+					SimulatorVM.Config.Uuid).Scan(&VirtualMachineID)
+				defer models.Database.Unscoped().Where("id = ?", VirtualMachineID).Delete(&models.VirtualMachine{})
+				defer models.Database.Where("virtual_machine_id = ?", VirtualMachineID).Delete(&models.VMEvent{})
+
+				assert.NoError(this.T(), this.Manager.Resize(VirtualMachine, 2, 2048))
+
+				Record, LookupError := models.GetVirtualMachineByID(fmt.Sprintf("%d", VirtualMachineID))
+				if assert.NoError(this.T(), LookupError) {
+					assert.Equal(this.T(), int32(2), Record.Configuration.Resources.CpuNum)
+					assert.Equal(this.T(), int64(2048), Record.Configuration.Resources.MemoryInMegabytes)
+				}
+				Events, _ := models.GetVMTimeline(VirtualMachineID, 1)
+				if assert.Len(this.T(), Events, 1) {
+					assert.Equal(this.T(), models.EventResized, Events[0].Type)
+					assert.Equal(this.T(), models.ResizeEventDetail(2, 2048, 0, 0), Events[0].Detail)
+				}
+			}},
+		})
+}
+
+func (this *ReconfigureTestSuite) TestRename() {
+	Finder := find.NewFinder(this.Client.Client)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "/DC0/vm/DC0_H0_VM0")
+	UUID := simulator.Map.Get(VirtualMachine.Reference()).(*simulator.VirtualMachine).Config.Uuid
+
+	// Database Record of the Simulator Virtual Machine, Owned by the Separate Customer
+	var CustomerID, VirtualMachineID int
+	Name := fmt.Sprintf("rename-%d", time.Now().UnixNano())
+	models.Database.Raw("INSERT INTO customers (username, email, password, city, country, zip_code, street) "+
+		"VALUES (?, ?, '', '', '', '', '') RETURNING id", Name, Name+"@example.com").Scan(&CustomerID)
+	models.Database.Raw("INSERT INTO virtual_machines (state, owner_id, virtual_machine_name, item_path, uuid) "+
+		"VALUES (?, ?, ?, ?, ?) RETURNING id", models.StatusReady, CustomerID, "DC0_H0_VM0", "/DC0/vm/DC0_H0_VM0", UUID).Scan(&VirtualMachineID)
+	defer models.Database.Unscoped().Where("id = ?", CustomerID).Delete(&models.Customer{})
+	defer models.Database.Unscoped().Where("id = ?", VirtualMachineID).Delete(&models.VirtualMachine{})
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Names, that Break the vSphere Naming Rules, should be Rejected", func(t *testing.T) {
+				for _, Invalid := range []string{"", "web/1", "web 1", "-web", "50%", strings.Repeat("a", reconfigure.MaxVirtualMachineNameLength+1)} {
+					assert.ErrorIs(this.T(), this.Manager.Rename(VirtualMachine, Invalid), reconfigure.ErrInvalidVirtualMachineName, Invalid)
+				}
+			}},
+
+			{"Virtual Machine should be Renamed in vSphere and in the Database", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.Rename(VirtualMachine, "web-renamed"))
+				assert.Equal(this.T(), "/DC0/vm/web-renamed", VirtualMachine.InventoryPath)
+
+				Renamed, FindError := Finder.VirtualMachine(context.Background(), "/DC0/vm/web-renamed")
+				assert.NoError(this.T(), FindError)
+				if FindError == nil {
+					assert.Equal(this.T(), VirtualMachine.Reference(), Renamed.Reference())
+				}
+
+				Record, LookupError := models.GetVirtualMachineByID(fmt.Sprintf("%d", VirtualMachineID))
+				assert.NoError(this.T(), LookupError)
+				if Record != nil {
+					assert.Equal(this.T(), "web-renamed", Record.VirtualMachineName)
+					assert.Equal(this.T(), "/DC0/vm/web-renamed", Record.ItemPath)
+				}
+			}},
+
+			{"Name of the Sibling Virtual Machine can't be Taken", func(t *testing.T) {
+				assert.Error(this.T(), this.Manager.Rename(VirtualMachine, "DC0_H0_VM1"))
+			}},
+		})
+}
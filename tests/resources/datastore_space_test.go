@@ -0,0 +1,74 @@
+package resource_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LovePelmeni/Infrastructure/resources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+)
+
+type DatastoreSpaceTestSuite struct {
+	suite.Suite
+	Simulator *simulator.Model
+	Server    *simulator.Server
+	Client    *govmomi.Client
+}
+
+func TestDatastoreSpaceSuite(t *testing.T) {
+	suite.Run(t, new(DatastoreSpaceTestSuite))
+}
+
+func (this *DatastoreSpaceTestSuite) SetupTest() {
+	this.Simulator = simulator.VPX()
+	this.Simulator.Create()
+	this.Server = this.Simulator.Service.NewServer()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), this.Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	this.Client = Client
+}
+
+func (this *DatastoreSpaceTestSuite) TearDownTest() {
+	this.Server.Close()
+	this.Simulator.Remove()
+}
+
+func (this *DatastoreSpaceTestSuite) TestDatastoreFreeSpace() {
+	Datastore, _ := find.NewFinder(this.Client.Client).Datastore(context.Background(), "/DC0/datastore/LocalDS_0")
+	const FreeSpace = int64(50 * 1024 * 1024 * 1024)
+	simulator.Map.Get(Datastore.Reference()).(*simulator.Datastore).Summary.FreeSpace = FreeSpace
+
+	Manager := resources.NewDatastoreResourceManager(this.Client.Client)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Free Space of the Datastore should be Reported", func(t *testing.T) {
+				Free, SpaceError := resources.GetDatastoreFreeSpace(context.Background(), this.Client.Client, "LocalDS_0")
+				assert.NoError(this.T(), SpaceError)
+				assert.Equal(this.T(), FreeSpace, Free)
+			}},
+
+			{"Space should be Sufficient up to the Free Space", func(t *testing.T) {
+				Sufficient, SpaceError := Manager.HasSufficientSpace("LocalDS_0", FreeSpace)
+				assert.NoError(this.T(), SpaceError)
+				assert.True(this.T(), Sufficient)
+
+				Sufficient, SpaceError = Manager.HasSufficientSpace("LocalDS_0", FreeSpace+1)
+				assert.NoError(this.T(), SpaceError)
+				assert.False(this.T(), Sufficient)
+			}},
+
+			{"Unknown Datastore should not be Found", func(t *testing.T) {
+				_, SpaceError := resources.GetDatastoreFreeSpace(context.Background(), this.Client.Client, "missing")
+				assert.ErrorIs(this.T(), SpaceError, resources.ErrDatastoreNotFound)
+				_, SpaceError = Manager.HasSufficientSpace("", 1)
+				assert.ErrorIs(this.T(), SpaceError, resources.ErrDatastoreNotFound)
+			}},
+		})
+}
@@ -0,0 +1,96 @@
+package resource_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LovePelmeni/Infrastructure/resources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type PlacementTestSuite struct {
+	suite.Suite
+	Simulator *simulator.Model
+	Server    *simulator.Server
+	Client    *vim25.Client
+	Finder    *find.Finder
+}
+
+func TestPlacementSuite(t *testing.T) {
+	suite.Run(t, new(PlacementTestSuite))
+}
+
+func (this *PlacementTestSuite) SetupTest() {
+	this.Simulator = simulator.VPX()
+	this.Simulator.Create()
+	this.Server = this.Simulator.Service.NewServer()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), this.Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	this.Client = Client.Client
+	this.Finder = find.NewFinder(Client.Client)
+}
+
+func (this *PlacementTestSuite) TearDownTest() {
+	this.Server.Close()
+	this.Simulator.Remove()
+}
+
+func (this *PlacementTestSuite) TestGetVMPlacement() {
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Virtual Machine in the Cluster should Report the Host and the Cluster", func(t *testing.T) {
+				VirtualMachine, _ := this.Finder.VirtualMachine(context.Background(), "/DC0/vm/DC0_C0_RP0_VM0")
+				Placement, Error := resources.GetVMPlacement(context.Background(), this.Client, VirtualMachine)
+				assert.NoError(this.T(), Error)
+				assert.NotEmpty(this.T(), Placement.HostName)
+				assert.Equal(this.T(), "DC0_C0", Placement.ClusterName)
+				assert.Equal(this.T(), "Resources", Placement.ResourcePoolName)
+				assert.Equal(this.T(), "/DC0/host/DC0_C0/Resources", Placement.ResourcePoolPath)
+			}},
+
+			{"Virtual Machine on the Standalone Host should have no Cluster", func(t *testing.T) {
+				VirtualMachine, _ := this.Finder.VirtualMachine(context.Background(), "/DC0/vm/DC0_H0_VM0")
+				Placement, Error := resources.GetVMPlacement(context.Background(), this.Client, VirtualMachine)
+				assert.NoError(this.T(), Error)
+				assert.Equal(this.T(), "DC0_H0", Placement.HostName)
+				assert.Empty(this.T(), Placement.ClusterName)
+				assert.Equal(this.T(), "Resources", Placement.ResourcePoolName)
+			}},
+
+			{"Virtual Machine in the Child Resource Pool should Report that Pool", func(t *testing.T) {
+				Pool, _ := this.Finder.ResourcePool(context.Background(), "/DC0/host/DC0_C0/Resources")
+				Child, CreateError := Pool.Create(context.Background(), "Scheduling", types.DefaultResourceConfigSpec())
+				assert.NoError(this.T(), CreateError)
+				VirtualMachine, _ := this.Finder.VirtualMachine(context.Background(), "/DC0/vm/DC0_C0_RP0_VM1")
+				ChildReference := Child.Reference()
+				MigrateTask, _ := VirtualMachine.Relocate(context.Background(), types.VirtualMachineRelocateSpec{Pool: &ChildReference},
+					types.VirtualMachineMovePriorityDefaultPriority)
+				assert.NoError(this.T(), MigrateTask.Wait(context.Background()))
+
+				Placement, Error := resources.GetVMPlacement(context.Background(), this.Client, VirtualMachine)
+				assert.NoError(this.T(), Error)
+				assert.Equal(this.T(), "Scheduling", Placement.ResourcePoolName)
+				assert.Equal(this.T(), "/DC0/host/DC0_C0/Resources/Scheduling", Placement.ResourcePoolPath)
+				assert.Equal(this.T(), "DC0_C0", Placement.ClusterName)
+			}},
+
+			{"Template should be Reported without the Host", func(t *testing.T) {
+				VirtualMachine, _ := this.Finder.VirtualMachine(context.Background(), "/DC0/vm/DC0_H0_VM1")
+				PowerOffTask, _ := VirtualMachine.PowerOff(context.Background())
+				assert.NoError(this.T(), PowerOffTask.Wait(context.Background()))
+				assert.NoError(this.T(), VirtualMachine.MarkAsTemplate(context.Background()))
+				Placement, Error := resources.GetVMPlacement(context.Background(), this.Client, VirtualMachine)
+				assert.NoError(this.T(), Error)
+				assert.True(this.T(), Placement.IsTemplate)
+				assert.Empty(this.T(), Placement.ResourcePoolPath)
+			}},
+		})
+}
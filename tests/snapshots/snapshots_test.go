@@ -0,0 +1,167 @@
+package snapshots_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/snapshots"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type SnapshotsTestSuite struct {
+	suite.Suite
+	Simulator *simulator.Model
+	Server    *simulator.Server
+	Client    *govmomi.Client
+	Manager   *snapshots.VirtualMachineSnapshotManager
+}
+
+func TestSnapshotsSuite(t *testing.T) {
+	suite.Run(t, new(SnapshotsTestSuite))
+}
+
+func (this *SnapshotsTestSuite) SetupTest() {
+	this.Simulator = simulator.VPX()
+	this.Simulator.Create()
+	this.Server = this.Simulator.Service.NewServer()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), this.Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	this.Client = Client
+	this.Manager = snapshots.NewVirtualMachineSnapshotManager(*Client.Client)
+}
+
+func (this *SnapshotsTestSuite) TearDownTest() {
+	this.Server.Close()
+	this.Simulator.Remove()
+}
+
+func (this *SnapshotsTestSuite) TestSnapshots() {
+	Finder := find.NewFinder(this.Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Virtual Machine without Snapshots should have an Empty List", func(t *testing.T) {
+				Snapshots, ListError := this.Manager.ListSnapshots(VirtualMachine)
+				assert.NoError(this.T(), ListError)
+				assert.Empty(this.T(), Snapshots)
+			}},
+
+			{"Snapshots should be Created and Listed Parents first", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.CreateSnapshot(VirtualMachine, "before-upgrade", "Running", false, false))
+				PowerOffTask, _ := VirtualMachine.PowerOff(context.Background())
+				assert.NoError(this.T(), PowerOffTask.Wait(context.Background()))
+				assert.NoError(this.T(), this.Manager.CreateSnapshot(VirtualMachine, "after-upgrade", "Stopped", false, false))
+
+				Snapshots, ListError := this.Manager.ListSnapshots(VirtualMachine)
+				assert.NoError(this.T(), ListError)
+				if assert.Len(this.T(), Snapshots, 2) {
+					assert.Equal(this.T(), "before-upgrade", Snapshots[0].Name)
+					assert.Equal(this.T(), "Running", Snapshots[0].Description)
+					assert.Empty(this.T(), Snapshots[0].Parent)
+					assert.False(this.T(), Snapshots[0].Current)
+					assert.Equal(this.T(), "after-upgrade", Snapshots[1].Name)
+					assert.Equal(this.T(), "before-upgrade", Snapshots[1].Parent)
+					assert.True(this.T(), Snapshots[1].Current)
+				}
+			}},
+
+			{"Snapshot with the Duplicate Name should be Rejected", func(t *testing.T) {
+				assert.ErrorIs(this.T(), this.Manager.CreateSnapshot(VirtualMachine, "before-upgrade", "", false, false),
+					snapshots.ErrSnapshotNameTaken)
+				assert.ErrorIs(this.T(), this.Manager.CreateSnapshot(VirtualMachine, "", "", false, false),
+					snapshots.ErrSnapshotNameRequired)
+			}},
+
+			{"Virtual Machine should be Reverted to the Snapshot", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.RevertToSnapshot(VirtualMachine, "before-upgrade"))
+
+				Snapshots, _ := this.Manager.ListSnapshots(VirtualMachine)
+				if assert.Len(this.T(), Snapshots, 2) {
+					assert.True(this.T(), Snapshots[0].Current)
+					assert.False(this.T(), Snapshots[1].Current)
+				}
+			}},
+
+			{"Reverting to the Unknown Snapshot should Fail", func(t *testing.T) {
+				assert.ErrorIs(this.T(), this.Manager.RevertToSnapshot(VirtualMachine, "missing"), snapshots.ErrSnapshotNotFound)
+			}},
+		})
+}
+
+func (this *SnapshotsTestSuite) TestSnapshotEvent() {
+	Finder := find.NewFinder(this.Client.Client)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "/DC0/vm/DC0_H0_VM0")
+	UUID := simulator.Map.Get(VirtualMachine.Reference()).(*simulator.VirtualMachine).Config.Uuid
+
+	Name := fmt.Sprintf("snapshot-event-%d", time.Now().UnixNano())
+	var VirtualMachineID int
+	models.Database.Raw("INSERT INTO virtual_machines (state, owner_id, virtual_machine_name, item_path, ip_address, uuid) "+
+		"VALUES (?, ?, ?, ?, ?, ?) RETURNING id", models.StatusReady, 0, Name, "/DC0/vm/DC0_H0_VM0", Name, UUID).Scan(&VirtualMachineID)
+	defer models.Database.Unscoped().Where("id = ?", VirtualMachineID).Delete(&models.VirtualMachine{})
+	defer models.Database.Where("virtual_machine_id = ?", VirtualMachineID).Delete(&models.VMEvent{})
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Taken Snapshot should be Recorded to the Timeline", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.CreateSnapshot(VirtualMachine, "nightly", "", false, false))
+				assert.Eventually(this.T(), func() bool {
+					Events, _ := models.GetVMTimeline(VirtualMachineID, 1)
+					return len(Events) == 1 && Events[0].Type == models.EventSnapshotTaken
+				}, time.Second*5, time.Millisecond*100)
+			}},
+		})
+}
+
+func (this *SnapshotsTestSuite) TestDeleteSnapshot() {
+	Finder := find.NewFinder(this.Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+
+	assert.NoError(this.T(), this.Manager.CreateSnapshot(VirtualMachine, "first", "", false, false))
+	assert.NoError(this.T(), this.Manager.CreateSnapshot(VirtualMachine, "second", "", false, false))
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Deleted Snapshot should be Removed from the Tree", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.DeleteSnapshot(VirtualMachine, "first", false))
+
+				Snapshots, ListError := this.Manager.ListSnapshots(VirtualMachine)
+				assert.NoError(this.T(), ListError)
+				if assert.Len(this.T(), Snapshots, 1) {
+					assert.Equal(this.T(), "second", Snapshots[0].Name)
+					assert.Empty(this.T(), Snapshots[0].Parent)
+					assert.True(this.T(), Snapshots[0].Current)
+				}
+			}},
+
+			{"Deleting the Unknown Snapshot should Fail", func(t *testing.T) {
+				assert.ErrorIs(this.T(), this.Manager.DeleteSnapshot(VirtualMachine, "first", false), snapshots.ErrSnapshotNotFound)
+			}},
+
+			{"Consolidation should be Skipped, if it is not Needed", func(t *testing.T) {
+				assert.NoError(this.T(), this.Manager.ConsolidateDisks(VirtualMachine))
+			}},
+
+			{"Error of the Consolidation Task should be Returned", func(t *testing.T) {
+				// vcsim does not Implement the Consolidation, so the Call Fails, once it is Reported as Needed
+				simulator.Map.Get(VirtualMachine.Reference()).(*simulator.VirtualMachine).Runtime.ConsolidationNeeded = types.NewBool(true)
+				assert.Error(this.T(), this.Manager.ConsolidateDisks(VirtualMachine))
+			}},
+		})
+}
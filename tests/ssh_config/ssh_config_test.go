@@ -0,0 +1,1156 @@
+package ssh_config_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/certificates"
+	"github.com/LovePelmeni/Infrastructure/guest"
+	"github.com/LovePelmeni/Infrastructure/models"
+	"github.com/LovePelmeni/Infrastructure/options"
+	"github.com/LovePelmeni/Infrastructure/ssh_config"
+	"github.com/LovePelmeni/Infrastructure/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/time/rate"
+)
+
+type SshConfigTestSuite struct {
+	suite.Suite
+	Store *ssh_config.DatabaseSecretStore
+}
+
+func TestSshConfigSuite(t *testing.T) {
+	suite.Run(t, new(SshConfigTestSuite))
+}
+
+func (this *SshConfigTestSuite) SetupTest() {
+	Store, StoreError := ssh_config.NewDatabaseSecretStore(bytes.Repeat([]byte("k"), 32))
+	assert.NoError(this.T(), StoreError)
+	this.Store = Store
+}
+
+func (this *SshConfigTestSuite) TestDatabaseSecretStore() {
+	VirtualMachineId := "vm-secret-test"
+	defer models.Database.Where("virtual_machine_id = ?", VirtualMachineId).Delete(&models.VirtualMachineSecret{})
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Invalid Encryption Key should be Rejected", func(t *testing.T) {
+				_, StoreError := ssh_config.NewDatabaseSecretStore([]byte("short-key"))
+				assert.Error(this.T(), StoreError)
+			}},
+
+			{"Missing Password should return Not Found Error", func(t *testing.T) {
+				_, SecretError := this.Store.GetPassword("vm-does-not-exist")
+				assert.ErrorIs(this.T(), SecretError, ssh_config.ErrSecretNotFound)
+			}},
+
+			{"Stored Password should be Encrypted and Decrypted back", func(t *testing.T) {
+				assert.NoError(this.T(), this.Store.SetPassword(VirtualMachineId, "root-password"))
+
+				var Secret models.VirtualMachineSecret
+				models.Database.Where("virtual_machine_id = ?", VirtualMachineId).First(&Secret)
+				assert.NotContains(this.T(), string(Secret.Ciphertext), "root-password", "Password should not be Stored as Plain Text")
+
+				Password, SecretError := this.Store.GetPassword(VirtualMachineId)
+				assert.NoError(this.T(), SecretError)
+				assert.Equal(this.T(), "root-password", Password)
+			}},
+
+			{"Setting Password again should Rotate it", func(t *testing.T) {
+				assert.NoError(this.T(), this.Store.SetPassword(VirtualMachineId, "rotated-password"))
+				Password, _ := this.Store.GetPassword(VirtualMachineId)
+				assert.Equal(this.T(), "rotated-password", Password)
+			}},
+
+			{"Password can't be Decrypted with another Key", func(t *testing.T) {
+				AnotherStore, _ := ssh_config.NewDatabaseSecretStore(bytes.Repeat([]byte("x"), 32))
+				_, SecretError := AnotherStore.GetPassword(VirtualMachineId)
+				assert.ErrorIs(this.T(), SecretError, ssh_config.ErrInvalidSecretsEncryption)
+			}},
+
+			{"Password should be Deleted along with the Virtual Machine Record", func(t *testing.T) {
+				Name := fmt.Sprintf("secret-%d", time.Now().UnixNano())
+				var CustomerID int
+				models.Database.Raw("INSERT INTO customers (username, email, password, city, country, zip_code, street) "+
+					"VALUES (?, ?, '', '', '', '', '') RETURNING id", Name, Name+"@example.com").Scan(&CustomerID)
+				defer models.Database.Unscoped().Where("id = ?", CustomerID).Delete(&models.Customer{})
+				var RecordID int
+				models.Database.Raw("INSERT INTO virtual_machines (state, owner_id, virtual_machine_name, item_path) "+
+					"VALUES (?, ?, ?, ?) RETURNING id", models.StatusReady, CustomerID, Name, "/DC0/vm/"+Name).Scan(&RecordID)
+				defer models.Database.Unscoped().Where("id = ?", RecordID).Delete(&models.VirtualMachine{})
+
+				assert.NoError(this.T(), this.Store.SetPassword(strconv.Itoa(RecordID), "root-password"))
+				assert.NoError(this.T(), models.DeleteVirtualMachineRecords(RecordID))
+				_, SecretError := this.Store.GetPassword(strconv.Itoa(RecordID))
+				assert.ErrorIs(this.T(), SecretError, ssh_config.ErrSecretNotFound)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestSshKeyFileNames() {
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Distinct Virtual Machines should get Distinct Key File Names", func(t *testing.T) {
+				assert.Equal(this.T(), "web-1_ssh_key.pub", ssh_config.SshKeyFileName("web-1"))
+				assert.Equal(this.T(), "web-2_ssh_key.pub", ssh_config.SshKeyFileName("web-2"))
+			}},
+
+			{"Unsafe Characters should be Sanitized out of the File Name", func(t *testing.T) {
+				assert.Equal(this.T(), "etc_passwd_ssh_key.pub", ssh_config.SshKeyFileName("../etc/passwd"))
+				assert.Equal(this.T(), "my_server_ssh_key.pub", ssh_config.SshKeyFileName("my server"))
+				assert.Equal(this.T(), "vm_ssh_key.pub", ssh_config.SshKeyFileName("//"))
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestRetrievePartialProperties() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	VirtualMachine := simulator.Map.Any("VirtualMachine").(*simulator.VirtualMachine)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Missing Property should not fail the Retrieval of the other ones", func(t *testing.T) {
+				var MoVirtualMachine mo.VirtualMachine
+				Missing, RetrieveError := ssh_config.RetrievePartialProperties(context.Background(), Client.Client,
+					VirtualMachine.Reference(), []string{"name", "guest.noSuchProperty"}, &MoVirtualMachine)
+
+				assert.NoError(this.T(), RetrieveError)
+				assert.Equal(this.T(), VirtualMachine.Name, MoVirtualMachine.Name)
+				assert.Len(this.T(), Missing, 1)
+				assert.Error(this.T(), Missing["guest.noSuchProperty"])
+			}},
+
+			{"All the Properties should be Retrieved without any Property Error", func(t *testing.T) {
+				var MoVirtualMachine mo.VirtualMachine
+				Missing, RetrieveError := ssh_config.RetrievePartialProperties(context.Background(), Client.Client,
+					VirtualMachine.Reference(), []string{"name", "guest"}, &MoVirtualMachine)
+
+				assert.NoError(this.T(), RetrieveError)
+				assert.Empty(this.T(), Missing)
+				assert.NotNil(this.T(), MoVirtualMachine.Guest)
+			}},
+		})
+}
+
+type fakeHostCertificateManager struct {
+	mo.HostCertificateManager
+	Installed       []string // Certificates, Installed by the `InstallServerCertificate`
+	FailInstall     bool
+	KeepCertificate bool // Host keeps Serving the Old Certificate after the Install
+}
+
+func (this *fakeHostCertificateManager) InstallServerCertificate(ctx *simulator.Context, req *types.InstallServerCertificate) soap.HasFault {
+	if this.FailInstall {
+		return &methods.InstallServerCertificateBody{Fault_: simulator.Fault("", &types.InvalidArgument{InvalidProperty: "cert"})}
+	}
+	this.Installed = append(this.Installed, req.Cert)
+	if Block, _ := pem.Decode([]byte(req.Cert)); Block != nil && !this.KeepCertificate {
+		if Certificate, ParseError := x509.ParseCertificate(Block.Bytes); ParseError == nil {
+			this.CertificateInfo = (&object.HostCertificateInfo{}).FromCertificate(Certificate).HostCertificateManagerCertificateInfo
+		}
+	}
+	return &methods.InstallServerCertificateBody{Res: &types.InstallServerCertificateResponse{}}
+}
+
+func (this *fakeHostCertificateManager) NotifyAffectedServices(ctx *simulator.Context, req *types.Refresh) soap.HasFault {
+	// Called by the `object.HostCertificateManager` right after the Certificate is Installed
+	return &methods.RefreshBody{Res: &types.RefreshResponse{}}
+}
+
+func init() {
+	// `NotifyAffectedServices` is Internal Method of the vSphere, so it is not Registered in the `types`
+	types.Add("NotifyAffectedServices", reflect.TypeOf((*types.Refresh)(nil)).Elem())
+}
+
+func (this *fakeHostCertificateManager) GenerateCertificateSigningRequestByDn(ctx *simulator.Context, req *types.GenerateCertificateSigningRequestByDn) soap.HasFault {
+	return &methods.GenerateCertificateSigningRequestByDnBody{Res: &types.GenerateCertificateSigningRequestByDnResponse{
+		Returnval: "-----BEGIN CERTIFICATE REQUEST-----\n" + req.DistinguishedName + "\n-----END CERTIFICATE REQUEST-----\n"}}
+}
+
+func (this *SshConfigTestSuite) TestGenerateSshKeys() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	Orphan, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM1")
+
+	// Simulator does not Implement the Host Certificate Manager, so the Fake one is Attached to the Host
+	CertificateManager := &fakeHostCertificateManager{}
+	CertificateManager.Self = types.ManagedObjectReference{Type: "HostCertificateManager", Value: "certificateManager-fake"}
+	simulator.Map.Put(CertificateManager)
+	HostSystem, _ := VirtualMachine.HostSystem(context.Background())
+	simulator.Map.Get(HostSystem.Reference()).(*simulator.HostSystem).ConfigManager.CertificateManager = &CertificateManager.Self
+	simulator.Map.Get(Orphan.Reference()).(*simulator.VirtualMachine).Summary.Runtime.Host = nil
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Certificate Signing Request should be Generated by the Host of the Virtual Machine", func(t *testing.T) {
+				Key, GenerateError := Manager.GenerateSshKeys(VirtualMachine, "42")
+				assert.NoError(this.T(), GenerateError)
+				if assert.NotNil(this.T(), Key) {
+					assert.Contains(this.T(), string(Key.Content), "DC0_H0_VM0-42")
+					assert.Equal(this.T(), "DC0_H0_VM0_ssh_key.pub", Key.FileName)
+				}
+			}},
+
+			{"Virtual Machine without the Host should Return the Error instead of Panicking", func(t *testing.T) {
+				Key, GenerateError := Manager.GenerateSshKeys(Orphan, "43")
+				assert.Error(this.T(), GenerateError)
+				assert.Nil(this.T(), Key)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestGenerateSshKeyPair() {
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(vim25.Client{})
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Generated Private Key should Match the Public Key of the Pair", func(t *testing.T) {
+				for _, Algorithm := range []string{ssh_config.KeyAlgorithmEd25519, ssh_config.KeyAlgorithmRSA} {
+					Credentials, GenerateError := Manager.GenerateSshKeyPair(Algorithm)
+					assert.NoError(this.T(), GenerateError)
+					assert.Equal(this.T(), "id_"+Algorithm+".pub", Credentials.FileName)
+
+					PublicKey, _, _, _, ParseError := ssh.ParseAuthorizedKey(Credentials.Content)
+					assert.NoError(this.T(), ParseError)
+					Signer, SignerError := ssh.ParsePrivateKey(Credentials.PrivateKey)
+					if assert.NoError(this.T(), SignerError, Algorithm) {
+						assert.Equal(this.T(), PublicKey.Marshal(), Signer.PublicKey().Marshal())
+					}
+				}
+			}},
+
+			{"Unsupported Algorithm should be Rejected", func(t *testing.T) {
+				_, GenerateError := Manager.GenerateSshKeyPair("dsa")
+				assert.ErrorIs(this.T(), GenerateError, ssh_config.ErrUnsupportedKeyAlgorithm)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestVirtualMachinePowerManager() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachinePowerManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Running Virtual Machine can't be Powered On again", func(t *testing.T) {
+				assert.ErrorIs(this.T(), Manager.PowerOn(VirtualMachine), ssh_config.ErrAlreadyPoweredOn)
+				assert.NoError(this.T(), Manager.Reboot(VirtualMachine))
+			}},
+
+			{"Virtual Machine should be Powered Off and can't be Powered Off or Rebooted again", func(t *testing.T) {
+				assert.NoError(this.T(), Manager.PowerOff(VirtualMachine))
+				assert.ErrorIs(this.T(), Manager.PowerOff(VirtualMachine), ssh_config.ErrAlreadyPoweredOff)
+				assert.ErrorIs(this.T(), Manager.Reboot(VirtualMachine), ssh_config.ErrNotPoweredOn)
+			}},
+
+			{"Powered Off Virtual Machine should be Powered On", func(t *testing.T) {
+				assert.NoError(this.T(), Manager.PowerOn(VirtualMachine))
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestGetPowerState() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachinePowerManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	Deleted, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM1")
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Power State should Follow the Power Operations", func(t *testing.T) {
+				State, StateError := Manager.GetPowerState(VirtualMachine)
+				assert.NoError(this.T(), StateError)
+				assert.Equal(this.T(), types.VirtualMachinePowerStatePoweredOn, State)
+
+				assert.NoError(this.T(), Manager.PowerOff(VirtualMachine))
+				State, _ = Manager.GetPowerState(VirtualMachine)
+				assert.Equal(this.T(), types.VirtualMachinePowerStatePoweredOff, State)
+
+				assert.NoError(this.T(), Manager.PowerOn(VirtualMachine))
+				State, _ = Manager.GetPowerState(VirtualMachine)
+				assert.Equal(this.T(), types.VirtualMachinePowerStatePoweredOn, State)
+			}},
+
+			{"Deleted Virtual Machine should be Reported as Not Found", func(t *testing.T) {
+				PowerOffTask, _ := Deleted.PowerOff(context.Background())
+				assert.NoError(this.T(), PowerOffTask.Wait(context.Background()))
+				DestroyTask, _ := Deleted.Destroy(context.Background())
+				assert.NoError(this.T(), DestroyTask.Wait(context.Background()))
+
+				_, StateError := Manager.GetPowerState(Deleted)
+				assert.ErrorIs(this.T(), StateError, ssh_config.ErrVirtualMachineNotFound)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestTimeouts() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	PowerManager := ssh_config.NewVirtualMachinePowerManager(*Client.Client)
+	CertificateManager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+
+	// Every vSphere Call is Slower, than the Short Timeout, but Faster than the Default one
+	Model.DelayConfig.Delay = 200
+	defer func() { Model.DelayConfig.Delay = 0 }()
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Managers should be Created with the Default Timeouts", func(t *testing.T) {
+				assert.Equal(this.T(), ssh_config.DefaultTimeouts(), PowerManager.Timeouts)
+				assert.Equal(this.T(), ssh_config.DefaultTimeouts(), CertificateManager.Timeouts)
+
+				_, StateError := PowerManager.GetPowerState(VirtualMachine)
+				assert.NoError(this.T(), StateError)
+			}},
+
+			{"Short Timeout of the Manager should Exceed the Deadline", func(t *testing.T) {
+				PowerManager.Timeouts.Query = time.Millisecond * 50
+				_, StateError := PowerManager.GetPowerState(VirtualMachine)
+				assert.ErrorIs(this.T(), StateError, context.DeadlineExceeded)
+
+				CertificateManager.Timeouts.Query = time.Millisecond * 50
+				_, AddressError := CertificateManager.GetGuestIPAddress(VirtualMachine)
+				assert.ErrorIs(this.T(), AddressError, context.DeadlineExceeded)
+			}},
+
+			{"Timeout of the Call should Override the one of the Manager", func(t *testing.T) {
+				_, StateError := PowerManager.GetPowerState(VirtualMachine, options.WithTimeout(time.Second*5))
+				assert.NoError(this.T(), StateError)
+			}},
+
+			{"Cancellation of the Caller should not be Swallowed", func(t *testing.T) {
+				PowerManager.Timeouts = ssh_config.DefaultTimeouts()
+				Context, CancelFunc := context.WithCancel(context.Background())
+				CancelFunc()
+				_, StateError := PowerManager.GetPowerState(VirtualMachine, options.WithContext(Context))
+				assert.ErrorIs(this.T(), StateError, context.Canceled)
+
+				Deadline, CancelDeadline := context.WithTimeout(context.Background(), time.Millisecond*50)
+				defer CancelDeadline()
+				_, GenerateError := CertificateManager.GenerateSshKeysContext(Deadline, VirtualMachine, "1")
+				assert.ErrorIs(this.T(), GenerateError, context.DeadlineExceeded)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestLoggerLevel() {
+	os.Setenv("LOG_FILE", filepath.Join(this.T().TempDir(), "Main.json"))
+	defer ssh_config.InitializeProductionLogger()
+	defer os.Unsetenv("LOG_LEVEL")
+	defer os.Unsetenv("LOG_FILE")
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Log Level should be Taken from the Environment", func(t *testing.T) {
+				os.Setenv("LOG_LEVEL", "warn")
+				ssh_config.InitializeProductionLogger()
+				assert.False(this.T(), ssh_config.Logger.Core().Enabled(zapcore.InfoLevel))
+				assert.True(this.T(), ssh_config.Logger.Core().Enabled(zapcore.WarnLevel))
+			}},
+
+			{"Unknown Log Level should Fall back to the Default one", func(t *testing.T) {
+				os.Setenv("LOG_LEVEL", "verbose")
+				ssh_config.InitializeProductionLogger()
+				assert.True(this.T(), ssh_config.Logger.Core().Enabled(ssh_config.DefaultLogLevel))
+
+				_, LevelError := ssh_config.ParseLogLevel("verbose")
+				assert.ErrorIs(this.T(), LevelError, ssh_config.ErrInvalidLogLevel)
+			}},
+
+			{"Unwritable Log File should be Reported", func(t *testing.T) {
+				os.Setenv("LOG_FILE", filepath.Join(this.T().TempDir(), "missing", "Main.json"))
+				Previous := ssh_config.Logger
+				assert.Error(this.T(), ssh_config.InitializeLogger())
+				assert.Same(this.T(), Previous, ssh_config.Logger, "Current Logger should be Kept")
+			}},
+
+			{"Logger should be Closed", func(t *testing.T) {
+				os.Setenv("LOG_FILE", filepath.Join(this.T().TempDir(), "Main.json"))
+				assert.NoError(this.T(), ssh_config.InitializeLogger())
+				assert.NoError(this.T(), ssh_config.CloseLogger())
+				assert.False(this.T(), ssh_config.Logger.Core().Enabled(zapcore.ErrorLevel), "Closed Logger should Drop the Logs")
+				ssh_config.Logger.Error("Late Log should not Fail")
+				assert.NoError(this.T(), ssh_config.CloseLogger(), "Second Close should be a no-op")
+			}},
+		})
+}
+
+func newTestCertificatePEM(NotAfter ...time.Time) []byte {
+	// Returns Self-Signed PEM Certificate, Used as the Certificate of the Simulator Host, Valid for an Hour by default
+	Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "DC0_H0"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if len(NotAfter) != 0 {
+		Template.NotAfter = NotAfter[0]
+	}
+	Certificate, _ := x509.CreateCertificate(rand.Reader, Template, Template, &Key.PublicKey, Key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: Certificate})
+}
+
+func (this *SshConfigTestSuite) TestExportPublicCertificatePEM() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	HostSystem, _ := VirtualMachine.HostSystem(context.Background())
+	SimulatorHost := simulator.Map.Get(HostSystem.Reference()).(*simulator.HostSystem)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Installed Certificate should be Exported as PEM", func(t *testing.T) {
+				SimulatorHost.Config.Certificate = newTestCertificatePEM()
+				Content, FileName, ExportError := Manager.ExportPublicCertificatePEM(VirtualMachine)
+				assert.NoError(this.T(), ExportError)
+				assert.Equal(this.T(), "DC0_H0.pem", FileName)
+
+				Block, _ := pem.Decode(Content)
+				if assert.NotNil(this.T(), Block) {
+					assert.Equal(this.T(), "CERTIFICATE", Block.Type)
+					_, ParseError := x509.ParseCertificate(Block.Bytes)
+					assert.NoError(this.T(), ParseError)
+				}
+			}},
+
+			{"Missing Certificate should be Reported", func(t *testing.T) {
+				SimulatorHost.Config.Certificate = nil
+				_, _, ExportError := Manager.ExportPublicCertificatePEM(VirtualMachine)
+				assert.ErrorIs(this.T(), ExportError, ssh_config.ErrCertificateNotInstalled)
+			}},
+
+			{"Malformed Certificate should be Rejected", func(t *testing.T) {
+				SimulatorHost.Config.Certificate = []byte("10")
+				_, _, ExportError := Manager.ExportPublicCertificatePEM(VirtualMachine)
+				assert.ErrorIs(this.T(), ExportError, ssh_config.ErrInvalidHostCertificate)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestRotateSshKey() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+
+	CertificateManager := &fakeHostCertificateManager{}
+	CertificateManager.Self = types.ManagedObjectReference{Type: "HostCertificateManager", Value: "certificateManager-rotation"}
+	simulator.Map.Put(CertificateManager)
+	HostSystem, _ := VirtualMachine.HostSystem(context.Background())
+	simulator.Map.Get(HostSystem.Reference()).(*simulator.HostSystem).ConfigManager.CertificateManager = &CertificateManager.Self
+
+	// Database Record of the Simulator Virtual Machine with the Old Key
+	UUID := simulator.Map.Get(VirtualMachine.Reference()).(*simulator.VirtualMachine).Config.Uuid
+	Name := fmt.Sprintf("rotate-%d", time.Now().UnixNano())
+	var CustomerID int
+	models.Database.Raw("INSERT INTO customers (username, email, password, city, country, zip_code, street) "+
+		"VALUES (?, ?, '', '', '', '', '') RETURNING id", Name, Name+"@example.com").Scan(&CustomerID)
+	defer models.Database.Unscoped().Where("id = ?", CustomerID).Delete(&models.Customer{})
+	var VirtualMachineID int
+	models.Database.Raw("INSERT INTO virtual_machines (state, owner_id, virtual_machine_name, item_path, ip_address, uuid) "+
+		"VALUES (?, ?, ?, ?, ?, ?) RETURNING id", models.StatusReady, CustomerID, "rotate", "/DC0/vm/DC0_H0_VM0", Name, UUID).Scan(&VirtualMachineID)
+	defer models.Database.Unscoped().Where("id = ?", VirtualMachineID).Delete(&models.VirtualMachine{})
+	defer models.Database.Unscoped().Where("virtual_machine_id = ?", VirtualMachineID).Delete(&models.SSHPublicKey{})
+	OldKey, _ := Manager.GenerateSshKeyPair(ssh_config.KeyAlgorithmEd25519)
+	NewKey, _ := Manager.GenerateSshKeyPair(ssh_config.KeyAlgorithmEd25519)
+	models.NewSshPublicKey(VirtualMachineID, OldKey.Content, "old.pub").Create()
+
+	StoredKeys := func() []string {
+		Keys, _ := (&models.VirtualMachine{ID: VirtualMachineID}).ListSshKeys()
+		FileNames := []string{}
+		for _, Key := range Keys {
+			FileNames = append(FileNames, Key.Filename)
+		}
+		return FileNames
+	}
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Failed Upload should Leave the Database Untouched", func(t *testing.T) {
+				CertificateManager.FailInstall = true
+				defer func() { CertificateManager.FailInstall = false }()
+
+				RotateError := Manager.RotateSshKey(VirtualMachine, *ssh_config.NewSshCertificateCredentials(
+					NewKey.Content, "new.pub"), CustomerID)
+				assert.Error(this.T(), RotateError)
+				assert.NotErrorIs(this.T(), RotateError, ssh_config.ErrSshKeyNotPersisted)
+				assert.Equal(this.T(), []string{"old.pub"}, StoredKeys())
+			}},
+
+			{"Failed Database Update should be Reported for the Reconciliation", func(t *testing.T) {
+				// File Name does not fit the Column, so the Insert Fails after the Old Keys are Deleted
+				RotateError := Manager.RotateSshKey(VirtualMachine, *ssh_config.NewSshCertificateCredentials(
+					NewKey.Content, strings.Repeat("k", 150)+".pub"), CustomerID)
+				assert.ErrorIs(this.T(), RotateError, ssh_config.ErrSshKeyNotPersisted)
+				assert.Len(this.T(), CertificateManager.Installed, 1, "Key should have been Uploaded")
+				assert.Equal(this.T(), []string{"old.pub"}, StoredKeys(), "Transaction should be Rolled back")
+			}},
+
+			{"Successful Rotation should Replace the Keys", func(t *testing.T) {
+				assert.NoError(this.T(), Manager.RotateSshKey(VirtualMachine, *ssh_config.NewSshCertificateCredentials(
+					NewKey.Content, "new.pub"), CustomerID))
+				assert.Equal(this.T(), []string{"new.pub"}, StoredKeys())
+			}},
+
+			{"Invalid Key should be neither Uploaded nor Saved", func(t *testing.T) {
+				Installed := len(CertificateManager.Installed)
+				RotateError := Manager.RotateSshKey(VirtualMachine, *ssh_config.NewSshCertificateCredentials(
+					[]byte("not-a-key"), "broken.pub"), CustomerID)
+				assert.ErrorIs(this.T(), RotateError, models.ErrInvalidSshPublicKey)
+				assert.Len(this.T(), CertificateManager.Installed, Installed)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestUploadSshCertificate() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+	Manager.RateLimiter = nil
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+
+	CertificateManager := &fakeHostCertificateManager{}
+	CertificateManager.Self = types.ManagedObjectReference{Type: "HostCertificateManager", Value: "certificateManager-upload"}
+	simulator.Map.Put(CertificateManager)
+	HostSystem, _ := VirtualMachine.HostSystem(context.Background())
+	simulator.Map.Get(HostSystem.Reference()).(*simulator.HostSystem).ConfigManager.CertificateManager = &CertificateManager.Self
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"PEM Certificate should be Installed and Verified", func(t *testing.T) {
+				UploadError := Manager.UploadSshKeys(VirtualMachine, *ssh_config.NewSshCertificateCredentials(
+					newTestCertificatePEM(), "host.pem"), 1)
+				assert.NoError(this.T(), UploadError)
+				assert.Len(this.T(), CertificateManager.Installed, 1)
+			}},
+
+			{"Certificate, the Host does not Serve, should be Reported", func(t *testing.T) {
+				CertificateManager.KeepCertificate = true
+				defer func() { CertificateManager.KeepCertificate = false }()
+
+				UploadError := Manager.UploadSshKeys(VirtualMachine, *ssh_config.NewSshCertificateCredentials(
+					newTestCertificatePEM(time.Now().Add(time.Hour*2)), "host.pem"), 1)
+				assert.ErrorIs(this.T(), UploadError, certificates.ErrCertificateNotEffective)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestCertificateExpiry() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	HostSystem, _ := VirtualMachine.HostSystem(context.Background())
+	SimulatorHost := simulator.Map.Get(HostSystem.Reference()).(*simulator.HostSystem)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Expiry should Match the Certificate of the Host", func(t *testing.T) {
+				NotAfter := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+				SimulatorHost.Config.Certificate = newTestCertificatePEM(NotAfter)
+
+				Expiry, ExpiryError := Manager.GetCertificateExpiry(VirtualMachine)
+				assert.NoError(this.T(), ExpiryError)
+				assert.True(this.T(), NotAfter.Equal(Expiry))
+			}},
+
+			{"Certificate, that Expires Soon, should be Reported", func(t *testing.T) {
+				SimulatorHost.Config.Certificate = newTestCertificatePEM(time.Now().Add(time.Hour * 24 * 10))
+
+				Expiring, ExpiryError := Manager.IsCertificateExpiringWithin(VirtualMachine, time.Hour*24*30)
+				assert.NoError(this.T(), ExpiryError)
+				assert.True(this.T(), Expiring)
+
+				Expiring, _ = Manager.IsCertificateExpiringWithin(VirtualMachine, time.Hour*24)
+				assert.False(this.T(), Expiring)
+			}},
+
+			{"Missing or Unparseable Certificate should be Reported", func(t *testing.T) {
+				SimulatorHost.Config.Certificate = nil
+				_, ExpiryError := Manager.GetCertificateExpiry(VirtualMachine)
+				assert.ErrorIs(this.T(), ExpiryError, ssh_config.ErrCertificateNotInstalled)
+
+				SimulatorHost.Config.Certificate = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")})
+				_, ExpiryError = Manager.IsCertificateExpiringWithin(VirtualMachine, time.Hour)
+				assert.ErrorIs(this.T(), ExpiryError, ssh_config.ErrInvalidHostCertificate)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestRunGuestCommand() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachineSshRootCredentialsManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	SimulatorVM := simulator.Map.Get(VirtualMachine.Reference()).(*simulator.VirtualMachine)
+	Auth := &types.NamePasswordAuthentication{Username: "root", Password: "password"}
+
+	// Starting the Program itself Requires the Container Backed VM in the Simulator, so only the Checks are Covered
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Program should be Required", func(t *testing.T) {
+				_, RunError := Manager.RunGuestCommand(VirtualMachine, Auth, "", "")
+				assert.ErrorIs(this.T(), RunError, ssh_config.ErrGuestProgramRequired)
+			}},
+
+			{"Command should not be Started, if Tools are not Running", func(t *testing.T) {
+				SimulatorVM.Guest.ToolsRunningStatus = string(types.VirtualMachineToolsRunningStatusGuestToolsNotRunning)
+				_, RunError := Manager.RunGuestCommand(VirtualMachine, Auth, "/bin/true", "")
+				assert.ErrorIs(this.T(), RunError, guest.ErrToolsNotRunning)
+			}},
+		})
+}
+
+type memorySecretStore struct {
+	// Secret Store, that keeps the Passwords in Memory, so the Credentials can be Tested without the Database
+	Passwords map[string]string
+}
+
+func (this *memorySecretStore) GetPassword(VirtualMachineId string) (string, error) {
+	Password, Exists := this.Passwords[VirtualMachineId]
+	if !Exists {
+		return "", ssh_config.ErrSecretNotFound
+	}
+	return Password, nil
+}
+
+func (this *memorySecretStore) SetPassword(VirtualMachineId string, Password string) error {
+	this.Passwords[VirtualMachineId] = Password
+	return nil
+}
+
+func (this *SshConfigTestSuite) TestGetSshRootCredentials() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachineSshRootCredentialsManager(*Client.Client)
+	Secrets := &memorySecretStore{Passwords: map[string]string{}}
+	Manager.Secrets = Secrets
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	AnotherVirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM1")
+	UnknownVirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_C0_RP0_VM0")
+
+	// Passwords are Stored by the ID of the Virtual Machine Record, which is Found by the UUID
+	Name := fmt.Sprintf("root-credentials-%d", time.Now().UnixNano())
+	var CustomerID int
+	models.Database.Raw("INSERT INTO customers (username, email, password, city, country, zip_code, street) "+
+		"VALUES (?, ?, '', '', '', '', '') RETURNING id", Name, Name+"@example.com").Scan(&CustomerID)
+	defer models.Database.Unscoped().Where("id = ?", CustomerID).Delete(&models.Customer{})
+	RecordIDs := map[*object.VirtualMachine]int{}
+	for _, Simulated := range []*object.VirtualMachine{VirtualMachine, AnotherVirtualMachine} {
+		var RecordID int
+		models.Database.Raw("INSERT INTO virtual_machines (state, owner_id, virtual_machine_name, item_path, uuid) "+
+			"VALUES (?, ?, ?, ?, ?) RETURNING id", models.StatusReady, CustomerID, Name, Simulated.InventoryPath,
+			simulator.Map.Get(Simulated.Reference()).(*simulator.VirtualMachine).Config.Uuid).Scan(&RecordID)
+		RecordIDs[Simulated] = RecordID
+		defer models.Database.Unscoped().Where("id = ?", RecordID).Delete(&models.VirtualMachine{})
+	}
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Virtual Machine without the Record should have no Password", func(t *testing.T) {
+				Credentials, CredentialsError := Manager.GetSshRootCredentials(UnknownVirtualMachine)
+				assert.ErrorIs(this.T(), CredentialsError, models.ErrNotFound)
+				assert.Nil(this.T(), Credentials)
+			}},
+
+			{"Password, that has not been Set in the Guest, should not be Returned", func(t *testing.T) {
+				Credentials, CredentialsError := Manager.GetSshRootCredentials(AnotherVirtualMachine)
+				assert.ErrorIs(this.T(), CredentialsError, ssh_config.ErrRootPasswordNotProvisioned)
+				assert.Nil(this.T(), Credentials)
+			}},
+
+			{"Provisioned Password should be Returned", func(t *testing.T) {
+				Secrets.Passwords[strconv.Itoa(RecordIDs[VirtualMachine])] = "provisioned"
+				Credentials, CredentialsError := Manager.GetSshRootCredentials(VirtualMachine)
+				assert.NoError(this.T(), CredentialsError)
+				if assert.NotNil(this.T(), Credentials) {
+					assert.Equal(this.T(), "root", Credentials.Username)
+					assert.Equal(this.T(), "provisioned", Credentials.Password)
+				}
+			}},
+
+			{"Password should not be Provisioned without the Bootstrap Credentials", func(t *testing.T) {
+				Manager.Bootstrap = nil
+				assert.ErrorIs(this.T(), Manager.ProvisionRootPassword(AnotherVirtualMachine), ssh_config.ErrBootstrapCredentialsRequired)
+				assert.NotContains(this.T(), Secrets.Passwords, strconv.Itoa(RecordIDs[AnotherVirtualMachine]))
+			}},
+
+			{"Password should not be Saved, if it can't be Set in the Guest", func(t *testing.T) {
+				// Setting the Password Requires the Container Backed VM in the Simulator, so only the Tools Check is Covered
+				Manager.Bootstrap = &types.NamePasswordAuthentication{Username: "root", Password: "template"}
+				SimulatorVM := simulator.Map.Get(AnotherVirtualMachine.Reference()).(*simulator.VirtualMachine)
+				SimulatorVM.Guest.ToolsRunningStatus = string(types.VirtualMachineToolsRunningStatusGuestToolsNotRunning)
+
+				assert.ErrorIs(this.T(), Manager.ProvisionRootPassword(AnotherVirtualMachine), guest.ErrToolsNotRunning)
+				assert.NotContains(this.T(), Secrets.Passwords, strconv.Itoa(RecordIDs[AnotherVirtualMachine]))
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestGetGuestIPAddress() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	SimulatorVM := simulator.Map.Get(VirtualMachine.Reference()).(*simulator.VirtualMachine)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Reported IP Address should be Returned", func(t *testing.T) {
+				SimulatorVM.Guest.IpAddress = "10.0.0.15"
+				IPAddress, AddressError := Manager.GetGuestIPAddress(VirtualMachine)
+				assert.NoError(this.T(), AddressError)
+				assert.Equal(this.T(), "10.0.0.15", IPAddress)
+			}},
+
+			{"Missing IP Address should be Reported", func(t *testing.T) {
+				SimulatorVM.Guest.IpAddress = ""
+				IPAddress, AddressError := Manager.GetGuestIPAddress(VirtualMachine)
+				assert.ErrorIs(this.T(), AddressError, ssh_config.ErrGuestIPAddressNotReported)
+				assert.Empty(this.T(), IPAddress)
+
+				_, SyncError := Manager.SyncGuestIPAddress(VirtualMachine)
+				assert.ErrorIs(this.T(), SyncError, ssh_config.ErrGuestIPAddressNotReported)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestUploadSshKeysToMany() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachines, _ := Finder.VirtualMachineList(context.Background(), "*")
+	Orphan, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM1")
+
+	// Every Host gets the Fake Certificate Manager, the Orphan has no Host, so the Upload to it Fails
+	CertificateManager := &fakeHostCertificateManager{}
+	CertificateManager.Self = types.ManagedObjectReference{Type: "HostCertificateManager", Value: "certificateManager-fake"}
+	simulator.Map.Put(CertificateManager)
+	for _, Host := range simulator.Map.All("HostSystem") {
+		Host.(*simulator.HostSystem).ConfigManager.CertificateManager = &CertificateManager.Self
+	}
+	simulator.Map.Get(Orphan.Reference()).(*simulator.VirtualMachine).Summary.Runtime.Host = nil
+
+	Key, _ := Manager.GenerateSshKeyPair(ssh_config.KeyAlgorithmEd25519)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Failing Virtual Machine should not Block the others", func(t *testing.T) {
+				Errors := Manager.UploadSshKeysToMany(VirtualMachines, *Key, 2, 1)
+
+				assert.Len(this.T(), Errors, 1)
+				assert.Error(this.T(), Errors["DC0_H0_VM1"])
+				assert.Len(this.T(), CertificateManager.Installed, len(VirtualMachines)-1)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestUploadSshKeysTracing() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	tracing.InstrumentClient(Client.Client)
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+
+	Exporter := tracetest.NewInMemoryExporter()
+	Provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(Exporter))
+	Previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(Provider)
+	defer otel.SetTracerProvider(Previous)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+	Orphan, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM1")
+
+	CertificateManager := &fakeHostCertificateManager{}
+	CertificateManager.Self = types.ManagedObjectReference{Type: "HostCertificateManager", Value: "certificateManager-tracing"}
+	simulator.Map.Put(CertificateManager)
+	for _, Host := range simulator.Map.All("HostSystem") {
+		Host.(*simulator.HostSystem).ConfigManager.CertificateManager = &CertificateManager.Self
+	}
+	simulator.Map.Get(Orphan.Reference()).(*simulator.VirtualMachine).Summary.Runtime.Host = nil
+
+	Key, _ := Manager.GenerateSshKeyPair(ssh_config.KeyAlgorithmEd25519)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Upload should be Traced along with its vSphere Calls", func(t *testing.T) {
+				Exporter.Reset()
+				assert.NoError(this.T(), Manager.UploadSshKeys(VirtualMachine, *Key, 1))
+
+				Spans := Exporter.GetSpans()
+				var Upload *tracetest.SpanStub
+				for Index := range Spans {
+					if Spans[Index].Name == "ssh_config.UploadSshKeys" {
+						Upload = &Spans[Index]
+					}
+				}
+				if !assert.NotNil(this.T(), Upload) {
+					return
+				}
+				assert.Equal(this.T(), codes.Ok, Upload.Status.Code)
+				assert.Contains(this.T(), Upload.Attributes, tracing.AttributeVirtualMachine.String("DC0_H0_VM0"))
+				assert.Contains(this.T(), Upload.Attributes, tracing.AttributeOperation.String("UploadSshKeys"))
+
+				var Installation *tracetest.SpanStub
+				for Index := range Spans {
+					if Spans[Index].Name == "vsphere.InstallServerCertificate" {
+						Installation = &Spans[Index]
+					}
+				}
+				if assert.NotNil(this.T(), Installation) {
+					assert.Equal(this.T(), Upload.SpanContext.SpanID(), Installation.Parent.SpanID())
+				}
+			}},
+
+			{"Failed Upload should Record the Error", func(t *testing.T) {
+				Exporter.Reset()
+				assert.Error(this.T(), Manager.UploadSshKeys(Orphan, *Key, 1))
+
+				Spans := Exporter.GetSpans()
+				if !assert.NotEmpty(this.T(), Spans) {
+					return
+				}
+				Upload := Spans[len(Spans)-1]
+				assert.Equal(this.T(), "ssh_config.UploadSshKeys", Upload.Name)
+				assert.Equal(this.T(), codes.Error, Upload.Status.Code)
+				if assert.NotEmpty(this.T(), Upload.Events) {
+					assert.Equal(this.T(), "exception", Upload.Events[0].Name)
+				}
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestRateLimiter() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+	Manager := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+
+	CertificateManager := &fakeHostCertificateManager{}
+	CertificateManager.Self = types.ManagedObjectReference{Type: "HostCertificateManager", Value: "certificateManager-limited"}
+	simulator.Map.Put(CertificateManager)
+	for _, Host := range simulator.Map.All("HostSystem") {
+		Host.(*simulator.HostSystem).ConfigManager.CertificateManager = &CertificateManager.Self
+	}
+	Key, _ := Manager.GenerateSshKeyPair(ssh_config.KeyAlgorithmEd25519)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Call over the Burst should be Rejected", func(t *testing.T) {
+				Limiter := ssh_config.NewRateLimiter(rate.Every(time.Hour), 3)
+				for Call := 0; Call < 3; Call++ {
+					assert.NoError(this.T(), Limiter.CheckLimit(1))
+				}
+				assert.ErrorIs(this.T(), Limiter.CheckLimit(1), ssh_config.ErrRateLimited)
+				assert.NoError(this.T(), Limiter.CheckLimit(2), "Customers should have Separate Limits")
+			}},
+
+			{"Limit should be Reset over Time", func(t *testing.T) {
+				Limiter := ssh_config.NewRateLimiter(rate.Every(time.Millisecond*100), 1)
+				assert.NoError(this.T(), Limiter.CheckLimit(1))
+				assert.ErrorIs(this.T(), Limiter.CheckLimit(1), ssh_config.ErrRateLimited)
+
+				time.Sleep(time.Millisecond * 150)
+				assert.NoError(this.T(), Limiter.CheckLimit(1))
+			}},
+
+			{"Rate should be Configurable by the Environment", func(t *testing.T) {
+				this.T().Setenv("SSH_KEY_OPERATIONS_PER_MINUTE", "30")
+				this.T().Setenv("SSH_KEY_OPERATIONS_BURST", "2")
+				Limiter := ssh_config.NewRateLimiterFromEnvironment()
+				assert.Equal(this.T(), rate.Every(time.Second*2), Limiter.Limit)
+				assert.Equal(this.T(), 2, Limiter.Burst)
+
+				this.T().Setenv("SSH_KEY_OPERATIONS_PER_MINUTE", "invalid")
+				Limiter = ssh_config.NewRateLimiterFromEnvironment()
+				assert.Equal(this.T(), rate.Every(time.Minute/ssh_config.DefaultSshKeyOperationsPerMinute), Limiter.Limit)
+			}},
+
+			{"Uploads of the Customer should be Limited", func(t *testing.T) {
+				Manager.RateLimiter = ssh_config.NewRateLimiter(rate.Every(time.Hour), 1)
+
+				assert.NoError(this.T(), Manager.UploadSshKeys(VirtualMachine, *Key, 7))
+				assert.ErrorIs(this.T(), Manager.UploadSshKeys(VirtualMachine, *Key, 7), ssh_config.ErrRateLimited)
+				assert.Len(this.T(), CertificateManager.Installed, 1)
+			}},
+
+			{"Operations without the Customer should be Rejected", func(t *testing.T) {
+				Installed := len(CertificateManager.Installed)
+				assert.ErrorIs(this.T(), Manager.UploadSshKeys(VirtualMachine, *Key, 0), ssh_config.ErrCustomerRequired)
+				assert.ErrorIs(this.T(), Manager.RotateSshKey(VirtualMachine, *Key, 0), ssh_config.ErrCustomerRequired)
+				for _, UploadError := range Manager.UploadSshKeysToMany([]*object.VirtualMachine{VirtualMachine}, *Key, 1, 0) {
+					assert.ErrorIs(this.T(), UploadError, ssh_config.ErrCustomerRequired)
+				}
+				assert.Len(this.T(), CertificateManager.Installed, Installed)
+			}},
+		})
+}
+
+func (this *SshConfigTestSuite) TestNewSshManager() {
+	Model := simulator.VPX()
+	Model.Create()
+	defer Model.Remove()
+	Server := Model.Service.NewServer()
+	defer Server.Close()
+
+	Client, ConnectionError := govmomi.NewClient(context.Background(), Server.URL, true)
+	assert.NoError(this.T(), ConnectionError)
+
+	Finder := find.NewFinder(Client.Client)
+	Datacenter, _ := Finder.DefaultDatacenter(context.Background())
+	Finder.SetDatacenter(Datacenter)
+	VirtualMachine, _ := Finder.VirtualMachine(context.Background(), "DC0_H0_VM0")
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Certificate Manager should be Returned for the Certificate Type", func(t *testing.T) {
+				Manager, ManagerError := ssh_config.NewSshManager(models.TypeByRootCertificate, *Client.Client)
+				assert.NoError(this.T(), ManagerError)
+				assert.IsType(this.T(), &ssh_config.VirtualMachineSshCertificateManager{}, Manager)
+				assert.Equal(this.T(), models.TypeByRootCertificate, Manager.Type())
+			}},
+
+			{"Root Credentials Manager should be Returned for the Credentials Type", func(t *testing.T) {
+				Manager, ManagerError := ssh_config.NewSshManager(models.TypeByRootCredentials, *Client.Client)
+				assert.NoError(this.T(), ManagerError)
+				assert.IsType(this.T(), &ssh_config.VirtualMachineSshRootCredentialsManager{}, Manager)
+				assert.Equal(this.T(), models.TypeByRootCredentials, Manager.Type())
+
+				Key, GenerateError := Manager.GenerateKeys(VirtualMachine, "42")
+				assert.NoError(this.T(), GenerateError)
+				assert.NoError(this.T(), models.ValidateSshPublicKey(Key.Content))
+				assert.Equal(this.T(), "DC0_H0_VM0_ssh_key.pub", Key.FileName)
+			}},
+
+			{"Both Managers should Implement the Interface", func(t *testing.T) {
+				assert.Implements(this.T(), (*ssh_config.VirtualMachineSshManagerInterface)(nil),
+					ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client))
+				assert.Implements(this.T(), (*ssh_config.VirtualMachineSshManagerInterface)(nil),
+					ssh_config.NewVirtualMachineSshRootCredentialsManager(*Client.Client))
+			}},
+
+			{"Options of the Caller should be Applied to the Upload", func(t *testing.T) {
+				Manager := ssh_config.NewVirtualMachineSshRootCredentialsManager(*Client.Client)
+				Manager.Secrets = &memorySecretStore{Passwords: map[string]string{}}
+				Key, _ := Manager.GenerateKeys(VirtualMachine, "42")
+				simulator.Map.Get(VirtualMachine.Reference()).(*simulator.VirtualMachine).Guest.ToolsRunningStatus =
+					string(types.VirtualMachineToolsRunningStatusGuestToolsRunning)
+
+				Cancelled, Cancel := context.WithCancel(context.Background())
+				Cancel()
+				UploadError := Manager.UploadKeys(VirtualMachine, *Key, 1, options.WithContext(Cancelled))
+				assert.ErrorIs(this.T(), UploadError, context.Canceled)
+			}},
+
+			{"Unknown Type should be Rejected", func(t *testing.T) {
+				Manager, ManagerError := ssh_config.NewSshManager("ByPassword", *Client.Client)
+				assert.ErrorIs(this.T(), ManagerError, ssh_config.ErrUnsupportedSshManager)
+				assert.Nil(this.T(), Manager)
+			}},
+		})
+}
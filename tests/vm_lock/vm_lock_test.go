@@ -0,0 +1,107 @@
+package vm_lock_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LovePelmeni/Infrastructure/vm_lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type LockTestSuite struct {
+	suite.Suite
+	Registry *vm_lock.LockRegistry
+}
+
+func TestLockSuite(t *testing.T) {
+	suite.Run(t, new(LockTestSuite))
+}
+
+func (this *LockTestSuite) SetupTest() {
+	this.Registry = vm_lock.NewLockRegistry()
+}
+
+func virtualMachineReference(Value string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{Type: "VirtualMachine", Value: Value}
+}
+
+func (this *LockTestSuite) runConcurrently(References []types.ManagedObjectReference, Operations int) int32 {
+	// Runs the Operations against the Virtual Machines Concurrently, Returns Max Amount of the Operations, that were Running at once
+	var Running, MaxRunning int32
+	var Group sync.WaitGroup
+	for Index := 0; Index < Operations; Index++ {
+		Group.Add(1)
+		go func(Reference types.ManagedObjectReference) {
+			defer Group.Done()
+			Release, LockError := this.Registry.Acquire(context.Background(), Reference, true)
+			assert.NoError(this.T(), LockError)
+			defer Release()
+
+			Current := atomic.AddInt32(&Running, 1)
+			for {
+				Max := atomic.LoadInt32(&MaxRunning)
+				if Current <= Max || atomic.CompareAndSwapInt32(&MaxRunning, Max, Current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond * 5)
+			atomic.AddInt32(&Running, -1)
+		}(References[Index%len(References)])
+	}
+	Group.Wait()
+	return MaxRunning
+}
+
+func (this *LockTestSuite) TestSerialization() {
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Operations on the same Virtual Machine should be Serialized", func(t *testing.T) {
+				MaxRunning := this.runConcurrently([]types.ManagedObjectReference{virtualMachineReference("vm-1")}, 20)
+				assert.Equal(this.T(), int32(1), MaxRunning)
+				assert.Zero(this.T(), this.Registry.Size(), "Released Locks should be Removed from the Registry")
+			}},
+
+			{"Operations on the different Virtual Machines should only be Serialized per Virtual Machine", func(t *testing.T) {
+				MaxRunning := this.runConcurrently([]types.ManagedObjectReference{
+					virtualMachineReference("vm-1"), virtualMachineReference("vm-2")}, 20)
+				assert.LessOrEqual(this.T(), MaxRunning, int32(2))
+			}},
+		})
+}
+
+func (this *LockTestSuite) TestBusyVirtualMachine() {
+	Reference := virtualMachineReference("vm-busy")
+	Release, LockError := this.Registry.Acquire(context.Background(), Reference, true)
+	assert.NoError(this.T(), LockError)
+
+	testing.RunTests(func(st string, pa string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+
+			{"Operation should Fail Immediately, if it does not Wait", func(t *testing.T) {
+				_, BusyError := this.Registry.Acquire(context.Background(), Reference, false)
+				assert.ErrorIs(this.T(), BusyError, vm_lock.ErrVMBusy)
+			}},
+
+			{"Waiting should be Cancelled along with the Context", func(t *testing.T) {
+				TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Millisecond*20)
+				defer CancelFunc()
+				_, WaitError := this.Registry.Acquire(TimeoutContext, Reference, true)
+				assert.ErrorIs(this.T(), WaitError, context.DeadlineExceeded)
+			}},
+
+			{"Lock should be Acquired again after the Release", func(t *testing.T) {
+				Release()
+				Release() // Releasing Twice should be Harmless
+				NextRelease, NextError := this.Registry.Acquire(context.Background(), Reference, false)
+				assert.NoError(this.T(), NextError)
+				NextRelease()
+				assert.Zero(this.T(), this.Registry.Size())
+			}},
+		})
+}
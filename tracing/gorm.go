@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span" // Key of the Span in the Instance Settings of the Statement
+
+func RegisterGormCallbacks(Database *gorm.DB) error {
+	// Wraps every Query of the Database into the Span, Named after the Kind of the Query (e.g `gorm.query`),
+	// Spans are Children of the Span of the Statement Context, so Queries should be Run with `WithContext`
+	Callback := Database.Callback()
+	RegisterErrors := []error{
+		Callback.Create().Before("gorm:create").Register("tracing:before_create", startGormSpan("create")),
+		Callback.Create().After("gorm:create").Register("tracing:after_create", endGormSpan),
+		Callback.Query().Before("gorm:query").Register("tracing:before_query", startGormSpan("query")),
+		Callback.Query().After("gorm:query").Register("tracing:after_query", endGormSpan),
+		Callback.Update().Before("gorm:update").Register("tracing:before_update", startGormSpan("update")),
+		Callback.Update().After("gorm:update").Register("tracing:after_update", endGormSpan),
+		Callback.Delete().Before("gorm:delete").Register("tracing:before_delete", startGormSpan("delete")),
+		Callback.Delete().After("gorm:delete").Register("tracing:after_delete", endGormSpan),
+		Callback.Row().Before("gorm:row").Register("tracing:before_row", startGormSpan("row")),
+		Callback.Row().After("gorm:row").Register("tracing:after_row", endGormSpan),
+		Callback.Raw().Before("gorm:raw").Register("tracing:before_raw", startGormSpan("raw")),
+		Callback.Raw().After("gorm:raw").Register("tracing:after_raw", endGormSpan),
+	}
+	for _, RegisterError := range RegisterErrors {
+		if RegisterError != nil {
+			return RegisterError
+		}
+	}
+	return nil
+}
+
+func startGormSpan(Operation string) func(*gorm.DB) {
+	// Returns the Callback, that Starts the Span of the Query and Passes it to the `endGormSpan` through the Statement
+	return func(Database *gorm.DB) {
+		SpanContext, Span := StartSpan(Database.Statement.Context, "gorm."+Operation, AttributeOperation.String(Operation))
+		Database.Statement.Context = SpanContext
+		Database.InstanceSet(gormSpanKey, Span)
+	}
+}
+
+func endGormSpan(Database *gorm.DB) {
+	// Ends the Span of the Query, Missing Records are Expected by the Callers, so they are not Treated as Errors
+	Value, Found := Database.InstanceGet(gormSpanKey)
+	if !Found {
+		return
+	}
+	Span, IsSpan := Value.(trace.Span)
+	if !IsSpan {
+		return
+	}
+	Span.SetAttributes(attribute.String("db.table", Database.Statement.Table),
+		attribute.String("db.statement", Database.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", Database.Statement.RowsAffected))
+
+	QueryError := Database.Error
+	if errors.Is(QueryError, gorm.ErrRecordNotFound) {
+		QueryError = nil
+	}
+	EndSpan(Span, QueryError)
+}
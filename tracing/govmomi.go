@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+type tracingRoundTripper struct {
+	// Round Tripper of the vSphere Client, that Wraps every SOAP Call into the Span
+	soap.RoundTripper
+}
+
+func (this *tracingRoundTripper) RoundTrip(Context context.Context, Request, Response soap.HasFault) error {
+	// Runs the SOAP Call within the Span, Named after the vSphere Method (e.g `vsphere.PowerOnVM_Task`),
+	// Faults are Returned as Errors by the Client, so they are Recorded as well
+	Method := methodName(Request)
+	SpanContext, Span := StartSpan(Context, "vsphere."+Method, AttributeOperation.String(Method))
+	CallError := this.RoundTripper.RoundTrip(SpanContext, Request, Response)
+	EndSpan(Span, CallError)
+	return CallError
+}
+
+func InstrumentClient(Client *vim25.Client) {
+	// Makes every vSphere Call of the Client to be Traced, Instrumenting the same Client Twice has no Effect
+	if Client == nil {
+		return
+	}
+	if _, Instrumented := Client.RoundTripper.(*tracingRoundTripper); Instrumented {
+		return
+	}
+	Client.RoundTripper = &tracingRoundTripper{RoundTripper: Client.RoundTripper}
+}
+
+func methodName(Request soap.HasFault) string {
+	// Returns Name of the vSphere Method by the Request Body, e.g `*methods.PowerOnVM_TaskBody` -> `PowerOnVM_Task`
+	Type := reflect.TypeOf(Request)
+	for Type.Kind() == reflect.Pointer {
+		Type = Type.Elem()
+	}
+	return strings.TrimSuffix(Type.Name(), "Body")
+}
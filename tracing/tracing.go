@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/LovePelmeni/Infrastructure/logging"
+)
+
+// Package consists of the OpenTelemetry Tracing Setup of the Project,
+// vSphere Calls (See `InstrumentClient`) and Database Queries (See `RegisterGormCallbacks`) get their own Spans,
+// which are Nested under the Spans of the Operations, as long as the Context is Passed Down (See `options.WithContext`)
+//
+// Until the `InitTracer` is Called, the Global Tracer Provider is the No-Op one, so Spans cost Nothing
+
+const TracerName = "github.com/LovePelmeni/Infrastructure"
+const ServiceName = "infrastructure"
+
+// Attributes of the Spans
+const AttributeVirtualMachine = attribute.Key("vm.name")
+const AttributeOperation = attribute.Key("operation")
+
+var (
+	Logger *zap.Logger
+)
+
+func InitializeProductionLogger() {
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("TracingLog.json")
+}
+
+func init() {
+	InitializeProductionLogger()
+}
+
+func InitTracer(Endpoint string) (func(context.Context) error, error) {
+	// Sets Up the Global Tracer Provider, that Exports Spans in Batches to the Collector Endpoint
+	// (e.g `http://jaeger:14268/api/traces`), Returns the Function, that Flushes Pending Spans and Stops the Provider,
+	// it should be Called on Shutdown. If the Endpoint is Empty, Tracing stays Disabled
+
+	if len(Endpoint) == 0 {
+		Logger.Debug("Tracing Endpoint is not Configured, Tracing is Disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	Exporter, ExporterError := jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(Endpoint)))
+	if ExporterError != nil {
+		Logger.Error("Failed to Initialize Span Exporter", zap.String("Endpoint", Endpoint), zap.Error(ExporterError))
+		return nil, ExporterError
+	}
+	Provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(Exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(ServiceName))),
+	)
+	otel.SetTracerProvider(Provider)
+
+	Logger.Debug("Tracing has been Initialized", zap.String("Endpoint", Endpoint))
+	return Provider.Shutdown, nil
+}
+
+func StartSpan(Context context.Context, Name string, Attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	// Starts the Span as a Child of the Span of the Context (if any), the Returned Context Carries the New Span
+	if Context == nil {
+		Context = context.Background()
+	}
+	return otel.Tracer(TracerName).Start(Context, Name, trace.WithAttributes(Attributes...))
+}
+
+func EndSpan(Span trace.Span, Error error) {
+	// Records the Error of the Operation (if any), Sets the Status of the Span accordingly and Ends it
+	if Error != nil {
+		Span.RecordError(Error)
+		Span.SetStatus(codes.Error, Error.Error())
+	} else {
+		Span.SetStatus(codes.Ok, "")
+	}
+	Span.End()
+}
@@ -0,0 +1,106 @@
+package vm_lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Package consists of the Per-VM Lock Registry, that Serializes Mutating Operations against the same Virtual Machine,
+// vSphere Rejects Concurrent Tasks on the same VM (e.g Reconfigure, while the Clone is Running) with the Concurrency Faults
+//
+//	Release, LockError := vm_lock.Acquire(TimeoutContext, VirtualMachine.Reference(), true)
+//	if LockError != nil {
+//		return LockError
+//	}
+//	defer Release()
+
+var (
+	ErrVMBusy = errors.New("Another Operation is Running on the Virtual Machine")
+)
+
+var (
+	// Process-Wide Registry, used by every Mutating Operation
+	DefaultRegistry = NewLockRegistry()
+)
+
+type lockEntry struct {
+	Semaphore chan struct{} // Holds a Value, while the Lock is Acquired
+	Users     int           // Amount of the Holders and Waiters, Entry is being Removed, once it drops to Zero
+}
+
+type LockRegistry struct {
+	// Registry of the Locks, Keyed by the Virtual Machine Reference
+	mutex sync.Mutex
+	locks map[string]*lockEntry
+}
+
+func NewLockRegistry() *LockRegistry {
+	return &LockRegistry{
+		locks: map[string]*lockEntry{},
+	}
+}
+
+func (this *LockRegistry) Acquire(Context context.Context, Reference types.ManagedObjectReference, Wait bool) (func(), error) {
+	// Acquires the Lock of the Virtual Machine and Returns the Function, that Releases it
+	// If the Lock is Held by another Operation, Waits until it is Released or the Context is Done (If `Wait` is True),
+	// Otherwise Returns `ErrVMBusy` Immediately
+
+	Key := Reference.String()
+
+	this.mutex.Lock()
+	Entry, Exists := this.locks[Key]
+	if !Exists {
+		Entry = &lockEntry{Semaphore: make(chan struct{}, 1)}
+		this.locks[Key] = Entry
+	}
+	Entry.Users++
+	this.mutex.Unlock()
+
+	if Wait {
+		select {
+		case Entry.Semaphore <- struct{}{}:
+		case <-Context.Done():
+			this.leave(Key, Entry)
+			return nil, Context.Err()
+		}
+	} else {
+		select {
+		case Entry.Semaphore <- struct{}{}:
+		default:
+			this.leave(Key, Entry)
+			return nil, ErrVMBusy
+		}
+	}
+
+	var Once sync.Once
+	return func() {
+		Once.Do(func() {
+			<-Entry.Semaphore
+			this.leave(Key, Entry)
+		})
+	}, nil
+}
+
+func (this *LockRegistry) leave(Key string, Entry *lockEntry) {
+	// Removes the Entry of the Virtual Machine, if Nobody Holds or Waits for it anymore
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if Entry.Users--; Entry.Users == 0 {
+		delete(this.locks, Key)
+	}
+}
+
+func (this *LockRegistry) Size() int {
+	// Returns Amount of the Virtual Machines, that are Locked or being Waited for
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return len(this.locks)
+}
+
+func Acquire(Context context.Context, Reference types.ManagedObjectReference, Wait bool) (func(), error) {
+	// Acquires the Lock of the Virtual Machine within the Default Registry
+	return DefaultRegistry.Acquire(Context, Reference, Wait)
+}
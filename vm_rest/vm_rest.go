@@ -3,6 +3,7 @@ package vm_rest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -17,14 +18,18 @@ import (
 
 	"github.com/LovePelmeni/Infrastructure/authentication"
 	"github.com/LovePelmeni/Infrastructure/deploy"
+	"github.com/LovePelmeni/Infrastructure/guest"
 	"github.com/LovePelmeni/Infrastructure/healthcheck"
+	"github.com/LovePelmeni/Infrastructure/logging"
 	"github.com/LovePelmeni/Infrastructure/models"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"github.com/LovePelmeni/Infrastructure/parsers"
+	"github.com/LovePelmeni/Infrastructure/reconfigure"
 	"github.com/LovePelmeni/Infrastructure/resources"
+	"github.com/LovePelmeni/Infrastructure/ssh_config"
+	"github.com/LovePelmeni/Infrastructure/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/vmware/govmomi"
@@ -63,15 +68,8 @@ var (
 )
 
 func InitializeProductionLogger() {
-
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = zapcore.ISO8601TimeEncoder
-	fileEncoder := zapcore.NewJSONEncoder(config)
-	file, _ := os.OpenFile("VmRestLog.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logWriter := zapcore.AddSync(file)
-
-	Core := zapcore.NewTee(zapcore.NewCore(fileEncoder, logWriter, zapcore.DebugLevel))
-	Logger = zap.New(Core)
+	// Initializes Logger, Configured by the Environment (See `logging.NewProductionLogger`)
+	Logger = logging.NewProductionLogger("VmRestLog.json")
 }
 
 func init() {
@@ -86,6 +84,7 @@ func init() {
 		Logger.Error("FAILED TO INITIALIZE CLIENT, DOES THE VMWARE HYPERVISOR ACTUALLY RUNNING?")
 
 	case ConnectionError == nil:
+		tracing.InstrumentClient(APIClient.Client)
 		RestClient = rest.NewClient(APIClient.Client)
 		if FailedToLogin := RestClient.Login(TimeoutContext, APIUrl.User); FailedToLogin != nil {
 			Logger.Error("FAILED TO LOGIN TO THE VMWARE HYPERVISOR SERVER", zap.Error(FailedToLogin))
@@ -180,6 +179,9 @@ type VirtualMachineSchemaStructure struct {
 		Memory          int `json:"Memory" xml:"Memory"`
 		StorageCapacity int `json:"StorageCapacity" xml:"StorageCapacity"`
 	} `json:"Resources" xml:"Resources"`
+
+	// Host and Cluster, the Virtual Machine currently Runs on
+	Placement *resources.Placement `json:"Placement,omitempty" xml:"Placement,omitempty"`
 }
 
 func GetCustomerVirtualMachine(RequestContext *gin.Context) {
@@ -196,12 +198,24 @@ func GetCustomerVirtualMachine(RequestContext *gin.Context) {
 	CustomerId := jwtCredentials.UserId
 
 	VirtualMachineId := RequestContext.Query("VirtualMachineId")
-	models.Database.Model(&VirtualMachineDatabaseObject).Where(
-		"owner_id = ? AND id = ?", CustomerId, VirtualMachineId).Find(&VirtualMachineDatabaseObject)
+	VirtualMachineGorm := models.Database.Model(&VirtualMachineDatabaseObject).Where(
+		"owner_id = ? AND id = ?", CustomerId, VirtualMachineId).First(&VirtualMachineDatabaseObject)
 
 	// Receiving the Customer
-	models.Database.Model(&models.Customer{}).Where(
-		"id = ?", CustomerId).Find(&CustomerDatabaseObject)
+	CustomerGorm := models.Database.Model(&models.Customer{}).Where(
+		"id = ?", CustomerId).First(&CustomerDatabaseObject)
+
+	for _, FindError := range []error{models.TranslateNotFound(VirtualMachineGorm.Error), models.TranslateNotFound(CustomerGorm.Error)} {
+		switch {
+		case errors.Is(FindError, models.ErrNotFound):
+			RequestContext.JSON(http.StatusNotFound, gin.H{"Error": "Virtual Machine Does Not Exist"})
+			return
+		case FindError != nil:
+			Logger.Error("Failed to Retrieve Virtual Machine", zap.String("Virtual Machine ID", VirtualMachineId), zap.Error(FindError))
+			RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": "Failed to Retrieve Virtual Machine"})
+			return
+		}
+	}
 
 	VirtualMachineManager := deploy.NewVirtualMachineManager(*Client.Client)
 	VirtualMachineInstance, FindError := VirtualMachineManager.GetVirtualMachine(VirtualMachineId, string(CustomerId))
@@ -230,9 +244,19 @@ func GetCustomerVirtualMachine(RequestContext *gin.Context) {
 	MemoryResourceUsage := healthManager.GetMemoryUsageMetrics().Active
 	StorageResourceUsage := healthManager.GetStorageUsageMetrics().Committed
 
+	// Receiving the Location of the Virtual Server, it is Optional for the Response
+	var Placement *resources.Placement
+	if VirtualMachinePlacement, PlacementError := resources.GetVMPlacement(
+		TimeoutContext, Client.Client, VirtualMachineInstance); PlacementError != nil {
+		Logger.Error("Failed to Get Virtual Machine Placement", zap.Error(PlacementError))
+	} else {
+		Placement = &VirtualMachinePlacement
+	}
+
 	VirtualMachine := VirtualMachineSchemaStructure{
 
 		VirtualMachineName: VirtualMachineDatabaseObject.VirtualMachineName,
+		Placement:          Placement,
 		VirtualMachineId:   strconv.Itoa(VirtualMachineDatabaseObject.ID),
 
 		CreatedAt: fmt.Sprintf("%s-%s-%s",
@@ -346,6 +370,13 @@ func GetCustomerVirtualMachines(RequestContext *gin.Context) {
 	}
 }
 
+func recordVirtualMachineEvent(VirtualMachineId string, Type string, Detail string) {
+	// Appends the Event to the Virtual Machine Timeline, the Write is Non-Blocking
+	if Id, ParseError := strconv.Atoi(VirtualMachineId); ParseError == nil {
+		models.RecordVMEvent(Id, Type, Detail)
+	}
+}
+
 // Virtual Machine Rest API Endpoints
 
 func InitializeVirtualMachineRestController(RequestContext *gin.Context) {
@@ -467,10 +498,19 @@ func InitializeVirtualMachineRestController(RequestContext *gin.Context) {
 			VirtualMachineName: VirtualMachineName,
 		}
 
-		Created, CreationError := NewVirtualMachine.Create()
+		Created, CreationError := NewVirtualMachine.CreateContext(RequestContext.Request.Context())
 		if CreationError != nil {
 			Created.Rollback()
 			Logger.Error("Failed to Create new Database VM Record", zap.Error(CreationError))
+		} else {
+			models.RecordVMEvent(NewVirtualMachine.ID, models.EventCreated, "Virtual Machine has been Initialized")
+		}
+
+		// Exposing the Owner to the Operators, who use vCenter directly, Failure is not Critical
+		OwnerError := reconfigure.NewVirtualMachineReconfigureManager(*Client.Client).SetOwnerCustomAttribute(
+			InitializedInstance, strconv.Itoa(CustomerId))
+		if OwnerError != nil {
+			Logger.Error("Failed to Set Owner Custom Attribute", zap.Error(OwnerError))
 		}
 		RequestContext.JSON(http.StatusCreated,
 			gin.H{"Status": "Initialized"})
@@ -527,15 +567,49 @@ func DeployVirtualMachineRestController(RequestContext *gin.Context) {
 		var VirtualMachineCustomConfiguration models.VirtualMachineConfiguration
 		var VirtualMachineSshConfiguration models.SSHConfiguration
 
+		// Generated Public Key is being Kept under its File Name, so it can be Downloaded Later
+		var PublicKey struct {
+			KeyContent []byte `json:"KeyContent"`
+			Filename   string `json:"Filename"`
+		}
+		if VmInfo.SshType == models.TypeByRootCertificate {
+			if DecodeError := json.Unmarshal([]byte(VmInfo.SshInfo), &PublicKey); DecodeError != nil {
+				Logger.Error("Failed to Decode Generated SSH Public Key", zap.String("Virtual Machine ID", VmId), zap.Error(DecodeError))
+				RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": "Invalid SSH Key has been Generated"})
+				return
+			}
+			Id, ConvertError := strconv.Atoi(VmId)
+			if ConvertError != nil {
+				RequestContext.JSON(http.StatusBadRequest, gin.H{"Error": "Invalid Virtual Machine ID"})
+				return
+			}
+			if _, SaveError := ssh_config.NewVirtualMachineSshCertificateManager(*Client.Client).SaveSshKey(Id,
+				*ssh_config.NewSshCertificateCredentials(PublicKey.KeyContent, PublicKey.Filename)); SaveError != nil {
+				Logger.Error("Failed to Save Generated SSH Public Key", zap.String("Virtual Machine ID", VmId), zap.Error(SaveError))
+				RequestContext.JSON(http.StatusInternalServerError, gin.H{"Error": "Failed to Save SSH Key"})
+				return
+			}
+		}
+
 		VirtualMachineSshConfiguration = models.SSHConfiguration{
 			Type:                 VmInfo.SshType,
 			SshCredentialsMethod: *models.NewSshCredentialsInfo(),
-			SshPublicKeyMethod:   *models.NewSshPublicKeyInfo(),
+			SshPublicKeyMethod:   *models.NewSshPublicKeyInfo(PublicKey.KeyContent, PublicKey.Filename),
 			VirtualMachineId:     VirtualMachine.ID,
 		}
 
-		json.Unmarshal(VmCustomConfig.ToJson(), &VirtualMachineCustomConfiguration)
-		models.Database.Model(&models.VirtualMachine{}).Where("id = ?").Find(&VirtualMachine)
+		if DecodeError := json.Unmarshal(VmCustomConfig.ToJson(), &VirtualMachineCustomConfiguration); DecodeError != nil {
+			Logger.Error("Failed to Decode Custom Configuration", zap.String("Virtual Machine ID", VmId), zap.Error(DecodeError))
+			RequestContext.JSON(http.StatusInternalServerError, gin.H{"Error": "Failed to Save Custom Configuration"})
+			return
+		}
+		Stored, LookupError := models.GetVirtualMachineByID(VmId)
+		if LookupError != nil {
+			Logger.Error("Failed to Find Virtual Machine Database Record", zap.String("Virtual Machine ID", VmId), zap.Error(LookupError))
+			RequestContext.JSON(http.StatusNotFound, gin.H{"Error": "Virtual Server Does Not Exist"})
+			return
+		}
+		VirtualMachine = *Stored
 
 		// Applying Custom Configuration, that Customer has been Specified Initially
 
@@ -555,6 +629,7 @@ func DeployVirtualMachineRestController(RequestContext *gin.Context) {
 				zap.Error(Error))
 		}
 
+		recordVirtualMachineEvent(VmId, models.EventConfigured, "Custom Configuration has been Applied")
 		RequestContext.JSON(http.StatusOK, gin.H{"Status": "Applied",
 			"IPAddress": VmInfo.IPAddress, "SshInfo": VmInfo.SshInfo})
 
@@ -575,19 +650,31 @@ func StartVirtualMachineRestController(RequestContext *gin.Context) {
 	// Updating the State of the Virtual Machine to `NotReady` in order to prevent other operations on this Virtual Machine
 
 	var VirtualMachine models.VirtualMachine
-	models.Database.Model(&models.VirtualMachine{}).Where(
-		"id = ?", VirtualMachineId).Find(&VirtualMachine)
+	Gorm := models.Database.Model(&models.VirtualMachine{}).Where(
+		"id = ?", VirtualMachineId).First(&VirtualMachine)
+
+	switch FindError := models.TranslateNotFound(Gorm.Error); {
+	case errors.Is(FindError, models.ErrNotFound):
+		RequestContext.JSON(http.StatusBadRequest, gin.H{"Error": "VM Does not Exist."})
+		return
+	case FindError != nil:
+		Logger.Error("Failed to Retrieve Virtual Machine", zap.String("Virtual Machine ID", VirtualMachineId), zap.Error(FindError))
+		RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": "Failed to Retrieve Virtual Machine"})
+		return
+	}
 
 	VmManager := deploy.NewVirtualMachineManager(*Client.Client)
 	Vm, VmError := VmManager.GetVirtualMachine(VirtualMachineId, strconv.Itoa(CustomerId))
 
 	if VmError != nil {
 		RequestContext.JSON(http.StatusBadRequest, gin.H{"Error": "VM Does not Exist."})
+		return
 	}
 	StartedError := VmManager.StartVirtualMachine(Vm)
 
 	switch StartedError {
 	case nil:
+		recordVirtualMachineEvent(VirtualMachineId, models.EventPoweredOn, "Virtual Machine has been Started")
 		RequestContext.JSON(http.StatusOK, gin.H{"Status": "Started"})
 	default:
 		RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": StartedError})
@@ -609,13 +696,15 @@ func RebootVirtualMachineRestController(RequestContext *gin.Context) {
 		return
 	}
 
-	Rebooted := NewVmManager.RebootVirtualMachine(Vm)
+	RebootError := NewVmManager.RebootVirtualMachine(Vm)
 
-	switch Rebooted {
-	case true:
+	switch {
+	case RebootError == nil:
+		recordVirtualMachineEvent(VirtualMachineId, models.EventRebooted, "Virtual Machine has been Rebooted")
 		RequestContext.JSON(http.StatusOK, gin.H{"Status": "Rebooted"})
-	case false:
-		RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": VmError})
+	default:
+		RequestContext.JSON(http.StatusBadGateway,
+			gin.H{"Error": fmt.Sprintf("Failed to Reboot the Server, %s", RebootError)})
 	}
 }
 
@@ -674,17 +763,23 @@ func RemoveVirtualMachineRestController(RequestContext *gin.Context) {
 	case StartedError == nil && Started:
 
 		var VirtualMachine models.VirtualMachine
-		models.Database.Model(&models.VirtualMachine{}).Where(
-
-			"id = ?", VirtualMachineId).Find(&VirtualMachine)
-		Deleted, Error := VirtualMachine.Delete()
-
-		if Error != nil {
-			Deleted.Rollback()
-			Logger.Error(
-				"Failed to Delete Virtual Machine Object with ID: `%s`, Error: %s",
-				zap.String("Virtual Machine ID", VirtualMachineId), zap.Error(Error))
+		Gorm := models.Database.Model(&models.VirtualMachine{}).Where(
+			"id = ?", VirtualMachineId).First(&VirtualMachine)
+		if FindError := models.TranslateNotFound(Gorm.Error); FindError != nil {
+			Logger.Error("Failed to Retrieve Record of the Destroyed Virtual Machine",
+				zap.String("Virtual Machine ID", VirtualMachineId), zap.Error(FindError))
+			RequestContext.JSON(http.StatusCreated, gin.H{"Operation": "Success"})
+			return
 		}
+		// Record is Kept Soft Deleted, so the Virtual Machine is still Billed until now (See `models.DeleteVirtualMachineRecords`)
+		if DeleteError := models.DeleteVirtualMachineRecords(VirtualMachine.ID); DeleteError != nil {
+			Logger.Error("Failed to Delete Record of the Destroyed Virtual Machine",
+				zap.String("Virtual Machine ID", VirtualMachineId), zap.Error(DeleteError))
+			RequestContext.JSON(http.StatusInternalServerError,
+				gin.H{"Error": "Server has been Destroyed, but its Record has not been Deleted"})
+			return
+		}
+		recordVirtualMachineEvent(VirtualMachineId, models.EventDestroyed, "Virtual Machine has been Destroyed")
 		RequestContext.JSON(http.StatusCreated, gin.H{"Operation": "Success"})
 	}
 }
@@ -702,15 +797,19 @@ func RebootGuestOSRestController(RequestContext *gin.Context) {
 			gin.H{"Error": "Virtual Machine Server not found"})
 		return
 	}
-	TimeoutContext, CancelFunc := context.WithTimeout(context.Background(), time.Second*20)
-	defer CancelFunc()
+	GuestManager := guest.NewVirtualMachineGuestManager(*Client.Client)
+	RebootedError := GuestManager.RebootGuest(VirtualMachine, false)
 
-	RebootedError := VirtualMachine.RebootGuest(TimeoutContext)
+	if errors.Is(RebootedError, guest.ErrToolsNotRunning) {
+		RequestContext.JSON(http.StatusBadRequest, gin.H{"Error": "VMware Tools are not Running on the Virtual Machine, Use Hard Reboot instead"})
+		return
+	}
 	if RebootedError != nil {
 		Logger.Error("Failed to Reboot OS on Virtual Machine Server, Error: %s", zap.Error(RebootedError))
 		RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": "Failed to Reboot Operational System"})
 		return
 	}
+	recordVirtualMachineEvent(VirtualMachineId, models.EventRebooted, "Operational System has been Rebooted")
 	RequestContext.JSON(http.StatusOK, gin.H{"Status": "Rebooted"})
 }
 
@@ -735,6 +834,7 @@ func StartGuestOSRestController(RequestContext *gin.Context) {
 		RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": "Failed to Reboot Operational System"})
 		return
 	}
+	recordVirtualMachineEvent(VirtualMachineId, models.EventPoweredOn, "Operational System has been Started")
 	RequestContext.JSON(http.StatusOK, gin.H{"Status": "Started"})
 }
 
@@ -760,5 +860,6 @@ func ShutdownGuestOsRestController(RequestContext *gin.Context) {
 		RequestContext.JSON(http.StatusBadGateway, gin.H{"Error": "Failed to shutdown Operational System"})
 		return
 	}
+	recordVirtualMachineEvent(VirtualMachineId, models.EventPoweredOff, "Operational System has been Shutdown")
 	RequestContext.JSON(http.StatusOK, gin.H{"Status": "Shutdowned"})
 }